@@ -0,0 +1,160 @@
+package bulk
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Supported file formats
+const (
+	FormatCSV     = "csv"
+	FormatPain001 = "pain.001"
+)
+
+// MaxPayments caps the number of payments in one file
+const MaxPayments = 10000
+
+// maxReferenceLength is the longest end-to-end reference ISO 20022 allows
+const maxReferenceLength = 35
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Payment is one transfer requested by a bulk file
+type Payment struct {
+	Row           int     // Position in the file: the CSV line or the n-th pain.001 transaction
+	Reference     string  // End-to-end reference, unique within the file
+	FromAccountID string  // Account debited
+	ToAccountID   string  // Account credited
+	Amount        float64 // Amount transferred
+	Currency      string  // ISO 4217 currency, empty for the currency of FromAccountID
+	Description   string  // Free text copied onto the transfer
+}
+
+// File is a parsed bulk payment file
+type File struct {
+	Format    string    // FormatCSV or FormatPain001
+	MessageID string    // pain.001 message identification, empty for CSV
+	Payments  []Payment // Payments in file order
+}
+
+// Total returns the sum of the payment amounts
+func (f *File) Total() float64 {
+	total := 0.0
+	for _, payment := range f.Payments {
+		total += payment.Amount
+	}
+	return math.Round(total*100) / 100
+}
+
+// RowError describes why a payment was refused. Row 0 refers to the file as a whole.
+type RowError struct {
+	Row       int    `json:"row"`
+	Reference string `json:"reference,omitempty"`
+	Message   string `json:"message"`
+}
+
+// ValidationError lists every problem found in a file. Files are accepted or refused as a whole.
+type ValidationError struct {
+	Errors []RowError
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Errors) == 1 {
+		return fmt.Sprintf("bulk file rejected: %s", e.Errors[0].describe())
+	}
+	return fmt.Sprintf("bulk file rejected: %d errors, first: %s", len(e.Errors), e.Errors[0].describe())
+}
+
+func (e RowError) describe() string {
+	if e.Row == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("row %d: %s", e.Row, e.Message)
+}
+
+// DetectFormat picks the format from the file name, falling back to the content
+func DetectFormat(name string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return FormatCSV
+	case ".xml":
+		return FormatPain001
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+		return FormatPain001
+	}
+	return FormatCSV
+}
+
+// Parse reads a bulk file and validates every payment in it. A *ValidationError lists all the
+// rows that were refused; any other error means the file could not be read at all.
+func Parse(format string, data []byte) (*File, error) {
+	var file *File
+	var errs []RowError
+	var err error
+	switch format {
+	case FormatCSV:
+		file, errs, err = parseCSV(data)
+	case FormatPain001:
+		file, errs, err = parsePain001(data)
+	default:
+		return nil, fmt.Errorf("unsupported bulk file format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	errs = append(errs, validate(file)...)
+	if len(errs) > 0 {
+		return nil, &ValidationError{Errors: errs}
+	}
+	return file, nil
+}
+
+// validate checks each payment and the file as a whole
+func validate(file *File) []RowError {
+	var errs []RowError
+	if len(file.Payments) == 0 {
+		errs = append(errs, RowError{Message: "the file contains no payments"})
+	}
+	if len(file.Payments) > MaxPayments {
+		errs = append(errs, RowError{Message: fmt.Sprintf("the file contains %d payments, at most %d are allowed", len(file.Payments), MaxPayments)})
+	}
+
+	seen := make(map[string]int, len(file.Payments))
+	for _, payment := range file.Payments {
+		fail := func(format string, args ...any) {
+			errs = append(errs, RowError{Row: payment.Row, Reference: payment.Reference, Message: fmt.Sprintf(format, args...)})
+		}
+		if payment.FromAccountID == "" || payment.ToAccountID == "" {
+			fail("from and to accounts are required")
+		} else if payment.FromAccountID == payment.ToAccountID {
+			fail("cannot transfer to the same account")
+		}
+		if math.IsNaN(payment.Amount) || math.IsInf(payment.Amount, 0) {
+			fail("amount is not a number: %v", payment.Amount)
+		} else if payment.Amount <= 0 {
+			fail("amount must be positive")
+		} else if math.Abs(payment.Amount*100-math.Round(payment.Amount*100)) > 1e-6 {
+			fail("amount has more than two decimals: %v", payment.Amount)
+		}
+		if payment.Currency != "" && !currencyPattern.MatchString(payment.Currency) {
+			fail("invalid currency %q", payment.Currency)
+		}
+		if len(payment.Reference) > maxReferenceLength {
+			fail("reference is longer than %d characters", maxReferenceLength)
+		}
+		if payment.Reference != "" {
+			if first, ok := seen[payment.Reference]; ok {
+				fail("reference %q is already used on row %d", payment.Reference, first)
+			} else {
+				seen[payment.Reference] = payment.Row
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,103 @@
+package bulk
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const samplePain001 = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.03">
+  <CstmrCdtTrfInitn>
+    <GrpHdr>
+      <MsgId>PAYROLL-2025-03</MsgId>
+      <CreDtTm>2025-03-28T09:00:00</CreDtTm>
+      <NbOfTxs>2</NbOfTxs>
+      <CtrlSum>3250.50</CtrlSum>
+      <InitgPty><Nm>Acme Ltd</Nm></InitgPty>
+    </GrpHdr>
+    <PmtInf>
+      <PmtInfId>SALARIES</PmtInfId>
+      <DbtrAcct><Id><Othr><Id>ACC000000001</Id></Othr></Id></DbtrAcct>
+      <CdtTrfTxInf>
+        <PmtId><EndToEndId>EMP-001</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="USD">1500.00</InstdAmt></Amt>
+        <CdtrAcct><Id><Othr><Id>ACC000000002</Id></Othr></Id></CdtrAcct>
+        <RmtInf><Ustrd>March salary</Ustrd></RmtInf>
+      </CdtTrfTxInf>
+      <CdtTrfTxInf>
+        <PmtId><EndToEndId>EMP-002</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="USD">1750.50</InstdAmt></Amt>
+        <CdtrAcct><Id><Othr><Id>ACC000000003</Id></Othr></Id></CdtrAcct>
+      </CdtTrfTxInf>
+    </PmtInf>
+  </CstmrCdtTrfInitn>
+</Document>`
+
+// TestParseCSV tests reading a CSV file with columns in any order
+func TestParseCSV(t *testing.T) {
+	data := []byte("reference,amount,from_account_id,to_account_id,description\n" +
+		"EMP-001,1500.00,ACC000000001,ACC000000002,March salary\n" +
+		"\n" +
+		"EMP-002,1750.5,ACC000000001,ACC000000003,\n")
+
+	file, err := Parse(FormatCSV, data)
+	assert.NoError(t, err)
+	assert.Len(t, file.Payments, 2)
+	assert.Equal(t, Payment{Row: 2, Reference: "EMP-001", FromAccountID: "ACC000000001", ToAccountID: "ACC000000002",
+		Amount: 1500, Description: "March salary"}, file.Payments[0])
+	assert.Equal(t, 4, file.Payments[1].Row)
+	assert.Equal(t, 3250.5, file.Total())
+
+	_, err = Parse(FormatCSV, []byte("from,to,amount\nACC1,ACC2,10\n"))
+	assert.Error(t, err)
+}
+
+// TestParsePain001 tests reading a pain.001 file and checking its control totals
+func TestParsePain001(t *testing.T) {
+	assert.Equal(t, FormatPain001, DetectFormat("payroll", []byte(samplePain001)))
+
+	file, err := Parse(FormatPain001, []byte(samplePain001))
+	assert.NoError(t, err)
+	assert.Equal(t, "PAYROLL-2025-03", file.MessageID)
+	assert.Len(t, file.Payments, 2)
+	assert.Equal(t, Payment{Row: 1, Reference: "EMP-001", FromAccountID: "ACC000000001", ToAccountID: "ACC000000002",
+		Amount: 1500, Currency: "USD", Description: "March salary"}, file.Payments[0])
+
+	// a control total that does not match refuses the whole file
+	var validation *ValidationError
+	_, err = Parse(FormatPain001, []byte(strings.Replace(samplePain001, "<NbOfTxs>2", "<NbOfTxs>3", 1)))
+	assert.True(t, errors.As(err, &validation))
+	_, err = Parse(FormatPain001, []byte(strings.Replace(samplePain001, "3250.50", "3250.00", 1)))
+	assert.True(t, errors.As(err, &validation))
+	assert.Equal(t, 0, validation.Errors[0].Row)
+
+	_, err = Parse(FormatPain001, []byte("<Document><CstmrCdtTrfInitn>"))
+	assert.Error(t, err)
+}
+
+// TestParseRejectsWholeFile tests that every invalid row is reported and nothing is accepted
+func TestParseRejectsWholeFile(t *testing.T) {
+	data := []byte("from_account_id,to_account_id,amount,currency,reference\n" +
+		"ACC1,ACC2,10.00,USD,A\n" +
+		"ACC1,ACC1,10.00,USD,B\n" +
+		"ACC1,ACC3,-5,USD,C\n" +
+		"ACC1,ACC3,1.005,usdollar,D\n" +
+		"ACC1,ACC4,ten,USD,E\n" +
+		"ACC1,ACC5,10.00,USD,A\n" +
+		"ACC1,ACC6,NaN,USD,F\n" +
+		"ACC1,ACC7,+Inf,USD,G\n")
+
+	file, err := Parse(FormatCSV, data)
+	assert.Nil(t, file)
+	var validation *ValidationError
+	assert.True(t, errors.As(err, &validation))
+
+	rows := []int{}
+	for _, rowErr := range validation.Errors {
+		rows = append(rows, rowErr.Row)
+	}
+	assert.Equal(t, []int{6, 3, 4, 5, 5, 7, 8, 9}, rows)
+}
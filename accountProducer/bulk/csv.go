@@ -0,0 +1,83 @@
+package bulk
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// csvColumns are the columns a CSV file may have; the header names them in any order
+var csvColumns = []string{"from_account_id", "to_account_id", "amount", "currency", "reference", "description"}
+
+// parseCSV reads a CSV file with a header row. from_account_id, to_account_id and amount are
+// required; currency, reference and description are optional.
+func parseCSV(data []byte) (*File, []RowError, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return &File{Format: FormatCSV}, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range csvColumns[:3] {
+		if _, ok := index[required]; !ok {
+			return nil, nil, fmt.Errorf("csv header must include %s", strings.Join(csvColumns[:3], ", "))
+		}
+	}
+
+	file := &File{Format: FormatCSV}
+	var errs []RowError
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			errs = append(errs, RowError{Row: parseErr.Line, Message: parseErr.Err.Error()})
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read csv: %w", err)
+		}
+		row, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := index[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		if strings.Join(record, "") == "" {
+			continue
+		}
+
+		payment := Payment{
+			Row:           row,
+			Reference:     field("reference"),
+			FromAccountID: field("from_account_id"),
+			ToAccountID:   field("to_account_id"),
+			Currency:      strings.ToUpper(field("currency")),
+			Description:   field("description"),
+		}
+		amount, err := strconv.ParseFloat(field("amount"), 64)
+		if err != nil {
+			errs = append(errs, RowError{Row: row, Reference: payment.Reference, Message: fmt.Sprintf("invalid amount %q", field("amount"))})
+			continue
+		}
+		payment.Amount = amount
+		file.Payments = append(file.Payments, payment)
+	}
+	return file, errs, nil
+}
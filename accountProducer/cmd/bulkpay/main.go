@@ -0,0 +1,177 @@
+// Command bulkpay submits bulk payment files (CSV or ISO 20022 pain.001) to the account
+// producer and reports on batches already submitted.
+//
+//	bulkpay -check payroll.xml            validate the file locally without sending it
+//	bulkpay payroll.xml                   submit the file and print the batch
+//	bulkpay -status <batch-id>            print the per-row outcome of a batch
+package main
+
+import (
+	"accountProducer/bulk"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func main() {
+	server := flag.String("url", envOr("BULKPAY_URL", "http://localhost:9091"), "Base URL of the account producer")
+	format := flag.String("format", "", "File format, csv or pain.001; detected from the file when omitted")
+	check := flag.Bool("check", false, "Only validate the file locally")
+	status := flag.String("status", "", "Print the status report of this batch instead of submitting a file")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: bulkpay [flags] FILE | bulkpay -status BATCH_ID\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	client := &http.Client{Timeout: 2 * time.Minute}
+	var err error
+	switch {
+	case *status != "":
+		err = report(client, *server, *status)
+	case flag.NArg() == 1 && *check:
+		err = validate(flag.Arg(0), *format)
+	case flag.NArg() == 1:
+		err = submit(client, *server, flag.Arg(0), *format)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "bulkpay:", err)
+		os.Exit(1)
+	}
+}
+
+// validate parses the file with the same rules the server applies
+func validate(path, format string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if format == "" {
+		format = bulk.DetectFormat(path, data)
+	}
+	file, err := bulk.Parse(format, data)
+	var validation *bulk.ValidationError
+	if errors.As(err, &validation) {
+		printRowErrors(validation.Errors)
+		return fmt.Errorf("%d problems found", len(validation.Errors))
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s file OK: %d payments, total %.2f\n", file.Format, len(file.Payments), file.Total())
+	return nil
+}
+
+// submit uploads the file and prints the accepted batch, or the rows that were refused
+func submit(client *http.Client, server, path, format string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	query := url.Values{"filename": {filepath.Base(path)}}
+	if format != "" {
+		query.Set("format", format)
+	}
+	resp, err := client.Post(server+"/batches?"+query.Encode(), "application/octet-stream", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		var batch struct {
+			ID     string  `json:"id"`
+			Status string  `json:"status"`
+			Count  int     `json:"count"`
+			Total  float64 `json:"total"`
+		}
+		if err := json.Unmarshal(body, &batch); err != nil {
+			return err
+		}
+		fmt.Printf("batch %s %s: %d payments, total %.2f\n", batch.ID, batch.Status, batch.Count, batch.Total)
+		return nil
+	case http.StatusUnprocessableEntity:
+		var rejected struct {
+			Msg    string          `json:"msg"`
+			Errors []bulk.RowError `json:"errors"`
+		}
+		if err := json.Unmarshal(body, &rejected); err != nil {
+			return err
+		}
+		printRowErrors(rejected.Errors)
+		return errors.New(rejected.Msg)
+	default:
+		return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+}
+
+// report prints the outcome of every row of a batch
+func report(client *http.Client, server, id string) error {
+	resp, err := client.Get(server + "/batches/" + url.PathEscape(id))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+
+	var batch struct {
+		ID      string         `json:"id"`
+		Summary map[string]int `json:"summary"`
+		Rows    []struct {
+			Row           int     `json:"row"`
+			Reference     string  `json:"reference"`
+			TransactionID string  `json:"transaction_id"`
+			ToAccountID   string  `json:"to_account_id"`
+			Amount        float64 `json:"amount"`
+			Status        string  `json:"status"`
+			Error         string  `json:"error"`
+		} `json:"rows"`
+	}
+	if err := json.Unmarshal(body, &batch); err != nil {
+		return err
+	}
+	fmt.Printf("batch %s: %v\n", batch.ID, batch.Summary)
+	for _, row := range batch.Rows {
+		fmt.Printf("%6d  %-20s %-16s %12.2f  %-15s %s %s\n", row.Row, row.Reference, row.ToAccountID, row.Amount, row.Status, row.TransactionID, row.Error)
+	}
+	return nil
+}
+
+func printRowErrors(errs []bulk.RowError) {
+	for _, rowErr := range errs {
+		if rowErr.Row == 0 {
+			fmt.Printf("file: %s\n", rowErr.Message)
+			continue
+		}
+		fmt.Printf("row %d %s: %s\n", rowErr.Row, rowErr.Reference, rowErr.Message)
+	}
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
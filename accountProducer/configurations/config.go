@@ -1,14 +1,22 @@
 package configurations
 
 import (
+	"strings"
+
 	"github.com/nicholasjackson/env"
 )
 
 var appConfig *appConfigs
 var goUri *string = env.String("GO_URI", false, "0.0.0.0:9091", "Bind address for the app server")
+var eventCodec *string = env.String("EVENT_CODEC", false, "json", "Encoding of the events published on account-creation and transaction: json or protobuf")
+var kafkaBroker *string = env.String("KAFKA_BROKER", false, "kafka:9092", "Comma-separated Kafka brokers events are published to")
+var topicMapFile *string = env.String("TOPIC_MAP_FILE", false, "", "JSON topic map naming the topics on the cluster, empty for the built-in names")
 
 type appConfigs struct {
-	appURI string
+	appURI       string
+	eventCodec   string
+	kafkaBrokers []string
+	topicMapFile string
 }
 
 func NewAppConfig() (*appConfigs, error) {
@@ -22,7 +30,10 @@ func NewAppConfig() (*appConfigs, error) {
 	}
 
 	appConfig = &appConfigs{
-		appURI: *goUri,
+		appURI:       *goUri,
+		eventCodec:   *eventCodec,
+		kafkaBrokers: strings.Split(*kafkaBroker, ","),
+		topicMapFile: *topicMapFile,
 	}
 	return appConfig, nil
 }
@@ -31,3 +42,18 @@ func NewAppConfig() (*appConfigs, error) {
 func (apconfig *appConfigs) GetAppURI() *string {
 	return &apconfig.appURI
 }
+
+// gets the name of the codec events are published with
+func (apconfig *appConfigs) GetEventCodec() string {
+	return apconfig.eventCodec
+}
+
+// gets the brokers of the Kafka cluster
+func (apconfig *appConfigs) GetKafkaBrokers() []string {
+	return apconfig.kafkaBrokers
+}
+
+// gets the topic map file, empty when the topics keep their built-in names
+func (apconfig *appConfigs) GetTopicMapFile() string {
+	return apconfig.topicMapFile
+}
@@ -0,0 +1,28 @@
+package configurations
+
+import "github.com/nicholasjackson/env" // Importing env package for environment variable parsing
+
+// SchedulerConfig holds the business-day calendar used to run standing orders.
+type SchedulerConfig struct {
+	HolidayCalendarFile string // HolidayCalendarFile lists holidays, one YYYY-MM-DD per line; empty for none
+	WeekendDays         string // WeekendDays is a comma separated list of non-business weekdays (e.g. "Sat,Sun")
+	RollConvention      string // RollConvention is "following" or "preceding"
+}
+
+// NewSchedulerConfig creates a new SchedulerConfig instance by parsing environment variables.
+func NewSchedulerConfig() (*SchedulerConfig, error) {
+	var holidayFile *string = env.String("HOLIDAY_CALENDAR_FILE", false, "", "File listing the holidays standing orders skip")
+	var weekendDays *string = env.String("WEEKEND_DAYS", false, "Sat,Sun", "Weekdays standing orders skip")
+	var rollConvention *string = env.String("ROLL_CONVENTION", false, "following", "Whether runs on holidays move to the following or preceding business day")
+
+	// Parse environment variables; returns an error if parsing fails (e.g., invalid format)
+	if err := env.Parse(); err != nil {
+		return nil, err
+	}
+
+	return &SchedulerConfig{
+		HolidayCalendarFile: *holidayFile,
+		WeekendDays:         *weekendDays,
+		RollConvention:      *rollConvention,
+	}, nil
+}
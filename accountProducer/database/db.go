@@ -3,6 +3,7 @@ package database
 import (
 	"accountProducer/models" // Importing the models package to use TransactionLedger struct
 	"context"                // Importing context for handling request-scoped values and cancellation
+	"time"                   // Importing time for scheduling queries
 )
 
 // Database defines the interface for database operations related to account transactions.
@@ -26,4 +27,55 @@ type Database interface {
 	// package, which represent the transaction records, or an error if the query fails (e.g., due to
 	// database unavailability or invalid account number).
 	GetTransactionsByAccountNumber(ctx context.Context, accountNumber string) (*[]models.TransactionLedger, error)
+
+	// InsertStandingOrder stores a new recurring transfer definition.
+	// Returns an error if the insert fails (e.g., due to a duplicate ID).
+	InsertStandingOrder(ctx context.Context, order *models.StandingOrder) error
+
+	// GetStandingOrder retrieves a standing order by ID.
+	// Returns nil without an error if no order has that ID.
+	GetStandingOrder(ctx context.Context, id string) (*models.StandingOrder, error)
+
+	// GetStandingOrdersByAccountNumber retrieves every standing order paying out of the given account.
+	GetStandingOrdersByAccountNumber(ctx context.Context, accountNumber string) ([]models.StandingOrder, error)
+
+	// ReplaceStandingOrder overwrites a standing order.
+	// Returns false without an error if no order has that ID.
+	ReplaceStandingOrder(ctx context.Context, order *models.StandingOrder) (bool, error)
+
+	// DeleteStandingOrder removes a standing order.
+	// Returns false without an error if no order has that ID.
+	DeleteStandingOrder(ctx context.Context, id string) (bool, error)
+
+	// GetDueStandingOrders retrieves the active standing orders whose next execution is at or before now.
+	GetDueStandingOrders(ctx context.Context, now time.Time) ([]models.StandingOrder, error)
+
+	// AdvanceStandingOrder moves a standing order to its following occurrence, but only if its next
+	// occurrence is still the one that was just executed. This compare-and-set lets several scheduler
+	// replicas race for the same order without double-advancing it. Returns true if this call advanced it.
+	AdvanceStandingOrder(ctx context.Context, order *models.StandingOrder, executed time.Time) (bool, error)
+
+	// InsertBatch stores an accepted bulk payment file and its rows.
+	InsertBatch(ctx context.Context, batch *models.Batch) error
+
+	// GetBatch retrieves a batch by ID. Returns nil without an error if no batch has that ID.
+	GetBatch(ctx context.Context, id string) (*models.Batch, error)
+
+	// GetBatchByChecksum retrieves the batch created from a file with the given SHA-256, or nil.
+	GetBatchByChecksum(ctx context.Context, checksum string) (*models.Batch, error)
+
+	// ReplaceBatch overwrites a batch, e.g. once its rows have been published.
+	ReplaceBatch(ctx context.Context, batch *models.Batch) error
+
+	// GetBatchOutcomes returns the ledger status of every transaction of a batch the ledger has
+	// recorded so far, keyed by transaction ID: "completed", or "failed" for rejected transactions.
+	GetBatchOutcomes(ctx context.Context, batchID string) (map[string]string, error)
+
+	// GetAccountSummary retrieves the summary the ledger service projected for an account.
+	// Returns nil without an error if no message about the account has been projected yet.
+	GetAccountSummary(ctx context.Context, accountNumber string) (*models.AccountSummaryDocument, error)
+
+	// GetProjectionCheckpoint retrieves how far a projection has read its topics, or nil if it has
+	// not processed any message yet.
+	GetProjectionCheckpoint(ctx context.Context, projection string) (*models.ProjectionCheckpoint, error)
 }
@@ -110,3 +110,243 @@ func (mango *MongoDB) GetTransactionsByAccountNumber(ctx context.Context, accoun
 	// Return the slice of transactions
 	return &transactions, nil
 }
+
+// InsertStandingOrder stores a new standing order in the "standing_orders" collection.
+// Implements the Database interface's method. Returns an error if the insert fails.
+func (mango *MongoDB) InsertStandingOrder(ctx context.Context, order *models.StandingOrder) error {
+	// Set a 5-second timeout for the insert
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := mango.Database.Collection("standing_orders").InsertOne(ctx, order)
+	if err != nil {
+		(*mango.loggs).Error("Failed to insert standing order", "ID", order.ID, "Error", err)
+		return fmt.Errorf("failed to insert standing order %s: %w", order.ID, err)
+	}
+	return nil
+}
+
+// GetStandingOrder retrieves a standing order by ID from the "standing_orders" collection.
+// Returns nil without an error if the order does not exist.
+func (mango *MongoDB) GetStandingOrder(ctx context.Context, id string) (*models.StandingOrder, error) {
+	// Set a 5-second timeout for the query
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var order models.StandingOrder
+	err := mango.Database.Collection("standing_orders").FindOne(ctx, bson.M{"_id": id}).Decode(&order)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get standing order %s: %w", id, err)
+	}
+	return &order, nil
+}
+
+// GetStandingOrdersByAccountNumber retrieves every standing order paying out of the given account,
+// ordered by next execution.
+func (mango *MongoDB) GetStandingOrdersByAccountNumber(ctx context.Context, accountNumber string) ([]models.StandingOrder, error) {
+	return mango.findStandingOrders(ctx, bson.M{"from_account_id": accountNumber})
+}
+
+// ReplaceStandingOrder overwrites a standing order. Returns false if the order does not exist.
+func (mango *MongoDB) ReplaceStandingOrder(ctx context.Context, order *models.StandingOrder) (bool, error) {
+	// Set a 5-second timeout for the update
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := mango.Database.Collection("standing_orders").ReplaceOne(ctx, bson.M{"_id": order.ID}, order)
+	if err != nil {
+		return false, fmt.Errorf("failed to replace standing order %s: %w", order.ID, err)
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// DeleteStandingOrder removes a standing order. Returns false if the order does not exist.
+func (mango *MongoDB) DeleteStandingOrder(ctx context.Context, id string) (bool, error) {
+	// Set a 5-second timeout for the delete
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := mango.Database.Collection("standing_orders").DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return false, fmt.Errorf("failed to delete standing order %s: %w", id, err)
+	}
+	return result.DeletedCount > 0, nil
+}
+
+// GetDueStandingOrders retrieves the active standing orders whose next execution is at or before now.
+func (mango *MongoDB) GetDueStandingOrders(ctx context.Context, now time.Time) ([]models.StandingOrder, error) {
+	return mango.findStandingOrders(ctx, bson.M{"active": true, "next_execution": bson.M{"$lte": now}})
+}
+
+// AdvanceStandingOrder moves order to the next occurrence stored on it, matching on the occurrence
+// that was executed so that only one scheduler replica advances each occurrence.
+func (mango *MongoDB) AdvanceStandingOrder(ctx context.Context, order *models.StandingOrder, executed time.Time) (bool, error) {
+	// Set a 5-second timeout for the update
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": order.ID, "next_occurrence": executed}
+	update := bson.M{"$set": bson.M{
+		"next_occurrence": order.NextOccurrence,
+		"next_execution":  order.NextExecution,
+		"last_execution":  order.LastExecution,
+		"active":          order.Active,
+		"updated_at":      order.UpdatedAt,
+	}}
+	result, err := mango.Database.Collection("standing_orders").UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to advance standing order %s: %w", order.ID, err)
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// InsertBatch stores an accepted bulk payment file in the "batches" collection.
+func (mango *MongoDB) InsertBatch(ctx context.Context, batch *models.Batch) error {
+	// Set a 10-second timeout for the insert; batches can hold thousands of rows
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := mango.Database.Collection("batches").InsertOne(ctx, batch)
+	if err != nil {
+		(*mango.loggs).Error("Failed to insert batch", "ID", batch.ID, "Error", err)
+		return fmt.Errorf("failed to insert batch %s: %w", batch.ID, err)
+	}
+	return nil
+}
+
+// GetBatch retrieves a batch by ID from the "batches" collection.
+// Returns nil without an error if the batch does not exist.
+func (mango *MongoDB) GetBatch(ctx context.Context, id string) (*models.Batch, error) {
+	return mango.findBatch(ctx, bson.M{"_id": id})
+}
+
+// GetBatchByChecksum retrieves the batch created from a file with the given checksum, or nil.
+func (mango *MongoDB) GetBatchByChecksum(ctx context.Context, checksum string) (*models.Batch, error) {
+	return mango.findBatch(ctx, bson.M{"checksum": checksum})
+}
+
+// ReplaceBatch overwrites a batch in the "batches" collection.
+func (mango *MongoDB) ReplaceBatch(ctx context.Context, batch *models.Batch) error {
+	// Set a 10-second timeout for the update
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := mango.Database.Collection("batches").ReplaceOne(ctx, bson.M{"_id": batch.ID}, batch)
+	if err != nil {
+		return fmt.Errorf("failed to replace batch %s: %w", batch.ID, err)
+	}
+	return nil
+}
+
+// GetBatchOutcomes reads the ledger entries of a batch from the "transactions" collection and
+// the transactions the transaction service refused from "rejected_transactions".
+func (mango *MongoDB) GetBatchOutcomes(ctx context.Context, batchID string) (map[string]string, error) {
+	// Set a 10-second timeout for the queries
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	outcomes := make(map[string]string)
+	opts := options.Find().SetProjection(bson.M{"transaction_id": 1})
+	for collection, status := range map[string]string{"rejected_transactions": "failed", "transactions": "completed"} {
+		cursor, err := mango.Database.Collection(collection).Find(ctx, bson.M{"batch_id": batchID}, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %s of batch %s: %w", collection, batchID, err)
+		}
+		var entries []struct {
+			TransactionID string `bson:"transaction_id"`
+		}
+		err = cursor.All(ctx, &entries)
+		cursor.Close(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s of batch %s: %w", collection, batchID, err)
+		}
+		for _, entry := range entries {
+			// a transaction retried after a failure counts as completed
+			if outcomes[entry.TransactionID] != "completed" {
+				outcomes[entry.TransactionID] = status
+			}
+		}
+	}
+	return outcomes, nil
+}
+
+// GetAccountSummary retrieves an account's document from the "account_summaries" collection.
+// Returns nil without an error if the account has no summary yet.
+func (mango *MongoDB) GetAccountSummary(ctx context.Context, accountNumber string) (*models.AccountSummaryDocument, error) {
+	// Set a 5-second timeout for the query
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var summary models.AccountSummaryDocument
+	err := mango.Database.Collection("account_summaries").FindOne(ctx, bson.M{"_id": accountNumber}).Decode(&summary)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get account summary %s: %w", accountNumber, err)
+	}
+	return &summary, nil
+}
+
+// GetProjectionCheckpoint retrieves a projection's offsets from the "projection_checkpoints" collection.
+// Returns nil without an error if the projection has not saved any yet.
+func (mango *MongoDB) GetProjectionCheckpoint(ctx context.Context, projection string) (*models.ProjectionCheckpoint, error) {
+	// Set a 5-second timeout for the query
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var checkpoint models.ProjectionCheckpoint
+	err := mango.Database.Collection("projection_checkpoints").FindOne(ctx, bson.M{"_id": projection}).Decode(&checkpoint)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get checkpoint of %s: %w", projection, err)
+	}
+	return &checkpoint, nil
+}
+
+// findBatch returns the batch matching filter, or nil
+func (mango *MongoDB) findBatch(ctx context.Context, filter bson.M) (*models.Batch, error) {
+	// Set a 10-second timeout for the query
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var batch models.Batch
+	err := mango.Database.Collection("batches").FindOne(ctx, filter).Decode(&batch)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get batch: %w", err)
+	}
+	return &batch, nil
+}
+
+// findStandingOrders runs a query on the "standing_orders" collection ordered by next execution
+func (mango *MongoDB) findStandingOrders(ctx context.Context, filter bson.M) ([]models.StandingOrder, error) {
+	// Check if the database connection is initialized
+	if mango.Database == nil {
+		return nil, fmt.Errorf("database not initialized, call Connect first")
+	}
+
+	// Set a 10-second timeout for the query to prevent hanging
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "next_execution", Value: 1}})
+	cursor, err := mango.Database.Collection("standing_orders").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query standing orders: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	orders := []models.StandingOrder{}
+	if err = cursor.All(ctx, &orders); err != nil {
+		return nil, fmt.Errorf("failed to decode standing orders: %w", err)
+	}
+	return orders, nil
+}
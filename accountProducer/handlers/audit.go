@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"accountProducer/database"
+	"accountProducer/repositories"
+	"accountProducer/scheduler"
+	"context"
+	"shared/audit"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// TrackResources tells the audit recorder how to load the resources the API changes in place, so
+// that their state before and after every change is recorded. Money movements are only requested
+// here; their effect is recorded by the transaction service that applies them.
+func TrackResources(recorder *audit.Recorder, db database.Database, calendar *scheduler.Calendar, lobbs *hclog.Logger) {
+	orders := repositories.NewStandingOrderRepository(db, calendar, lobbs)
+	recorder.Track("/standing-orders/{id}", func(ctx context.Context, vars map[string]string) (any, error) {
+		return orders.GetStandingOrder(ctx, vars["id"])
+	})
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"accountProducer/bulk"
+	"accountProducer/database"
+	"accountProducer/repositories"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/hashicorp/go-hclog"
+)
+
+// maxBatchFileSize caps the size of an uploaded bulk file
+const maxBatchFileSize = 10 << 20
+
+type BatchHandler struct {
+	batchrepo repositories.BatchRepository
+	loggs     *hclog.Logger
+}
+
+// NewBatchHandler creates a new BatchHandler instance
+func NewBatchHandler(db database.Database, publisher repositories.BatchPublisher, lobbs *hclog.Logger) *BatchHandler {
+	return &BatchHandler{
+		batchrepo: repositories.NewBatchRepository(db, publisher, lobbs),
+		loggs:     lobbs,
+	}
+}
+
+// SubmitBatch godoc
+// @Summary Submit a bulk payment file
+// @Description Validates every row of a CSV or ISO 20022 pain.001 file and publishes one transfer per row with a shared batch ID. Files with an invalid row are refused as a whole. Upload the file as the request body or as the "file" field of a multipart form.
+// @Tags batches
+// @Accept text/csv,application/xml,multipart/form-data
+// @Produce json
+// @Param filename query string false "File name, used to detect the format"
+// @Param format query string false "csv or pain.001; detected when omitted"
+// @Success 202 {object} models.Batch
+// @Failure 400 {object} map[string]string "error: Unreadable file"
+// @Failure 409 {object} map[string]interface{} "success: false, msg, batch_id of the earlier submission"
+// @Failure 422 {object} map[string]interface{} "success: false, msg, errors: the rejected rows"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /batches [post]
+func (h *BatchHandler) SubmitBatch(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBatchFileSize)
+	fileName := r.URL.Query().Get("filename")
+	format := r.URL.Query().Get("format")
+
+	var body io.Reader = r.Body
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "multipart/form-data":
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, "Invalid request body, expected a file field", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		body = file
+		if fileName == "" {
+			fileName = header.Filename
+		}
+	case "text/csv":
+		if format == "" {
+			format = bulk.FormatCSV
+		}
+	case "application/xml", "text/xml":
+		if format == "" {
+			format = bulk.FormatPain001
+		}
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	batch, err := h.batchrepo.SubmitBatch(r.Context(), fileName, format, data)
+	var validation *bulk.ValidationError
+	switch {
+	case errors.As(err, &validation):
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"success": false,
+			"msg":     "Bulk file rejected, no payments were made",
+			"errors":  validation.Errors,
+		})
+		return
+	case errors.Is(err, repositories.ErrInvalidBatch):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, repositories.ErrDuplicateBatch):
+		writeJSON(w, http.StatusConflict, map[string]interface{}{
+			"success":  false,
+			"msg":      err.Error(),
+			"batch_id": batch.ID,
+		})
+		return
+	case err != nil:
+		http.Error(w, "Not able to submit batch", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, batch)
+}
+
+// GetBatch godoc
+// @Summary Get the status report of a batch
+// @Description Returns the batch with the outcome of every row ("completed", "failed", "pending" or "publish_failed") and a count per outcome.
+// @Tags batches
+// @Produce json
+// @Param id path string true "Batch ID"
+// @Success 200 {object} models.Batch
+// @Failure 404 {object} map[string]string "error: Batch not found"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /batches/{id} [get]
+func (h *BatchHandler) GetBatch(w http.ResponseWriter, r *http.Request) {
+	batch, err := h.batchrepo.GetBatchReport(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Not able to fetch batch", http.StatusInternalServerError)
+		return
+	}
+	if batch == nil {
+		http.Error(w, "Batch not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, batch)
+}
+
+func (h *BatchHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/batches", h.SubmitBatch).Methods("POST")
+	router.HandleFunc("/batches/{id}", h.GetBatch).Methods("GET")
+}
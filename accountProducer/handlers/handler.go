@@ -8,7 +8,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/hashicorp/go-hclog"
 )
@@ -80,7 +82,7 @@ func (h *AccountHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param transaction body models.Transaction true "Transaction details"
-// @Success 200 {object} map[string]interface{} "success: true, msg: Credit Transaction Successfully Recorded"
+// @Success 200 {object} map[string]interface{} "success: true, msg: Credit Transaction Successfully Recorded, transaction_id"
 // @Failure 400 {object} map[string]string "error: Invalid request body"
 // @Failure 500 {object} map[string]string "error: Internal server error or Kafka failure"
 // @Router /credit [post]
@@ -91,6 +93,7 @@ func (h *AccountHandler) CreditAmount(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
+	accept(&transaction, "deposit")
 
 	transactionInBytes, err := json.Marshal(transaction)
 	if err != nil {
@@ -107,8 +110,9 @@ func (h *AccountHandler) CreditAmount(w http.ResponseWriter, r *http.Request) {
 	}
 
 	response := map[string]interface{}{
-		"success": true,
-		"msg":     "Credit Transaction Successfully Recorded",
+		"success":        true,
+		"msg":            "Credit Transaction Successfully Recorded",
+		"transaction_id": transaction.ID,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -126,7 +130,7 @@ func (h *AccountHandler) CreditAmount(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param transaction body models.Transaction true "Transaction details"
-// @Success 200 {object} map[string]interface{} "success: true, msg: Withdraw Transaction Successfully Recorded"
+// @Success 200 {object} map[string]interface{} "success: true, msg: Withdraw Transaction Successfully Recorded, transaction_id"
 // @Failure 400 {object} map[string]string "error: Invalid request body"
 // @Failure 500 {object} map[string]string "error: Internal server error or Kafka failure"
 // @Router /debit [post]
@@ -137,6 +141,7 @@ func (h *AccountHandler) WithdrawAmount(w http.ResponseWriter, r *http.Request)
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
+	accept(&transaction, "withdrawal")
 
 	transactionInBytes, err := json.Marshal(transaction)
 	if err != nil {
@@ -153,8 +158,9 @@ func (h *AccountHandler) WithdrawAmount(w http.ResponseWriter, r *http.Request)
 	}
 
 	response := map[string]interface{}{
-		"success": true,
-		"msg":     "Withdraw Transaction Successfully Recorded",
+		"success":        true,
+		"msg":            "Withdraw Transaction Successfully Recorded",
+		"transaction_id": transaction.ID,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -172,7 +178,7 @@ func (h *AccountHandler) WithdrawAmount(w http.ResponseWriter, r *http.Request)
 // @Accept json
 // @Produce json
 // @Param transaction body models.Transaction true "Transaction details"
-// @Success 200 {object} map[string]interface{} "success: true, msg: Transfer Transaction Successfully Recorded"
+// @Success 200 {object} map[string]interface{} "success: true, msg: Transfer Transaction Successfully Recorded, transaction_id"
 // @Failure 400 {object} map[string]string "error: Invalid request body"
 // @Failure 500 {object} map[string]string "error: Internal server error or Kafka failure"
 // @Router /transfer [post]
@@ -183,6 +189,7 @@ func (h *AccountHandler) TransferAmount(w http.ResponseWriter, r *http.Request)
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
+	accept(&transaction, "transfer")
 
 	transactionInBytes, err := json.Marshal(transaction)
 	if err != nil {
@@ -199,8 +206,62 @@ func (h *AccountHandler) TransferAmount(w http.ResponseWriter, r *http.Request)
 	}
 
 	response := map[string]interface{}{
-		"success": true,
-		"msg":     "Transfer Transaction Successfully Recorded",
+		"success":        true,
+		"msg":            "Transfer Transaction Successfully Recorded",
+		"transaction_id": transaction.ID,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		fmt.Println(err)
+		http.Error(w, "Encode Not Happen Properly", http.StatusInternalServerError)
+		return
+	}
+}
+
+// ExternalTransfer godoc
+// @Summary Transfer an amount to an account at another bank
+// @Description Records an external transfer and sends it to Kafka. The amount is debited into the clearing suspense account and released once the clearing network settles or returns the payment.
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param transaction body models.Transaction true "Transaction details with creditor"
+// @Success 200 {object} map[string]interface{} "success: true, msg: External Transfer Successfully Recorded, transaction_id"
+// @Failure 400 {object} map[string]string "error: Invalid request body or missing creditor"
+// @Failure 500 {object} map[string]string "error: Internal server error or Kafka failure"
+// @Router /transfer/external [post]
+func (h *AccountHandler) ExternalTransfer(w http.ResponseWriter, r *http.Request) {
+	// decoding
+	var transaction models.Transaction
+	if err := json.NewDecoder(r.Body).Decode(&transaction); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if transaction.Creditor == nil {
+		http.Error(w, "Creditor is required", http.StatusBadRequest)
+		return
+	}
+	accept(&transaction, "external_transfer")
+	transaction.ToAccountID = ""
+
+	transactionInBytes, err := json.Marshal(transaction)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusInternalServerError)
+		return
+	}
+
+	// send the bytes to kafka
+	err = h.kafkactl.PushOrderToQueue("transaction", transactionInBytes)
+	if err != nil {
+		fmt.Println(err)
+		http.Error(w, "Not able to send message to kafka", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"success":        true,
+		"msg":            "External Transfer Successfully Recorded",
+		"transaction_id": transaction.ID,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -249,10 +310,124 @@ func (h *AccountHandler) FindTransactionHistory(w http.ResponseWriter, r *http.R
 
 }
 
+// GetAccountSummary godoc
+// @Summary Retrieve the dashboard summary of an account
+// @Description Returns the current balance, latest transactions, monthly totals and pending holds of an account, projected from the transaction outcome stream. The summary is eventually consistent; consistency gives the last Kafka offsets it reflects.
+// @Tags accounts
+// @Produce json
+// @Param accountNumber path string true "Account Number"
+// @Success 200 {object} models.AccountSummary
+// @Failure 404 {object} map[string]string "error: No summary for this account yet"
+// @Failure 500 {object} map[string]string "error: Failed to get account summary"
+// @Router /accounts/{accountNumber}/summary [get]
+func (h *AccountHandler) GetAccountSummary(w http.ResponseWriter, r *http.Request) {
+	accountNumber := mux.Vars(r)["accountNumber"]
+
+	summary, err := h.trrepo.FindAccountSummary(r.Context(), accountNumber)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get account summary: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if summary == nil {
+		http.Error(w, "No summary for this account yet", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(summary); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// ReverseTransaction godoc
+// @Summary Reverse or refund a transaction
+// @Description Requests a reversal of a completed transaction and sends it to Kafka. Deposits and withdrawals are reversed in full; transfers may be refunded partially.
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param transactionId path string true "Original transaction ID"
+// @Param reversal body models.Reversal false "Reversal details"
+// @Success 200 {object} map[string]interface{} "success: true, msg: Reversal Successfully Recorded, transaction_id"
+// @Failure 400 {object} map[string]string "error: Invalid transaction id or request body"
+// @Failure 500 {object} map[string]string "error: Internal server error or Kafka failure"
+// @Router /transactions/{transactionId}/reversal [post]
+func (h *AccountHandler) ReverseTransaction(w http.ResponseWriter, r *http.Request) {
+	originalID, err := uuid.Parse(mux.Vars(r)["transactionId"])
+	if err != nil {
+		http.Error(w, "Invalid transaction id", http.StatusBadRequest)
+		return
+	}
+
+	// decoding; an empty body reverses the full remaining amount
+	var reversal models.Reversal
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&reversal); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	if reversal.Amount < 0 {
+		http.Error(w, "Reversal amount cannot be negative", http.StatusBadRequest)
+		return
+	}
+
+	transaction := models.Transaction{
+		ID:                    uuid.New(),
+		Amount:                reversal.Amount,
+		TransactionType:       "reversal",
+		Description:           reversal.Description,
+		CreatedAt:             time.Now(),
+		Status:                "pending",
+		OriginalTransactionID: originalID.String(),
+	}
+
+	transactionInBytes, err := json.Marshal(transaction)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusInternalServerError)
+		return
+	}
+
+	// send the bytes to kafka
+	err = h.kafkactl.PushOrderToQueue("transaction", transactionInBytes)
+	if err != nil {
+		fmt.Println(err)
+		http.Error(w, "Not able to send message to kafka", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"success":        true,
+		"msg":            "Reversal Successfully Recorded",
+		"transaction_id": transaction.ID,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		fmt.Println(err)
+		http.Error(w, "Encode Not Happen Properly", http.StatusInternalServerError)
+		return
+	}
+}
+
+// accept prepares a transaction posted by a client for the "transaction" topic. The ID clients use
+// to look up or reverse it is always new and the type is that of the endpoint, so a request can
+// neither replay a transaction that was already reviewed or approved nor pose as one of the types
+// only the services publish, such as interest or reversals.
+func accept(transaction *models.Transaction, transactionType string) {
+	transaction.ID = uuid.New()
+	transaction.TransactionType = transactionType
+	transaction.OriginalTransactionID = ""
+	transaction.BatchID = ""
+}
+
 func (h *AccountHandler) RegisterRoutes(router *mux.Router) {
 	router.HandleFunc("/accounts", h.CreateUser).Methods("POST")
+	router.HandleFunc("/accounts/{accountNumber}/summary", h.GetAccountSummary).Methods("GET")
 	router.HandleFunc("/debit", h.WithdrawAmount).Methods("POST")
 	router.HandleFunc("/credit", h.CreditAmount).Methods("POST")
 	router.HandleFunc("/transfer", h.TransferAmount).Methods("POST")
+	router.HandleFunc("/transfer/external", h.ExternalTransfer).Methods("POST")
 	router.HandleFunc("/transactions/{accountNumber}", h.FindTransactionHistory).Methods("GET")
+	router.HandleFunc("/transactions/{transactionId}/reversal", h.ReverseTransaction).Methods("POST")
 }
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"accountProducer/database"
+	"accountProducer/models"
+	"accountProducer/repositories"
+	"accountProducer/scheduler"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/hashicorp/go-hclog"
+)
+
+type StandingOrderHandler struct {
+	sorepo repositories.StandingOrderRepository
+}
+
+// NewStandingOrderHandler creates a new StandingOrderHandler instance
+func NewStandingOrderHandler(db database.Database, calendar *scheduler.Calendar, lobbs *hclog.Logger) *StandingOrderHandler {
+	return &StandingOrderHandler{
+		sorepo: repositories.NewStandingOrderRepository(db, calendar, lobbs),
+	}
+}
+
+// CreateStandingOrder godoc
+// @Summary Create a standing order
+// @Description Stores a recurring transfer. Each run is published as a transfer on the scheduled business day.
+// @Tags standing-orders
+// @Accept json
+// @Produce json
+// @Param order body models.StandingOrder true "Standing order details"
+// @Success 201 {object} models.StandingOrder
+// @Failure 400 {object} map[string]string "error: Invalid request body or schedule"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /standing-orders [post]
+func (h *StandingOrderHandler) CreateStandingOrder(w http.ResponseWriter, r *http.Request) {
+	var order models.StandingOrder
+	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.sorepo.CreateStandingOrder(r.Context(), &order); err != nil {
+		writeStandingOrderError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, order)
+}
+
+// GetStandingOrder godoc
+// @Summary Get a standing order
+// @Tags standing-orders
+// @Produce json
+// @Param id path string true "Standing order ID"
+// @Success 200 {object} models.StandingOrder
+// @Failure 404 {object} map[string]string "error: Standing order not found"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /standing-orders/{id} [get]
+func (h *StandingOrderHandler) GetStandingOrder(w http.ResponseWriter, r *http.Request) {
+	order, err := h.sorepo.GetStandingOrder(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Not able to fetch standing order", http.StatusInternalServerError)
+		return
+	}
+	if order == nil {
+		http.Error(w, "Standing order not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, order)
+}
+
+// ListStandingOrders godoc
+// @Summary List the standing orders of an account
+// @Tags standing-orders
+// @Produce json
+// @Param accountNumber path string true "Account number"
+// @Success 200 {array} models.StandingOrder
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /accounts/{accountNumber}/standing-orders [get]
+func (h *StandingOrderHandler) ListStandingOrders(w http.ResponseWriter, r *http.Request) {
+	orders, err := h.sorepo.FindStandingOrdersByAccountNumber(r.Context(), mux.Vars(r)["accountNumber"])
+	if err != nil {
+		http.Error(w, "Not able to fetch standing orders", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, orders)
+}
+
+// UpdateStandingOrder godoc
+// @Summary Update a standing order
+// @Description Replaces a standing order and recomputes its next run. Set active to false to pause it.
+// @Tags standing-orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Standing order ID"
+// @Param order body models.StandingOrder true "Standing order details"
+// @Success 200 {object} models.StandingOrder
+// @Failure 400 {object} map[string]string "error: Invalid request body or schedule"
+// @Failure 404 {object} map[string]string "error: Standing order not found"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /standing-orders/{id} [put]
+func (h *StandingOrderHandler) UpdateStandingOrder(w http.ResponseWriter, r *http.Request) {
+	var order models.StandingOrder
+	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	order.ID = mux.Vars(r)["id"]
+
+	found, err := h.sorepo.UpdateStandingOrder(r.Context(), &order)
+	if err != nil {
+		writeStandingOrderError(w, err)
+		return
+	}
+	if !found {
+		http.Error(w, "Standing order not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, order)
+}
+
+// DeleteStandingOrder godoc
+// @Summary Delete a standing order
+// @Tags standing-orders
+// @Produce json
+// @Param id path string true "Standing order ID"
+// @Success 200 {object} map[string]interface{} "success: true, msg: Standing order deleted"
+// @Failure 404 {object} map[string]string "error: Standing order not found"
+// @Failure 500 {object} map[string]string "error: Internal server error"
+// @Router /standing-orders/{id} [delete]
+func (h *StandingOrderHandler) DeleteStandingOrder(w http.ResponseWriter, r *http.Request) {
+	found, err := h.sorepo.DeleteStandingOrder(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Not able to delete standing order", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Standing order not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"msg":     "Standing order deleted",
+	})
+}
+
+func (h *StandingOrderHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/standing-orders", h.CreateStandingOrder).Methods("POST")
+	router.HandleFunc("/standing-orders/{id}", h.GetStandingOrder).Methods("GET")
+	router.HandleFunc("/standing-orders/{id}", h.UpdateStandingOrder).Methods("PUT")
+	router.HandleFunc("/standing-orders/{id}", h.DeleteStandingOrder).Methods("DELETE")
+	router.HandleFunc("/accounts/{accountNumber}/standing-orders", h.ListStandingOrders).Methods("GET")
+}
+
+// writeStandingOrderError reports validation failures as bad requests and anything else as a server error
+func writeStandingOrderError(w http.ResponseWriter, err error) {
+	if errors.Is(err, repositories.ErrInvalidStandingOrder) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Error(w, "Not able to save standing order", http.StatusInternalServerError)
+}
+
+// writeJSON encodes body as the JSON response
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		fmt.Println(err)
+	}
+}
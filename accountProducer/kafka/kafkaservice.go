@@ -1,13 +1,18 @@
 package kafka
 
 import (
-	"fmt"  // Importing fmt for formatted output and error construction
-	"log"  // Importing log for fatal error logging
-	"time" // Importing time for delays in topic creation
+	"fmt"           // Importing fmt for formatted output and error construction
+	"log"           // Importing log for fatal error logging
+	"shared/events" // Importing events to wrap messages in versioned envelopes
+	"time"          // Importing time for delays in topic creation
 
 	"github.com/IBM/sarama" // Importing sarama for Kafka client functionality
 )
 
+// Brokers holds the addresses of the Kafka cluster messages are published to.
+// main sets it from KAFKA_BROKER.
+var Brokers = []string{"kafka:9092"}
+
 // KafkaController manages interactions with a Kafka cluster.
 // It provides methods to push messages to Kafka topics and handle topic creation.
 type KafkaController struct {
@@ -15,14 +20,21 @@ type KafkaController struct {
 }
 
 // PushOrderToQueue sends a message to a specified Kafka topic.
-// It connects to a Kafka producer, constructs a message, and sends it synchronously.
-// Takes a topic name and a byte slice message as input. Returns an error if the operation fails.
+// It encodes the message as an event starting a new trace, connects to a Kafka producer,
+// and sends it synchronously.
+// Takes a topic ID of the topic map and a byte slice message as input. Returns an error if the operation fails.
 func (k *KafkaController) PushOrderToQueue(topic string, message []byte) error {
-	// Define the Kafka broker address (hardcoded for simplicity)
-	brokers := []string{"kafka:9092"}
+	// Encode the message, checking it against the schema of the topic's event type
+	message, headers, err := events.Encode(topic, message, events.Trace{})
+	if err != nil {
+		return fmt.Errorf("failed to encode message for %s: %w", topic, err)
+	}
+
+	// Look up the name of the topic on the cluster in the topic map
+	name := events.TopicName(topic)
 
 	// Establish a connection to the Kafka producer
-	producer, err := k.connectProducer(brokers, topic)
+	producer, err := k.connectProducer(Brokers, name)
 	if err != nil {
 		return err // Return the error if producer initialization fails
 	}
@@ -30,8 +42,9 @@ func (k *KafkaController) PushOrderToQueue(topic string, message []byte) error {
 
 	// Create a new Kafka message with the provided topic and message content
 	msg := &sarama.ProducerMessage{
-		Topic: topic,
-		Value: sarama.StringEncoder(message), // Encode the byte slice as a string
+		Topic:   name,
+		Value:   sarama.ByteEncoder(message), // Protobuf events are binary
+		Headers: recordHeaders(headers),      // Content type, and the envelope of Protobuf events
 	}
 
 	// Send the message synchronously and retrieve partition and offset
@@ -41,10 +54,82 @@ func (k *KafkaController) PushOrderToQueue(topic string, message []byte) error {
 	}
 
 	// Log success with topic, partition, and offset details
-	fmt.Println("Account post request is stored", topic, partition, offset)
+	fmt.Println("Account post request is stored", name, partition, offset)
 	return nil
 }
 
+// PushKeyedToQueue sends a message with a key to a topic, so that messages sharing the key land
+// on the same partition and are consumed in order.
+func (k *KafkaController) PushKeyedToQueue(topic, key string, message []byte) error {
+	message, headers, err := events.Encode(topic, message, events.Trace{})
+	if err != nil {
+		return fmt.Errorf("failed to encode message for %s: %w", topic, err)
+	}
+
+	name := events.TopicName(topic)
+	producer, err := k.connectProducer(Brokers, name)
+	if err != nil {
+		return err
+	}
+	defer producer.Close()
+
+	msg := &sarama.ProducerMessage{
+		Topic:   name,
+		Key:     sarama.StringEncoder(key),
+		Value:   sarama.ByteEncoder(message),
+		Headers: recordHeaders(headers),
+	}
+	_, _, err = producer.SendMessage(msg)
+	return err
+}
+
+// PushMessagesToQueue sends several messages to a topic over one producer connection.
+// It returns one entry per message: nil when the message was stored, or the reason it was not.
+// The error is only set when the producer could not be created, in which case nothing was sent.
+// The messages share one trace, each in its own span.
+func (k *KafkaController) PushMessagesToQueue(topic string, messages [][]byte) ([]error, error) {
+	name := events.TopicName(topic)
+	producer, err := k.connectProducer(Brokers, name)
+	if err != nil {
+		return nil, err
+	}
+	defer producer.Close()
+
+	// Metadata carries the position of each message so failures can be matched back to it.
+	// A message that does not match its schema is not sent.
+	results := make([]error, len(messages))
+	trace := events.NewTrace()
+	msgs := make([]*sarama.ProducerMessage, 0, len(messages))
+	for i, message := range messages {
+		encoded, headers, err := events.Encode(topic, message, trace.Child())
+		if err != nil {
+			results[i] = fmt.Errorf("failed to encode message for %s: %w", topic, err)
+			continue
+		}
+		msgs = append(msgs, &sarama.ProducerMessage{
+			Topic:    name,
+			Value:    sarama.ByteEncoder(encoded),
+			Headers:  recordHeaders(headers),
+			Metadata: i,
+		})
+	}
+
+	if err := producer.SendMessages(msgs); err != nil {
+		producerErrors, ok := err.(sarama.ProducerErrors)
+		if !ok {
+			for _, msg := range msgs {
+				results[msg.Metadata.(int)] = err
+			}
+			return results, nil
+		}
+		for _, producerErr := range producerErrors {
+			results[producerErr.Msg.Metadata.(int)] = producerErr.Err
+		}
+	}
+	fmt.Println("Messages are stored", name, len(messages))
+	return results, nil
+}
+
 // connectProducer initializes a synchronous Kafka producer and ensures the topic exists.
 // Takes a list of broker addresses and the topic name as input. Returns a SyncProducer
 // instance or an error if the connection or topic creation fails.
@@ -120,3 +205,12 @@ func (k *KafkaController) createTopicIfNotExists(admin sarama.ClusterAdmin, topi
 func (k *KafkaController) stringPtr(s string) *string {
 	return &s
 }
+
+// recordHeaders converts the headers of an event to Kafka record headers.
+func recordHeaders(headers events.Headers) []sarama.RecordHeader {
+	records := make([]sarama.RecordHeader, 0, len(headers))
+	for key, value := range headers {
+		records = append(records, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+	}
+	return records
+}
@@ -4,11 +4,15 @@ import (
 	"accountProducer/configurations" // Importing configurations for app and MongoDB settings
 	"accountProducer/database"       // Importing database package for MongoDB operations
 	"accountProducer/handlers"       // Importing handlers for HTTP request handling
+	"accountProducer/kafka"          // Importing kafka for publishing standing order runs
+	"accountProducer/scheduler"      // Importing scheduler for standing orders
 	"context"                        // Importing context for request-scoped operations and timeouts
 	"fmt"                            // Importing fmt for formatted output
 	"net/http"                       // Importing http for running the HTTP server
 	"os"                             // Importing os for file operations and system signals
 	"os/signal"                      // Importing signal for handling OS interrupts
+	"shared/audit"                   // Importing audit for recording state-changing calls
+	"shared/events"                  // Importing events to name this service in the events it publishes
 	"time"                           // Importing time for timeout and duration settings
 
 	_ "accountProducer/docs" // Importing docs package (Swagger) as a side effect for documentation
@@ -37,6 +41,7 @@ import (
 func main() {
 	// Log the application startup
 	fmt.Println("Starting to develop banking application")
+	events.Producer = "accountProducer" // Name this service in the envelope of every event it publishes
 
 	// Load environment variables from .env file
 	err := godotenv.Load()
@@ -65,6 +70,19 @@ func main() {
 		loggs.Error("Not able to create Retrieve App Configurations")
 		os.Exit(1) // Exit if app config cannot be loaded
 	}
+	// Publish events in the configured encoding
+	if err := events.UseCodec(uri.GetEventCodec()); err != nil {
+		loggs.Error("Invalid EVENT_CODEC", "Error", err)
+		os.Exit(1)
+	}
+	// Publish to the configured cluster, under the topic names of the topic map
+	kafka.Brokers = uri.GetKafkaBrokers()
+	if path := uri.GetTopicMapFile(); path != "" {
+		if err := events.LoadTopics(path); err != nil {
+			loggs.Error("Not able to load topic map", "File", path, "Error", err)
+			os.Exit(1)
+		}
+	}
 
 	// Retrieve MongoDB configuration from environment variables
 	mongodbconfig, err := configurations.NewMongoDbConfig()
@@ -73,6 +91,23 @@ func main() {
 		os.Exit(1) // Exit if MongoDB config cannot be loaded
 	}
 
+	// Retrieve the business-day calendar configuration for standing orders
+	schedulerconfig, err := configurations.NewSchedulerConfig()
+	if err != nil {
+		loggs.Error("Not able to create Retrieve Scheduler Configurations")
+		os.Exit(1) // Exit if scheduler config cannot be loaded
+	}
+	weekend, err := scheduler.ParseWeekend(schedulerconfig.WeekendDays)
+	if err != nil {
+		loggs.Error("Invalid weekend days", "Error", err)
+		os.Exit(1)
+	}
+	calendar, err := scheduler.LoadCalendar(schedulerconfig.HolidayCalendarFile, weekend, schedulerconfig.RollConvention)
+	if err != nil {
+		loggs.Error("Not able to load the holiday calendar", "Error", err)
+		os.Exit(1)
+	}
+
 	// Initialize MongoDB instance with config and logger
 	mongodb := database.NewMongoDB(mongodbconfig, &loggs)
 	ctx := context.Background() // Create a background context for database operations
@@ -86,10 +121,22 @@ func main() {
 
 	// Create a new handler instance with MongoDB and logger
 	handler := handlers.NewUserHandler(mongodb, &loggs)
+	standingOrderHandler := handlers.NewStandingOrderHandler(mongodb, calendar, &loggs)
+	batchHandler := handlers.NewBatchHandler(mongodb, &kafka.KafkaController{}, &loggs)
+
+	// Run due standing orders every minute until shutdown
+	schedulerCtx, stopScheduler := context.WithCancel(ctx)
+	defer stopScheduler()
+	go scheduler.NewRunner(mongodb, &kafka.KafkaController{}, calendar, &loggs).Run(schedulerCtx, time.Minute)
 
 	// Initialize the HTTP router
 	router := mux.NewRouter()
 
+	// Record every state-changing call in the audit log
+	recorder := audit.NewRecorder("accountProducer", &kafka.KafkaController{}, &loggs)
+	router.Use(recorder.Middleware)
+	handlers.TrackResources(recorder, mongodb, calendar, &loggs)
+
 	// Set up Swagger UI endpoint for API documentation
 	router.PathPrefix("/swagger/").Handler(httpSwagger.Handler(
 		httpSwagger.URL("/swagger/doc.json"), // Point to the Swagger JSON file
@@ -97,6 +144,8 @@ func main() {
 
 	// Register handler routes with the router
 	handler.RegisterRoutes(router)
+	standingOrderHandler.RegisterRoutes(router)
+	batchHandler.RegisterRoutes(router)
 
 	// Configure standard logger options for HTTP server error logging
 	opts := hclog.StandardLoggerOptions{
@@ -125,7 +174,7 @@ func main() {
 	}()
 
 	// Set up signal handling for graceful shutdown
-	signalChannel := make(chan os.Signal)
+	signalChannel := make(chan os.Signal, 1)
 	signal.Notify(signalChannel, os.Interrupt) // Capture Ctrl+C
 	signal.Notify(signalChannel, os.Kill)      // Capture kill signals
 
@@ -134,6 +183,8 @@ func main() {
 	loggs.Info("System Interruptions Received", waitingForChanel)
 
 	// Gracefully shut down the server with a 30-second timeout
-	newctx, _ := context.WithTimeout(context.Background(), 30*time.Second)
+	stopScheduler()
+	newctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
 	httpServer.Shutdown(newctx) // Shutdown the server cleanly
 }
@@ -45,6 +45,20 @@ type Account struct {
 	// Required: true
 	// swagger:example true
 	IsActive bool `json:"is_active"` // Account status (active or inactive)
+
+	// The limit tier of the account ("basic", "premium" or "business"). Defaults to "basic".
+	// swagger:example "premium"
+	Tier string `json:"tier"` // Account limit tier
+
+	// The ISO 4217 currency the balance is held in. Defaults to "USD".
+	// swagger:example "EUR"
+	Currency string `json:"currency"` // Account currency
+
+	// The customer holding the account (see the customer API of the account service). When set,
+	// username and email default to the customer's. The account can only transact once the
+	// customer is verified.
+	// swagger:example "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+	CustomerID *uuid.UUID `json:"customer_id,omitempty"` // Customer holding the account
 }
 
 // NewAccount creates a new Account instance with default values
@@ -57,5 +71,7 @@ func NewAccount(username, email, password string) *Account {
 		CreatedAt: now,
 		UpdatedAt: now,
 		IsActive:  true, // Default to active
+		Tier:      "basic",
+		Currency:  "USD",
 	}
 }
@@ -0,0 +1,185 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// AccountSummary is the dashboard view of an account, read from the projection the ledger service
+// builds from the transaction outcome stream. It is eventually consistent: Consistency tells how
+// far the projection had got when the summary was read.
+// swagger:model AccountSummary
+type AccountSummary struct {
+	// The account the summary is for.
+	// swagger:example "ACC123456789"
+	AccountNumber string `json:"account_number"`
+
+	// The balance after the latest posting projected so far.
+	// swagger:example 1000.50
+	Balance float64 `json:"balance"`
+
+	// The version of that posting in the account's event stream. 0 while no posting has been projected.
+	// swagger:example 42
+	BalanceVersion int64 `json:"balance_version"`
+
+	// The latest transactions on the account, newest first, including refused ones.
+	RecentTransactions []SummaryTransaction `json:"recent_transactions"`
+
+	// Money in and out of the account per month, newest first.
+	MonthlyTotals []MonthlyTotals `json:"monthly_totals"`
+
+	// The holds still reserving funds on the account, oldest first.
+	PendingHolds []PendingHold `json:"pending_holds"`
+
+	// The total of PendingHolds.
+	// swagger:example 75.00
+	HeldAmount float64 `json:"held_amount"`
+
+	// How far the projection had read the outcome stream.
+	Consistency SummaryConsistency `json:"consistency"`
+}
+
+// SummaryTransaction is a transaction as seen from the summarized account.
+// swagger:model SummaryTransaction
+type SummaryTransaction struct {
+	// swagger:example "6f1c2b9e-8a4d-4c1e-9b7a-2f3d4e5a6b7c"
+	TransactionID string `bson:"transaction_id" json:"transaction_id"`
+
+	// swagger:example "transfer"
+	TransactionType string `bson:"transaction_type" json:"transaction_type"`
+
+	// What the transaction did to the account, fees included: positive in, negative out.
+	// swagger:example -102.50
+	Amount float64 `bson:"amount" json:"amount"`
+
+	// The other account of a transfer.
+	// swagger:example "ACC987654321"
+	Counterparty string `bson:"counterparty,omitempty" json:"counterparty,omitempty"`
+
+	// swagger:example "Rent"
+	Description string `bson:"description,omitempty" json:"description,omitempty"`
+
+	// "completed", or "failed" when the transaction service refused it.
+	// swagger:example "completed"
+	Status string `bson:"status" json:"status"`
+
+	// swagger:example "2026-10-19T09:00:00Z"
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// MonthlyTotals is the money that went in and out of an account in a month.
+// swagger:model MonthlyTotals
+type MonthlyTotals struct {
+	// swagger:example "2026-10"
+	Month string `json:"month"`
+
+	// swagger:example 2500.00
+	In float64 `bson:"in" json:"in"`
+
+	// swagger:example 1830.25
+	Out float64 `bson:"out" json:"out"`
+
+	// The number of transactions that moved the balance.
+	// swagger:example 14
+	Count int64 `bson:"count" json:"count"`
+}
+
+// PendingHold is funds reserved on an account until they are captured or released.
+// swagger:model PendingHold
+type PendingHold struct {
+	// swagger:example "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	HoldID string `bson:"hold_id" json:"hold_id"`
+
+	// swagger:example 75.00
+	Amount float64 `bson:"amount" json:"amount"`
+
+	// swagger:example "POS-4711"
+	Reference string `bson:"reference,omitempty" json:"reference,omitempty"`
+
+	// swagger:example "2026-10-26T09:00:00Z"
+	ExpiresAt time.Time `bson:"expires_at" json:"expires_at"`
+
+	// swagger:example "2026-10-19T09:00:00Z"
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// SummaryConsistency tells how current an account summary is. Offsets are keyed by
+// "<topic>-<partition>" of the topics the projection reads.
+// swagger:model SummaryConsistency
+type SummaryConsistency struct {
+	// The last offset of each partition applied to this account's summary.
+	AccountOffsets map[string]int64 `json:"account_offsets"`
+
+	// When this account's summary last changed.
+	// swagger:example "2026-10-19T09:00:01Z"
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// The last offset of each partition the projection has processed, for any account. Messages up
+	// to these offsets are reflected in every summary.
+	ProjectionOffsets map[string]int64 `json:"projection_offsets"`
+
+	// When the projection last processed a message.
+	// swagger:example "2026-10-19T09:00:05Z"
+	ProjectionUpdatedAt time.Time `json:"projection_updated_at"`
+}
+
+// AccountSummaryDocument is an account summary as the ledger service stores it in the
+// "account_summaries" collection.
+type AccountSummaryDocument struct {
+	AccountNumber      string                   `bson:"account_number"`
+	Balance            float64                  `bson:"balance"`
+	BalanceVersion     int64                    `bson:"balance_version"`
+	RecentTransactions []SummaryTransaction     `bson:"recent_transactions"`
+	Monthly            map[string]MonthlyTotals `bson:"monthly"`
+	PendingHolds       map[string]PendingHold   `bson:"pending_holds"`
+	Offsets            map[string]int64         `bson:"offsets"`
+	UpdatedAt          time.Time                `bson:"updated_at"`
+}
+
+// ProjectionCheckpoint is how far a projection has read its topics, from the
+// "projection_checkpoints" collection.
+type ProjectionCheckpoint struct {
+	Offsets   map[string]int64 `bson:"offsets"`
+	UpdatedAt time.Time        `bson:"updated_at"`
+}
+
+// Summary turns the stored document into the dashboard view. Holds past their expiry no longer
+// reserve funds, so they are left out even before the expiry reaches the projection.
+func (d *AccountSummaryDocument) Summary(checkpoint *ProjectionCheckpoint, now time.Time) *AccountSummary {
+	summary := &AccountSummary{
+		AccountNumber:      d.AccountNumber,
+		Balance:            d.Balance,
+		BalanceVersion:     d.BalanceVersion,
+		RecentTransactions: d.RecentTransactions,
+		MonthlyTotals:      []MonthlyTotals{},
+		PendingHolds:       []PendingHold{},
+		Consistency: SummaryConsistency{
+			AccountOffsets: d.Offsets,
+			UpdatedAt:      d.UpdatedAt,
+		},
+	}
+	if summary.RecentTransactions == nil {
+		summary.RecentTransactions = []SummaryTransaction{}
+	}
+	for month, totals := range d.Monthly {
+		totals.Month = month
+		summary.MonthlyTotals = append(summary.MonthlyTotals, totals)
+	}
+	sort.Slice(summary.MonthlyTotals, func(i, j int) bool {
+		return summary.MonthlyTotals[i].Month > summary.MonthlyTotals[j].Month
+	})
+	for _, hold := range d.PendingHolds {
+		if hold.ExpiresAt.After(now) {
+			summary.PendingHolds = append(summary.PendingHolds, hold)
+			summary.HeldAmount += hold.Amount
+		}
+	}
+	sort.Slice(summary.PendingHolds, func(i, j int) bool {
+		return summary.PendingHolds[i].CreatedAt.Before(summary.PendingHolds[j].CreatedAt)
+	})
+	if checkpoint != nil {
+		summary.Consistency.ProjectionOffsets = checkpoint.Offsets
+		summary.Consistency.ProjectionUpdatedAt = checkpoint.UpdatedAt
+	}
+	return summary
+}
@@ -0,0 +1,88 @@
+package models
+
+import "time"
+
+// Batch is a bulk payment file and the transfers published from it.
+// swagger:model Batch
+type Batch struct {
+	// The unique identifier of the batch, copied onto every transfer as batch_id.
+	// swagger:example "0b6f3c1a-9d2e-4f7a-8c5b-1e2d3f4a5b6c"
+	ID string `bson:"_id" json:"id"`
+
+	// The name of the uploaded file.
+	// swagger:example "payroll-march.xml"
+	FileName string `bson:"file_name" json:"file_name"`
+
+	// The file format: "csv" or "pain.001".
+	// swagger:example "pain.001"
+	Format string `bson:"format" json:"format"`
+
+	// The pain.001 message identification. Empty for CSV files.
+	// swagger:example "PAYROLL-2025-03"
+	MessageID string `bson:"message_id,omitempty" json:"message_id,omitempty"`
+
+	// The SHA-256 of the file. The same file is only accepted once.
+	// swagger:example "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	Checksum string `bson:"checksum" json:"checksum"`
+
+	// "published" when every transfer was sent, "partially_published" when some could not be.
+	// swagger:example "published"
+	Status string `bson:"status" json:"status"`
+
+	// The number of transfers in the file.
+	// swagger:example 250
+	Count int `bson:"count" json:"count"`
+
+	// The sum of the transfer amounts.
+	// swagger:example 412500.00
+	Total float64 `bson:"total" json:"total"`
+
+	// The timestamp when the file was accepted.
+	// swagger:example "2025-03-28T09:00:00Z"
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+
+	// The outcome of each row, filled in from the ledger when the batch is read.
+	Summary map[string]int `bson:"-" json:"summary,omitempty"`
+
+	// The transfers in file order.
+	Rows []BatchRow `bson:"rows" json:"rows"`
+}
+
+// BatchRow is one transfer of a batch.
+// swagger:model BatchRow
+type BatchRow struct {
+	// The CSV line, or the position of the transaction in a pain.001 file.
+	// swagger:example 2
+	Row int `bson:"row" json:"row"`
+
+	// The end-to-end reference of the payment.
+	// swagger:example "EMP-001"
+	Reference string `bson:"reference,omitempty" json:"reference,omitempty"`
+
+	// The transaction published for the row.
+	// swagger:example "6f1c2b9e-8a4d-4c1e-9b7a-2f3d4e5a6b7c"
+	TransactionID string `bson:"transaction_id" json:"transaction_id"`
+
+	// swagger:example "ACC000000001"
+	FromAccountID string `bson:"from_account_id" json:"from_account_id"`
+
+	// swagger:example "ACC000000002"
+	ToAccountID string `bson:"to_account_id" json:"to_account_id"`
+
+	// swagger:example 1500.00
+	Amount float64 `bson:"amount" json:"amount"`
+
+	// swagger:example "USD"
+	Currency string `bson:"currency,omitempty" json:"currency,omitempty"`
+
+	// swagger:example "March salary"
+	Description string `bson:"description,omitempty" json:"description,omitempty"`
+
+	// "published" or "publish_failed" when stored; "completed", "failed" or "pending" in the
+	// status report, depending on whether the ledger has recorded the transaction yet.
+	// swagger:example "completed"
+	Status string `bson:"status" json:"status"`
+
+	// Why the row could not be published.
+	Error string `bson:"error,omitempty" json:"error,omitempty"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+// StandingOrder is a recurring transfer definition (e.g. rent on the 1st of every month).
+// swagger:model StandingOrder
+type StandingOrder struct {
+	// The unique identifier of the standing order. Assigned by the server.
+	// swagger:example "3b9d6a52-1f0e-4c8a-9d7e-5a4b3c2d1e0f"
+	ID string `bson:"_id" json:"id"`
+
+	// The account the money is taken from.
+	// Required: true
+	// swagger:example "acc123"
+	FromAccountID string `bson:"from_account_id" json:"from_account_id"`
+
+	// The account the money is sent to.
+	// Required: true
+	// swagger:example "acc456"
+	ToAccountID string `bson:"to_account_id" json:"to_account_id"`
+
+	// The amount transferred on every run.
+	// Required: true
+	// swagger:example 1200.00
+	Amount float64 `bson:"amount" json:"amount"`
+
+	// A brief description copied onto every transfer.
+	// swagger:example "Monthly rent"
+	Description string `bson:"description" json:"description"`
+
+	// A five-field cron expression (minute hour day-of-month month day-of-week), evaluated in UTC.
+	// Required: true
+	// swagger:example "0 9 1 * *"
+	Schedule string `bson:"schedule" json:"schedule"`
+
+	// The first day the order may run. Defaults to now.
+	// swagger:example "2025-03-01T00:00:00Z"
+	StartDate time.Time `bson:"start_date" json:"start_date"`
+
+	// The last day the order may run. Omit for an open-ended order.
+	// swagger:example "2026-03-01T00:00:00Z"
+	EndDate *time.Time `bson:"end_date,omitempty" json:"end_date,omitempty"`
+
+	// Whether the order is currently running.
+	// swagger:example true
+	Active bool `bson:"active" json:"active"`
+
+	// The next occurrence produced by the schedule, before business-day adjustment.
+	// swagger:example "2025-04-01T09:00:00Z"
+	NextOccurrence time.Time `bson:"next_occurrence" json:"next_occurrence"`
+
+	// When the next occurrence will actually run, after moving it off weekends and holidays.
+	// swagger:example "2025-04-01T09:00:00Z"
+	NextExecution time.Time `bson:"next_execution" json:"next_execution"`
+
+	// When the order last produced a transfer.
+	// swagger:example "2025-03-03T09:00:00Z"
+	LastExecution *time.Time `bson:"last_execution,omitempty" json:"last_execution,omitempty"`
+
+	// The timestamp when the order was created.
+	// swagger:example "2025-02-25T09:00:00Z"
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+
+	// The timestamp when the order was last updated.
+	// swagger:example "2025-02-25T09:00:00Z"
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
@@ -13,6 +13,10 @@ type TransactionLedger struct {
 	// swagger:example 507f1f77bcf86cd799439011
 	ID bson.ObjectID `bson:"_id"`
 
+	// The identifier of the transaction this entry records.
+	// swagger:example "6f1c2b9e-8a4d-4c1e-9b7a-2f3d4e5a6b7c"
+	TransactionID string `bson:"transaction_id" json:"transaction_id"`
+
 	// The ID of the account from which the transaction originates.
 	// Required: true
 	// swagger:example "acc123"
@@ -45,4 +49,58 @@ type TransactionLedger struct {
 	// Required: true
 	// swagger:example "completed"
 	Status string `bson:"status" json:"status"`
+
+	// The transaction undone by this entry. Only set on reversals.
+	// swagger:example "6f1c2b9e-8a4d-4c1e-9b7a-2f3d4e5a6b7c"
+	OriginalTransactionID string `bson:"original_transaction_id,omitempty" json:"original_transaction_id,omitempty"`
+
+	// The reversals posted against this entry.
+	ReversalIDs []string `bson:"reversal_ids,omitempty" json:"reversal_ids,omitempty"`
+
+	// The total amount refunded by reversals.
+	// swagger:example 50.00
+	ReversedAmount float64 `bson:"reversed_amount,omitempty" json:"reversed_amount,omitempty"`
+
+	// The ISO 4217 currency of the amount.
+	// swagger:example "USD"
+	Currency string `bson:"currency,omitempty" json:"currency,omitempty"`
+
+	// The exchange rate applied when the destination account holds another currency.
+	// swagger:example 0.92
+	FxRate float64 `bson:"fx_rate,omitempty" json:"fx_rate,omitempty"`
+
+	// The amount credited to the destination account after conversion.
+	// swagger:example 92.00
+	ConvertedAmount float64 `bson:"converted_amount,omitempty" json:"converted_amount,omitempty"`
+
+	// The currency of the converted amount.
+	// swagger:example "EUR"
+	ConvertedCurrency string `bson:"converted_currency,omitempty" json:"converted_currency,omitempty"`
+
+	// The fees charged to the paying account on top of the amount.
+	Fees []FeeLine `bson:"fees,omitempty" json:"fees,omitempty"`
+
+	// The bulk payment file the transaction was published from.
+	// swagger:example "0b6f3c1a-9d2e-4f7a-8c5b-1e2d3f4a5b6c"
+	BatchID string `bson:"batch_id,omitempty" json:"batch_id,omitempty"`
+}
+
+// FeeLine is a fee charged on a transaction.
+// swagger:model FeeLine
+type FeeLine struct {
+	// The fee rule that produced the fee.
+	// swagger:example 3
+	RuleID int64 `bson:"rule_id" json:"rule_id"`
+
+	// The label of the fee rule.
+	// swagger:example "Transfer fee"
+	Name string `bson:"name" json:"name"`
+
+	// The amount of the fee.
+	// swagger:example 0.50
+	Amount float64 `bson:"amount" json:"amount"`
+
+	// The currency of the fee.
+	// swagger:example "USD"
+	Currency string `bson:"currency" json:"currency"`
 }
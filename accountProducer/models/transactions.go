@@ -2,11 +2,17 @@ package models
 
 import (
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // Transaction represents a financial transaction between accounts.
 // swagger:model Transaction
 type Transaction struct {
+	// The unique identifier of the transaction. Always assigned by the server.
+	// swagger:example "6f1c2b9e-8a4d-4c1e-9b7a-2f3d4e5a6b7c"
+	ID uuid.UUID `json:"id"` // Unique identifier for the transaction
+
 	// The ID of the account from which the transaction originates.
 	// Required: true
 	// swagger:example "acc123"
@@ -22,8 +28,8 @@ type Transaction struct {
 	// swagger:example 250.75
 	Amount float64 `json:"amount"` // Transaction amount
 
-	// The type of transaction (e.g., "transfer", "deposit", "withdrawal").
-	// Required: true
+	// The type of transaction (e.g., "transfer", "deposit", "withdrawal", "external_transfer").
+	// Set by the server from the endpoint the transaction is posted to.
 	// swagger:example "transfer"
 	TransactionType string `json:"transaction_type"` // Type of transaction (e.g., "transfer", "deposit", "withdrawal")
 
@@ -39,4 +45,72 @@ type Transaction struct {
 	// Required: true
 	// swagger:example "pending"
 	Status string `json:"status"` // Transaction status (e.g., "pending", "completed", "failed")
+
+	// The transaction undone by a reversal. Only set on reversals.
+	// swagger:example "6f1c2b9e-8a4d-4c1e-9b7a-2f3d4e5a6b7c"
+	OriginalTransactionID string `json:"original_transaction_id,omitempty"` // Transaction undone by a reversal
+
+	// The ISO 4217 currency of the amount. Defaults to the currency of the source account;
+	// deposits and withdrawals in any other currency are rejected.
+	// swagger:example "USD"
+	Currency string `json:"currency,omitempty"` // Currency of the amount
+
+	// The customer initiating a withdrawal or transfer. They must be a verified owner or joint
+	// holder of the source account. When the account has an approval rule their initiation
+	// counts as the first approval.
+	// swagger:example "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+	InitiatedBy string `json:"initiated_by,omitempty"` // Customer initiating the transaction
+
+	// A payee registered on the source account (see /accounts/{accountNumber}/payees on the
+	// transaction service). Transfers may name a payee instead of to_account_id.
+	// swagger:example "3d5f7a9b-1c2e-4f6a-8b0d-9e1f2a3b4c5d"
+	PayeeID string `json:"payee_id,omitempty"` // Registered payee of a transfer
+
+	// The bulk payment file the transfer was published from.
+	// swagger:example "0b6f3c1a-9d2e-4f7a-8c5b-1e2d3f4a5b6c"
+	BatchID string `json:"batch_id,omitempty"` // Bulk payment batch
+
+	// The beneficiary of an external transfer at another bank. Required when transaction_type
+	// is "external_transfer"; the amount is held in the clearing suspense account until the
+	// clearing network settles or returns the payment.
+	Creditor *Party `json:"creditor,omitempty"` // Beneficiary of an external transfer
+
+	// The remittance reference sent to the beneficiary's bank with an external transfer.
+	// swagger:example "INV-2025-0042"
+	Reference string `json:"reference,omitempty"` // Remittance reference
+}
+
+// Party is the beneficiary of an external transfer.
+// swagger:model Party
+type Party struct {
+	// The beneficiary's name.
+	// Required: true
+	// swagger:example "John Smith"
+	Name string `json:"name"` // Beneficiary name
+
+	// The beneficiary's IBAN. Either iban or account is required.
+	// swagger:example "GB33BUKB20201555555555"
+	IBAN string `json:"iban,omitempty"` // Beneficiary IBAN
+
+	// The beneficiary's account number when they have no IBAN.
+	// swagger:example "12345678"
+	Account string `json:"account,omitempty"` // Beneficiary account number
+
+	// The BIC of the beneficiary's bank.
+	// Required: true
+	// swagger:example "BUKBGB22"
+	BIC string `json:"bic"` // Beneficiary bank BIC
+}
+
+// Reversal is the request body for reversing or refunding a transaction.
+// swagger:model Reversal
+type Reversal struct {
+	// The amount to refund. Omit or set to 0 to reverse everything not yet refunded.
+	// Partial amounts are only accepted for transfers.
+	// swagger:example 50.00
+	Amount float64 `json:"amount"` // Amount to refund
+
+	// A brief reason for the reversal.
+	// swagger:example "Duplicate payment"
+	Description string `json:"description"` // Reason for the reversal
 }
@@ -0,0 +1,169 @@
+package repositories
+
+import (
+	"accountProducer/bulk"     // Importing bulk package to parse and validate payment files
+	"accountProducer/database" // Importing database package for database operations
+	"accountProducer/models"   // Importing models package for the Batch struct
+	"context"                  // Importing context for handling request-scoped values and cancellation
+	"crypto/sha256"            // Importing sha256 to recognise files uploaded twice
+	"encoding/hex"             // Importing hex to encode the checksum
+	"encoding/json"            // Importing json to encode the transfers
+	"errors"                   // Importing errors for the sentinels
+	"fmt"                      // Importing fmt for error construction
+	"time"                     // Importing time for timestamps
+
+	"github.com/google/uuid"        // Importing uuid for batch and transaction IDs
+	"github.com/hashicorp/go-hclog" // Importing hclog for structured logging
+)
+
+var (
+	// ErrInvalidBatch is wrapped by every error caused by the contents of a bulk file
+	ErrInvalidBatch = errors.New("invalid bulk payment file")
+	// ErrDuplicateBatch is returned when the same file has already been accepted
+	ErrDuplicateBatch = errors.New("bulk payment file already submitted")
+)
+
+// batchNamespace seeds the deterministic transaction IDs of batch rows
+var batchNamespace = uuid.MustParse("c2a7e4b1-5d3f-4a8e-9b6c-0f1e2d3c4b5a")
+
+// BatchPublisher sends several messages to a Kafka topic; kafka.KafkaController satisfies it
+type BatchPublisher interface {
+	PushMessagesToQueue(topic string, messages [][]byte) ([]error, error)
+}
+
+// BatchRepo implements the BatchRepository interface on top of the database.
+type BatchRepo struct {
+	mgdb      database.Database // mgdb is the database instance storing batches
+	publisher BatchPublisher    // publisher sends the transfers to the transaction service
+	loggs     *hclog.Logger     // loggs is the logger instance for logging repository activities
+}
+
+// NewBatchRepository creates a new BatchRepo instance.
+func NewBatchRepository(mgdb database.Database, publisher BatchPublisher, lobbs *hclog.Logger) BatchRepository {
+	return &BatchRepo{
+		mgdb:      mgdb,
+		publisher: publisher,
+		loggs:     lobbs,
+	}
+}
+
+// SubmitBatch validates every row of a bulk file and only then stores the batch and publishes
+// one transfer per row, all carrying the batch ID. Row transaction IDs are derived from the batch
+// and the row, so publishing them again can never move money twice.
+func (b *BatchRepo) SubmitBatch(ctx context.Context, fileName, format string, data []byte) (*models.Batch, error) {
+	if format == "" {
+		format = bulk.DetectFormat(fileName, data)
+	}
+	file, err := bulk.Parse(format, data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidBatch, err)
+	}
+
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+	existing, err := b.mgdb.GetBatchByChecksum(ctx, checksum)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, fmt.Errorf("%w as batch %s", ErrDuplicateBatch, existing.ID)
+	}
+
+	now := time.Now().UTC()
+	batch := &models.Batch{
+		ID:        uuid.New().String(),
+		FileName:  fileName,
+		Format:    file.Format,
+		MessageID: file.MessageID,
+		Checksum:  checksum,
+		Status:    "published",
+		Count:     len(file.Payments),
+		Total:     file.Total(),
+		CreatedAt: now,
+		Rows:      make([]models.BatchRow, len(file.Payments)),
+	}
+	messages := make([][]byte, len(file.Payments))
+	for i, payment := range file.Payments {
+		row := models.BatchRow{
+			Row:           payment.Row,
+			Reference:     payment.Reference,
+			TransactionID: uuid.NewSHA1(batchNamespace, []byte(fmt.Sprintf("%s/%d", batch.ID, payment.Row))).String(),
+			FromAccountID: payment.FromAccountID,
+			ToAccountID:   payment.ToAccountID,
+			Amount:        payment.Amount,
+			Currency:      payment.Currency,
+			Description:   payment.Description,
+			Status:        "published",
+		}
+		transaction := models.Transaction{
+			ID:              uuid.MustParse(row.TransactionID),
+			FromAccountID:   row.FromAccountID,
+			ToAccountID:     row.ToAccountID,
+			Amount:          row.Amount,
+			Currency:        row.Currency,
+			TransactionType: "transfer",
+			Description:     row.Description,
+			CreatedAt:       now,
+			Status:          "pending",
+			BatchID:         batch.ID,
+		}
+		if messages[i], err = json.Marshal(transaction); err != nil {
+			return nil, fmt.Errorf("failed to encode transfer for row %d: %w", payment.Row, err)
+		}
+		batch.Rows[i] = row
+	}
+
+	// the batch is stored before anything is sent so every published transfer can be traced
+	if err := b.mgdb.InsertBatch(ctx, batch); err != nil {
+		return nil, err
+	}
+	results, err := b.publisher.PushMessagesToQueue("transaction", messages)
+	if err != nil {
+		(*b.loggs).Error("Failed to publish batch", "ID", batch.ID, "Error", err)
+		results = make([]error, len(messages))
+		for i := range results {
+			results[i] = err
+		}
+	}
+	for i, result := range results {
+		if result != nil {
+			batch.Rows[i].Status = "publish_failed"
+			batch.Rows[i].Error = result.Error()
+			batch.Status = "partially_published"
+		}
+	}
+	if err := b.mgdb.ReplaceBatch(ctx, batch); err != nil {
+		(*b.loggs).Error("Failed to record batch publication", "ID", batch.ID, "Error", err)
+		return nil, err
+	}
+	(*b.loggs).Info("Batch published", "ID", batch.ID, "Rows", batch.Count, "Status", batch.Status)
+	return batch, nil
+}
+
+// GetBatchReport retrieves a batch with the ledger outcome of every row: "completed" or "failed"
+// once the transaction service has processed it, "pending" until then. Returns nil if the batch
+// does not exist.
+func (b *BatchRepo) GetBatchReport(ctx context.Context, id string) (*models.Batch, error) {
+	batch, err := b.mgdb.GetBatch(ctx, id)
+	if err != nil || batch == nil {
+		return nil, err
+	}
+	outcomes, err := b.mgdb.GetBatchOutcomes(ctx, id)
+	if err != nil {
+		(*b.loggs).Error("Error fetching batch outcomes", "ID", id, "Error", err)
+		return nil, err
+	}
+
+	batch.Summary = make(map[string]int)
+	for i := range batch.Rows {
+		row := &batch.Rows[i]
+		if row.Status == "published" {
+			row.Status = "pending"
+			if outcome, ok := outcomes[row.TransactionID]; ok {
+				row.Status = outcome
+			}
+		}
+		batch.Summary[row.Status]++
+	}
+	return batch, nil
+}
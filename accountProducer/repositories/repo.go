@@ -14,4 +14,38 @@ type Repository interface {
 	// Returns a pointer to a slice of TransactionLedger structs, representing the transaction records,
 	// or an error if the retrieval fails (e.g., due to storage unavailability or invalid account number).
 	FindTransactionByAccountNumber(ctx context.Context, accountNumber string) (*[]models.TransactionLedger, error)
+
+	// FindAccountSummary retrieves the dashboard summary of an account with how far the projection
+	// behind it had got. Returns nil if no message about the account has been projected yet.
+	FindAccountSummary(ctx context.Context, accountNumber string) (*models.AccountSummary, error)
+}
+
+// StandingOrderRepository defines the data access operations for recurring transfers.
+// Implementations validate the order and work out when it next runs before storing it.
+type StandingOrderRepository interface {
+	// CreateStandingOrder validates and stores a new standing order, assigning its ID and first run.
+	CreateStandingOrder(ctx context.Context, order *models.StandingOrder) error
+
+	// GetStandingOrder retrieves a standing order by ID, or nil if it does not exist.
+	GetStandingOrder(ctx context.Context, id string) (*models.StandingOrder, error)
+
+	// FindStandingOrdersByAccountNumber retrieves every standing order paying out of the account.
+	FindStandingOrdersByAccountNumber(ctx context.Context, accountNumber string) ([]models.StandingOrder, error)
+
+	// UpdateStandingOrder validates and replaces a standing order, recomputing its next run.
+	// Returns false if the order does not exist.
+	UpdateStandingOrder(ctx context.Context, order *models.StandingOrder) (bool, error)
+
+	// DeleteStandingOrder removes a standing order. Returns false if the order does not exist.
+	DeleteStandingOrder(ctx context.Context, id string) (bool, error)
+}
+
+// BatchRepository defines the data access operations for bulk payment files.
+type BatchRepository interface {
+	// SubmitBatch validates a bulk file (format "csv", "pain.001", or empty to detect it) and
+	// publishes one transfer per row. Files with any invalid row are refused as a whole.
+	SubmitBatch(ctx context.Context, fileName, format string, data []byte) (*models.Batch, error)
+
+	// GetBatchReport retrieves a batch with the outcome of each row, or nil if it does not exist.
+	GetBatchReport(ctx context.Context, id string) (*models.Batch, error)
 }
@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"accountProducer/database"  // Importing database package for database operations
+	"accountProducer/models"    // Importing models package for the StandingOrder struct
+	"accountProducer/scheduler" // Importing scheduler package to compute the next run
+	"context"                   // Importing context for handling request-scoped values and cancellation
+	"errors"                    // Importing errors for the validation sentinel
+	"fmt"                       // Importing fmt for error construction
+	"time"                      // Importing time for timestamps
+
+	"github.com/google/uuid"        // Importing uuid for order IDs
+	"github.com/hashicorp/go-hclog" // Importing hclog for structured logging
+)
+
+// ErrInvalidStandingOrder is wrapped by every error caused by the order's own fields
+var ErrInvalidStandingOrder = errors.New("invalid standing order")
+
+// StandingOrderRepo implements the StandingOrderRepository interface on top of the database.
+// It uses the business-day calendar to place every run on a day transfers may execute.
+type StandingOrderRepo struct {
+	mgdb     database.Database   // mgdb is the database instance storing standing orders
+	calendar *scheduler.Calendar // calendar moves runs off weekends and holidays
+	loggs    *hclog.Logger       // loggs is the logger instance for logging repository activities
+}
+
+// NewStandingOrderRepository creates a new StandingOrderRepo instance.
+func NewStandingOrderRepository(mgdb database.Database, calendar *scheduler.Calendar, lobbs *hclog.Logger) StandingOrderRepository {
+	return &StandingOrderRepo{
+		mgdb:     mgdb,
+		calendar: calendar,
+		loggs:    lobbs,
+	}
+}
+
+// CreateStandingOrder validates the order, assigns an ID, plans its first run and stores it.
+func (s *StandingOrderRepo) CreateStandingOrder(ctx context.Context, order *models.StandingOrder) error {
+	now := time.Now().UTC()
+	order.ID = uuid.New().String()
+	order.Active = true
+	order.LastExecution = nil
+	order.CreatedAt = now
+	order.UpdatedAt = now
+	if order.StartDate.IsZero() {
+		order.StartDate = now
+	}
+	if err := s.plan(order, now); err != nil {
+		return err
+	}
+
+	if err := s.mgdb.InsertStandingOrder(ctx, order); err != nil {
+		(*s.loggs).Error("Error creating the standing order", "Error", err)
+		return err
+	}
+	return nil
+}
+
+// GetStandingOrder retrieves a standing order by ID, or nil if it does not exist.
+func (s *StandingOrderRepo) GetStandingOrder(ctx context.Context, id string) (*models.StandingOrder, error) {
+	order, err := s.mgdb.GetStandingOrder(ctx, id)
+	if err != nil {
+		(*s.loggs).Error("Error fetching the standing order", "Error", err)
+		return nil, err
+	}
+	return order, nil
+}
+
+// FindStandingOrdersByAccountNumber retrieves every standing order paying out of the account.
+func (s *StandingOrderRepo) FindStandingOrdersByAccountNumber(ctx context.Context, accountNumber string) ([]models.StandingOrder, error) {
+	orders, err := s.mgdb.GetStandingOrdersByAccountNumber(ctx, accountNumber)
+	if err != nil {
+		(*s.loggs).Error("Error fetching the standing orders", "Error", err)
+		return nil, err
+	}
+	return orders, nil
+}
+
+// UpdateStandingOrder replaces the editable fields of an existing order and replans its next run
+// from now, so an occurrence that has already executed is never produced again.
+func (s *StandingOrderRepo) UpdateStandingOrder(ctx context.Context, order *models.StandingOrder) (bool, error) {
+	existing, err := s.mgdb.GetStandingOrder(ctx, order.ID)
+	if err != nil {
+		(*s.loggs).Error("Error fetching the standing order", "Error", err)
+		return false, err
+	}
+	if existing == nil {
+		return false, nil
+	}
+
+	now := time.Now().UTC()
+	order.CreatedAt = existing.CreatedAt
+	order.LastExecution = existing.LastExecution
+	order.UpdatedAt = now
+	if order.StartDate.IsZero() {
+		order.StartDate = existing.StartDate
+	}
+	if order.Active {
+		if err := s.plan(order, now); err != nil {
+			return false, err
+		}
+	} else if err := s.validate(order); err != nil {
+		return false, err
+	} else {
+		order.NextOccurrence, order.NextExecution = existing.NextOccurrence, existing.NextExecution
+	}
+
+	found, err := s.mgdb.ReplaceStandingOrder(ctx, order)
+	if err != nil {
+		(*s.loggs).Error("Error updating the standing order", "Error", err)
+		return false, err
+	}
+	return found, nil
+}
+
+// DeleteStandingOrder removes a standing order. Returns false if the order does not exist.
+func (s *StandingOrderRepo) DeleteStandingOrder(ctx context.Context, id string) (bool, error) {
+	found, err := s.mgdb.DeleteStandingOrder(ctx, id)
+	if err != nil {
+		(*s.loggs).Error("Error deleting the standing order", "Error", err)
+		return false, err
+	}
+	return found, nil
+}
+
+// plan validates the order and sets its next run
+func (s *StandingOrderRepo) plan(order *models.StandingOrder, now time.Time) error {
+	if err := s.validate(order); err != nil {
+		return err
+	}
+	schedule, err := scheduler.NewSchedule(order.Schedule, s.calendar)
+	if err != nil {
+		return err
+	}
+	schedule.Plan(order, now)
+	if !order.Active {
+		return fmt.Errorf("%w: schedule %q has no run before the end date", ErrInvalidStandingOrder, order.Schedule)
+	}
+	return nil
+}
+
+// validate checks the fields a customer supplies
+func (s *StandingOrderRepo) validate(order *models.StandingOrder) error {
+	if order.FromAccountID == "" || order.ToAccountID == "" {
+		return fmt.Errorf("%w: from_account_id and to_account_id are required", ErrInvalidStandingOrder)
+	}
+	if order.FromAccountID == order.ToAccountID {
+		return fmt.Errorf("%w: cannot transfer to the same account", ErrInvalidStandingOrder)
+	}
+	if order.Amount <= 0 {
+		return fmt.Errorf("%w: amount must be positive: %.2f", ErrInvalidStandingOrder, order.Amount)
+	}
+	if order.EndDate != nil && order.EndDate.Before(order.StartDate) {
+		return fmt.Errorf("%w: end_date cannot be before start_date", ErrInvalidStandingOrder)
+	}
+	if _, err := scheduler.NewSchedule(order.Schedule, s.calendar); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidStandingOrder, err)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"accountProducer/database" // Importing database package for database operations
 	"accountProducer/models"   // Importing models package for the TransactionLedger struct
 	"context"                  // Importing context for handling request-scoped values and cancellation
+	"time"                     // Importing time for leaving out expired holds
 
 	"github.com/hashicorp/go-hclog" // Importing hclog for structured logging
 )
@@ -40,3 +41,23 @@ func (t *TransactionRepo) FindTransactionByAccountNumber(ctx context.Context, ac
 	// Return the retrieved transaction list on success
 	return list, nil
 }
+
+// FindAccountSummary retrieves the account's summary and the checkpoint of the projection that
+// writes it, and turns them into the dashboard view.
+func (t *TransactionRepo) FindAccountSummary(ctx context.Context, accountNumber string) (*models.AccountSummary, error) {
+	document, err := t.mgdb.GetAccountSummary(ctx, accountNumber)
+	if err != nil {
+		(*t.loggs).Error("Error Fetching the account summary", "Error", err)
+		return nil, err
+	}
+	if document == nil {
+		return nil, nil
+	}
+
+	checkpoint, err := t.mgdb.GetProjectionCheckpoint(ctx, "account_summaries")
+	if err != nil {
+		(*t.loggs).Error("Error Fetching the projection checkpoint", "Error", err)
+		return nil, err
+	}
+	return document.Summary(checkpoint, time.Now()), nil
+}
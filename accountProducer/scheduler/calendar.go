@@ -0,0 +1,112 @@
+package scheduler
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Calendar decides which days transfers may run on. Weekends and listed holidays are
+// non-business days; occurrences that fall on them are rolled to a business day.
+type Calendar struct {
+	weekend  map[time.Weekday]bool
+	holidays map[string]bool // keyed by YYYY-MM-DD
+	// preceding rolls back to the previous business day instead of forward to the next one
+	preceding bool
+}
+
+// NewCalendar creates a Calendar with the given weekend days, holidays and roll convention
+// ("following" or "preceding")
+func NewCalendar(weekend []time.Weekday, holidays []time.Time, convention string) (*Calendar, error) {
+	if convention != "following" && convention != "preceding" {
+		return nil, fmt.Errorf("unknown roll convention %q, expected following or preceding", convention)
+	}
+	if len(weekend) >= 7 {
+		return nil, fmt.Errorf("a calendar needs at least one business day per week")
+	}
+	c := &Calendar{
+		weekend:   make(map[time.Weekday]bool),
+		holidays:  make(map[string]bool),
+		preceding: convention == "preceding",
+	}
+	for _, day := range weekend {
+		c.weekend[day] = true
+	}
+	for _, day := range holidays {
+		c.holidays[day.Format("2006-01-02")] = true
+	}
+	return c, nil
+}
+
+// LoadCalendar builds a Calendar from a holidays file with one YYYY-MM-DD date per line.
+// Blank lines and lines starting with # are ignored; anything after the date is treated as
+// a comment. An empty path gives a calendar with weekends only.
+func LoadCalendar(path string, weekend []time.Weekday, convention string) (*Calendar, error) {
+	holidays := []time.Time{}
+	if path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open holiday calendar: %w", err)
+		}
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		line := 0
+		for scanner.Scan() {
+			line++
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" || strings.HasPrefix(text, "#") {
+				continue
+			}
+			day, err := time.Parse("2006-01-02", strings.Fields(text)[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid holiday on line %d: %w", line, err)
+			}
+			holidays = append(holidays, day)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read holiday calendar: %w", err)
+		}
+	}
+	return NewCalendar(weekend, holidays, convention)
+}
+
+// ParseWeekend parses a comma separated list of weekday abbreviations such as "Sat,Sun"
+func ParseWeekend(value string) ([]time.Weekday, error) {
+	days := map[string]time.Weekday{
+		"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+		"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+	}
+	weekend := []time.Weekday{}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+		day, ok := days[part]
+		if !ok {
+			return nil, fmt.Errorf("unknown weekday %q", part)
+		}
+		weekend = append(weekend, day)
+	}
+	return weekend, nil
+}
+
+// IsBusinessDay reports whether transfers may run on t's date
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	return !c.weekend[t.Weekday()] && !c.holidays[t.Format("2006-01-02")]
+}
+
+// Adjust moves t to the nearest business day according to the roll convention, keeping the time of day
+func (c *Calendar) Adjust(t time.Time) time.Time {
+	step := 1
+	if c.preceding {
+		step = -1
+	}
+	for !c.IsBusinessDay(t) {
+		t = t.AddDate(0, 0, step)
+	}
+	return t
+}
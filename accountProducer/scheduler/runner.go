@@ -0,0 +1,128 @@
+package scheduler
+
+import (
+	"accountProducer/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/go-hclog"
+)
+
+// transactionNamespace seeds the deterministic transaction IDs of standing order runs
+var transactionNamespace = uuid.MustParse("8f4c2a1e-6b3d-4e5f-9a7b-1c2d3e4f5a6b")
+
+// Store is the persistence the runner needs; database.Database satisfies it
+type Store interface {
+	GetDueStandingOrders(ctx context.Context, now time.Time) ([]models.StandingOrder, error)
+	AdvanceStandingOrder(ctx context.Context, order *models.StandingOrder, executed time.Time) (bool, error)
+}
+
+// Publisher sends a message to a Kafka topic; kafka.KafkaController satisfies it
+type Publisher interface {
+	PushOrderToQueue(topic string, message []byte) error
+}
+
+// Runner materializes due standing orders as transfers on the "transaction" topic.
+// Every run of an order gets a transaction ID derived from the order and the nominal
+// occurrence, so when several replicas pick up the same run the transaction service
+// applies it only once.
+type Runner struct {
+	store     Store
+	publisher Publisher
+	calendar  *Calendar
+	loggs     *hclog.Logger
+}
+
+// NewRunner creates a new Runner
+func NewRunner(store Store, publisher Publisher, calendar *Calendar, loggs *hclog.Logger) *Runner {
+	return &Runner{store: store, publisher: publisher, calendar: calendar, loggs: loggs}
+}
+
+// TransactionID returns the ID of the transfer produced by the given occurrence of an order
+func TransactionID(orderID string, occurrence time.Time) uuid.UUID {
+	return uuid.NewSHA1(transactionNamespace, []byte(orderID+"/"+occurrence.UTC().Format(time.RFC3339)))
+}
+
+// Run checks for due orders every interval until ctx is cancelled
+func (r *Runner) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := r.RunDue(ctx, time.Now().UTC()); err != nil {
+			(*r.loggs).Error("Standing order run failed", "Error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue publishes a transfer for every order due at now and advances it to its next occurrence
+func (r *Runner) RunDue(ctx context.Context, now time.Time) error {
+	orders, err := r.store.GetDueStandingOrders(ctx, now)
+	if err != nil {
+		return err
+	}
+	for i := range orders {
+		if err := r.execute(ctx, &orders[i], now); err != nil {
+			(*r.loggs).Error("Failed to execute standing order", "ID", orders[i].ID, "Error", err)
+		}
+	}
+	return nil
+}
+
+// execute publishes every occurrence of order due at now, oldest first, moving the order on after
+// each one, so that an order missed during downtime catches up in a single run
+func (r *Runner) execute(ctx context.Context, order *models.StandingOrder, now time.Time) error {
+	schedule, err := NewSchedule(order.Schedule, r.calendar)
+	if err != nil {
+		return err
+	}
+	for {
+		executed := order.NextOccurrence
+
+		if order.EndDate == nil || !executed.After(*order.EndDate) {
+			transaction := models.Transaction{
+				ID:              TransactionID(order.ID, executed),
+				FromAccountID:   order.FromAccountID,
+				ToAccountID:     order.ToAccountID,
+				Amount:          order.Amount,
+				TransactionType: "transfer",
+				Description:     order.Description,
+				CreatedAt:       now,
+				Status:          "pending",
+			}
+			transactionInBytes, err := json.Marshal(transaction)
+			if err != nil {
+				return fmt.Errorf("failed to encode transfer: %w", err)
+			}
+			if err := r.publisher.PushOrderToQueue("transaction", transactionInBytes); err != nil {
+				// Leave the order due so the next tick retries with the same transaction ID
+				return fmt.Errorf("failed to publish transfer: %w", err)
+			}
+			order.LastExecution = &now
+		}
+
+		order.NextOccurrence, order.NextExecution = schedule.Next(executed)
+		if order.EndDate != nil && order.NextOccurrence.After(*order.EndDate) {
+			order.Active = false
+		}
+		order.UpdatedAt = now
+		advanced, err := r.store.AdvanceStandingOrder(ctx, order, executed)
+		if err != nil {
+			return err
+		}
+		if !advanced {
+			(*r.loggs).Debug("Standing order already advanced by another replica", "ID", order.ID)
+			return nil
+		}
+		if !order.Active || order.NextExecution.After(now) || ctx.Err() != nil {
+			return nil
+		}
+	}
+}
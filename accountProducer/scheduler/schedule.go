@@ -0,0 +1,48 @@
+package scheduler
+
+import (
+	"accountProducer/models"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// parser accepts standard five-field cron expressions and descriptors such as @monthly
+var parser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// Schedule computes the occurrences of a standing order
+type Schedule struct {
+	cron     cron.Schedule
+	calendar *Calendar
+}
+
+// NewSchedule parses a cron expression evaluated in UTC
+func NewSchedule(expression string, calendar *Calendar) (*Schedule, error) {
+	sched, err := parser.Parse(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", expression, err)
+	}
+	return &Schedule{cron: sched, calendar: calendar}, nil
+}
+
+// Next returns the first nominal occurrence strictly after t, and when it should execute once
+// moved off weekends and holidays
+func (s *Schedule) Next(t time.Time) (occurrence, execution time.Time) {
+	occurrence = s.cron.Next(t.UTC())
+	return occurrence, s.calendar.Adjust(occurrence)
+}
+
+// Plan sets the first occurrence of order after now, or after its start date if that is later,
+// and deactivates the order if that occurrence falls past its end date. When a preceding roll
+// moves the run before now it is due straight away.
+func (s *Schedule) Plan(order *models.StandingOrder, now time.Time) {
+	from := now
+	if order.StartDate.After(now) {
+		from = order.StartDate.Add(-time.Nanosecond)
+	}
+	order.NextOccurrence, order.NextExecution = s.Next(from)
+	if order.EndDate != nil && order.NextOccurrence.After(*order.EndDate) {
+		order.Active = false
+	}
+}
@@ -0,0 +1,145 @@
+package scheduler
+
+import (
+	"accountProducer/models"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+func date(value string) time.Time {
+	t, _ := time.Parse("2006-01-02 15:04", value)
+	return t
+}
+
+// TestCalendarAdjust tests rolling weekends and holidays in both directions
+func TestCalendarAdjust(t *testing.T) {
+	weekend, err := ParseWeekend("Sat, Sun")
+	assert.NoError(t, err)
+
+	following, err := NewCalendar(weekend, []time.Time{date("2025-03-03 00:00")}, "following")
+	assert.NoError(t, err)
+	// Saturday 1 March rolls past Sunday and the Monday holiday
+	assert.Equal(t, date("2025-03-04 09:00"), following.Adjust(date("2025-03-01 09:00")))
+	assert.Equal(t, date("2025-03-05 09:00"), following.Adjust(date("2025-03-05 09:00")))
+
+	preceding, err := NewCalendar(weekend, nil, "preceding")
+	assert.NoError(t, err)
+	assert.Equal(t, date("2025-02-28 09:00"), preceding.Adjust(date("2025-03-01 09:00")))
+
+	_, err = NewCalendar(weekend, nil, "modified")
+	assert.Error(t, err)
+	_, err = ParseWeekend("Sat,Funday")
+	assert.Error(t, err)
+}
+
+// TestSchedulePlan tests that the first run honours the start date and the calendar
+func TestSchedulePlan(t *testing.T) {
+	calendar, _ := NewCalendar([]time.Weekday{time.Saturday, time.Sunday}, nil, "following")
+	schedule, err := NewSchedule("0 9 1 * *", calendar)
+	assert.NoError(t, err)
+
+	order := &models.StandingOrder{StartDate: date("2025-02-10 00:00"), Active: true}
+	schedule.Plan(order, date("2025-01-15 12:00"))
+	assert.Equal(t, date("2025-03-01 09:00"), order.NextOccurrence)
+	assert.Equal(t, date("2025-03-03 09:00"), order.NextExecution)
+	assert.True(t, order.Active)
+
+	end := date("2025-02-20 00:00")
+	order = &models.StandingOrder{StartDate: date("2025-02-10 00:00"), EndDate: &end, Active: true}
+	schedule.Plan(order, date("2025-01-15 12:00"))
+	assert.False(t, order.Active)
+
+	_, err = NewSchedule("every tuesday", calendar)
+	assert.Error(t, err)
+}
+
+type fakeStore struct {
+	orders   []models.StandingOrder
+	advanced map[string]time.Time
+}
+
+func (f *fakeStore) GetDueStandingOrders(ctx context.Context, now time.Time) ([]models.StandingOrder, error) {
+	return append([]models.StandingOrder{}, f.orders...), nil
+}
+
+func (f *fakeStore) AdvanceStandingOrder(ctx context.Context, order *models.StandingOrder, executed time.Time) (bool, error) {
+	if next, ok := f.advanced[order.ID]; ok && next.After(executed) {
+		return false, nil
+	}
+	f.advanced[order.ID] = order.NextOccurrence
+	return true, nil
+}
+
+type fakePublisher struct {
+	messages []models.Transaction
+}
+
+func (f *fakePublisher) PushOrderToQueue(topic string, message []byte) error {
+	var transaction models.Transaction
+	if err := json.Unmarshal(message, &transaction); err != nil {
+		return err
+	}
+	f.messages = append(f.messages, transaction)
+	return nil
+}
+
+// TestRunDueIsDeterministic tests that replicas running the same occurrence publish the same transaction ID
+func TestRunDueIsDeterministic(t *testing.T) {
+	calendar, _ := NewCalendar([]time.Weekday{time.Saturday, time.Sunday}, nil, "following")
+	order := models.StandingOrder{
+		ID:             "rent",
+		FromAccountID:  "acc123",
+		ToAccountID:    "acc456",
+		Amount:         1200,
+		Schedule:       "0 9 1 * *",
+		Active:         true,
+		NextOccurrence: date("2025-03-01 09:00"),
+		NextExecution:  date("2025-03-03 09:00"),
+	}
+	store := &fakeStore{orders: []models.StandingOrder{order}, advanced: map[string]time.Time{}}
+	publisher := &fakePublisher{}
+	logger := hclog.NewNullLogger()
+
+	// Two replicas pick up the same due order before either advances it
+	for i := 0; i < 2; i++ {
+		runner := NewRunner(store, publisher, calendar, &logger)
+		assert.NoError(t, runner.RunDue(context.Background(), date("2025-03-03 09:00")))
+	}
+
+	assert.Len(t, publisher.messages, 2)
+	assert.Equal(t, publisher.messages[0].ID, publisher.messages[1].ID)
+	assert.Equal(t, TransactionID("rent", date("2025-03-01 09:00")), publisher.messages[0].ID)
+	assert.Equal(t, "transfer", publisher.messages[0].TransactionType)
+	assert.Equal(t, date("2025-04-01 09:00"), store.advanced["rent"])
+}
+
+// TestRunDueCatchesUp tests that every occurrence missed during downtime is run in one pass
+func TestRunDueCatchesUp(t *testing.T) {
+	calendar, _ := NewCalendar([]time.Weekday{time.Saturday, time.Sunday}, nil, "following")
+	order := models.StandingOrder{
+		ID:             "rent",
+		FromAccountID:  "acc123",
+		ToAccountID:    "acc456",
+		Amount:         1200,
+		Schedule:       "0 9 1 * *",
+		Active:         true,
+		NextOccurrence: date("2025-01-01 09:00"),
+		NextExecution:  date("2025-01-01 09:00"),
+	}
+	store := &fakeStore{orders: []models.StandingOrder{order}, advanced: map[string]time.Time{}}
+	publisher := &fakePublisher{}
+	logger := hclog.NewNullLogger()
+
+	runner := NewRunner(store, publisher, calendar, &logger)
+	assert.NoError(t, runner.RunDue(context.Background(), date("2025-03-10 12:00")))
+
+	assert.Len(t, publisher.messages, 3)
+	assert.Equal(t, TransactionID("rent", date("2025-01-01 09:00")), publisher.messages[0].ID)
+	assert.Equal(t, TransactionID("rent", date("2025-03-01 09:00")), publisher.messages[2].ID)
+	assert.Equal(t, date("2025-04-01 09:00"), store.advanced["rent"])
+}
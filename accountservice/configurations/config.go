@@ -1,14 +1,26 @@
 package configurations
 
 import (
+	"shared/screening"
+	"strings"
+
 	"github.com/nicholasjackson/env"
 )
 
 var appConfig *appConfigs
 var goUri *string = env.String("GO_URI", false, "0.0.0.0:9090", "Bind address for the app server")
+var watchlistFile *string = env.String("WATCHLIST_FILE", false, "", "Sanctions watchlist (CSV or OFAC sdn.xml) new account holders are screened against, empty to disable")
+var screeningBlockScore *float64 = env.Float64("SCREENING_BLOCK_SCORE", false, 0.95, "Name similarity at or above which an account is not opened")
+var screeningFlagScore *float64 = env.Float64("SCREENING_FLAG_SCORE", false, 0.85, "Name similarity at or above which an account is opened inactive")
+var kafkaBroker *string = env.String("KAFKA_BROKER", false, "kafka:9092", "Comma-separated Kafka brokers every topic is consumed from and published to")
+var topicMapFile *string = env.String("TOPIC_MAP_FILE", false, "", "JSON topic map naming the topics on the cluster, empty for the built-in names")
 
 type appConfigs struct {
-	appURI string
+	appURI        string
+	watchlistFile string
+	screening     screening.Thresholds
+	kafkaBrokers  []string
+	topicMapFile  string
 }
 
 func NewAppConfig() (*appConfigs, error) {
@@ -22,7 +34,11 @@ func NewAppConfig() (*appConfigs, error) {
 	}
 
 	appConfig = &appConfigs{
-		appURI: *goUri,
+		appURI:        *goUri,
+		watchlistFile: *watchlistFile,
+		screening:     screening.Thresholds{Block: *screeningBlockScore, Flag: *screeningFlagScore},
+		kafkaBrokers:  strings.Split(*kafkaBroker, ","),
+		topicMapFile:  *topicMapFile,
 	}
 	return appConfig, nil
 }
@@ -31,3 +47,23 @@ func NewAppConfig() (*appConfigs, error) {
 func (apconfig *appConfigs) GetAppURI() *string {
 	return &apconfig.appURI
 }
+
+// gets the sanctions watchlist file, empty when screening is disabled
+func (apconfig *appConfigs) GetWatchlistFile() string {
+	return apconfig.watchlistFile
+}
+
+// gets the similarity scores at which screened names are blocked or flagged
+func (apconfig *appConfigs) GetScreeningThresholds() screening.Thresholds {
+	return apconfig.screening
+}
+
+// gets the brokers of the Kafka cluster
+func (apconfig *appConfigs) GetKafkaBrokers() []string {
+	return apconfig.kafkaBrokers
+}
+
+// gets the topic map file, empty when the topics keep their built-in names
+func (apconfig *appConfigs) GetTopicMapFile() string {
+	return apconfig.topicMapFile
+}
@@ -5,9 +5,13 @@ go 1.23.5
 require (
 	github.com/IBM/sarama v1.45.0
 	github.com/google/uuid v1.6.0
+	github.com/gorilla/mux v1.8.1
 	github.com/hashicorp/go-hclog v1.6.3
 	github.com/jackc/pgx/v5 v5.7.2
 	github.com/nicholasjackson/env v0.6.1
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
+	google.golang.org/protobuf v1.36.11
+	shared v0.0.0
 )
 
 require (
@@ -39,3 +43,5 @@ require (
 	golang.org/x/sys v0.29.0 // indirect
 	golang.org/x/text v0.21.0 // indirect
 )
+
+replace shared => ../shared
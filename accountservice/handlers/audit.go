@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"accountservice/database"
+	"accountservice/repositories"
+	"context"
+	"shared/audit"
+)
+
+// TrackResources tells the audit recorder how to load the resources the customer API changes, so
+// that their state before and after every change is recorded
+func TrackResources(recorder *audit.Recorder, db *database.PostgresPoolDB) {
+	customers := repositories.NewCustomerRepository(db)
+	holders := repositories.NewHolderRepository(db)
+
+	customer := func(ctx context.Context, vars map[string]string) (any, error) {
+		return customers.GetCustomer(ctx, vars["id"])
+	}
+	recorder.Track("/customers/{id}", customer)
+	recorder.Track("/customers/{id}/kyc", customer)
+	recorder.Track("/customers/{id}/accounts/{accountNumber}", func(ctx context.Context, vars map[string]string) (any, error) {
+		return customers.ListCustomerAccounts(ctx, vars["id"])
+	})
+	recorder.Track("/accounts/{accountNumber}/holders/{customerId}", func(ctx context.Context, vars map[string]string) (any, error) {
+		list, err := holders.ListHolders(ctx, vars["accountNumber"])
+		if err != nil {
+			return nil, err
+		}
+		for i := range list {
+			if list[i].CustomerID.String() == vars["customerId"] {
+				return &list[i], nil
+			}
+		}
+		return nil, nil
+	})
+}
@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"accountservice/database"
+	"accountservice/models"
+	"accountservice/repositories"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"shared/screening"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/hashicorp/go-hclog"
+)
+
+// CustomerHandler serves the customer (KYC) profile endpoints
+type CustomerHandler struct {
+	customerrepo  repositories.CustomerRepo
+	screeningrepo repositories.ScreeningRepo
+	screener      *screening.Screener
+	loggs         *hclog.Logger
+}
+
+// NewCustomerHandler creates a new CustomerHandler instance that screens customers' legal names
+// against screener when they are registered or verified; a nil screener disables screening
+func NewCustomerHandler(db *database.PostgresPoolDB, screener *screening.Screener, lobbs *hclog.Logger) *CustomerHandler {
+	return &CustomerHandler{
+		customerrepo:  repositories.NewCustomerRepository(db),
+		screeningrepo: repositories.NewScreeningRepository(db),
+		screener:      screener,
+		loggs:         lobbs,
+	}
+}
+
+// CreateCustomer registers a customer pending verification. A customer whose name is on the
+// sanctions watchlist is registered as rejected, and a possible match is noted for the reviewer.
+func (h *CustomerHandler) CreateCustomer(w http.ResponseWriter, r *http.Request) {
+	var customer models.Customer
+	if err := json.NewDecoder(r.Body).Decode(&customer); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.customerrepo.CreateCustomer(r.Context(), &customer); err != nil {
+		h.writeError(w, "create", err)
+		return
+	}
+	(*h.loggs).Info("Customer created", "Customer", customer.ID)
+
+	result, err := h.screen(r.Context(), &customer)
+	if err != nil {
+		h.writeError(w, "screen", err)
+		return
+	}
+	if result.Decision != screening.Clear {
+		status := "pending"
+		if result.Decision == screening.Block {
+			status = "rejected"
+		}
+		screened, err := h.customerrepo.SetKYCStatus(r.Context(), customer.ID.String(), status, watchlistNote(result))
+		if err != nil {
+			h.writeError(w, "screen", err)
+			return
+		}
+		(*h.loggs).Warn("Customer matches the watchlist", "Customer", customer.ID, "Decision", result.Decision, "Entry", result.EntryID)
+		customer = *screened
+	}
+	writeJSON(w, http.StatusCreated, customer)
+}
+
+// GetCustomer returns a customer
+func (h *CustomerHandler) GetCustomer(w http.ResponseWriter, r *http.Request) {
+	id, ok := customerID(w, r)
+	if !ok {
+		return
+	}
+
+	customer, err := h.customerrepo.GetCustomer(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "get", err)
+		return
+	}
+	if customer == nil {
+		http.Error(w, "Customer not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, customer)
+}
+
+// UpdateCustomer replaces the details of a customer
+func (h *CustomerHandler) UpdateCustomer(w http.ResponseWriter, r *http.Request) {
+	id, ok := customerID(w, r)
+	if !ok {
+		return
+	}
+	var customer models.Customer
+	if err := json.NewDecoder(r.Body).Decode(&customer); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	customer.ID = uuid.MustParse(id)
+
+	if err := h.customerrepo.UpdateCustomer(r.Context(), &customer); err != nil {
+		h.writeError(w, "update", err)
+		return
+	}
+	(*h.loggs).Info("Customer updated", "Customer", id, "KYCStatus", customer.KYCStatus)
+	writeJSON(w, http.StatusOK, customer)
+}
+
+// DeleteCustomer removes a customer that holds no accounts
+func (h *CustomerHandler) DeleteCustomer(w http.ResponseWriter, r *http.Request) {
+	id, ok := customerID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.customerrepo.DeleteCustomer(r.Context(), id); err != nil {
+		h.writeError(w, "delete", err)
+		return
+	}
+	(*h.loggs).Info("Customer deleted", "Customer", id)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"msg":     fmt.Sprintf("customer %s deleted", id),
+	})
+}
+
+// SetKYCStatus records the outcome of a customer verification. A customer whose name is blocked
+// by the sanctions watchlist cannot be verified.
+func (h *CustomerHandler) SetKYCStatus(w http.ResponseWriter, r *http.Request) {
+	id, ok := customerID(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		Status string `json:"status"`
+		Note   string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if body.Status == "verified" {
+		customer, err := h.customerrepo.GetCustomer(r.Context(), id)
+		if err != nil {
+			h.writeError(w, "verify", err)
+			return
+		}
+		if customer == nil {
+			http.Error(w, "Customer not found", http.StatusNotFound)
+			return
+		}
+		result, err := h.screen(r.Context(), customer)
+		if err != nil {
+			h.writeError(w, "screen", err)
+			return
+		}
+		if result.Decision == screening.Block {
+			(*h.loggs).Warn("Customer verification refused", "Customer", id, "Entry", result.EntryID)
+			http.Error(w, watchlistNote(result), http.StatusConflict)
+			return
+		}
+	}
+
+	customer, err := h.customerrepo.SetKYCStatus(r.Context(), id, body.Status, body.Note)
+	if err != nil {
+		h.writeError(w, "verify", err)
+		return
+	}
+	(*h.loggs).Info("Customer KYC status changed", "Customer", id, "KYCStatus", customer.KYCStatus)
+	writeJSON(w, http.StatusOK, customer)
+}
+
+// ListCustomerAccounts returns the accounts held by a customer
+func (h *CustomerHandler) ListCustomerAccounts(w http.ResponseWriter, r *http.Request) {
+	id, ok := customerID(w, r)
+	if !ok {
+		return
+	}
+
+	customer, err := h.customerrepo.GetCustomer(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "get", err)
+		return
+	}
+	if customer == nil {
+		http.Error(w, "Customer not found", http.StatusNotFound)
+		return
+	}
+	accounts, err := h.customerrepo.ListCustomerAccounts(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "list accounts of", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, accounts)
+}
+
+// LinkAccount attaches an existing account to a customer
+func (h *CustomerHandler) LinkAccount(w http.ResponseWriter, r *http.Request) {
+	id, ok := customerID(w, r)
+	if !ok {
+		return
+	}
+	accountNumber := mux.Vars(r)["accountNumber"]
+
+	if err := h.customerrepo.LinkAccount(r.Context(), id, accountNumber); err != nil {
+		h.writeError(w, "link account to", err)
+		return
+	}
+	(*h.loggs).Info("Account linked to customer", "Customer", id, "Account", accountNumber)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"msg":     fmt.Sprintf("account %s linked to customer %s", accountNumber, id),
+	})
+}
+
+// RegisterRoutes wires the customer endpoints onto the router
+func (h *CustomerHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/customers", h.CreateCustomer).Methods("POST")
+	router.HandleFunc("/customers/{id}", h.GetCustomer).Methods("GET")
+	router.HandleFunc("/customers/{id}", h.UpdateCustomer).Methods("PUT")
+	router.HandleFunc("/customers/{id}", h.DeleteCustomer).Methods("DELETE")
+	router.HandleFunc("/customers/{id}/kyc", h.SetKYCStatus).Methods("PUT")
+	router.HandleFunc("/customers/{id}/accounts", h.ListCustomerAccounts).Methods("GET")
+	router.HandleFunc("/customers/{id}/accounts/{accountNumber}", h.LinkAccount).Methods("PUT")
+}
+
+// writeError maps repository errors onto HTTP statuses
+func (h *CustomerHandler) writeError(w http.ResponseWriter, action string, err error) {
+	switch {
+	case errors.Is(err, repositories.ErrCustomerNotFound), errors.Is(err, repositories.ErrAccountNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, repositories.ErrInvalidCustomer):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		(*h.loggs).Error("Customer request failed", "Action", action, "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to %s customer: %v", action, err), http.StatusInternalServerError)
+	}
+}
+
+// screen screens the legal name of a customer and records the result
+func (h *CustomerHandler) screen(ctx context.Context, customer *models.Customer) (screening.Result, error) {
+	return repositories.ScreenNames(ctx, h.screeningrepo, h.screener, "customer", customer.ID.String(), "customer", customer.FullName)
+}
+
+// watchlistNote describes the watchlist entry a screened name matched
+func watchlistNote(result screening.Result) string {
+	return fmt.Sprintf("name %q matches watchlist entry %s %q (%s, score %.2f)",
+		result.Name, result.EntryID, result.MatchedName, result.Program, result.Score)
+}
+
+// customerID reads and validates the {id} path variable
+func customerID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := mux.Vars(r)["id"]
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(w, "Invalid customer id", http.StatusBadRequest)
+		return "", false
+	}
+	return id, true
+}
+
+// writeJSON encodes body as the JSON response
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		fmt.Println(err)
+	}
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"accountservice/database"
+	"accountservice/models"
+	"accountservice/repositories"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/hashicorp/go-hclog"
+)
+
+// HolderHandler serves the joint account holder endpoints
+type HolderHandler struct {
+	holderrepo repositories.HolderRepo
+	loggs      *hclog.Logger
+}
+
+// NewHolderHandler creates a new HolderHandler instance
+func NewHolderHandler(db *database.PostgresPoolDB, lobbs *hclog.Logger) *HolderHandler {
+	return &HolderHandler{
+		holderrepo: repositories.NewHolderRepository(db),
+		loggs:      lobbs,
+	}
+}
+
+// ListHolders returns the holders of an account
+func (h *HolderHandler) ListHolders(w http.ResponseWriter, r *http.Request) {
+	accountNumber := mux.Vars(r)["accountNumber"]
+
+	holders, err := h.holderrepo.ListHolders(r.Context(), accountNumber)
+	if err != nil {
+		h.writeError(w, "list", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, holders)
+}
+
+// SetHolder adds a customer to an account with a role, or changes their role
+func (h *HolderHandler) SetHolder(w http.ResponseWriter, r *http.Request) {
+	accountNumber := mux.Vars(r)["accountNumber"]
+	customer, err := uuid.Parse(mux.Vars(r)["customerId"])
+	if err != nil {
+		http.Error(w, "Invalid customer id", http.StatusBadRequest)
+		return
+	}
+	var body struct {
+		Role string `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	holder := &models.AccountHolder{AccountNumber: accountNumber, CustomerID: customer, Role: body.Role}
+	if err := h.holderrepo.SetHolder(r.Context(), holder); err != nil {
+		h.writeError(w, "set", err)
+		return
+	}
+	(*h.loggs).Info("Account holder set", "Account", accountNumber, "Customer", customer, "Role", holder.Role)
+	writeJSON(w, http.StatusOK, holder)
+}
+
+// RemoveHolder takes a customer off an account
+func (h *HolderHandler) RemoveHolder(w http.ResponseWriter, r *http.Request) {
+	accountNumber := mux.Vars(r)["accountNumber"]
+	customer := mux.Vars(r)["customerId"]
+	if _, err := uuid.Parse(customer); err != nil {
+		http.Error(w, "Invalid customer id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.holderrepo.RemoveHolder(r.Context(), accountNumber, customer); err != nil {
+		h.writeError(w, "remove", err)
+		return
+	}
+	(*h.loggs).Info("Account holder removed", "Account", accountNumber, "Customer", customer)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"msg":     fmt.Sprintf("customer %s removed from account %s", customer, accountNumber),
+	})
+}
+
+// RegisterRoutes wires the holder endpoints onto the router
+func (h *HolderHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/accounts/{accountNumber}/holders", h.ListHolders).Methods("GET")
+	router.HandleFunc("/accounts/{accountNumber}/holders/{customerId}", h.SetHolder).Methods("PUT")
+	router.HandleFunc("/accounts/{accountNumber}/holders/{customerId}", h.RemoveHolder).Methods("DELETE")
+}
+
+// writeError maps repository errors onto HTTP statuses
+func (h *HolderHandler) writeError(w http.ResponseWriter, action string, err error) {
+	switch {
+	case errors.Is(err, repositories.ErrCustomerNotFound), errors.Is(err, repositories.ErrAccountNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, repositories.ErrInvalidCustomer):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		(*h.loggs).Error("Holder request failed", "Action", action, "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to %s account holder: %v", action, err), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,94 @@
+package kafka
+
+import (
+	"fmt"
+	"shared/events"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// KafkaController publishes messages to the cluster at Brokers. Topics are given by their ID in
+// the topic map, which names them on the cluster.
+type KafkaController struct {
+	Brokers []string
+}
+
+// PushKeyedToQueue publishes a message with a key, so that messages sharing the key are consumed in
+// order. The message is encoded as an event starting a new trace.
+func (k *KafkaController) PushKeyedToQueue(topic, key string, message []byte) error {
+	message, headers, err := events.Encode(topic, message, events.Trace{})
+	if err != nil {
+		return fmt.Errorf("failed to encode message for %s: %w", topic, err)
+	}
+
+	config := sarama.NewConfig()
+	config.Producer.Return.Successes = true
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Retry.Max = 5
+
+	name := events.TopicName(topic)
+	if err := k.createTopicIfNotExists(config, name); err != nil {
+		return err
+	}
+	producer, err := sarama.NewSyncProducer(k.Brokers, config)
+	if err != nil {
+		return err
+	}
+	defer producer.Close()
+
+	msg := &sarama.ProducerMessage{
+		Topic:   name,
+		Key:     sarama.StringEncoder(key),
+		Value:   sarama.ByteEncoder(message),
+		Headers: recordHeaders(headers),
+	}
+	_, _, err = producer.SendMessage(msg)
+	return err
+}
+
+// createTopicIfNotExists creates a topic with 3 partitions and 7 days retention if it doesn't already exist
+func (k *KafkaController) createTopicIfNotExists(config *sarama.Config, topic string) error {
+	admin, err := sarama.NewClusterAdmin(k.Brokers, config)
+	if err != nil {
+		return fmt.Errorf("failed to create cluster admin: %v", err)
+	}
+	defer admin.Close()
+
+	topics, err := admin.ListTopics()
+	if err != nil {
+		return fmt.Errorf("failed to list topics: %v", err)
+	}
+	if _, exists := topics[topic]; exists {
+		return nil
+	}
+	retention := "604800000"
+	detail := &sarama.TopicDetail{
+		NumPartitions:     3,
+		ReplicationFactor: 1,
+		ConfigEntries:     map[string]*string{"retention.ms": &retention},
+	}
+	if err := admin.CreateTopic(topic, detail, false); err != nil {
+		return fmt.Errorf("failed to create topic %s: %v", topic, err)
+	}
+	time.Sleep(2 * time.Second)
+	return nil
+}
+
+// recordHeaders converts the headers of an event to Kafka record headers
+func recordHeaders(headers events.Headers) []sarama.RecordHeader {
+	records := make([]sarama.RecordHeader, 0, len(headers))
+	for key, value := range headers {
+		records = append(records, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+	}
+	return records
+}
+
+// eventHeaders converts the headers of a consumed Kafka record to event headers
+func eventHeaders(records []*sarama.RecordHeader) events.Headers {
+	headers := make(events.Headers, len(records))
+	for _, record := range records {
+		headers[string(record.Key)] = string(record.Value)
+	}
+	return headers
+}
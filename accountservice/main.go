@@ -1,23 +1,32 @@
 package main
 
 import (
+	"accountservice/configurations"
 	"accountservice/database"
+	"accountservice/handlers"
 	"accountservice/kafka"
 	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"shared/audit"
+	"shared/events"
+	"shared/screening"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/IBM/sarama"
+	"github.com/gorilla/mux"
 	"github.com/hashicorp/go-hclog"
 )
 
 func main() {
 
 	fmt.Println("Starting to develop banking application")
+	events.Producer = "accountservice"
 	// logging app file
 	logFile, err := os.OpenFile("app.log", os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
 	if err != nil {
@@ -46,15 +55,41 @@ func main() {
 	}
 	defer db.Close(ctx)
 
+	uri, err := configurations.NewAppConfig()
+	if err != nil {
+		loggs.Error("Not able to create Retrieve App Configurations")
+		os.Exit(1)
+	}
+
+	if path := uri.GetTopicMapFile(); path != "" {
+		if err := events.LoadTopics(path); err != nil {
+			loggs.Error("Not able to load topic map", "File", path, "Error", err)
+			os.Exit(1)
+		}
+	}
+	brokers := uri.GetKafkaBrokers()
+
+	// Screen new account holders and customers against the sanctions watchlist
+	var screener *screening.Screener
+	if path := uri.GetWatchlistFile(); path != "" {
+		entries, err := screening.LoadFile(path)
+		if err != nil {
+			loggs.Error("Not able to load watchlist", "File", path, "Error", err)
+			os.Exit(1)
+		}
+		screener = screening.NewScreener(entries, uri.GetScreeningThresholds())
+		loggs.Info("Loaded watchlist", "File", path, "Entries", len(entries), "Names", screener.Size())
+	}
+
 	// handlers
-	kafkaConsumer := kafka.NewKafkaConsumer(db)
+	recorder := audit.NewRecorder("accountservice", &kafka.KafkaController{Brokers: brokers}, &loggs)
+	kafkaConsumer := kafka.NewKafkaConsumer(db, screener, recorder)
 
 	// Kafka configuration
 	config := sarama.NewConfig()
 	config.Consumer.Group.Rebalance.Strategy = sarama.BalanceStrategyRoundRobin
 	config.Consumer.Offsets.Initial = sarama.OffsetNewest
 	config.Consumer.Return.Errors = true
-	brokers := []string{"kafka:9092"}
 	groupID := "account-creation-group"
 
 	// Create consumer group
@@ -73,7 +108,7 @@ func main() {
 		defer wg.Done()
 		for {
 			// Reconnect and resume on errors
-			err = consumerGroup.Consume(ctx, []string{"account-creation"}, kafkaConsumer)
+			err = consumerGroup.Consume(ctx, events.TopicNames("account-creation"), kafkaConsumer)
 			if err != nil {
 				log.Printf("Consumer error: %v", err)
 			}
@@ -92,12 +127,42 @@ func main() {
 
 	log.Println("Consumer group started. Waiting for messages...")
 
+	// Customer and account holder API
+	router := mux.NewRouter()
+	router.Use(recorder.Middleware)
+	handlers.TrackResources(recorder, db)
+	handlers.NewCustomerHandler(db, screener, &loggs).RegisterRoutes(router)
+	handlers.NewHolderHandler(db, &loggs).RegisterRoutes(router)
+
+	opts := hclog.StandardLoggerOptions{
+		InferLevels: true,
+	}
+	httpServer := http.Server{
+		Addr:         *uri.GetAppURI(),
+		Handler:      router,
+		ErrorLog:     loggs.StandardLogger(&opts),
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		IdleTimeout:  120 * time.Second,
+	}
+
+	go func() {
+		loggs.Info("starting customer server on ", "Port", *uri.GetAppURI())
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			loggs.Error("Error starting customer server", "Error", err)
+			os.Exit(1)
+		}
+	}()
+
 	// Handle SIGINT/SIGTERM for shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 	<-sigChan
 
 	log.Println("Shutting down consumer...")
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer shutdownCancel()
+	httpServer.Shutdown(shutdownCtx)
 	cancel()
 	wg.Wait()
 }
@@ -1,20 +1,25 @@
 package models
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type Account struct {
-	ID            uuid.UUID `json:"id"`             // Unique identifier for the account
-	AccountNumber string    `json:"account_number"` // Unique account number
-	Username      string    `json:"username"`       // Account username
-	Email         string    `json:"email"`          // Account email address
-	Balance       float64   `json:"balance"`        // Account balance (e.g., for financial apps)
-	CreatedAt     time.Time `json:"created_at"`     // Timestamp of account creation
-	UpdatedAt     time.Time `json:"updated_at"`     // Timestamp of last update
-	IsActive      bool      `json:"is_active"`      // Account status (active or inactive)
+	ID            uuid.UUID  `json:"id"`                    // Unique identifier for the account
+	AccountNumber string     `json:"account_number"`        // Unique account number
+	Username      string     `json:"username"`              // Account username
+	Email         string     `json:"email"`                 // Account email address
+	Balance       float64    `json:"balance"`               // Account balance (e.g., for financial apps)
+	CreatedAt     time.Time  `json:"created_at"`            // Timestamp of account creation
+	UpdatedAt     time.Time  `json:"updated_at"`            // Timestamp of last update
+	IsActive      bool       `json:"is_active"`             // Account status (active or inactive)
+	Tier          string     `json:"tier"`                  // Limit tier (e.g., "basic", "premium", "business")
+	Currency      string     `json:"currency"`              // ISO 4217 code the balance is held in (defaults to "USD")
+	CustomerID    *uuid.UUID `json:"customer_id,omitempty"` // Customer holding the account; the account can only transact once the customer is verified
 }
 
 // NewAccount creates a new Account instance with default values
@@ -27,5 +32,21 @@ func NewAccount(username, email, password string) *Account {
 		CreatedAt: now,
 		UpdatedAt: now,
 		IsActive:  true, // Default to active
+		Tier:      "basic",
+		Currency:  "USD",
 	}
 }
+
+// NormalizeCurrency upper-cases a three letter ISO 4217 code and rejects anything else
+func NormalizeCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 3 {
+		return "", fmt.Errorf("invalid currency code %q", code)
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return "", fmt.Errorf("invalid currency code %q", code)
+		}
+	}
+	return code, nil
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Customer is the person behind one or more accounts, with the identity details collected for
+// know-your-customer (KYC) checks
+type Customer struct {
+	ID               uuid.UUID  `json:"id"`                    // Unique identifier for the customer
+	FullName         string     `json:"full_name"`             // Legal name as on the identity document
+	Email            string     `json:"email"`                 // Contact email address, unique per customer
+	Phone            string     `json:"phone,omitempty"`       // Contact phone number
+	DateOfBirth      string     `json:"date_of_birth"`         // Date of birth (YYYY-MM-DD)
+	Nationality      string     `json:"nationality"`           // ISO 3166-1 alpha-2 country code
+	Address          string     `json:"address"`               // Residential address
+	IDDocumentType   string     `json:"id_document_type"`      // "passport", "national_id" or "driving_licence"
+	IDDocumentNumber string     `json:"id_document_number"`    // Number of the identity document
+	KYCStatus        string     `json:"kyc_status"`            // Verification status ("pending", "verified" or "rejected")
+	KYCNote          string     `json:"kyc_note,omitempty"`    // Reason given with the last verification decision
+	VerifiedAt       *time.Time `json:"verified_at,omitempty"` // Timestamp the customer was verified
+	CreatedAt        time.Time  `json:"created_at"`            // Timestamp of customer creation
+	UpdatedAt        time.Time  `json:"updated_at"`            // Timestamp of last update
+}
+
+// Validate normalises the customer and checks the KYC fields are complete
+func (c *Customer) Validate() error {
+	c.FullName = strings.TrimSpace(c.FullName)
+	c.Email = strings.TrimSpace(c.Email)
+	c.Nationality = strings.ToUpper(strings.TrimSpace(c.Nationality))
+	c.IDDocumentType = strings.ToLower(strings.TrimSpace(c.IDDocumentType))
+	c.IDDocumentNumber = strings.TrimSpace(c.IDDocumentNumber)
+
+	if c.FullName == "" {
+		return fmt.Errorf("full name is required")
+	}
+	if _, err := mail.ParseAddress(c.Email); err != nil {
+		return fmt.Errorf("invalid email %q", c.Email)
+	}
+	birth, err := time.Parse("2006-01-02", c.DateOfBirth)
+	if err != nil {
+		return fmt.Errorf("invalid date of birth %q, expected YYYY-MM-DD", c.DateOfBirth)
+	}
+	if birth.After(time.Now()) {
+		return fmt.Errorf("date of birth %s is in the future", c.DateOfBirth)
+	}
+	if len(c.Nationality) != 2 || strings.IndexFunc(c.Nationality, func(r rune) bool { return r < 'A' || r > 'Z' }) >= 0 {
+		return fmt.Errorf("invalid nationality %q, expected an ISO 3166-1 alpha-2 code", c.Nationality)
+	}
+	if strings.TrimSpace(c.Address) == "" {
+		return fmt.Errorf("address is required")
+	}
+	switch c.IDDocumentType {
+	case "passport", "national_id", "driving_licence":
+	default:
+		return fmt.Errorf("invalid id document type %q", c.IDDocumentType)
+	}
+	if c.IDDocumentNumber == "" {
+		return fmt.Errorf("id document number is required")
+	}
+	return nil
+}
+
+// ValidKYCStatus reports whether status is a verification status
+func ValidKYCStatus(status string) bool {
+	return status == "pending" || status == "verified" || status == "rejected"
+}
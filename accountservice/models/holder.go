@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AccountHolder gives a customer access to an account. Owners and joint holders can transact
+// and approve transactions; viewers can only see the account.
+type AccountHolder struct {
+	AccountNumber string    `json:"account_number"` // Account the customer holds
+	CustomerID    uuid.UUID `json:"customer_id"`    // Holder
+	Role          string    `json:"role"`           // "owner", "joint" or "viewer"
+	AddedAt       time.Time `json:"added_at"`       // Timestamp the holder was added
+}
+
+// ValidHolderRole reports whether role is an account holder role
+func ValidHolderRole(role string) bool {
+	return role == "owner" || role == "joint" || role == "viewer"
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ScreeningResult is the audit record of one name screened against the sanctions watchlist
+type ScreeningResult struct {
+	ID          uuid.UUID `json:"id"`                     // Unique identifier for the result
+	Subject     string    `json:"subject"`                // What was screened ("account", "customer" or "transfer")
+	Reference   string    `json:"reference"`              // Account number, customer ID or transaction ID
+	Party       string    `json:"party"`                  // Role of the name ("account_holder", "customer", "payer" or "beneficiary")
+	Name        string    `json:"name"`                   // Name that was screened
+	Decision    string    `json:"decision"`               // Outcome ("clear", "flag" or "block")
+	Score       float64   `json:"score"`                  // Best similarity found, 0 to 1
+	EntryID     string    `json:"entry_id,omitempty"`     // Closest watchlist entry
+	MatchedName string    `json:"matched_name,omitempty"` // Name or alias of the closest entry
+	Program     string    `json:"program,omitempty"`      // Sanctions program of the closest entry
+	CreatedAt   time.Time `json:"created_at"`             // Timestamp of the screening
+}
@@ -0,0 +1,254 @@
+package repositories
+
+import (
+	"accountservice/database"
+	"accountservice/models"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrCustomerNotFound is returned when a customer ID does not exist
+var ErrCustomerNotFound = errors.New("customer not found")
+
+// ErrAccountNotFound is returned when an account number does not exist
+var ErrAccountNotFound = errors.New("account not found")
+
+// ErrInvalidCustomer wraps validation failures so handlers can answer 400 instead of 500
+var ErrInvalidCustomer = errors.New("invalid customer")
+
+// CustomerRepository implements CustomerRepo
+type CustomerRepository struct {
+	db *database.PostgresPoolDB
+}
+
+// NewCustomerRepository creates a new CustomerRepository
+func NewCustomerRepository(db *database.PostgresPoolDB) *CustomerRepository {
+	return &CustomerRepository{db: db}
+}
+
+const customerColumns = `id, full_name, email, COALESCE(phone, ''), to_char(date_of_birth, 'YYYY-MM-DD'), nationality, address,
+               id_document_type, id_document_number, kyc_status, COALESCE(kyc_note, ''), verified_at, created_at, updated_at`
+
+// CreateCustomer stores a new customer pending verification
+func (r *CustomerRepository) CreateCustomer(ctx context.Context, customer *models.Customer) error {
+	if err := customer.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCustomer, err)
+	}
+	query := `
+        INSERT INTO usersschema.customers (full_name, email, phone, date_of_birth, nationality, address, id_document_type, id_document_number)
+        VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8)
+        RETURNING ` + customerColumns
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	created, err := scanCustomer(conn.QueryRow(ctx, query, customer.FullName, customer.Email, customer.Phone, customer.DateOfBirth,
+		customer.Nationality, customer.Address, customer.IDDocumentType, customer.IDDocumentNumber))
+	if err != nil {
+		return customerWriteError("create", err)
+	}
+	*customer = *created
+	return nil
+}
+
+// GetCustomer returns a customer, or nil if it does not exist
+func (r *CustomerRepository) GetCustomer(ctx context.Context, id string) (*models.Customer, error) {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	customer, err := scanCustomer(conn.QueryRow(ctx, "SELECT "+customerColumns+" FROM usersschema.customers WHERE id = $1", id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+	return customer, nil
+}
+
+// UpdateCustomer replaces the details of a customer. Changing the name, date of birth,
+// nationality or identity document sends a verified customer back to pending, since the
+// verification no longer covers the stored identity.
+func (r *CustomerRepository) UpdateCustomer(ctx context.Context, customer *models.Customer) error {
+	if err := customer.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCustomer, err)
+	}
+	query := `
+        UPDATE usersschema.customers
+        SET kyc_status = CASE WHEN (full_name, date_of_birth, nationality, id_document_type, id_document_number)
+                                   IS DISTINCT FROM ($2, $5::date, $6, $8, $9)
+                              THEN 'pending' ELSE kyc_status END,
+            verified_at = CASE WHEN (full_name, date_of_birth, nationality, id_document_type, id_document_number)
+                                    IS DISTINCT FROM ($2, $5::date, $6, $8, $9)
+                               THEN NULL ELSE verified_at END,
+            full_name = $2, email = $3, phone = NULLIF($4, ''), date_of_birth = $5, nationality = $6,
+            address = $7, id_document_type = $8, id_document_number = $9, updated_at = NOW()
+        WHERE id = $1
+        RETURNING ` + customerColumns
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	updated, err := scanCustomer(conn.QueryRow(ctx, query, customer.ID, customer.FullName, customer.Email, customer.Phone,
+		customer.DateOfBirth, customer.Nationality, customer.Address, customer.IDDocumentType, customer.IDDocumentNumber))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("%w: %s", ErrCustomerNotFound, customer.ID)
+		}
+		return customerWriteError("update", err)
+	}
+	*customer = *updated
+	return nil
+}
+
+// DeleteCustomer removes a customer that holds no accounts
+func (r *CustomerRepository) DeleteCustomer(ctx context.Context, id string) error {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	result, err := conn.Exec(ctx, "DELETE FROM usersschema.customers WHERE id = $1", id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return fmt.Errorf("%w: customer %s still holds accounts", ErrInvalidCustomer, id)
+		}
+		return fmt.Errorf("failed to delete customer: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %s", ErrCustomerNotFound, id)
+	}
+	return nil
+}
+
+// SetKYCStatus records a verification decision
+func (r *CustomerRepository) SetKYCStatus(ctx context.Context, id, status, note string) (*models.Customer, error) {
+	if !models.ValidKYCStatus(status) {
+		return nil, fmt.Errorf("%w: invalid kyc status %q", ErrInvalidCustomer, status)
+	}
+	query := `
+        UPDATE usersschema.customers
+        SET kyc_status = $2,
+            kyc_note = NULLIF($3, ''),
+            verified_at = CASE WHEN $2 = 'verified' THEN NOW() ELSE NULL END,
+            updated_at = NOW()
+        WHERE id = $1
+        RETURNING ` + customerColumns
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	customer, err := scanCustomer(conn.QueryRow(ctx, query, id, status, note))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("%w: %s", ErrCustomerNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to set kyc status: %w", err)
+	}
+	return customer, nil
+}
+
+// ListCustomerAccounts returns the accounts held by a customer
+func (r *CustomerRepository) ListCustomerAccounts(ctx context.Context, id string) ([]models.Account, error) {
+	query := `
+        SELECT id, account_number, username, email, balance, created_at, updated_at, is_active, tier, currency, customer_id
+        FROM usersschema.accounts
+        WHERE customer_id = $1
+        ORDER BY created_at`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list customer accounts: %w", err)
+	}
+	defer rows.Close()
+
+	accounts := []models.Account{}
+	for rows.Next() {
+		var account models.Account
+		if err := rows.Scan(&account.ID, &account.AccountNumber, &account.Username, &account.Email, &account.Balance,
+			&account.CreatedAt, &account.UpdatedAt, &account.IsActive, &account.Tier, &account.Currency, &account.CustomerID); err != nil {
+			return nil, fmt.Errorf("failed to scan account: %w", err)
+		}
+		accounts = append(accounts, account)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating account rows: %w", err)
+	}
+	return accounts, nil
+}
+
+// LinkAccount moves an existing account to a customer, for accounts opened before customers existed
+func (r *CustomerRepository) LinkAccount(ctx context.Context, id, accountNumber string) error {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	// the linked customer also becomes an owner, keeping any other holders the account has
+	query := `
+        WITH account AS (
+            UPDATE usersschema.accounts SET customer_id = $1, updated_at = NOW()
+            WHERE account_number = $2
+            RETURNING account_number, customer_id
+        ), owner AS (
+            INSERT INTO usersschema.account_holders (account_number, customer_id, role)
+            SELECT account_number, customer_id, 'owner' FROM account
+            ON CONFLICT (account_number, customer_id) DO UPDATE SET role = 'owner'
+        )
+        SELECT COUNT(*) FROM account`
+	var linked int
+	err = conn.QueryRow(ctx, query, id, accountNumber).Scan(&linked)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return fmt.Errorf("%w: %s", ErrCustomerNotFound, id)
+		}
+		return fmt.Errorf("failed to link account: %w", err)
+	}
+	if linked == 0 {
+		return fmt.Errorf("%w: %s", ErrAccountNotFound, accountNumber)
+	}
+	return nil
+}
+
+// scanCustomer scans a row selected with customerColumns
+func scanCustomer(row pgx.Row) (*models.Customer, error) {
+	customer := &models.Customer{}
+	err := row.Scan(&customer.ID, &customer.FullName, &customer.Email, &customer.Phone, &customer.DateOfBirth, &customer.Nationality,
+		&customer.Address, &customer.IDDocumentType, &customer.IDDocumentNumber, &customer.KYCStatus, &customer.KYCNote,
+		&customer.VerifiedAt, &customer.CreatedAt, &customer.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return customer, nil
+}
+
+// customerWriteError turns a duplicate email into a validation error
+func customerWriteError(action string, err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return fmt.Errorf("%w: a customer with this email already exists", ErrInvalidCustomer)
+	}
+	return fmt.Errorf("failed to %s customer: %w", action, err)
+}
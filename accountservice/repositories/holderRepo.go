@@ -0,0 +1,169 @@
+package repositories
+
+import (
+	"accountservice/database"
+	"accountservice/models"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// HolderRepository implements HolderRepo for joint accounts
+type HolderRepository struct {
+	db *database.PostgresPoolDB
+}
+
+// NewHolderRepository creates a new HolderRepository
+func NewHolderRepository(db *database.PostgresPoolDB) *HolderRepository {
+	return &HolderRepository{db: db}
+}
+
+// ListHolders returns the holders of an account, owners first
+func (r *HolderRepository) ListHolders(ctx context.Context, accountNumber string) ([]models.AccountHolder, error) {
+	query := `
+        SELECT account_number, customer_id, role, added_at
+        FROM usersschema.account_holders
+        WHERE account_number = $1
+        ORDER BY CASE role WHEN 'owner' THEN 0 WHEN 'joint' THEN 1 ELSE 2 END, added_at`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, query, accountNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list account holders: %w", err)
+	}
+	defer rows.Close()
+
+	holders := []models.AccountHolder{}
+	for rows.Next() {
+		var holder models.AccountHolder
+		if err := rows.Scan(&holder.AccountNumber, &holder.CustomerID, &holder.Role, &holder.AddedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan account holder: %w", err)
+		}
+		holders = append(holders, holder)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating account holder rows: %w", err)
+	}
+	return holders, nil
+}
+
+// SetHolder adds a customer to an account or changes their role. An account always keeps at
+// least one owner.
+func (r *HolderRepository) SetHolder(ctx context.Context, holder *models.AccountHolder) error {
+	if !models.ValidHolderRole(holder.Role) {
+		return fmt.Errorf("%w: invalid holder role %q", ErrInvalidCustomer, holder.Role)
+	}
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	if err = lockAccount(ctx, tx, holder.AccountNumber); err != nil {
+		return err
+	}
+	query := `
+        INSERT INTO usersschema.account_holders (account_number, customer_id, role)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (account_number, customer_id) DO UPDATE SET role = EXCLUDED.role
+        RETURNING added_at`
+	err = tx.QueryRow(ctx, query, holder.AccountNumber, holder.CustomerID, holder.Role).Scan(&holder.AddedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			err = fmt.Errorf("%w: %s", ErrCustomerNotFound, holder.CustomerID)
+			return err
+		}
+		return fmt.Errorf("failed to save account holder: %w", err)
+	}
+	if err = requireOwner(ctx, tx, holder.AccountNumber); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// RemoveHolder takes a customer off an account; the last owner cannot be removed
+func (r *HolderRepository) RemoveHolder(ctx context.Context, accountNumber, customerID string) error {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	if err = lockAccount(ctx, tx, accountNumber); err != nil {
+		return err
+	}
+	result, err := tx.Exec(ctx, "DELETE FROM usersschema.account_holders WHERE account_number = $1 AND customer_id = $2", accountNumber, customerID)
+	if err != nil {
+		return fmt.Errorf("failed to remove account holder: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		err = fmt.Errorf("%w: %s does not hold account %s", ErrCustomerNotFound, customerID, accountNumber)
+		return err
+	}
+	if err = requireOwner(ctx, tx, accountNumber); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// lockAccount serialises holder changes on an account so the owner check cannot race
+func lockAccount(ctx context.Context, tx pgx.Tx, accountNumber string) error {
+	var locked string
+	err := tx.QueryRow(ctx, "SELECT account_number FROM usersschema.accounts WHERE account_number = $1 FOR UPDATE", accountNumber).Scan(&locked)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("%w: %s", ErrAccountNotFound, accountNumber)
+		}
+		return fmt.Errorf("failed to lock account: %w", err)
+	}
+	return nil
+}
+
+// requireOwner fails if the account would be left without an owner
+func requireOwner(ctx context.Context, tx pgx.Tx, accountNumber string) error {
+	var owners int
+	query := "SELECT COUNT(*) FROM usersschema.account_holders WHERE account_number = $1 AND role = 'owner'"
+	if err := tx.QueryRow(ctx, query, accountNumber).Scan(&owners); err != nil {
+		return fmt.Errorf("failed to count owners: %w", err)
+	}
+	if owners == 0 {
+		return fmt.Errorf("%w: account %s must keep at least one owner", ErrInvalidCustomer, accountNumber)
+	}
+	return nil
+}
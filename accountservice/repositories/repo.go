@@ -23,3 +23,23 @@ type Repository interface {
 	Debit(ctx context.Context, accountNumber string, amount float64) error
 	Credit(ctx context.Context, accountNumber string, amount float64) error
 }
+
+type ScreeningRepo interface {
+	RecordScreening(ctx context.Context, result *models.ScreeningResult) error
+}
+
+type CustomerRepo interface {
+	CreateCustomer(ctx context.Context, customer *models.Customer) error
+	GetCustomer(ctx context.Context, id string) (*models.Customer, error)
+	UpdateCustomer(ctx context.Context, customer *models.Customer) error
+	DeleteCustomer(ctx context.Context, id string) error
+	SetKYCStatus(ctx context.Context, id, status, note string) (*models.Customer, error)
+	ListCustomerAccounts(ctx context.Context, id string) ([]models.Account, error)
+	LinkAccount(ctx context.Context, id, accountNumber string) error
+}
+
+type HolderRepo interface {
+	ListHolders(ctx context.Context, accountNumber string) ([]models.AccountHolder, error)
+	SetHolder(ctx context.Context, holder *models.AccountHolder) error
+	RemoveHolder(ctx context.Context, accountNumber, customerID string) error
+}
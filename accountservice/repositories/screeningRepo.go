@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"accountservice/database"
+	"accountservice/models"
+	"context"
+	"fmt"
+	"shared/screening"
+	"strings"
+)
+
+// ScreeningRepository implements ScreeningRepo
+type ScreeningRepository struct {
+	db *database.PostgresPoolDB
+}
+
+// NewScreeningRepository creates a new ScreeningRepository
+func NewScreeningRepository(db *database.PostgresPoolDB) *ScreeningRepository {
+	return &ScreeningRepository{db: db}
+}
+
+// RecordScreening stores the audit record of a screening decision
+func (r *ScreeningRepository) RecordScreening(ctx context.Context, result *models.ScreeningResult) error {
+	query := `
+        INSERT INTO usersschema.screening_results (subject, reference, party, name, decision, score, entry_id, matched_name, program)
+        VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''))
+        RETURNING id, created_at`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	err = conn.QueryRow(ctx, query, result.Subject, result.Reference, result.Party, result.Name, result.Decision, result.Score,
+		result.EntryID, result.MatchedName, result.Program).Scan(&result.ID, &result.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record screening result: %w", err)
+	}
+	return nil
+}
+
+// ScreenNames screens each distinct name with screener, records every result under subject and
+// reference, and returns the strictest one. A nil screener clears everyone.
+func ScreenNames(ctx context.Context, repo ScreeningRepo, screener *screening.Screener, subject, reference, party string, names ...string) (screening.Result, error) {
+	if screener == nil {
+		return screening.Result{Decision: screening.Clear}, nil
+	}
+	results := []screening.Result{}
+	for i, name := range names {
+		if name == "" || containsFold(names[:i], name) {
+			continue
+		}
+		result := screener.Screen(name)
+		record := &models.ScreeningResult{
+			Subject:     subject,
+			Reference:   reference,
+			Party:       party,
+			Name:        name,
+			Decision:    string(result.Decision),
+			Score:       result.Score,
+			EntryID:     result.EntryID,
+			MatchedName: result.MatchedName,
+			Program:     result.Program,
+		}
+		if err := repo.RecordScreening(ctx, record); err != nil {
+			return screening.Result{}, err
+		}
+		results = append(results, result)
+	}
+	return screening.Strictest(results...), nil
+}
+
+// containsFold reports whether names holds name, ignoring case
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -21,14 +21,35 @@ func NewUserRepository(db *database.PostgresPoolDB) *UserRepository {
 }
 
 func (r *UserRepository) Create(ctx context.Context, account *models.Account) error {
-	query := "INSERT INTO  usersschema.accounts (account_number, username, email, balance, created_at, updated_at, is_active) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id"
+	if account.Currency != "" {
+		currency, err := models.NormalizeCurrency(account.Currency)
+		if err != nil {
+			return err
+		}
+		account.Currency = currency
+	}
+	// the customer the account is opened for becomes its first owner, and an opening balance
+	// starts the account's event stream
+	query := `
+        WITH account AS (
+            INSERT INTO usersschema.accounts (account_number, username, email, balance, created_at, updated_at, is_active, tier, currency, customer_id, version)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE(NULLIF($8, ''), 'basic'), COALESCE(NULLIF($9, ''), 'USD'), $10, CASE WHEN $4 > 0 THEN 1 ELSE 0 END)
+            RETURNING id, account_number, currency, customer_id, balance, version
+        ), owner AS (
+            INSERT INTO usersschema.account_holders (account_number, customer_id, role)
+            SELECT account_number, customer_id, 'owner' FROM account WHERE customer_id IS NOT NULL
+        ), opened AS (
+            INSERT INTO usersschema.account_events (account_number, version, event_type, amount, cause)
+            SELECT account_number, version, 'opened', balance, 'opening_balance' FROM account WHERE version = 1
+        )
+        SELECT id, currency FROM account`
 	conn, err := r.db.Pool().Acquire(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to acquire connection: %w", err)
 	}
 	defer conn.Release()
 
-	err = conn.QueryRow(ctx, query, account.AccountNumber, account.Username, account.Email, account.Balance, account.CreatedAt, account.UpdatedAt, account.IsActive).Scan(&account.ID)
+	err = conn.QueryRow(ctx, query, account.AccountNumber, account.Username, account.Email, account.Balance, account.CreatedAt, account.UpdatedAt, account.IsActive, account.Tier, account.Currency, account.CustomerID).Scan(&account.ID, &account.Currency)
 	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
 	}
@@ -36,7 +57,7 @@ func (r *UserRepository) Create(ctx context.Context, account *models.Account) er
 }
 
 func (r *UserRepository) GetByID(ctx context.Context, id string) (*models.Account, error) {
-	query := "SELECT account_number, username, email FROM  usersschema.accounts WHERE account_number = $1"
+	query := "SELECT account_number, username, email, tier, currency, customer_id FROM  usersschema.accounts WHERE account_number = $1"
 	conn, err := r.db.Pool().Acquire(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to acquire connection: %w", err)
@@ -44,7 +65,7 @@ func (r *UserRepository) GetByID(ctx context.Context, id string) (*models.Accoun
 	defer conn.Release()
 
 	account := &models.Account{}
-	err = conn.QueryRow(ctx, query, id).Scan(&account.AccountNumber, &account.Username, &account.Email)
+	err = conn.QueryRow(ctx, query, id).Scan(&account.AccountNumber, &account.Username, &account.Email, &account.Tier, &account.Currency, &account.CustomerID)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, nil // or a custom "not found" error
@@ -121,20 +142,34 @@ func (r *UserRepository) UpdateBalance(ctx context.Context, accountNumber string
 		}
 	}
 
-	// Update balance and updated_at timestamp
+	// Update balance and updated_at timestamp, and append the movement to the account's events
 	updateQuery := `
         UPDATE usersschema.accounts 
         SET balance = $1, 
+            overdraft_used = GREATEST(-$1, 0),
+            version = version + 1,
             updated_at = NOW() 
-        WHERE account_number = $2`
+        WHERE account_number = $2
+        RETURNING version`
 
-	result, err := tx.Exec(ctx, updateQuery, newBalance, accountNumber)
+	var version int64
+	err = tx.QueryRow(ctx, updateQuery, newBalance, accountNumber).Scan(&version)
 	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("account not found during update: %s", accountNumber)
+		}
 		return fmt.Errorf("failed to update balance: %w", err)
 	}
 
-	if result.RowsAffected() == 0 {
-		return fmt.Errorf("account not found during update: %s", accountNumber)
+	eventType, cause := "debited", "withdrawal"
+	if isCredit {
+		eventType, cause = "credited", "deposit"
+	}
+	eventQuery := `
+        INSERT INTO usersschema.account_events (account_number, version, event_type, amount, cause)
+        VALUES ($1, $2, $3, $4, $5)`
+	if _, err = tx.Exec(ctx, eventQuery, accountNumber, version, eventType, amount, cause); err != nil {
+		return fmt.Errorf("failed to append account event: %w", err)
 	}
 
 	// Commit transaction
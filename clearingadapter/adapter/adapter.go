@@ -0,0 +1,392 @@
+// Package adapter translates between the bank's JSON transactions and the ISO 20022 messages of
+// the clearing system. Inbound pacs.008 credit transfers become deposits on the "transaction"
+// topic and are answered with a pacs.002 once validated and again once the transaction service
+// has posted or refused them; external transfers published on "clearing-outbound" are rendered
+// as pacs.008, and the pacs.002 reports on them are published on "clearing-status".
+package adapter
+
+import (
+	"clearingadapter/filedrop"
+	"clearingadapter/iso20022"
+	"clearingadapter/models"
+	"errors"
+	"fmt"
+	"regexp"
+	"shared/audit"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// IDs in the topic map of the topics the adapter reads and writes
+const (
+	TransactionTopic = "transaction"
+	OutboundTopic    = "clearing-outbound"
+	StatusTopic      = "clearing-status"
+	LedgerTopic      = "transaction-ledger"
+	DeadLedgerTopic  = "dead-ledger"
+)
+
+// ErrInvalidTransfer is returned when an outbound transfer cannot be expressed as a pacs.008
+var ErrInvalidTransfer = errors.New("invalid external transfer")
+
+// inboundNamespace derives transaction IDs for inbound transfers that carry no UETR, so a
+// message delivered twice produces the same deposits and is applied once
+var inboundNamespace = uuid.MustParse("6f1b5a0e-3c1d-4e8a-9f3b-2d7c4a9e1b60")
+
+var (
+	currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+	unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+)
+
+// Publisher publishes a message as JSON on a Kafka topic
+type Publisher interface {
+	PushToQueue(topic, key string, v any) error
+}
+
+// Adapter connects the file drop with Kafka
+type Adapter struct {
+	BIC          string           // This bank, the creditor agent of inbound transfers
+	ClearingBIC  string           // The clearing system outbound transfers are instructed to
+	Transactions Publisher        // Cluster carrying the "transaction" topic
+	Statuses     Publisher        // Cluster carrying the "clearing-status" topic
+	Outbox       *filedrop.Outbox // Where messages for the clearing system are written
+	Recorder     *audit.Recorder  // Records the files processed, nothing when nil
+	Now          func() time.Time // Clock, time.Now when nil
+}
+
+func (a *Adapter) now() time.Time {
+	if a.Now != nil {
+		return a.Now()
+	}
+	return time.Now()
+}
+
+// Receive handles a message dropped in the inbox. Messages that can never be processed are
+// returned as filedrop.ErrRejected.
+func (a *Adapter) Receive(name string, data []byte) error {
+	messageType := iso20022.MessageType(data)
+	var err error
+	switch messageType {
+	case "pacs.008":
+		err = a.receiveCredits(data)
+	case "pacs.002":
+		err = a.receiveStatuses(data)
+	default:
+		err = fmt.Errorf("%w: %s is not a pacs.008 or pacs.002 document", filedrop.ErrRejected, name)
+	}
+
+	// a file that failed for another reason stays in the inbox and is retried, so only its
+	// final outcome is recorded
+	if a.Recorder != nil && (err == nil || errors.Is(err, filedrop.ErrRejected)) {
+		outcome := "processed"
+		if err != nil {
+			outcome = "rejected: " + err.Error()
+		}
+		a.Recorder.Consumed("inbox-"+name, "clearing.file_received", name, outcome, nil, map[string]string{"message_type": messageType})
+	}
+	return err
+}
+
+// receiveCredits publishes the valid transfers of an inbound pacs.008 as deposits and answers
+// with a pacs.002 accepting or rejecting each one. Accepted transfers get their final status
+// from Report.
+func (a *Adapter) receiveCredits(data []byte) error {
+	msg, err := iso20022.ParsePacs008(data)
+	if err != nil {
+		return fmt.Errorf("%w: %v", filedrop.ErrRejected, err)
+	}
+	credits, statuses := a.Credits(msg)
+	for _, trans := range credits {
+		if err := a.Transactions.PushToQueue(TransactionTopic, trans.FromAccountID, trans); err != nil {
+			return fmt.Errorf("failed to publish transfer %s: %w", trans.ID, err)
+		}
+	}
+
+	messageID := msg.Transfer.GroupHeader.MessageID
+	report := iso20022.NewPacs002(truncate("STS"+messageID, 35), messageID, statuses, a.now())
+	return a.write("pacs002-"+messageID, report)
+}
+
+// receiveStatuses publishes the statuses of a pacs.002 reporting on outbound transfers
+func (a *Adapter) receiveStatuses(data []byte) error {
+	report, err := iso20022.ParsePacs002(data)
+	if err != nil {
+		return fmt.Errorf("%w: %v", filedrop.ErrRejected, err)
+	}
+	statuses := a.StatusesOf(report)
+	if len(statuses) == 0 {
+		return fmt.Errorf("%w: pacs.002 %s reports on no known transfer", filedrop.ErrRejected, report.Report.GroupHeader.MessageID)
+	}
+	for _, status := range statuses {
+		if err := a.Statuses.PushToQueue(StatusTopic, status.TransactionID, status); err != nil {
+			return fmt.Errorf("failed to publish status of %s: %w", status.TransactionID, err)
+		}
+	}
+	return nil
+}
+
+// Send renders an outbound transfer as a pacs.008 in the outbox and returns "sent". A transfer
+// that cannot be rendered is reported as rejected on "clearing-status" instead, and Send returns
+// models.ClearingRejected.
+func (a *Adapter) Send(trans models.Transaction) (string, error) {
+	msg, err := a.Pacs008For(trans)
+	if errors.Is(err, ErrInvalidTransfer) {
+		status := models.ClearingStatus{
+			TransactionID: trans.ID.String(),
+			Status:        models.ClearingRejected,
+			ISOStatus:     iso20022.StatusRejected,
+			ReasonCode:    "NARR",
+			Reason:        err.Error(),
+			ReceivedAt:    a.now(),
+		}
+		return models.ClearingRejected, a.Statuses.PushToQueue(StatusTopic, status.TransactionID, status)
+	}
+	if err != nil {
+		return "", err
+	}
+	return "sent", a.write("pacs008-"+msg.Transfer.GroupHeader.MessageID, msg)
+}
+
+// Credits turns the transfers of an inbound pacs.008 into deposits. Every transfer gets a status
+// for the pacs.002 answer: ACTC when it passed validation, RJCT with an ISO reason code
+// otherwise. Only the transaction service can tell whether an accepted transfer is credited.
+func (a *Adapter) Credits(msg *iso20022.Pacs008) ([]models.Transaction, []iso20022.TxStatus) {
+	messageID := msg.Transfer.GroupHeader.MessageID
+	var credits []models.Transaction
+	statuses := make([]iso20022.TxStatus, 0, len(msg.Transfer.Transactions))
+	for _, tx := range msg.Transfer.Transactions {
+		status := iso20022.TxStatus{
+			OriginalEndToEndID: tx.EndToEndID,
+			OriginalTxID:       tx.TxID,
+			OriginalUETR:       tx.UETR,
+			Status:             iso20022.StatusAcceptedTechnical,
+		}
+		trans, code, reason := a.credit(messageID, tx)
+		if code != "" {
+			status.Status = iso20022.StatusRejected
+			status.ReasonCode = code
+			status.AdditionalInfo = reason
+		} else {
+			credits = append(credits, trans)
+		}
+		statuses = append(statuses, status)
+	}
+	return credits, statuses
+}
+
+// Report answers an inbound transfer with its final pacs.002 once the transaction service has
+// decided on its deposit, read from "transaction-ledger" or "dead-ledger": ACCC if the deposit
+// was posted, RJCT otherwise, so the clearing system returns the funds to the debtor. It returns
+// the status sent, or "" for transactions that are not inbound transfers.
+func (a *Adapter) Report(trans models.Transaction) (string, error) {
+	sep := strings.LastIndex(trans.ClearingRef, "/")
+	if trans.TransactionType != "deposit" || sep < 0 {
+		return "", nil
+	}
+	messageID, txID := trans.ClearingRef[:sep], trans.ClearingRef[sep+1:]
+	status := iso20022.TxStatus{
+		OriginalEndToEndID: trans.Reference,
+		OriginalTxID:       txID,
+		Status:             iso20022.StatusAcceptedCredited,
+	}
+	// transfers without a UETR were given an ID derived from their references
+	ref := txID
+	if ref == "" {
+		ref = trans.Reference
+	}
+	if trans.ID != uuid.NewSHA1(inboundNamespace, []byte(messageID+"/"+ref)) {
+		status.OriginalUETR = trans.ID.String()
+	}
+	if len(trans.Postings) == 0 {
+		status.Status = iso20022.StatusRejected
+		status.ReasonCode = "NARR"
+		status.AdditionalInfo = "credit refused by the creditor bank"
+	}
+
+	id := "F" + strings.ReplaceAll(trans.ID.String(), "-", "")
+	report := iso20022.NewPacs002(id, messageID, []iso20022.TxStatus{status}, a.now())
+	return status.Status, a.write("pacs002-"+id, report)
+}
+
+// credit validates one inbound transfer and builds its deposit, or returns the ISO reason code
+// it is rejected with
+func (a *Adapter) credit(messageID string, tx iso20022.CreditTransferTx) (models.Transaction, string, string) {
+	if tx.CreditorAgentBIC != "" && a.BIC != "" && !sameBank(tx.CreditorAgentBIC, a.BIC) {
+		return models.Transaction{}, "RC01", fmt.Sprintf("creditor agent %s is not this bank", tx.CreditorAgentBIC)
+	}
+	account := tx.CreditorAccount.Number()
+	if account == "" {
+		return models.Transaction{}, "AC01", "creditor account is missing"
+	}
+	amount, err := tx.Amount.Float()
+	if err != nil || amount <= 0 {
+		return models.Transaction{}, "AM12", fmt.Sprintf("invalid amount %q", tx.Amount.Value)
+	}
+	if !currencyPattern.MatchString(tx.Amount.Currency) {
+		return models.Transaction{}, "AM03", fmt.Sprintf("invalid currency %q", tx.Amount.Currency)
+	}
+
+	id, err := uuid.Parse(tx.UETR)
+	if err != nil {
+		ref := tx.TxID
+		if ref == "" {
+			ref = tx.EndToEndID
+		}
+		id = uuid.NewSHA1(inboundNamespace, []byte(messageID+"/"+ref))
+	}
+	description := "Credit transfer"
+	if tx.DebtorName != "" {
+		description += " from " + tx.DebtorName
+	}
+	if tx.Remittance != "" {
+		description += ": " + tx.Remittance
+	}
+	return models.Transaction{
+		ID:              id,
+		FromAccountID:   account,
+		Amount:          amount,
+		TransactionType: "deposit",
+		Description:     description,
+		CreatedAt:       a.now(),
+		Status:          "pending",
+		Currency:        tx.Amount.Currency,
+		Debtor: &models.Party{
+			Name:    tx.DebtorName,
+			IBAN:    tx.DebtorAccount.IBAN,
+			Account: tx.DebtorAccount.Other,
+			BIC:     tx.DebtorAgentBIC,
+		},
+		Reference:   tx.EndToEndID,
+		ClearingRef: messageID + "/" + tx.TxID,
+	}, "", ""
+}
+
+// Pacs008For renders an outbound transfer as a single-transaction pacs.008. The transaction ID
+// is the UETR, and without dashes the message, instruction and transaction IDs, so the pacs.002
+// answer can be matched back to it.
+func (a *Adapter) Pacs008For(trans models.Transaction) (*iso20022.Pacs008, error) {
+	if trans.ID == uuid.Nil {
+		return nil, fmt.Errorf("%w: transaction has no ID", ErrInvalidTransfer)
+	}
+	creditor := trans.Creditor
+	if creditor == nil || creditor.AccountNumber() == "" || creditor.Name == "" || creditor.BIC == "" {
+		return nil, fmt.Errorf("%w: creditor name, account and bic are required", ErrInvalidTransfer)
+	}
+	if trans.Amount <= 0 {
+		return nil, fmt.Errorf("%w: amount must be positive", ErrInvalidTransfer)
+	}
+	if !currencyPattern.MatchString(trans.Currency) {
+		return nil, fmt.Errorf("%w: invalid currency %q", ErrInvalidTransfer, trans.Currency)
+	}
+
+	id := strings.ReplaceAll(trans.ID.String(), "-", "")
+	debtorName := trans.FromAccountID
+	if trans.Debtor != nil && trans.Debtor.Name != "" {
+		debtorName = trans.Debtor.Name
+	}
+	tx := iso20022.CreditTransferTx{
+		InstructionID:    id,
+		EndToEndID:       truncate(trans.Reference, 35),
+		TxID:             id,
+		UETR:             trans.ID.String(),
+		Amount:           iso20022.NewAmount(trans.Amount, trans.Currency),
+		DebtorName:       debtorName,
+		DebtorAccount:    iso20022.Account{Other: trans.FromAccountID},
+		DebtorAgentBIC:   a.BIC,
+		CreditorAgentBIC: creditor.BIC,
+		CreditorName:     creditor.Name,
+		CreditorAccount:  iso20022.Account{IBAN: creditor.IBAN},
+		Remittance:       truncate(trans.Description, 140),
+	}
+	if creditor.IBAN == "" {
+		tx.CreditorAccount = iso20022.Account{Other: creditor.Account}
+	}
+	return iso20022.NewPacs008(id, a.BIC, a.ClearingBIC, tx, a.now()), nil
+}
+
+// StatusesOf reads the transfer statuses of a pacs.002. A report with only a group status
+// applies it to the transfer the original message carried.
+func (a *Adapter) StatusesOf(report *iso20022.Pacs002) []models.ClearingStatus {
+	original := report.Report.OriginalGroup
+	txs := report.Report.Transactions
+	if len(txs) == 0 && original.GroupStatus != "" {
+		txs = []iso20022.TxStatus{{OriginalTxID: original.MessageID, Status: original.GroupStatus}}
+	}
+
+	var statuses []models.ClearingStatus
+	for _, tx := range txs {
+		id, ok := transactionID(tx.OriginalUETR, tx.OriginalTxID, original.MessageID)
+		status := clearingStatus(tx.Status)
+		if !ok || status == "" {
+			continue
+		}
+		statuses = append(statuses, models.ClearingStatus{
+			TransactionID: id,
+			Status:        status,
+			ISOStatus:     tx.Status,
+			ReasonCode:    tx.ReasonCode,
+			Reason:        tx.AdditionalInfo,
+			MessageID:     report.Report.GroupHeader.MessageID,
+			ReceivedAt:    a.now(),
+		})
+	}
+	return statuses
+}
+
+// write renders a message into the outbox under a file name derived from its ID
+func (a *Adapter) write(name string, msg any) error {
+	data, err := iso20022.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return a.Outbox.Write(unsafeFileChars.ReplaceAllString(name, "_")+".xml", data)
+}
+
+// clearingStatus maps an ISO transaction status onto the statuses published on "clearing-status"
+func clearingStatus(code string) string {
+	switch code {
+	case "ACTC", "ACCP", "ACSP", "ACWC", "PDNG":
+		return models.ClearingAccepted
+	case "ACSC", "ACCC":
+		return models.ClearingSettled
+	case "RJCT":
+		return models.ClearingRejected
+	}
+	return ""
+}
+
+// transactionID returns the first reference that is a transaction UUID, with or without dashes
+func transactionID(refs ...string) (string, bool) {
+	for _, ref := range refs {
+		if id, err := uuid.Parse(strings.TrimSpace(ref)); err == nil {
+			return id.String(), true
+		}
+	}
+	return "", false
+}
+
+// sameBank compares BICs ignoring the branch code, so "TBNKGB2L" matches "TBNKGB2LXXX"
+func sameBank(a, b string) bool {
+	a, b = strings.ToUpper(strings.TrimSpace(a)), strings.ToUpper(strings.TrimSpace(b))
+	if len(a) < 8 || len(b) < 8 {
+		return a == b
+	}
+	return a[:8] == b[:8]
+}
+
+// truncate cuts s to at most n characters, as ISO 20022 text lengths are counted in characters
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	for i := range s {
+		if n == 0 {
+			return s[:i]
+		}
+		n--
+	}
+	return s
+}
@@ -0,0 +1,211 @@
+package adapter
+
+import (
+	"clearingadapter/filedrop"
+	"clearingadapter/iso20022"
+	"clearingadapter/models"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"shared/events"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const inboundPacs008 = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pacs.008.001.08">
+  <FIToFICstmrCdtTrf>
+    <GrpHdr>
+      <MsgId>CLRG-20261019-001</MsgId>
+      <CreDtTm>2026-10-19T09:00:00</CreDtTm>
+      <NbOfTxs>2</NbOfTxs>
+      <TtlIntrBkSttlmAmt Ccy="USD">1250.50</TtlIntrBkSttlmAmt>
+      <SttlmInf><SttlmMtd>CLRG</SttlmMtd></SttlmInf>
+    </GrpHdr>
+    <CdtTrfTxInf>
+      <PmtId><EndToEndId>INV-77</EndToEndId><TxId>TX1</TxId><UETR>0a4c1e2f-9b3d-4c6e-8f1a-2b3c4d5e6f70</UETR></PmtId>
+      <IntrBkSttlmAmt Ccy="USD">1000.00</IntrBkSttlmAmt>
+      <ChrgBr>SLEV</ChrgBr>
+      <Dbtr><Nm>Acme Ltd</Nm></Dbtr>
+      <DbtrAcct><Id><IBAN>GB29NWBK60161331926819</IBAN></Id></DbtrAcct>
+      <DbtrAgt><FinInstnId><BICFI>NWBKGB2L</BICFI></FinInstnId></DbtrAgt>
+      <CdtrAgt><FinInstnId><BICFI>TBNKGB2LXXX</BICFI></FinInstnId></CdtrAgt>
+      <Cdtr><Nm>Jane Doe</Nm></Cdtr>
+      <CdtrAcct><Id><Othr><Id>ACC123456789</Id></Othr></Id></CdtrAcct>
+      <RmtInf><Ustrd>Invoice 77</Ustrd></RmtInf>
+    </CdtTrfTxInf>
+    <CdtTrfTxInf>
+      <PmtId><EndToEndId>INV-78</EndToEndId><TxId>TX2</TxId></PmtId>
+      <IntrBkSttlmAmt Ccy="USD">250.50</IntrBkSttlmAmt>
+      <ChrgBr>SLEV</ChrgBr>
+      <CdtrAgt><FinInstnId><BICFI>OTHRGB2L</BICFI></FinInstnId></CdtrAgt>
+      <CdtrAcct><Id><Othr><Id>ACC987654321</Id></Othr></Id></CdtrAcct>
+    </CdtTrfTxInf>
+  </FIToFICstmrCdtTrf>
+</Document>`
+
+// recorder collects published messages, refusing those that do not match the schema of their topic
+type recorder struct {
+	messages []any
+}
+
+func (r *recorder) PushToQueue(topic, key string, v any) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, _, err := events.Encode(topic, payload, events.Trace{}); err != nil {
+		return err
+	}
+	r.messages = append(r.messages, v)
+	return nil
+}
+
+func newAdapter(t *testing.T) (*Adapter, *recorder, *recorder) {
+	transactions, statuses := &recorder{}, &recorder{}
+	now := time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC)
+	return &Adapter{
+		BIC:          "TBNKGB2LXXX",
+		ClearingBIC:  "CLRGGB2LXXX",
+		Transactions: transactions,
+		Statuses:     statuses,
+		Outbox:       &filedrop.Outbox{Dir: t.TempDir()},
+		Now:          func() time.Time { return now },
+	}, transactions, statuses
+}
+
+// TestReceiveCredits tests that inbound transfers become deposits and are answered with a pacs.002
+func TestReceiveCredits(t *testing.T) {
+	a, transactions, _ := newAdapter(t)
+	require.NoError(t, a.Receive("in.xml", []byte(inboundPacs008)))
+
+	require.Len(t, transactions.messages, 1)
+	deposit := transactions.messages[0].(models.Transaction)
+	assert.Equal(t, "0a4c1e2f-9b3d-4c6e-8f1a-2b3c4d5e6f70", deposit.ID.String())
+	assert.Equal(t, "deposit", deposit.TransactionType)
+	assert.Equal(t, "ACC123456789", deposit.FromAccountID)
+	assert.Equal(t, 1000.0, deposit.Amount)
+	assert.Equal(t, "Credit transfer from Acme Ltd: Invoice 77", deposit.Description)
+	assert.Equal(t, "GB29NWBK60161331926819", deposit.Debtor.IBAN)
+
+	data, err := os.ReadFile(filepath.Join(a.Outbox.Dir, "pacs002-CLRG-20261019-001.xml"))
+	require.NoError(t, err)
+	report, err := iso20022.ParsePacs002(data)
+	require.NoError(t, err)
+	assert.Equal(t, "CLRG-20261019-001", report.Report.OriginalGroup.MessageID)
+	require.Len(t, report.Report.Transactions, 2)
+	assert.Equal(t, iso20022.StatusAcceptedTechnical, report.Report.Transactions[0].Status)
+	assert.Equal(t, iso20022.StatusRejected, report.Report.Transactions[1].Status)
+	assert.Equal(t, "RC01", report.Report.Transactions[1].ReasonCode)
+}
+
+// TestReportCredits tests that an inbound transfer gets a final pacs.002 once its deposit is posted or refused
+func TestReportCredits(t *testing.T) {
+	a, transactions, _ := newAdapter(t)
+	require.NoError(t, a.Receive("in.xml", []byte(inboundPacs008)))
+	deposit := transactions.messages[0].(models.Transaction)
+
+	read := func(deposit models.Transaction) iso20022.TxStatus {
+		data, err := os.ReadFile(filepath.Join(a.Outbox.Dir, "pacs002-F"+strings.ReplaceAll(deposit.ID.String(), "-", "")+".xml"))
+		require.NoError(t, err)
+		report, err := iso20022.ParsePacs002(data)
+		require.NoError(t, err)
+		assert.Equal(t, "CLRG-20261019-001", report.Report.OriginalGroup.MessageID)
+		require.Len(t, report.Report.Transactions, 1)
+		return report.Report.Transactions[0]
+	}
+
+	deposit.Postings = []json.RawMessage{json.RawMessage(`{"account_number":"ACC123456789","amount":1000}`)}
+	status, err := a.Report(deposit)
+	require.NoError(t, err)
+	assert.Equal(t, iso20022.StatusAcceptedCredited, status)
+	credited := read(deposit)
+	assert.Equal(t, "TX1", credited.OriginalTxID)
+	assert.Equal(t, "INV-77", credited.OriginalEndToEndID)
+	assert.Equal(t, deposit.ID.String(), credited.OriginalUETR)
+
+	// refused deposits carry no postings; an ID derived from the references is not a UETR
+	deposit.Postings = nil
+	deposit.ID = uuid.NewSHA1(inboundNamespace, []byte("CLRG-20261019-001/TX1"))
+	status, err = a.Report(deposit)
+	require.NoError(t, err)
+	assert.Equal(t, iso20022.StatusRejected, status)
+	refused := read(deposit)
+	assert.Equal(t, iso20022.StatusRejected, refused.Status)
+	assert.Empty(t, refused.OriginalUETR)
+
+	// other transactions on the ledger are not inbound transfers
+	status, err = a.Report(models.Transaction{ID: uuid.New(), TransactionType: "transfer"})
+	require.NoError(t, err)
+	assert.Empty(t, status)
+}
+
+// TestTruncate tests that text is cut on character boundaries
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "Zahlung für", truncate("Zahlung für Miete", 11))
+	assert.Equal(t, "für", truncate("für", 3))
+	assert.Equal(t, "", truncate("ü", 0))
+}
+
+// TestReceiveRejectsBadControlTotal tests that a pacs.008 whose header does not match its transfers is refused
+func TestReceiveRejectsBadControlTotal(t *testing.T) {
+	a, transactions, _ := newAdapter(t)
+	err := a.Receive("in.xml", []byte(strings.Replace(inboundPacs008, "1250.50", "1250.00", 1)))
+	assert.ErrorIs(t, err, filedrop.ErrRejected)
+	assert.Empty(t, transactions.messages)
+
+	assert.ErrorIs(t, a.Receive("note.xml", []byte("<Document><Other/></Document>")), filedrop.ErrRejected)
+}
+
+// TestSendAndStatus tests an outbound transfer from pacs.008 to the clearing status of its pacs.002
+func TestSendAndStatus(t *testing.T) {
+	a, _, statuses := newAdapter(t)
+	trans := models.Transaction{
+		ID:              uuid.MustParse("5b2e7c1a-4d3f-4a8b-9c6d-7e8f9a0b1c2d"),
+		FromAccountID:   "ACC123456789",
+		Amount:          75.5,
+		TransactionType: "external_transfer",
+		Description:     "Rent",
+		Currency:        "USD",
+		Creditor:        &models.Party{Name: "John Smith", IBAN: "GB33BUKB20201555555555", BIC: "BUKBGB22"},
+	}
+	outcome, err := a.Send(trans)
+	require.NoError(t, err)
+	assert.Equal(t, "sent", outcome)
+
+	data, err := os.ReadFile(filepath.Join(a.Outbox.Dir, "pacs008-5b2e7c1a4d3f4a8b9c6d7e8f9a0b1c2d.xml"))
+	require.NoError(t, err)
+	msg, err := iso20022.ParsePacs008(data)
+	require.NoError(t, err)
+	tx := msg.Transfer.Transactions[0]
+	assert.Equal(t, "75.50", tx.Amount.Value)
+	assert.Equal(t, trans.ID.String(), tx.UETR)
+	assert.Equal(t, "GB33BUKB20201555555555", tx.CreditorAccount.IBAN)
+	assert.Equal(t, "NOTPROVIDED", tx.EndToEndID)
+
+	report := iso20022.NewPacs002("STS1", msg.Transfer.GroupHeader.MessageID, []iso20022.TxStatus{
+		{OriginalTxID: tx.TxID, Status: "RJCT", ReasonCode: "AC04", AdditionalInfo: "Account closed"},
+	}, a.now())
+	data, err = iso20022.Marshal(report)
+	require.NoError(t, err)
+	require.NoError(t, a.Receive("sts.xml", data))
+
+	require.Len(t, statuses.messages, 1)
+	status := statuses.messages[0].(models.ClearingStatus)
+	assert.Equal(t, trans.ID.String(), status.TransactionID)
+	assert.Equal(t, models.ClearingRejected, status.Status)
+	assert.Equal(t, "AC04", status.ReasonCode)
+
+	// a transfer without a beneficiary never reaches the outbox
+	trans.Creditor = nil
+	outcome, err = a.Send(trans)
+	require.NoError(t, err)
+	assert.Equal(t, models.ClearingRejected, outcome)
+	require.Len(t, statuses.messages, 2)
+	assert.Equal(t, models.ClearingRejected, statuses.messages[1].(models.ClearingStatus).Status)
+}
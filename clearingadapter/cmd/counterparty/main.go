@@ -0,0 +1,151 @@
+// Command counterparty simulates the clearing network for local testing. It collects the pacs.008
+// transfers the adapter writes to its outbox and answers each one with pacs.002 reports in the
+// adapter's inbox: accepted straight away and settled after a delay, or rejected.
+//
+//	counterparty -reject-accounts GB82WEST12345698765432 -limit 10000
+package main
+
+import (
+	"clearingadapter/filedrop"
+	"clearingadapter/iso20022"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// simulator answers the transfers collected from the adapter's outbox
+type simulator struct {
+	replies     *filedrop.Outbox
+	rejected    map[string]bool
+	limit       float64
+	settleAfter time.Duration
+	sequence    atomic.Int64
+}
+
+func main() {
+	outbox := flag.String("outbox", envOr("CLEARING_OUTBOX", "/clearing/outbox"), "Directory the adapter writes pacs.008 transfers to")
+	inbox := flag.String("inbox", envOr("CLEARING_INBOX", "/clearing/inbox"), "Directory the adapter reads pacs.002 reports from")
+	reject := flag.String("reject-accounts", envOr("COUNTERPARTY_REJECT_ACCOUNTS", ""), "Comma separated creditor accounts or IBANs to reject as closed")
+	limit := flag.Float64("limit", 0, "Reject transfers above this amount; 0 for no limit")
+	settleAfter := flag.Duration("settle-after", 10*time.Second, "Delay between accepting and settling a transfer")
+	interval := flag.Duration("interval", 2*time.Second, "Outbox poll interval")
+	flag.Parse()
+
+	sim := &simulator{
+		replies:     &filedrop.Outbox{Dir: *inbox},
+		rejected:    map[string]bool{},
+		limit:       *limit,
+		settleAfter: *settleAfter,
+	}
+	for _, account := range strings.Split(*reject, ",") {
+		if account = strings.TrimSpace(account); account != "" {
+			sim.rejected[account] = true
+		}
+	}
+
+	collected := &filedrop.Inbox{Dir: *outbox}
+	log.Printf("Clearing counterparty collecting from %s, answering to %s", *outbox, *inbox)
+	for {
+		if err := collected.Poll(sim.handle); err != nil {
+			log.Printf("Failed to collect from outbox: %v", err)
+		}
+		time.Sleep(*interval)
+	}
+}
+
+// handle answers a pacs.008 and logs the adapter's reports on inbound credits
+func (s *simulator) handle(name string, data []byte) error {
+	switch iso20022.MessageType(data) {
+	case "pacs.008":
+		msg, err := iso20022.ParsePacs008(data)
+		if err != nil {
+			return fmt.Errorf("%w: %v", filedrop.ErrRejected, err)
+		}
+		return s.answer(msg)
+	case "pacs.002":
+		report, err := iso20022.ParsePacs002(data)
+		if err != nil {
+			return fmt.Errorf("%w: %v", filedrop.ErrRejected, err)
+		}
+		for _, tx := range report.Report.Transactions {
+			log.Printf("Credit %s of %s: %s %s", tx.OriginalTxID, report.Report.OriginalGroup.MessageID, tx.Status, tx.ReasonCode)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: %s is not a pacs.008 or pacs.002", filedrop.ErrRejected, name)
+}
+
+// answer rejects or accepts every transfer of the message, and settles the accepted ones later
+func (s *simulator) answer(msg *iso20022.Pacs008) error {
+	original := msg.Transfer.GroupHeader.MessageID
+	var accepted, rejected []iso20022.TxStatus
+	for _, tx := range msg.Transfer.Transactions {
+		status := iso20022.TxStatus{OriginalEndToEndID: tx.EndToEndID, OriginalTxID: tx.TxID, OriginalUETR: tx.UETR}
+		if code, reason := s.rejection(tx); code != "" {
+			status.Status, status.ReasonCode, status.AdditionalInfo = iso20022.StatusRejected, code, reason
+			rejected = append(rejected, status)
+			log.Printf("Rejecting %s: %s", tx.TxID, reason)
+			continue
+		}
+		status.Status = iso20022.StatusAcceptedProcessing
+		accepted = append(accepted, status)
+		log.Printf("Accepted %s for %s %s", tx.TxID, tx.Amount.Value, tx.Amount.Currency)
+	}
+
+	if err := s.report(original, append(accepted, rejected...)); err != nil {
+		return err
+	}
+	if len(accepted) == 0 {
+		return nil
+	}
+	time.AfterFunc(s.settleAfter, func() {
+		settled := make([]iso20022.TxStatus, len(accepted))
+		for i, status := range accepted {
+			status.Status = iso20022.StatusAcceptedSettled
+			settled[i] = status
+		}
+		if err := s.report(original, settled); err != nil {
+			log.Printf("Failed to settle %s: %v", original, err)
+			return
+		}
+		log.Printf("Settled %d transfers of %s", len(settled), original)
+	})
+	return nil
+}
+
+// rejection returns the ISO reason code and text for a transfer the counterparty refuses
+func (s *simulator) rejection(tx iso20022.CreditTransferTx) (string, string) {
+	if s.rejected[tx.CreditorAccount.IBAN] || s.rejected[tx.CreditorAccount.Other] {
+		return "AC04", "Closed account number"
+	}
+	amount, err := tx.Amount.Float()
+	if err != nil {
+		return "AM12", "Invalid amount"
+	}
+	if s.limit > 0 && amount > s.limit {
+		return "AM02", fmt.Sprintf("Amount exceeds the limit of %.2f", s.limit)
+	}
+	return "", ""
+}
+
+// report drops a pacs.002 on the original message into the adapter's inbox
+func (s *simulator) report(original string, statuses []iso20022.TxStatus) error {
+	now := time.Now()
+	id := fmt.Sprintf("SIM-%s-%d", now.UTC().Format("20060102150405"), s.sequence.Add(1))
+	data, err := iso20022.Marshal(iso20022.NewPacs002(id, original, statuses, now))
+	if err != nil {
+		return err
+	}
+	return s.replies.Write("pacs002-"+id+".xml", data)
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,91 @@
+package configurations
+
+import (
+	"strings"
+	"time"
+
+	"github.com/nicholasjackson/env"
+)
+
+var appConfig *appConfigs
+var inboxDir *string = env.String("CLEARING_INBOX", false, "/clearing/inbox", "Directory the clearing system drops pacs.008 and pacs.002 messages into")
+var outboxDir *string = env.String("CLEARING_OUTBOX", false, "/clearing/outbox", "Directory pacs.008 and pacs.002 messages for the clearing system are written to")
+var pollInterval *time.Duration = env.Duration("CLEARING_POLL_INTERVAL", false, 5*time.Second, "How often the inbox is read")
+var bankBIC *string = env.String("BANK_BIC", false, "TBNKGB2LXXX", "BIC of this bank, the creditor agent of inbound transfers")
+var clearingBIC *string = env.String("CLEARING_BIC", false, "CLRGGB2LXXX", "BIC of the clearing system outbound transfers are instructed to")
+var kafkaBroker *string = env.String("KAFKA_BROKER", false, "kafka:9092", "Comma-separated Kafka brokers every topic is consumed from and published to")
+var topicMapFile *string = env.String("TOPIC_MAP_FILE", false, "", "JSON topic map naming the topics on the cluster, empty for the built-in names")
+var eventCodec *string = env.String("EVENT_CODEC", false, "json", "Encoding of the inbound transfers published on transaction: json or protobuf")
+
+type appConfigs struct {
+	inboxDir     string
+	outboxDir    string
+	pollInterval time.Duration
+	bankBIC      string
+	clearingBIC  string
+	kafkaBrokers []string
+	topicMapFile string
+	eventCodec   string
+}
+
+func NewAppConfig() (*appConfigs, error) {
+	// return if the instance already exists
+	if appConfig != nil {
+		return appConfig, nil
+	}
+	if err := env.Parse(); err != nil {
+		return nil, err
+	}
+
+	appConfig = &appConfigs{
+		inboxDir:     *inboxDir,
+		outboxDir:    *outboxDir,
+		pollInterval: *pollInterval,
+		bankBIC:      *bankBIC,
+		clearingBIC:  *clearingBIC,
+		kafkaBrokers: strings.Split(*kafkaBroker, ","),
+		topicMapFile: *topicMapFile,
+		eventCodec:   *eventCodec,
+	}
+	return appConfig, nil
+}
+
+// gets the directory inbound messages are read from
+func (apconfig *appConfigs) GetInboxDir() string {
+	return apconfig.inboxDir
+}
+
+// gets the directory outbound messages are written to
+func (apconfig *appConfigs) GetOutboxDir() string {
+	return apconfig.outboxDir
+}
+
+// gets how often the inbox is read
+func (apconfig *appConfigs) GetPollInterval() time.Duration {
+	return apconfig.pollInterval
+}
+
+// gets the BIC of this bank
+func (apconfig *appConfigs) GetBankBIC() string {
+	return apconfig.bankBIC
+}
+
+// gets the BIC of the clearing system
+func (apconfig *appConfigs) GetClearingBIC() string {
+	return apconfig.clearingBIC
+}
+
+// gets the brokers of the Kafka cluster
+func (apconfig *appConfigs) GetKafkaBrokers() []string {
+	return apconfig.kafkaBrokers
+}
+
+// gets the topic map file, empty when the topics keep their built-in names
+func (apconfig *appConfigs) GetTopicMapFile() string {
+	return apconfig.topicMapFile
+}
+
+// gets the name of the codec events are published with
+func (apconfig *appConfigs) GetEventCodec() string {
+	return apconfig.eventCodec
+}
@@ -0,0 +1,102 @@
+// Package filedrop stands in for the clearing network: messages are exchanged as files in an
+// inbox and an outbox directory. Files are written under a hidden temporary name and renamed, so
+// a reader never sees half a message.
+package filedrop
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrRejected marks a message that can never be processed. Such files are moved to the failed
+// directory; on any other error they stay in the inbox and are retried on the next poll.
+var ErrRejected = errors.New("message rejected")
+
+// Inbox is the directory the clearing system drops messages into
+type Inbox struct {
+	Dir string
+}
+
+// Poll hands every message in the inbox to handle in name order. Handled files are moved to the
+// processed directory and rejected ones to the failed directory, next to a .err file giving the
+// reason. Poll stops at the first error that is not a rejection and returns it.
+func (in *Inbox) Poll(handle func(name string, data []byte) error) error {
+	entries, err := os.ReadDir(in.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to read inbox: %w", err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && !strings.HasPrefix(entry.Name(), ".") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		path := filepath.Join(in.Dir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		handleErr := handle(name, data)
+		if errors.Is(handleErr, ErrRejected) {
+			if err := in.move(name, "failed"); err != nil {
+				return err
+			}
+			if err := writeAtomic(filepath.Join(in.Dir, "failed"), name+".err", []byte(handleErr.Error()+"\n")); err != nil {
+				return err
+			}
+			continue
+		}
+		if handleErr != nil {
+			return fmt.Errorf("%s: %w", name, handleErr)
+		}
+		if err := in.move(name, "processed"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// move files a message away in a subdirectory of the inbox
+func (in *Inbox) move(name, subdir string) error {
+	dir := filepath.Join(in.Dir, subdir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	if err := os.Rename(filepath.Join(in.Dir, name), filepath.Join(dir, name)); err != nil {
+		return fmt.Errorf("failed to move %s to %s: %w", name, subdir, err)
+	}
+	return nil
+}
+
+// Outbox is the directory the clearing system collects messages from
+type Outbox struct {
+	Dir string
+}
+
+// Write stores a message in the outbox, replacing any earlier file of the same name
+func (out *Outbox) Write(name string, data []byte) error {
+	return writeAtomic(out.Dir, name, data)
+}
+
+// writeAtomic writes data to a temporary file and renames it into place
+func writeAtomic(dir, name string, data []byte) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	tmp := filepath.Join(dir, "."+name+".tmp")
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, name)); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
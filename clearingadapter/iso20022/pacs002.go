@@ -0,0 +1,104 @@
+package iso20022
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+// Transaction status codes (ExternalPaymentTransactionStatus1Code) used by the clearing system
+const (
+	StatusAcceptedTechnical  = "ACTC" // Passed technical validation
+	StatusAcceptedProcessing = "ACSP" // Accepted, settlement in process
+	StatusAcceptedSettled    = "ACSC" // Settled on the debtor side
+	StatusAcceptedCredited   = "ACCC" // Credited to the creditor
+	StatusPending            = "PDNG"
+	StatusRejected           = "RJCT"
+)
+
+// Pacs002 is an FI to FI payment status report
+type Pacs002 struct {
+	XMLName xml.Name            `xml:"Document"`
+	Xmlns   string              `xml:"xmlns,attr,omitempty"`
+	Report  Pacs002StatusReport `xml:"FIToFIPmtStsRpt"`
+}
+
+type Pacs002StatusReport struct {
+	GroupHeader struct {
+		MessageID        string `xml:"MsgId"`
+		CreationDateTime string `xml:"CreDtTm"`
+	} `xml:"GrpHdr"`
+	OriginalGroup struct {
+		MessageID   string `xml:"OrgnlMsgId"`
+		MessageName string `xml:"OrgnlMsgNmId"`
+		GroupStatus string `xml:"GrpSts,omitempty"`
+	} `xml:"OrgnlGrpInfAndSts"`
+	Transactions []TxStatus `xml:"TxInfAndSts"`
+}
+
+// TxStatus is the status of one transaction of the original message
+type TxStatus struct {
+	OriginalEndToEndID string `xml:"OrgnlEndToEndId,omitempty"`
+	OriginalTxID       string `xml:"OrgnlTxId,omitempty"`
+	OriginalUETR       string `xml:"OrgnlUETR,omitempty"`
+	Status             string `xml:"TxSts"`
+	ReasonCode         string `xml:"StsRsnInf>Rsn>Cd,omitempty"`
+	AdditionalInfo     string `xml:"StsRsnInf>AddtlInf,omitempty"`
+}
+
+// ParsePacs002 reads a pacs.002
+func ParsePacs002(data []byte) (*Pacs002, error) {
+	var msg Pacs002
+	if err := xml.NewDecoder(bytes.NewReader(data)).Decode(&msg); err != nil {
+		return nil, fmt.Errorf("failed to parse pacs.002: %w", err)
+	}
+	if msg.Report.GroupHeader.MessageID == "" {
+		return nil, fmt.Errorf("pacs.002 group header has no MsgId")
+	}
+	if msg.Report.OriginalGroup.MessageID == "" {
+		return nil, fmt.Errorf("pacs.002 %s does not reference an original message", msg.Report.GroupHeader.MessageID)
+	}
+	return &msg, nil
+}
+
+// NewPacs002 builds a status report on the transactions of an original pacs.008
+func NewPacs002(messageID, originalMessageID string, statuses []TxStatus, now time.Time) *Pacs002 {
+	msg := &Pacs002{Xmlns: Pacs002Namespace}
+	msg.Report.GroupHeader.MessageID = messageID
+	msg.Report.GroupHeader.CreationDateTime = now.UTC().Format(isoDateTime)
+	msg.Report.OriginalGroup.MessageID = originalMessageID
+	msg.Report.OriginalGroup.MessageName = Pacs008Name
+	msg.Report.Transactions = statuses
+	return msg
+}
+
+// MessageType reports which message a document is by its root element's child, so files dropped
+// in the inbox can be routed without knowing their namespace
+func MessageType(data []byte) string {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return ""
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			if _, end := token.(xml.EndElement); end {
+				depth--
+			}
+			continue
+		}
+		depth++
+		if depth == 2 {
+			switch start.Name.Local {
+			case "FIToFICstmrCdtTrf":
+				return "pacs.008"
+			case "FIToFIPmtStsRpt":
+				return "pacs.002"
+			}
+			return ""
+		}
+	}
+}
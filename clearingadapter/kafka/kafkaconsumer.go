@@ -0,0 +1,79 @@
+package kafka
+
+import (
+	"clearingadapter/adapter"
+	"clearingadapter/models"
+	"errors"
+	"log"
+	"shared/audit"
+	"shared/events"
+
+	"github.com/IBM/sarama"
+	"github.com/hashicorp/go-hclog"
+)
+
+// KafkaConsumer renders the external transfers published on "clearing-outbound" as pacs.008, and
+// reports the outcome of inbound transfers read from "transaction-ledger" and "dead-ledger"
+type KafkaConsumer struct {
+	adapter  *adapter.Adapter
+	recorder *audit.Recorder
+	loggs    *hclog.Logger
+}
+
+func NewKafkaConsumer(a *adapter.Adapter, recorder *audit.Recorder, lobbs *hclog.Logger) *KafkaConsumer {
+	return &KafkaConsumer{
+		adapter:  a,
+		recorder: recorder,
+		loggs:    lobbs,
+	}
+}
+
+func (h KafkaConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		var trans models.Transaction
+		_, err := events.Unmarshal(events.TopicID(msg.Topic), msg.Value, eventHeaders(msg.Headers), &trans)
+		if errors.Is(err, events.ErrUnsupportedVersion) {
+			// left for a newer version of this service
+			(*h.loggs).Error("Cannot consume transfer yet", "Offset", msg.Offset, "Error", err)
+			return err
+		}
+		if err != nil {
+			log.Printf("Failed to unmarshal message (offset %d): %v", msg.Offset, err)
+			continue
+		}
+
+		// inbound transfers are answered once their deposit is posted or refused
+		if topic := events.TopicID(msg.Topic); topic == adapter.LedgerTopic || topic == adapter.DeadLedgerTopic {
+			status, err := h.adapter.Report(trans)
+			if err != nil {
+				(*h.loggs).Error("Failed to report inbound transfer to clearing", "Transaction", trans.ID, "Error", err)
+				return err
+			}
+			if status != "" {
+				h.recorder.Consumed(audit.Source(msg.Topic, msg.Partition, msg.Offset), "clearing.credit_reported",
+					trans.ID.String(), status, nil, &trans)
+				log.Printf("Reported inbound transfer %s as %s (partition %d, offset %d)", trans.ID, status, msg.Partition, msg.Offset)
+			}
+			session.MarkMessage(msg, "")
+			continue
+		}
+
+		// a transfer that cannot be sent is reported back as rejected by Send, so only
+		// failures to reach the outbox or the status topic are retried
+		outcome, err := h.adapter.Send(trans)
+		if err != nil {
+			(*h.loggs).Error("Failed to send transfer to clearing", "Transaction", trans.ID, "Error", err)
+			return err
+		}
+		h.recorder.Consumed(audit.Source(msg.Topic, msg.Partition, msg.Offset), "external_transfer.sent_to_clearing",
+			trans.ID.String(), outcome, nil, &trans)
+
+		log.Printf("Sent transfer %s to clearing (partition %d, offset %d)", trans.ID, msg.Partition, msg.Offset)
+		session.MarkMessage(msg, "")
+	}
+
+	return nil
+}
+
+func (KafkaConsumer) Setup(_ sarama.ConsumerGroupSession) error   { return nil }
+func (KafkaConsumer) Cleanup(_ sarama.ConsumerGroupSession) error { return nil }
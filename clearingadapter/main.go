@@ -0,0 +1,145 @@
+package main
+
+import (
+	"clearingadapter/adapter"
+	"clearingadapter/configurations"
+	"clearingadapter/filedrop"
+	"clearingadapter/kafka"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"shared/audit"
+	"shared/events"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/hashicorp/go-hclog"
+)
+
+func main() {
+
+	fmt.Println("Starting the clearing adapter")
+	events.Producer = "clearingadapter"
+	// logging app file
+	logFile, err := os.OpenFile("app.log", os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		fmt.Println("Some error occured in creating or opeing a log file", err)
+		os.Exit(1)
+	}
+
+	// logger
+	loggs := hclog.New(&hclog.LoggerOptions{
+		Name:       "Clearing Adapter",
+		Output:     logFile,
+		Level:      hclog.Debug,
+		JSONFormat: false,
+	})
+
+	cfg, err := configurations.NewAppConfig()
+	if err != nil {
+		loggs.Error("Not able to create Retrieve App Configurations")
+		os.Exit(1)
+	}
+	if err := events.UseCodec(cfg.GetEventCodec()); err != nil {
+		loggs.Error("Invalid EVENT_CODEC", "Error", err)
+		os.Exit(1)
+	}
+
+	if path := cfg.GetTopicMapFile(); path != "" {
+		if err := events.LoadTopics(path); err != nil {
+			loggs.Error("Not able to load topic map", "File", path, "Error", err)
+			os.Exit(1)
+		}
+	}
+	brokers := cfg.GetKafkaBrokers()
+
+	recorder := audit.NewRecorder("clearingadapter", &kafka.KafkaController{Brokers: brokers}, &loggs)
+	inbox := &filedrop.Inbox{Dir: cfg.GetInboxDir()}
+	clearing := &adapter.Adapter{
+		BIC:          cfg.GetBankBIC(),
+		ClearingBIC:  cfg.GetClearingBIC(),
+		Transactions: &kafka.KafkaController{Brokers: brokers},
+		Statuses:     &kafka.KafkaController{Brokers: brokers},
+		Outbox:       &filedrop.Outbox{Dir: cfg.GetOutboxDir()},
+		Recorder:     recorder,
+	}
+	for _, dir := range []string{cfg.GetInboxDir(), cfg.GetOutboxDir()} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			loggs.Error("Failed to create clearing directory", "Dir", dir, "Error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Kafka configuration
+	config := sarama.NewConfig()
+	config.Consumer.Group.Rebalance.Strategy = sarama.BalanceStrategyRoundRobin
+	config.Consumer.Offsets.Initial = sarama.OffsetOldest
+	config.Consumer.Return.Errors = true
+	groupID := "clearing-outbound-group"
+	topicName := events.TopicNames(adapter.OutboundTopic, adapter.LedgerTopic, adapter.DeadLedgerTopic)
+	// Create consumer group
+	consumerGroup, err := sarama.NewConsumerGroup(brokers, groupID, config)
+	if err != nil {
+		log.Fatalf("Failed to start consumer group: %v", err)
+	}
+	defer consumerGroup.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+
+	// outbound transfers are rendered as pacs.008, and the outcome of inbound ones is reported
+	kafkaConsumer := kafka.NewKafkaConsumer(clearing, recorder, &loggs)
+	go func() {
+		defer wg.Done()
+		for {
+			// Reconnect and resume on errors
+			err := consumerGroup.Consume(ctx, topicName, kafkaConsumer)
+			if err != nil {
+				log.Printf("Consumer error: %v", err)
+			}
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+
+	// inbound messages are picked up from the file drop
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(cfg.GetPollInterval())
+		defer ticker.Stop()
+		for {
+			if err := inbox.Poll(clearing.Receive); err != nil {
+				loggs.Error("Failed to process clearing inbox", "Error", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	// Listen for errors
+	go func() {
+		for err := range consumerGroup.Errors() {
+			log.Printf("Consumer group error: %v", err)
+		}
+	}()
+
+	log.Println("Clearing adapter started. Waiting for messages...")
+
+	// Handle SIGINT/SIGTERM for shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	<-sigChan
+
+	log.Println("Shutting down clearing adapter...")
+	cancel()
+	wg.Wait()
+}
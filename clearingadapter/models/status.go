@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Clearing statuses published on the "clearing-status" topic
+const (
+	ClearingAccepted = "accepted" // The clearing system accepted the transfer; settlement is in progress
+	ClearingSettled  = "settled"  // The transfer settled
+	ClearingRejected = "rejected" // The transfer was rejected and will not settle
+)
+
+// ClearingStatus reports the progress of an outbound transfer, from a pacs.002 the clearing system sent
+type ClearingStatus struct {
+	TransactionID string    `json:"transaction_id"`        // Transaction the report is about
+	Status        string    `json:"status"`                // ClearingAccepted, ClearingSettled or ClearingRejected
+	ISOStatus     string    `json:"iso_status"`            // TxSts code as reported, e.g. "ACSC"
+	ReasonCode    string    `json:"reason_code,omitempty"` // ISO reason code of a rejection, e.g. "AC04"
+	Reason        string    `json:"reason,omitempty"`      // Additional information from the report
+	MessageID     string    `json:"message_id"`            // pacs.002 the status came from
+	ReceivedAt    time.Time `json:"received_at"`           // Timestamp the report was read
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Transaction is the message exchanged on the "transaction" and "clearing-outbound" topics, and
+// read back from "transaction-ledger" and "dead-ledger" to report on inbound transfers
+type Transaction struct {
+	ID              uuid.UUID `json:"id"`                     // Unique identifier for the transaction
+	FromAccountID   string    `json:"from_account_id"`        // Account debited, or credited by a deposit
+	ToAccountID     string    `json:"to_account_id"`          // Account credited by internal transfers
+	Amount          float64   `json:"amount"`                 // Transaction amount
+	TransactionType string    `json:"transaction_type"`       // e.g. "deposit" for inbound credit transfers
+	Description     string    `json:"description"`            // Optional description of the transaction
+	CreatedAt       time.Time `json:"created_at"`             // Timestamp of transaction creation
+	Status          string    `json:"status"`                 // Transaction status
+	Currency        string    `json:"currency,omitempty"`     // Currency of Amount
+	Creditor        *Party    `json:"creditor,omitempty"`     // Beneficiary at another bank, set on outbound transfers
+	Debtor          *Party    `json:"debtor,omitempty"`       // Payer at another bank, set on inbound transfers
+	Reference       string    `json:"reference,omitempty"`    // End-to-end reference agreed with the counterparty
+	ClearingRef     string    `json:"clearing_ref,omitempty"` // Message and transaction ID in the clearing system
+
+	Postings []json.RawMessage `json:"postings,omitempty"` // Balance movements, set once the transaction service has posted it
+}
+
+// Party is an account holder at another bank
+type Party struct {
+	Name    string `json:"name"`              // Account holder name
+	IBAN    string `json:"iban,omitempty"`    // International account number
+	Account string `json:"account,omitempty"` // Proprietary account number, when there is no IBAN
+	BIC     string `json:"bic,omitempty"`     // Bank holding the account
+}
+
+// AccountNumber returns the IBAN, or the proprietary number when there is none
+func (p *Party) AccountNumber() string {
+	if p.IBAN != "" {
+		return p.IBAN
+	}
+	return p.Account
+}
@@ -24,3 +24,12 @@ if (!transactionsExists) {
 } else {
     print("Collection 'transactions' already exists, skipping creation...");
 }
+
+// Ledger entries are looked up by their transaction ID when reversals are linked
+db.transactions.createIndex({ transaction_id: 1 });
+
+// Each ledger entry holds one position in the hash chain; entries from before the chain have none
+db.transactions.createIndex({ ledger_seq: 1 }, { unique: true, partialFilterExpression: { ledger_seq: { $exists: true } } });
+
+// Audit records are chained by sequence number (_id); an entry is chained once, by its ID
+db.audit_log.createIndex({ id: 1 }, { unique: true });
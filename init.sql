@@ -16,8 +16,91 @@ CREATE EXTENSION IF NOT EXISTS pgcrypto;
 -- Create the 'usersschema' schema
 CREATE SCHEMA IF NOT EXISTS usersschema;
 
-DROP TABLE IF EXISTS usersschema.accounts;
+DROP TABLE IF EXISTS usersschema.eod_balances;
+DROP TABLE IF EXISTS usersschema.account_snapshots;
+DROP TABLE IF EXISTS usersschema.account_events;
+DROP TABLE IF EXISTS usersschema.saga_steps;
+DROP TABLE IF EXISTS usersschema.sagas;
+DROP TABLE IF EXISTS usersschema.transaction_fees;
+DROP TABLE IF EXISTS usersschema.fee_rules;
+DROP TABLE IF EXISTS usersschema.interest_accruals;
+DROP TABLE IF EXISTS usersschema.external_transfers;
+DROP TABLE IF EXISTS usersschema.holds;
+DROP TABLE IF EXISTS usersschema.overdraft_interest_charges;
 DROP TABLE IF EXISTS usersschema.transactions;
+DROP TABLE IF EXISTS usersschema.accounts;
+DROP TABLE IF EXISTS usersschema.account_tiers;
+DROP TABLE IF EXISTS usersschema.interest_plans;
+DROP TABLE IF EXISTS usersschema.fx_rates;
+
+-- Create the 'fx_rates' table: one unit of base_currency buys rate units of quote_currency
+CREATE TABLE usersschema.fx_rates (
+    base_currency character(3) NOT NULL,
+    quote_currency character(3) NOT NULL,
+    rate double precision NOT NULL CHECK (rate > 0),
+    updated_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fx_rates_pkey PRIMARY KEY (base_currency, quote_currency),
+    CONSTRAINT fx_rates_distinct_check CHECK (base_currency <> quote_currency)
+);
+
+-- Create the 'account_tiers' table holding per-tier limits
+CREATE TABLE usersschema.account_tiers (
+    name character varying(50) NOT NULL,
+    max_per_transaction double precision NOT NULL, -- Largest single withdrawal or transfer
+    daily_withdrawal_limit double precision NOT NULL,
+    monthly_withdrawal_limit double precision NOT NULL,
+    daily_transfer_limit double precision NOT NULL,
+    monthly_transfer_limit double precision NOT NULL,
+    min_balance double precision NOT NULL DEFAULT 0.0, -- Balance that must remain after a debit
+    currency character(3) NOT NULL DEFAULT 'USD', -- ISO 4217 code the limits are expressed in
+    updated_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT account_tiers_pkey PRIMARY KEY (name),
+    CONSTRAINT account_tiers_limits_check CHECK (
+        max_per_transaction >= 0 AND daily_withdrawal_limit >= 0 AND monthly_withdrawal_limit >= 0
+        AND daily_transfer_limit >= 0 AND monthly_transfer_limit >= 0 AND min_balance >= 0)
+);
+
+-- Seed the default tiers
+INSERT INTO usersschema.account_tiers (name, max_per_transaction, daily_withdrawal_limit, monthly_withdrawal_limit, daily_transfer_limit, monthly_transfer_limit, min_balance) VALUES
+    ('basic', 1000, 2000, 20000, 5000, 50000, 0),
+    ('premium', 10000, 20000, 200000, 50000, 500000, 500),
+    ('business', 100000, 200000, 2000000, 500000, 5000000, 1000);
+
+-- Create the 'interest_plans' table describing how savings interest accrues
+CREATE TABLE usersschema.interest_plans (
+    name character varying(50) NOT NULL,
+    plan_type character varying(20) NOT NULL CHECK (plan_type IN ('simple', 'tiered', 'compound_monthly')),
+    annual_rate double precision NOT NULL DEFAULT 0.0 CHECK (annual_rate >= 0), -- Rate for simple and compound_monthly plans
+    tiers jsonb NOT NULL DEFAULT '[]', -- [{"up_to": 1000, "rate": 0.01}, {"up_to": 0, "rate": 0.02}] for tiered plans
+    day_count character varying(10) NOT NULL DEFAULT 'ACT/365' CHECK (day_count IN ('ACT/365', '30/360')),
+    updated_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT interest_plans_pkey PRIMARY KEY (name)
+);
+
+-- Seed the default savings plans
+INSERT INTO usersschema.interest_plans (name, plan_type, annual_rate, tiers, day_count) VALUES
+    ('easy-saver', 'simple', 0.015, '[]', 'ACT/365'),
+    ('tiered-saver', 'tiered', 0, '[{"up_to": 10000, "rate": 0.01}, {"up_to": 50000, "rate": 0.02}, {"up_to": 0, "rate": 0.03}]', 'ACT/365'),
+    ('monthly-compound', 'compound_monthly', 0.025, '[]', '30/360');
+
+-- Create the customers table: the person behind one or more accounts, with the details collected for KYC
+CREATE TABLE usersschema.customers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    full_name character varying(255) NOT NULL, -- Legal name as on the identity document
+    email character varying(255) NOT NULL,
+    phone character varying(50),
+    date_of_birth date NOT NULL,
+    nationality character(2) NOT NULL, -- ISO 3166-1 alpha-2 code
+    address TEXT NOT NULL,
+    id_document_type VARCHAR(20) NOT NULL CHECK (id_document_type IN ('passport', 'national_id', 'driving_licence')),
+    id_document_number character varying(100) NOT NULL,
+    kyc_status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (kyc_status IN ('pending', 'verified', 'rejected')),
+    kyc_note TEXT, -- Reason given with the last verification decision
+    verified_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT customers_email_key UNIQUE (email)
+);
 
 -- Create the 'accounts' table in the 'usersschema' schema
 CREATE TABLE usersschema.accounts (
@@ -29,10 +112,107 @@ CREATE TABLE usersschema.accounts (
     created_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
     is_active boolean NOT NULL DEFAULT true,
+    tier character varying(50) NOT NULL DEFAULT 'basic', -- Limits applied to the account
+    overdraft_limit double precision NOT NULL DEFAULT 0.0 CHECK (overdraft_limit >= 0), -- How far the balance may go below zero
+    overdraft_interest_rate double precision NOT NULL DEFAULT 0.0 CHECK (overdraft_interest_rate >= 0), -- Annual rate charged on the overdrawn amount
+    overdraft_used double precision NOT NULL DEFAULT 0.0, -- Amount currently drawn on the overdraft
+    currency character(3) NOT NULL DEFAULT 'USD', -- ISO 4217 code the balance is held in
+    interest_plan character varying(50), -- Savings plan the account earns interest under, if any
+    customer_id UUID, -- Customer holding the account; only accounts of verified customers can transact
+    version bigint NOT NULL DEFAULT 0, -- Version of the last event in account_events the balance includes
     CONSTRAINT accounts_pkey PRIMARY KEY (id),
-    CONSTRAINT accounts_accountnumber_key UNIQUE (account_number)
+    CONSTRAINT accounts_accountnumber_key UNIQUE (account_number),
+    CONSTRAINT fk_account_tier FOREIGN KEY (tier) REFERENCES usersschema.account_tiers(name) ON DELETE RESTRICT,
+    CONSTRAINT fk_account_interest_plan FOREIGN KEY (interest_plan) REFERENCES usersschema.interest_plans(name) ON DELETE RESTRICT,
+    CONSTRAINT fk_account_customer FOREIGN KEY (customer_id) REFERENCES usersschema.customers(id) ON DELETE RESTRICT
 );
 
+CREATE INDEX accounts_customer_idx ON usersschema.accounts (customer_id);
+
+-- Create the account holders table: every customer with access to an account and their role
+CREATE TABLE usersschema.account_holders (
+    account_number character varying(255) NOT NULL,
+    customer_id UUID NOT NULL,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'joint', 'viewer')), -- Owners and joint holders can transact and approve, viewers can only see the account
+    added_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT account_holders_pkey PRIMARY KEY (account_number, customer_id),
+    CONSTRAINT fk_holder_account FOREIGN KEY (account_number) REFERENCES usersschema.accounts(account_number) ON DELETE CASCADE,
+    CONSTRAINT fk_holder_customer FOREIGN KEY (customer_id) REFERENCES usersschema.customers(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX account_holders_customer_idx ON usersschema.account_holders (customer_id);
+
+-- Create the approval rules table: how many holders must approve a transaction type at or above an amount
+CREATE TABLE usersschema.approval_rules (
+    account_number character varying(255) NOT NULL,
+    transaction_type VARCHAR(50) NOT NULL CHECK (transaction_type IN ('withdrawal', 'transfer', 'external_transfer')),
+    min_amount double precision NOT NULL DEFAULT 0.0 CHECK (min_amount >= 0),
+    required_approvals integer NOT NULL CHECK (required_approvals >= 1), -- Distinct owners or joint holders
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT approval_rules_pkey PRIMARY KEY (account_number, transaction_type),
+    CONSTRAINT fk_approval_rule_account FOREIGN KEY (account_number) REFERENCES usersschema.accounts(account_number) ON DELETE CASCADE
+);
+
+-- Seed the internal account that collects transaction fees
+INSERT INTO usersschema.accounts (account_number, username, email, currency) VALUES
+    ('FEE-INCOME', 'fee-income', 'fees@bank.internal', 'USD');
+
+-- Seed the internal account external transfers are held in until the clearing system settles or returns them
+INSERT INTO usersschema.accounts (account_number, username, email, currency) VALUES
+    ('CLEARING-SUSPENSE', 'clearing-suspense', 'clearing@bank.internal', 'USD');
+
+-- Create the account events table: the append-only stream every balance change is recorded in.
+-- The balance in accounts is a projection of it and can be rebuilt by replaying the stream.
+CREATE TABLE usersschema.account_events (
+    account_number character varying(255) NOT NULL,
+    version bigint NOT NULL CHECK (version > 0), -- Position in the account's stream, starting at 1
+    event_type VARCHAR(20) NOT NULL CHECK (event_type IN ('opened', 'credited', 'debited')),
+    amount double precision NOT NULL CHECK (amount >= 0),
+    transaction_id UUID, -- Transaction that moved the balance; not a foreign key, as the event is written first
+    cause character varying(50) NOT NULL, -- Transaction type or internal movement, e.g. "fee" or "reversal"
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT clock_timestamp(),
+    CONSTRAINT account_events_pkey PRIMARY KEY (account_number, version),
+    CONSTRAINT fk_event_account FOREIGN KEY (account_number) REFERENCES usersschema.accounts(account_number) ON DELETE RESTRICT
+);
+
+CREATE INDEX account_events_occurred_idx ON usersschema.account_events (account_number, occurred_at);
+
+-- Events are never changed once written
+CREATE OR REPLACE FUNCTION usersschema.account_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'account_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER account_events_append_only
+    BEFORE UPDATE OR DELETE ON usersschema.account_events
+    FOR EACH ROW EXECUTE FUNCTION usersschema.account_events_append_only();
+
+-- Create the account snapshots table: the balance every SNAPSHOT_EVERY events, so a replay starts
+-- from the latest snapshot instead of the first event
+CREATE TABLE usersschema.account_snapshots (
+    account_number character varying(255) NOT NULL,
+    version bigint NOT NULL, -- Version of the last event included
+    balance double precision NOT NULL,
+    taken_at TIMESTAMP WITH TIME ZONE NOT NULL, -- When that event occurred
+    CONSTRAINT account_snapshots_pkey PRIMARY KEY (account_number, version),
+    CONSTRAINT fk_snapshot_account FOREIGN KEY (account_number) REFERENCES usersschema.accounts(account_number) ON DELETE RESTRICT
+);
+
+-- Create the end-of-day balances table: every account's balance at the close of each business day,
+-- written once by the end-of-day job and never changed
+CREATE TABLE usersschema.eod_balances (
+    account_number character varying(255) NOT NULL,
+    business_date date NOT NULL,
+    balance double precision NOT NULL,
+    version bigint NOT NULL, -- Version of the last event included
+    closed_at TIMESTAMP WITH TIME ZONE NOT NULL, -- Midnight ending the day in EOD_TIMEZONE; events before it are included
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT eod_balances_pkey PRIMARY KEY (account_number, business_date),
+    CONSTRAINT fk_eod_account FOREIGN KEY (account_number) REFERENCES usersschema.accounts(account_number) ON DELETE RESTRICT
+);
+
+CREATE INDEX eod_balances_date_idx ON usersschema.eod_balances (business_date);
 
 -- Create the transactions table
 CREATE TABLE usersschema.transactions (
@@ -40,16 +220,274 @@ CREATE TABLE usersschema.transactions (
     from_account_id character varying(255) NOT NULL,
     to_account_id character varying(255), -- Nullable for deposits/withdrawals involving external systems
     amount double precision NOT NULL CHECK (amount > 0), -- Precision for currency (e.g., 15 digits, 2 after decimal)
-    transaction_type VARCHAR(50) NOT NULL CHECK (transaction_type IN ('transfer', 'deposit', 'withdrawal')),
+    transaction_type VARCHAR(50) NOT NULL CHECK (transaction_type IN ('transfer', 'deposit', 'withdrawal', 'overdraft_interest', 'reversal', 'interest', 'external_transfer', 'clearing_settlement', 'clearing_return')),
     description TEXT, -- Optional field, can store longer text
     created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed', 'failed')),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed', 'failed', 'reversed')),
+    original_transaction_id UUID, -- Transaction undone by a reversal
+    reversed_amount double precision NOT NULL DEFAULT 0.0 CHECK (reversed_amount >= 0), -- Amount refunded by later reversals
+    currency character(3) NOT NULL DEFAULT 'USD', -- Currency of amount, always that of from_account_id
+    fx_rate double precision, -- Rate applied when to_account_id holds another currency
+    converted_amount double precision, -- Amount credited to to_account_id in converted_currency
+    converted_currency character(3),
     -- Foreign key constraints linking to accounts table
     CONSTRAINT fk_from_account FOREIGN KEY (from_account_id) REFERENCES usersschema.accounts(account_number) ON DELETE RESTRICT,
-    CONSTRAINT fk_to_account FOREIGN KEY (to_account_id) REFERENCES usersschema.accounts(account_number) ON DELETE RESTRICT
+    CONSTRAINT fk_to_account FOREIGN KEY (to_account_id) REFERENCES usersschema.accounts(account_number) ON DELETE RESTRICT,
+    CONSTRAINT fk_original_transaction FOREIGN KEY (original_transaction_id) REFERENCES usersschema.transactions(id) ON DELETE RESTRICT
+);
+
+-- Speeds up the daily and monthly limit lookups
+CREATE INDEX transactions_from_account_created_idx ON usersschema.transactions (from_account_id, transaction_type, created_at);
+
+-- Create the overdraft interest charges table, one row per account per day
+CREATE TABLE usersschema.overdraft_interest_charges (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_number character varying(255) NOT NULL,
+    charge_date date NOT NULL,
+    overdrawn_amount double precision NOT NULL, -- Overdraft used when the charge was taken
+    annual_rate double precision NOT NULL,
+    amount double precision NOT NULL CHECK (amount > 0),
+    transaction_id UUID NOT NULL, -- Transaction that posted the charge
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT overdraft_interest_charges_account_date_key UNIQUE (account_number, charge_date),
+    CONSTRAINT fk_overdraft_account FOREIGN KEY (account_number) REFERENCES usersschema.accounts(account_number) ON DELETE RESTRICT
+);
+
+-- Create the holds table: funds reserved on an account until captured, released or expired
+CREATE TABLE usersschema.holds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_number character varying(255) NOT NULL,
+    amount double precision NOT NULL CHECK (amount > 0), -- Amount reserved
+    captured_amount double precision NOT NULL DEFAULT 0.0 CHECK (captured_amount >= 0), -- Amount actually debited on capture
+    capture_transaction_id UUID, -- Transaction that posted the captured debit
+    reference TEXT, -- Optional merchant or authorization reference
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'captured', 'released', 'expired')),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_hold_account FOREIGN KEY (account_number) REFERENCES usersschema.accounts(account_number) ON DELETE RESTRICT
+);
+
+-- Active holds are summed on every debit
+CREATE INDEX holds_active_account_idx ON usersschema.holds (account_number) WHERE status = 'active';
+
+-- Create the external transfers table: transfers to other banks, held in the clearing suspense account until settled or returned
+CREATE TABLE usersschema.external_transfers (
+    id UUID PRIMARY KEY, -- Transaction that debited the customer into the suspense account
+    account_number character varying(255) NOT NULL,
+    amount double precision NOT NULL CHECK (amount > 0), -- Amount debited, in currency
+    currency character(3) NOT NULL,
+    suspense_amount double precision NOT NULL CHECK (suspense_amount > 0), -- Amount credited to the suspense account, in its currency
+    creditor_name character varying(140) NOT NULL,
+    creditor_iban character varying(34),
+    creditor_account character varying(255),
+    creditor_bic character varying(11) NOT NULL,
+    reference character varying(35), -- End-to-end reference agreed with the beneficiary
+    status VARCHAR(20) NOT NULL DEFAULT 'initiated' CHECK (status IN ('initiated', 'submitted', 'settled', 'returned')),
+    clearing_status character varying(4), -- Last ISO status reported by the clearing system
+    reason TEXT, -- Why the transfer was returned
+    settlement_transaction_id UUID, -- Transaction that settled or returned the funds held in suspense
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_external_transaction FOREIGN KEY (id) REFERENCES usersschema.transactions(id) ON DELETE RESTRICT,
+    CONSTRAINT fk_external_account FOREIGN KEY (account_number) REFERENCES usersschema.accounts(account_number) ON DELETE RESTRICT,
+    CONSTRAINT fk_external_settlement FOREIGN KEY (settlement_transaction_id) REFERENCES usersschema.transactions(id) ON DELETE RESTRICT,
+    CONSTRAINT external_transfers_creditor_check CHECK (creditor_iban IS NOT NULL OR creditor_account IS NOT NULL)
+);
+
+-- Transfers not yet submitted are picked up again by the resubmission job
+CREATE INDEX external_transfers_status_idx ON usersschema.external_transfers (status, updated_at);
+
+-- Create the interest accruals table, one row per account per day until paid out by the monthly credit
+CREATE TABLE usersschema.interest_accruals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_number character varying(255) NOT NULL,
+    accrual_date date NOT NULL,
+    plan character varying(50) NOT NULL,
+    balance double precision NOT NULL, -- End-of-day balance the interest was computed on
+    amount double precision NOT NULL CHECK (amount >= 0), -- Unrounded interest for the day
+    transaction_id UUID, -- Monthly credit that paid the accrual out
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT interest_accruals_account_date_key UNIQUE (account_number, accrual_date),
+    CONSTRAINT fk_interest_account FOREIGN KEY (account_number) REFERENCES usersschema.accounts(account_number) ON DELETE RESTRICT
+);
+
+-- Unposted accruals are summed by the monthly posting
+CREATE INDEX interest_accruals_unposted_idx ON usersschema.interest_accruals (account_number) WHERE transaction_id IS NULL;
+
+-- Create the fee rules table evaluated on every deposit, withdrawal and transfer
+CREATE TABLE usersschema.fee_rules (
+    id BIGSERIAL PRIMARY KEY,
+    name character varying(100) NOT NULL, -- Label shown on the fee line
+    transaction_type VARCHAR(50) NOT NULL CHECK (transaction_type IN ('deposit', 'withdrawal', 'transfer')),
+    tier character varying(50), -- Only accounts on this tier pay the fee; NULL for every tier
+    fee_type VARCHAR(20) NOT NULL CHECK (fee_type IN ('flat', 'percentage', 'tiered')),
+    amount double precision NOT NULL DEFAULT 0.0 CHECK (amount >= 0), -- Flat fee
+    rate double precision NOT NULL DEFAULT 0.0 CHECK (rate >= 0), -- Percentage fee as a fraction
+    min_fee double precision NOT NULL DEFAULT 0.0 CHECK (min_fee >= 0),
+    max_fee double precision NOT NULL DEFAULT 0.0 CHECK (max_fee >= 0), -- 0 for no cap
+    bands jsonb NOT NULL DEFAULT '[]', -- [{"up_to": 100, "fee": 0.5}, {"up_to": 0, "fee": 2}] for tiered fees
+    free_per_month integer NOT NULL DEFAULT 0 CHECK (free_per_month >= 0), -- Transactions per month exempt from the fee
+    active boolean NOT NULL DEFAULT true,
+    updated_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_fee_rule_tier FOREIGN KEY (tier) REFERENCES usersschema.account_tiers(name) ON DELETE CASCADE
+);
+
+-- Create the fee lines table: the fees charged on each transaction
+CREATE TABLE usersschema.transaction_fees (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    transaction_id UUID NOT NULL,
+    rule_id BIGINT, -- Rule that produced the fee; kept NULL if the rule is deleted later
+    name character varying(100) NOT NULL,
+    amount double precision NOT NULL CHECK (amount > 0), -- Taken from the paying account in currency
+    currency character(3) NOT NULL,
+    fee_account character varying(255) NOT NULL, -- Account the fee was credited to
+    credited_amount double precision NOT NULL, -- Amount credited to fee_account in its own currency
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_fee_transaction FOREIGN KEY (transaction_id) REFERENCES usersschema.transactions(id) ON DELETE RESTRICT,
+    CONSTRAINT fk_fee_rule FOREIGN KEY (rule_id) REFERENCES usersschema.fee_rules(id) ON DELETE SET NULL,
+    CONSTRAINT fk_fee_account FOREIGN KEY (fee_account) REFERENCES usersschema.accounts(account_number) ON DELETE RESTRICT
+);
+
+-- Create the pending approvals table: transactions held until enough holders of a joint account approve them
+CREATE TABLE usersschema.pending_approvals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    transaction_id UUID NOT NULL, -- ID the transaction is applied under once approved
+    account_number character varying(255) NOT NULL, -- Account whose rule applied
+    transaction jsonb NOT NULL, -- Transaction as it was received
+    required_approvals integer NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected', 'expired')),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    decided_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT pending_approvals_transaction_key UNIQUE (transaction_id),
+    CONSTRAINT fk_pending_approval_account FOREIGN KEY (account_number) REFERENCES usersschema.accounts(account_number) ON DELETE RESTRICT
 );
 
+-- Create the approval decisions table: one decision per holder per pending approval
+CREATE TABLE usersschema.approval_decisions (
+    pending_approval_id UUID NOT NULL,
+    customer_id UUID NOT NULL,
+    decision VARCHAR(20) NOT NULL CHECK (decision IN ('approve', 'reject')),
+    note TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT approval_decisions_pkey PRIMARY KEY (pending_approval_id, customer_id),
+    CONSTRAINT fk_decision_pending_approval FOREIGN KEY (pending_approval_id) REFERENCES usersschema.pending_approvals(id) ON DELETE CASCADE,
+    CONSTRAINT fk_decision_customer FOREIGN KEY (customer_id) REFERENCES usersschema.customers(id) ON DELETE RESTRICT
+);
+
+-- Create the payees table: the beneficiaries each account may transfer to
+CREATE TABLE usersschema.payees (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_number character varying(255) NOT NULL, -- Account the payee is registered on
+    nickname character varying(255) NOT NULL,
+    payee_account character varying(255) NOT NULL, -- Account transfers to the payee are credited to, or IBAN or account number at payee_bic
+    payee_bic character varying(11), -- Bank of a payee at another bank, paid by external transfers; NULL for accounts of this bank
+    payee_name character varying(255), -- Name of the beneficiary as given by the customer
+    status VARCHAR(20) NOT NULL DEFAULT 'unverified' CHECK (status IN ('unverified', 'verified')),
+    active_from TIMESTAMP WITH TIME ZONE NOT NULL, -- End of the cooling-off period
+    verified_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT payees_account_payee_key UNIQUE (account_number, payee_account),
+    CONSTRAINT payees_account_nickname_key UNIQUE (account_number, nickname),
+    CONSTRAINT payees_external_name_check CHECK (payee_bic IS NULL OR payee_name IS NOT NULL),
+    CONSTRAINT fk_payee_account FOREIGN KEY (account_number) REFERENCES usersschema.accounts(account_number) ON DELETE CASCADE
+);
+
+-- Create the risk reviews table: transactions held or rejected by the risk checks before any balance moved
+CREATE TABLE usersschema.risk_reviews (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    transaction_id UUID NOT NULL, -- ID the transaction is applied under once approved
+    transaction jsonb NOT NULL, -- Transaction as it was received
+    decision VARCHAR(20) NOT NULL CHECK (decision IN ('review', 'reject')),
+    score integer NOT NULL DEFAULT 0,
+    reasons TEXT[] NOT NULL DEFAULT '{}', -- Rules that fired
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected', 'failed')),
+    reviewer character varying(255), -- Operator who decided the review
+    note TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    decided_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT risk_reviews_transaction_key UNIQUE (transaction_id)
+);
+
+-- Create the screening results table: the audit trail of every name screened against the sanctions watchlist
+CREATE TABLE usersschema.screening_results (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    subject VARCHAR(20) NOT NULL CHECK (subject IN ('account', 'customer', 'transfer')),
+    reference character varying(255) NOT NULL, -- Account number, customer ID or transaction ID
+    party VARCHAR(20) NOT NULL CHECK (party IN ('account_holder', 'customer', 'payer', 'beneficiary')),
+    name character varying(255) NOT NULL, -- Name that was screened
+    decision VARCHAR(20) NOT NULL CHECK (decision IN ('clear', 'flag', 'block')),
+    score double precision NOT NULL, -- Best similarity found, 0 to 1
+    entry_id character varying(255), -- Closest watchlist entry
+    matched_name character varying(255),
+    program character varying(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX screening_results_reference_idx ON usersschema.screening_results (reference);
+
+-- Create the sagas table: multi-step transactions driven over the "transaction" topic
+CREATE TABLE usersschema.sagas (
+    id UUID PRIMARY KEY,
+    saga_type VARCHAR(30) NOT NULL CHECK (saga_type IN ('transfer_with_fee', 'external_transfer')),
+    status VARCHAR(20) NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'compensating', 'completed', 'compensated', 'failed')),
+    request JSONB NOT NULL, -- Request the steps were planned from
+    error TEXT, -- Why the saga did not complete
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Unfinished sagas are picked up again by the recovery job
+CREATE INDEX sagas_status_idx ON usersschema.sagas (status, updated_at);
+
+-- Create the saga steps table, one row per transaction a saga posts or waits for
+CREATE TABLE usersschema.saga_steps (
+    saga_id UUID NOT NULL,
+    step_index integer NOT NULL,
+    name character varying(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'failed', 'compensating', 'compensated')),
+    command JSONB, -- Transaction published to the "transaction" topic; NULL for steps waiting on another service
+    transaction_id UUID NOT NULL, -- Movement that completes the step
+    failure_id UUID, -- Movement that fails a step without a command
+    compensation_id UUID, -- Reversal undoing the step
+    attempts integer NOT NULL DEFAULT 0,
+    published_at TIMESTAMP WITH TIME ZONE,
+    error TEXT,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (saga_id, step_index),
+    CONSTRAINT fk_saga_steps_saga FOREIGN KEY (saga_id) REFERENCES usersschema.sagas(id) ON DELETE CASCADE
+);
+
+-- Ledger events are matched to the step waiting for them
+CREATE INDEX saga_steps_transaction_idx ON usersschema.saga_steps (transaction_id);
+CREATE INDEX saga_steps_failure_idx ON usersschema.saga_steps (failure_id) WHERE failure_id IS NOT NULL;
+CREATE INDEX saga_steps_compensation_idx ON usersschema.saga_steps (compensation_id) WHERE compensation_id IS NOT NULL;
+
 -- Optional: Grant privileges on the schema and table to the user
 GRANT USAGE ON SCHEMA usersschema TO postgres;
+GRANT ALL PRIVILEGES ON usersschema.customers TO postgres;
 GRANT ALL PRIVILEGES ON usersschema.accounts TO postgres;
-GRANT ALL PRIVILEGES ON usersschema.transactions TO postgres;
\ No newline at end of file
+GRANT ALL PRIVILEGES ON usersschema.account_holders TO postgres;
+GRANT ALL PRIVILEGES ON usersschema.approval_rules TO postgres;
+GRANT ALL PRIVILEGES ON usersschema.pending_approvals TO postgres;
+GRANT ALL PRIVILEGES ON usersschema.approval_decisions TO postgres;
+GRANT ALL PRIVILEGES ON usersschema.payees TO postgres;
+GRANT ALL PRIVILEGES ON usersschema.transactions TO postgres;
+GRANT ALL PRIVILEGES ON usersschema.account_tiers TO postgres;
+GRANT ALL PRIVILEGES ON usersschema.overdraft_interest_charges TO postgres;
+GRANT ALL PRIVILEGES ON usersschema.holds TO postgres;
+GRANT ALL PRIVILEGES ON usersschema.external_transfers TO postgres;
+GRANT ALL PRIVILEGES ON usersschema.fx_rates TO postgres;
+GRANT ALL PRIVILEGES ON usersschema.interest_plans TO postgres;
+GRANT ALL PRIVILEGES ON usersschema.interest_accruals TO postgres;
+GRANT ALL PRIVILEGES ON usersschema.fee_rules TO postgres;
+GRANT ALL PRIVILEGES ON usersschema.transaction_fees TO postgres;
+GRANT ALL PRIVILEGES ON usersschema.risk_reviews TO postgres;
+GRANT ALL PRIVILEGES ON usersschema.screening_results TO postgres;
+GRANT ALL PRIVILEGES ON usersschema.sagas TO postgres;
+GRANT ALL PRIVILEGES ON usersschema.saga_steps TO postgres;
+GRANT ALL PRIVILEGES ON usersschema.account_events TO postgres;
+GRANT ALL PRIVILEGES ON usersschema.account_snapshots TO postgres;
+GRANT ALL PRIVILEGES ON usersschema.eod_balances TO postgres;
+GRANT USAGE, SELECT ON SEQUENCE usersschema.fee_rules_id_seq TO postgres;
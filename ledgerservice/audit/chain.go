@@ -0,0 +1,120 @@
+// Package audit keeps the audit log: every state-changing API call and consumer action the
+// services publish on the "audit-log" topic is appended to the "audit_log" collection as a
+// record holding the hash of the record before it. Changing, removing or reordering a record
+// breaks every link after it, which Verifier detects.
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	shared "shared/audit"
+	"strings"
+	"time"
+)
+
+// Topic carries the audit entries of every service
+const Topic = shared.Topic
+
+// GenesisHash is the previous hash of the first record
+var GenesisHash = strings.Repeat("0", 64)
+
+// ErrTampered is returned when the audit log no longer matches its hashes
+var ErrTampered = errors.New("audit log tampered")
+
+// Entry is one recorded action, as the services publish it on Topic
+type Entry = shared.Entry
+
+// Record is an entry as chained in the "audit_log" collection. The request and states are kept
+// as the JSON text they were published as, so that they hash the same once read back.
+type Record struct {
+	Seq        int64     `bson:"_id" json:"seq"` // 1 for the first record, then one more for each
+	ID         string    `bson:"id" json:"id"`
+	OccurredAt time.Time `bson:"occurred_at" json:"occurred_at"`
+	Service    string    `bson:"service" json:"service"`
+	Kind       string    `bson:"kind" json:"kind"`
+	Action     string    `bson:"action" json:"action"`
+	Resource   string    `bson:"resource" json:"resource"`
+	Actor      string    `bson:"actor" json:"actor"`
+	RequestID  string    `bson:"request_id" json:"request_id"`
+	SourceIP   string    `bson:"source_ip,omitempty" json:"source_ip,omitempty"`
+	Outcome    string    `bson:"outcome" json:"outcome"`
+	Request    string    `bson:"request,omitempty" json:"request,omitempty"`
+	Before     string    `bson:"before,omitempty" json:"before,omitempty"`
+	After      string    `bson:"after,omitempty" json:"after,omitempty"`
+	ChainedAt  time.Time `bson:"chained_at" json:"chained_at"`
+	PrevHash   string    `bson:"prev_hash" json:"prev_hash"`
+	Hash       string    `bson:"hash" json:"hash,omitempty"`
+}
+
+// Chain makes the record following prev out of an entry; prev is nil for the first record.
+// Times are kept to the millisecond, as MongoDB stores them.
+func Chain(entry Entry, prev *Record, at time.Time) Record {
+	record := Record{
+		Seq:        1,
+		ID:         entry.ID,
+		OccurredAt: entry.OccurredAt.UTC().Truncate(time.Millisecond),
+		Service:    entry.Service,
+		Kind:       entry.Kind,
+		Action:     entry.Action,
+		Resource:   entry.Resource,
+		Actor:      entry.Actor,
+		RequestID:  entry.RequestID,
+		SourceIP:   entry.SourceIP,
+		Outcome:    entry.Outcome,
+		Request:    string(entry.Request),
+		Before:     string(entry.Before),
+		After:      string(entry.After),
+		ChainedAt:  at.UTC().Truncate(time.Millisecond),
+		PrevHash:   GenesisHash,
+	}
+	if prev != nil {
+		record.Seq = prev.Seq + 1
+		record.PrevHash = prev.Hash
+	}
+	record.Hash = Hash(record)
+	return record
+}
+
+// Hash is the SHA-256 of the JSON encoding of every field of a record but its hash
+func Hash(record Record) string {
+	record.Hash = ""
+	record.OccurredAt = record.OccurredAt.UTC()
+	record.ChainedAt = record.ChainedAt.UTC()
+	data, _ := json.Marshal(record) // a Record always encodes
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Verifier checks records read in sequence order
+type Verifier struct {
+	Count int64  // Records checked
+	Head  string // Hash of the last record checked
+	next  int64
+}
+
+// NewVerifier creates a Verifier expecting the first record of the log
+func NewVerifier() *Verifier {
+	return &Verifier{Head: GenesisHash, next: 1}
+}
+
+// Check checks that record is the next one and that neither it nor its link to the record
+// before it changed. After a failed check the verifier carries on from record, so that every
+// damaged record is reported.
+func (v *Verifier) Check(record Record) error {
+	var err error
+	switch {
+	case record.Seq != v.next:
+		err = fmt.Errorf("%w: expected record %d, found %d", ErrTampered, v.next, record.Seq)
+	case record.PrevHash != v.Head:
+		err = fmt.Errorf("%w: record %d does not link to record %d", ErrTampered, record.Seq, record.Seq-1)
+	case Hash(record) != record.Hash:
+		err = fmt.Errorf("%w: record %d was changed", ErrTampered, record.Seq)
+	}
+	v.Count++
+	v.Head = record.Hash
+	v.next = record.Seq + 1
+	return err
+}
@@ -0,0 +1,79 @@
+package audit
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func chain(t *testing.T, n int) []Record {
+	t.Helper()
+	at := time.Date(2026, 3, 31, 12, 0, 0, 123456789, time.UTC)
+	var records []Record
+	var prev *Record
+	for i := 0; i < n; i++ {
+		entry := Entry{
+			ID:         "transactionService:req-" + string(rune('a'+i)),
+			OccurredAt: at,
+			Service:    "transactionService",
+			Kind:       "api",
+			Action:     "PUT /admin/tiers/{name}",
+			Resource:   "/admin/tiers/gold",
+			Actor:      "ops@bank",
+			Outcome:    "200",
+			Before:     json.RawMessage(`{"name":"gold","daily_limit":1000}`),
+			After:      json.RawMessage(`{"name":"gold","daily_limit":5000}`),
+		}
+		record := Chain(entry, prev, at.Add(time.Duration(i)*time.Second))
+		records = append(records, record)
+		prev = &records[len(records)-1]
+	}
+	return records
+}
+
+func verify(records []Record) []error {
+	v := NewVerifier()
+	var errs []error
+	for _, record := range records {
+		if err := v.Check(record); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func TestChainVerifies(t *testing.T) {
+	records := chain(t, 3)
+	assert.Equal(t, GenesisHash, records[0].PrevHash)
+	assert.Equal(t, records[1].Hash, records[2].PrevHash)
+	assert.Equal(t, int64(3), records[2].Seq)
+	assert.Empty(t, verify(records))
+
+	// a record read back from MongoDB loses its time zone
+	records[1].OccurredAt = records[1].OccurredAt.In(time.FixedZone("CET", 3600))
+	assert.Empty(t, verify(records))
+}
+
+func TestTamperingIsDetected(t *testing.T) {
+	records := chain(t, 3)
+	records[1].After = `{"name":"gold","daily_limit":1000}`
+	errs := verify(records)
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], ErrTampered)
+	assert.Contains(t, errs[0].Error(), "record 2 was changed")
+
+	// rehashing the changed record breaks the link from the next one
+	records[1].Hash = Hash(records[1])
+	errs = verify(records)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "record 3 does not link")
+
+	// so does removing a record
+	records = chain(t, 3)
+	errs = verify([]Record{records[0], records[2]})
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "expected record 2, found 3")
+}
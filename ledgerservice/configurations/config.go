@@ -1,6 +1,12 @@
 package configurations
 
-import "github.com/nicholasjackson/env"
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nicholasjackson/env"
+)
 
 type MongoDbConfig struct {
 	MongoURI string
@@ -21,3 +27,73 @@ func NewMongoDbConfig() (*MongoDbConfig, error) {
 		DBName:   *dbname,
 	}, nil
 }
+
+// ProjectionConfig sets up the account summary projection
+type ProjectionConfig struct {
+	RecentTransactions int // Transactions kept in each account summary
+}
+
+func NewProjectionConfig() (*ProjectionConfig, error) {
+	var recent *int = env.Int("SUMMARY_RECENT_TRANSACTIONS", false, 20, "Transactions kept in each account summary")
+	if err := env.Parse(); err != nil {
+		return nil, err
+	}
+	if *recent <= 0 {
+		return nil, fmt.Errorf("SUMMARY_RECENT_TRANSACTIONS must be positive, got %d", *recent)
+	}
+
+	return &ProjectionConfig{
+		RecentTransactions: *recent,
+	}, nil
+}
+
+// KafkaConfig locates the Kafka cluster every topic is consumed from and published to
+type KafkaConfig struct {
+	Brokers      []string // Brokers of the cluster
+	TopicMapFile string   // JSON topic map naming the topics on the cluster; the built-in names when empty
+}
+
+func NewKafkaConfig() (*KafkaConfig, error) {
+	var brokers *string = env.String("KAFKA_BROKER", false, "kafka:9092", "Comma-separated Kafka brokers every topic is consumed from and published to")
+	var topicMap *string = env.String("TOPIC_MAP_FILE", false, "", "JSON topic map naming the topics on the cluster, empty for the built-in names")
+	if err := env.Parse(); err != nil {
+		return nil, err
+	}
+
+	return &KafkaConfig{
+		Brokers:      strings.Split(*brokers, ","),
+		TopicMapFile: *topicMap,
+	}, nil
+}
+
+// IntegrityConfig sets up the ledger's checkpoints and proof API
+type IntegrityConfig struct {
+	SigningKey         string        // Base64 Ed25519 seed or private key checkpoints are signed with
+	DevMode            bool          // Without a signing key, sign with a new key each start instead of failing
+	CheckpointInterval time.Duration // How often the ledger is checkpointed when it has grown
+	BindAddress        string        // Address the proof API listens on
+}
+
+func NewIntegrityConfig() (*IntegrityConfig, error) {
+	var key *string = env.String("LEDGER_SIGNING_KEY", false, "", "Base64 Ed25519 seed checkpoints are signed with")
+	var devMode *bool = env.Bool("LEDGER_DEV_MODE", false, false, "Sign checkpoints with a new key every start when LEDGER_SIGNING_KEY is not set")
+	var interval *time.Duration = env.Duration("LEDGER_CHECKPOINT_INTERVAL", false, time.Minute, "How often the ledger is checkpointed")
+	var bind *string = env.String("LEDGER_BIND_ADDRESS", false, ":9095", "Bind address for the proof API")
+	if err := env.Parse(); err != nil {
+		return nil, err
+	}
+	if *interval <= 0 {
+		return nil, fmt.Errorf("LEDGER_CHECKPOINT_INTERVAL must be positive, got %s", *interval)
+	}
+	// checkpoints signed with a throwaway key prove nothing once the service restarts
+	if *key == "" && !*devMode {
+		return nil, fmt.Errorf("LEDGER_SIGNING_KEY is required outside LEDGER_DEV_MODE")
+	}
+
+	return &IntegrityConfig{
+		SigningKey:         *key,
+		DevMode:            *devMode,
+		CheckpointInterval: *interval,
+		BindAddress:        *bind,
+	}, nil
+}
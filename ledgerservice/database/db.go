@@ -2,11 +2,35 @@ package database
 
 import (
 	"context"
+	"errors"
+	"ledgerservice/audit"
 	"ledgerservice/models"
 )
 
+// ErrChainPositionTaken is returned when another entry already holds a ledger entry's chain position
+var ErrChainPositionTaken = errors.New("ledger chain position already taken")
+
 type Database interface {
 	Connect(ctx context.Context) error
 	Disconnect(ctx context.Context) error
 	InsertTransaction(ctx context.Context, ledger models.TransactionLedger) (string, error)
+	LinkReversal(ctx context.Context, originalTransactionID, reversalTransactionID string, amount float64) error
+	InsertRejected(ctx context.Context, ledger models.TransactionLedger) (string, error)
+	UpdateClearingStatus(ctx context.Context, transactionID, status, reason string) error
+	ApplySummaryChange(ctx context.Context, change models.SummaryChange, source models.SourceOffset, recent int) (bool, error)
+	SaveSummaryCheckpoint(ctx context.Context, source models.SourceOffset) error
+	LastAuditRecord(ctx context.Context) (*audit.Record, error)
+	AuditRecordExists(ctx context.Context, id string) (bool, error)
+	InsertAuditRecord(ctx context.Context, record audit.Record) (bool, error)
+	WalkAuditLog(ctx context.Context, fn func(audit.Record) error) error
+	LastChainedTransaction(ctx context.Context) (*models.TransactionLedger, error)
+	ListUnchainedTransactions(ctx context.Context, limit int) ([]models.TransactionLedger, error)
+	ChainTransaction(ctx context.Context, ledger models.TransactionLedger) error
+	FindChainedTransaction(ctx context.Context, transactionID string) (*models.TransactionLedger, error)
+	LedgerHashes(ctx context.Context, size int64) ([]string, error)
+	WalkLedger(ctx context.Context, fn func(models.TransactionLedger) error) error
+	InsertCheckpoint(ctx context.Context, cp models.Checkpoint) (bool, error)
+	LatestCheckpoint(ctx context.Context) (*models.Checkpoint, error)
+	GetCheckpoint(ctx context.Context, size int64) (*models.Checkpoint, error)
+	ListCheckpoints(ctx context.Context) ([]models.Checkpoint, error)
 }
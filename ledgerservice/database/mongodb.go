@@ -2,6 +2,9 @@ package database
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"ledgerservice/audit"
 	configs "ledgerservice/configurations"
 	"ledgerservice/models"
 	"time"
@@ -59,7 +62,8 @@ func (mango *MongoDB) Disconnect(ctx context.Context) error {
 
 }
 
-// InsertTransaction inserts a TransactionLedger document into the transactions collection
+// InsertTransaction inserts a TransactionLedger document into the transactions collection. It
+// returns ErrChainPositionTaken when another entry holds the document's chain position.
 func (mango *MongoDB) InsertTransaction(ctx context.Context, ledger models.TransactionLedger) (string, error) {
 	// Set a timeout for the operation
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -70,6 +74,9 @@ func (mango *MongoDB) InsertTransaction(ctx context.Context, ledger models.Trans
 
 	// Insert the document
 	result, err := collection.InsertOne(ctx, ledger)
+	if mongo.IsDuplicateKeyError(err) {
+		return "", ErrChainPositionTaken
+	}
 	if err != nil {
 		(*mango.loggs).Error("Failed to insert transaction into MongoDB", "Error", err)
 		return "", err
@@ -81,3 +88,448 @@ func (mango *MongoDB) InsertTransaction(ctx context.Context, ledger models.Trans
 
 	return insertedID, nil
 }
+
+// LinkReversal records a reversal against the original entry in the transactions collection
+func (mango *MongoDB) LinkReversal(ctx context.Context, originalTransactionID, reversalTransactionID string, amount float64) error {
+	// Set a timeout for the operation
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	collection := mango.Database.Collection("transactions")
+
+	filter := bson.M{"transaction_id": originalTransactionID}
+	update := bson.M{
+		"$addToSet": bson.M{"reversal_ids": reversalTransactionID},
+		"$inc":      bson.M{"reversed_amount": amount},
+	}
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		(*mango.loggs).Error("Failed to link reversal", "Original", originalTransactionID, "Error", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("original transaction not found in ledger: %s", originalTransactionID)
+	}
+
+	(*mango.loggs).Info("Linked reversal", "Original", originalTransactionID, "Reversal", reversalTransactionID)
+	return nil
+}
+
+// InsertRejected stores a transaction the transaction service refused in the rejected_transactions collection
+func (mango *MongoDB) InsertRejected(ctx context.Context, ledger models.TransactionLedger) (string, error) {
+	// Set a timeout for the operation
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := mango.Database.Collection("rejected_transactions").InsertOne(ctx, ledger)
+	if err != nil {
+		(*mango.loggs).Error("Failed to insert rejected transaction into MongoDB", "Error", err)
+		return "", err
+	}
+
+	insertedID := result.InsertedID.(bson.ObjectID).Hex()
+	(*mango.loggs).Info("Successfully inserted rejected transaction", "ID", insertedID)
+	return insertedID, nil
+}
+
+// UpdateClearingStatus records the clearing system's latest report on a transaction in the transactions collection
+func (mango *MongoDB) UpdateClearingStatus(ctx context.Context, transactionID, status, reason string) error {
+	// Set a timeout for the operation
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"transaction_id": transactionID}
+	update := bson.M{"$set": bson.M{"clearing_status": status, "clearing_reason": reason}}
+	result, err := mango.Database.Collection("transactions").UpdateOne(ctx, filter, update)
+	if err != nil {
+		(*mango.loggs).Error("Failed to update clearing status", "Transaction", transactionID, "Error", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("transaction not found in ledger: %s", transactionID)
+	}
+
+	(*mango.loggs).Info("Updated clearing status", "Transaction", transactionID, "Status", status)
+	return nil
+}
+
+// ApplySummaryChange applies a change to an account's document in the "account_summaries"
+// collection, creating it on first use. Each document keeps the offset of the last message it
+// applied from every partition, so a message delivered again is skipped; it returns false then.
+// The balance is only moved forward, since postings from different partitions can arrive out of order.
+func (mango *MongoDB) ApplySummaryChange(ctx context.Context, change models.SummaryChange, source models.SourceOffset, recent int) (bool, error) {
+	// Set a timeout for the operation
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	collection := mango.Database.Collection("account_summaries")
+	offsetField := "offsets." + source.Key()
+	filter := bson.M{
+		"_id": change.AccountNumber,
+		"$or": bson.A{
+			bson.M{offsetField: bson.M{"$exists": false}},
+			bson.M{offsetField: bson.M{"$lt": source.Offset}},
+		},
+	}
+	set := bson.M{"account_number": change.AccountNumber, offsetField: source.Offset, "updated_at": time.Now()}
+	update := bson.M{"$set": set}
+	if change.Transaction != nil {
+		update["$push"] = bson.M{"recent_transactions": bson.M{
+			"$each":  bson.A{change.Transaction},
+			"$sort":  bson.M{"created_at": -1},
+			"$slice": recent,
+		}}
+	}
+	if change.In != 0 || change.Out != 0 {
+		update["$inc"] = bson.M{
+			"monthly." + change.Month + ".in":    change.In,
+			"monthly." + change.Month + ".out":   change.Out,
+			"monthly." + change.Month + ".count": 1,
+		}
+	}
+	if change.Hold != nil {
+		holdField := "pending_holds." + change.Hold.HoldID
+		if change.HoldReleased {
+			update["$unset"] = bson.M{holdField: ""}
+		} else {
+			set[holdField] = change.Hold
+		}
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update, options.UpdateOne().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		// the document exists but has already seen this offset
+		return false, nil
+	}
+	if err != nil {
+		(*mango.loggs).Error("Failed to update account summary", "Account", change.AccountNumber, "Error", err)
+		return false, err
+	}
+
+	if change.Balance != nil {
+		filter := bson.M{
+			"_id": change.AccountNumber,
+			"$or": bson.A{
+				bson.M{"balance_version": bson.M{"$exists": false}},
+				bson.M{"balance_version": bson.M{"$lt": change.Balance.Version}},
+			},
+		}
+		update := bson.M{"$set": bson.M{"balance": change.Balance.Balance, "balance_version": change.Balance.Version}}
+		if _, err := collection.UpdateOne(ctx, filter, update); err != nil {
+			(*mango.loggs).Error("Failed to update summary balance", "Account", change.AccountNumber, "Error", err)
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// SaveSummaryCheckpoint records in the "projection_checkpoints" collection how far the account
+// summary projection has read a partition
+func (mango *MongoDB) SaveSummaryCheckpoint(ctx context.Context, source models.SourceOffset) error {
+	// Set a timeout for the operation
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": "account_summaries"}
+	update := bson.M{
+		"$max": bson.M{"offsets." + source.Key(): source.Offset},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+	_, err := mango.Database.Collection("projection_checkpoints").UpdateOne(ctx, filter, update, options.UpdateOne().SetUpsert(true))
+	if err != nil {
+		(*mango.loggs).Error("Failed to save projection checkpoint", "Partition", source.Key(), "Error", err)
+		return err
+	}
+	return nil
+}
+
+// LastAuditRecord returns the record at the head of the "audit_log" collection, or nil when the
+// log is empty
+func (mango *MongoDB) LastAuditRecord(ctx context.Context) (*audit.Record, error) {
+	// Set a timeout for the operation
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var record audit.Record
+	opts := options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}})
+	err := mango.Database.Collection("audit_log").FindOne(ctx, bson.M{}, opts).Decode(&record)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		(*mango.loggs).Error("Failed to read the head of the audit log", "Error", err)
+		return nil, err
+	}
+	return &record, nil
+}
+
+// AuditRecordExists reports whether the entry with the given ID has been chained
+func (mango *MongoDB) AuditRecordExists(ctx context.Context, id string) (bool, error) {
+	// Set a timeout for the operation
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	count, err := mango.Database.Collection("audit_log").CountDocuments(ctx, bson.M{"id": id}, options.Count().SetLimit(1))
+	if err != nil {
+		(*mango.loggs).Error("Failed to look up audit entry", "ID", id, "Error", err)
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// InsertAuditRecord appends a record to the "audit_log" collection. It returns false when a
+// record with the same sequence number or entry ID is already there.
+func (mango *MongoDB) InsertAuditRecord(ctx context.Context, record audit.Record) (bool, error) {
+	// Set a timeout for the operation
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := mango.Database.Collection("audit_log").InsertOne(ctx, record)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		(*mango.loggs).Error("Failed to append to the audit log", "Seq", record.Seq, "Error", err)
+		return false, err
+	}
+	return true, nil
+}
+
+// WalkAuditLog calls fn with every record of the "audit_log" collection in sequence order,
+// stopping at the first error fn returns
+func (mango *MongoDB) WalkAuditLog(ctx context.Context, fn func(audit.Record) error) error {
+	cursor, err := mango.Database.Collection("audit_log").Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return fmt.Errorf("failed to read the audit log: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var record audit.Record
+		if err := cursor.Decode(&record); err != nil {
+			return fmt.Errorf("failed to decode audit record: %w", err)
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// chained matches the ledger entries that are part of the hash chain
+var chained = bson.M{"ledger_seq": bson.M{"$exists": true}}
+
+// LastChainedTransaction returns the ledger entry at the head of the hash chain, or nil when no
+// entry was chained yet
+func (mango *MongoDB) LastChainedTransaction(ctx context.Context) (*models.TransactionLedger, error) {
+	// Set a timeout for the operation
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var ledger models.TransactionLedger
+	opts := options.FindOne().SetSort(bson.D{{Key: "ledger_seq", Value: -1}})
+	err := mango.Database.Collection("transactions").FindOne(ctx, chained, opts).Decode(&ledger)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		(*mango.loggs).Error("Failed to read the head of the ledger chain", "Error", err)
+		return nil, err
+	}
+	return &ledger, nil
+}
+
+// ListUnchainedTransactions returns up to limit ledger entries inserted before the hash chain
+// was introduced, oldest first
+func (mango *MongoDB) ListUnchainedTransactions(ctx context.Context, limit int) ([]models.TransactionLedger, error) {
+	// Set a timeout for the operation
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	filter := bson.M{"ledger_seq": bson.M{"$exists": false}}
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := mango.Database.Collection("transactions").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unchained ledger entries: %w", err)
+	}
+	var entries []models.TransactionLedger
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode unchained ledger entries: %w", err)
+	}
+	return entries, nil
+}
+
+// ChainTransaction sets the chain position and hashes of a ledger entry that has none. It
+// returns ErrChainPositionTaken when another entry holds the position or the entry was chained since.
+func (mango *MongoDB) ChainTransaction(ctx context.Context, ledger models.TransactionLedger) error {
+	// Set a timeout for the operation
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": ledger.ID, "ledger_seq": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{
+		"ledger_seq": ledger.LedgerSeq,
+		"prev_hash":  ledger.PrevHash,
+		"hash":       ledger.Hash,
+	}}
+	result, err := mango.Database.Collection("transactions").UpdateOne(ctx, filter, update)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrChainPositionTaken
+	}
+	if err != nil {
+		(*mango.loggs).Error("Failed to chain ledger entry", "Transaction", ledger.TransactionID, "Error", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrChainPositionTaken
+	}
+	return nil
+}
+
+// FindChainedTransaction returns the first chained ledger entry of a transaction, or nil when
+// there is none
+func (mango *MongoDB) FindChainedTransaction(ctx context.Context, transactionID string) (*models.TransactionLedger, error) {
+	// Set a timeout for the operation
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var ledger models.TransactionLedger
+	filter := bson.M{"transaction_id": transactionID, "ledger_seq": bson.M{"$exists": true}}
+	opts := options.FindOne().SetSort(bson.D{{Key: "ledger_seq", Value: 1}})
+	err := mango.Database.Collection("transactions").FindOne(ctx, filter, opts).Decode(&ledger)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		(*mango.loggs).Error("Failed to find ledger entry", "Transaction", transactionID, "Error", err)
+		return nil, err
+	}
+	return &ledger, nil
+}
+
+// LedgerHashes returns the hashes of the first size entries of the hash chain, in chain order
+func (mango *MongoDB) LedgerHashes(ctx context.Context, size int64) ([]string, error) {
+	// Set a timeout for the operation
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	filter := bson.M{"ledger_seq": bson.M{"$gte": 1, "$lte": size}}
+	opts := options.Find().SetSort(bson.D{{Key: "ledger_seq", Value: 1}}).SetProjection(bson.M{"_id": 0, "hash": 1})
+	cursor, err := mango.Database.Collection("transactions").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ledger hashes: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	hashes := make([]string, 0, size)
+	for cursor.Next(ctx) {
+		var entry struct {
+			Hash string `bson:"hash"`
+		}
+		if err := cursor.Decode(&entry); err != nil {
+			return nil, fmt.Errorf("failed to decode ledger hash: %w", err)
+		}
+		hashes = append(hashes, entry.Hash)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ledger hashes: %w", err)
+	}
+	if int64(len(hashes)) != size {
+		return nil, fmt.Errorf("ledger chain has %d of its first %d entries", len(hashes), size)
+	}
+	return hashes, nil
+}
+
+// WalkLedger calls fn with every chained ledger entry in chain order, stopping at the first
+// error fn returns
+func (mango *MongoDB) WalkLedger(ctx context.Context, fn func(models.TransactionLedger) error) error {
+	opts := options.Find().SetSort(bson.D{{Key: "ledger_seq", Value: 1}})
+	cursor, err := mango.Database.Collection("transactions").Find(ctx, chained, opts)
+	if err != nil {
+		return fmt.Errorf("failed to read the ledger: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var ledger models.TransactionLedger
+		if err := cursor.Decode(&ledger); err != nil {
+			return fmt.Errorf("failed to decode ledger entry: %w", err)
+		}
+		if err := fn(ledger); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// InsertCheckpoint stores a checkpoint in the "ledger_checkpoints" collection. It returns false
+// when a checkpoint of the same size is already there.
+func (mango *MongoDB) InsertCheckpoint(ctx context.Context, cp models.Checkpoint) (bool, error) {
+	// Set a timeout for the operation
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := mango.Database.Collection("ledger_checkpoints").InsertOne(ctx, cp)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		(*mango.loggs).Error("Failed to store ledger checkpoint", "Size", cp.Size, "Error", err)
+		return false, err
+	}
+	return true, nil
+}
+
+// LatestCheckpoint returns the largest checkpoint, or nil when there is none
+func (mango *MongoDB) LatestCheckpoint(ctx context.Context) (*models.Checkpoint, error) {
+	// Set a timeout for the operation
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var cp models.Checkpoint
+	opts := options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}})
+	err := mango.Database.Collection("ledger_checkpoints").FindOne(ctx, bson.M{}, opts).Decode(&cp)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		(*mango.loggs).Error("Failed to read the latest ledger checkpoint", "Error", err)
+		return nil, err
+	}
+	return &cp, nil
+}
+
+// GetCheckpoint returns the checkpoint over the first size entries, or nil when there is none
+func (mango *MongoDB) GetCheckpoint(ctx context.Context, size int64) (*models.Checkpoint, error) {
+	// Set a timeout for the operation
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var cp models.Checkpoint
+	err := mango.Database.Collection("ledger_checkpoints").FindOne(ctx, bson.M{"_id": size}).Decode(&cp)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		(*mango.loggs).Error("Failed to read ledger checkpoint", "Size", size, "Error", err)
+		return nil, err
+	}
+	return &cp, nil
+}
+
+// ListCheckpoints returns every checkpoint, smallest first
+func (mango *MongoDB) ListCheckpoints(ctx context.Context) ([]models.Checkpoint, error) {
+	// Set a timeout for the operation
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	cursor, err := mango.Database.Collection("ledger_checkpoints").Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ledger checkpoints: %w", err)
+	}
+	var checkpoints []models.Checkpoint
+	if err := cursor.All(ctx, &checkpoints); err != nil {
+		return nil, fmt.Errorf("failed to decode ledger checkpoints: %w", err)
+	}
+	return checkpoints, nil
+}
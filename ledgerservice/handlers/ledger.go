@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"ledgerservice/repositories"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/hashicorp/go-hclog"
+)
+
+// LedgerHandler serves the ledger's checkpoints and the proofs that transactions are part of it
+type LedgerHandler struct {
+	repo  repositories.IntegrityRepository
+	loggs *hclog.Logger
+}
+
+// NewLedgerHandler creates a new LedgerHandler instance
+func NewLedgerHandler(repo repositories.IntegrityRepository, lobbs *hclog.Logger) *LedgerHandler {
+	return &LedgerHandler{
+		repo:  repo,
+		loggs: lobbs,
+	}
+}
+
+// RegisterRoutes registers the ledger routes
+func (h *LedgerHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/ledger/transactions/{transactionId}/proof", h.GetProof).Methods("GET")
+	router.HandleFunc("/ledger/checkpoints/latest", h.GetLatestCheckpoint).Methods("GET")
+	router.HandleFunc("/ledger/checkpoints/{size:[0-9]+}", h.GetCheckpoint).Methods("GET")
+	router.HandleFunc("/ledger/public-key", h.GetPublicKey).Methods("GET")
+}
+
+// GetProof returns the proof that a transaction is part of the ledger, against the checkpoint
+// given by ?size= or the latest one
+func (h *LedgerHandler) GetProof(w http.ResponseWriter, r *http.Request) {
+	var size int64
+	if s := r.URL.Query().Get("size"); s != "" {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || n <= 0 {
+			http.Error(w, "size must be a positive number", http.StatusBadRequest)
+			return
+		}
+		size = n
+	}
+
+	transactionID := mux.Vars(r)["transactionId"]
+	proof, err := h.repo.Prove(r.Context(), transactionID, size)
+	switch {
+	case errors.Is(err, repositories.ErrNoCheckpoint):
+		http.Error(w, "Checkpoint not found", http.StatusNotFound)
+		return
+	case errors.Is(err, repositories.ErrNotCheckpointed):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		(*h.loggs).Error("Failed to prove transaction", "Transaction", transactionID, "Error", err)
+		http.Error(w, "Failed to prove transaction", http.StatusInternalServerError)
+		return
+	case proof == nil:
+		http.Error(w, "Transaction not found in the ledger", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, proof)
+}
+
+// GetLatestCheckpoint returns the largest checkpoint
+func (h *LedgerHandler) GetLatestCheckpoint(w http.ResponseWriter, r *http.Request) {
+	cp, err := h.repo.LatestCheckpoint(r.Context())
+	if err != nil {
+		http.Error(w, "Failed to get checkpoint", http.StatusInternalServerError)
+		return
+	}
+	if cp == nil {
+		http.Error(w, "No checkpoint yet", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, cp)
+}
+
+// GetCheckpoint returns the checkpoint over the first {size} entries
+func (h *LedgerHandler) GetCheckpoint(w http.ResponseWriter, r *http.Request) {
+	size, err := strconv.ParseInt(mux.Vars(r)["size"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid checkpoint size", http.StatusBadRequest)
+		return
+	}
+	cp, err := h.repo.GetCheckpoint(r.Context(), size)
+	if err != nil {
+		http.Error(w, "Failed to get checkpoint", http.StatusInternalServerError)
+		return
+	}
+	if cp == nil {
+		http.Error(w, "Checkpoint not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, cp)
+}
+
+// GetPublicKey returns the key the checkpoints are signed with
+func (h *LedgerHandler) GetPublicKey(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"algorithm": "ed25519", "public_key": h.repo.PublicKey()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		fmt.Println(err)
+	}
+}
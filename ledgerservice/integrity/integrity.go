@@ -0,0 +1,236 @@
+// Package integrity makes the ledger tamper-evident. Every entry inserted in the "transactions"
+// collection is hashed together with the hash of the entry before it, the hashes are the leaves
+// of a Merkle tree, and signed checkpoints of the tree's root are published so that an entry can
+// be proven part of the ledger, and any later change to it detected, without trusting the database.
+package integrity
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"ledgerservice/merkle"
+	"ledgerservice/models"
+	"strings"
+	"time"
+)
+
+// GenesisHash is the previous hash of the first entry
+var GenesisHash = strings.Repeat("0", 64)
+
+// ErrTampered is returned when the ledger no longer matches its hashes or checkpoints
+var ErrTampered = errors.New("ledger tampered")
+
+// EntryHash hashes an entry as it was inserted, chain position and previous hash included. The
+// reversal and clearing fields are left out, as they are set on the entry after it was inserted.
+// The hash covers the entry's JSON encoding, so fields added to TransactionLedger must be omitempty
+// for the hashes of older entries to stay the same.
+func EntryHash(ledger models.TransactionLedger) string {
+	ledger.ReversalIDs = nil
+	ledger.ReversedAmount = 0
+	ledger.ClearingStatus = ""
+	ledger.ClearingReason = ""
+	ledger.Hash = ""
+	// MongoDB keeps times to the millisecond
+	ledger.CreatedAt = ledger.CreatedAt.UTC().Truncate(time.Millisecond)
+	data, _ := json.Marshal(ledger) // a TransactionLedger always encodes
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Chain sets the chain position and hashes of an entry following prev; prev is nil for the first entry
+func Chain(ledger *models.TransactionLedger, prev *models.TransactionLedger) {
+	ledger.LedgerSeq = 1
+	ledger.PrevHash = GenesisHash
+	if prev != nil {
+		ledger.LedgerSeq = prev.LedgerSeq + 1
+		ledger.PrevHash = prev.Hash
+	}
+	ledger.CreatedAt = ledger.CreatedAt.UTC().Truncate(time.Millisecond)
+	ledger.Hash = EntryHash(*ledger)
+}
+
+// Leaf returns the Merkle leaf of an entry hash
+func Leaf(hash string) ([]byte, error) {
+	data, err := hex.DecodeString(hash)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid entry hash %q", ErrTampered, hash)
+	}
+	return merkle.LeafHash(data), nil
+}
+
+// Leaves returns the Merkle leaves of entry hashes
+func Leaves(hashes []string) ([][]byte, error) {
+	leaves := make([][]byte, len(hashes))
+	for i, hash := range hashes {
+		leaf, err := Leaf(hash)
+		if err != nil {
+			return nil, err
+		}
+		leaves[i] = leaf
+	}
+	return leaves, nil
+}
+
+// Signer signs checkpoints
+type Signer struct {
+	key ed25519.PrivateKey
+}
+
+// NewSigner creates a Signer from a base64 Ed25519 seed (32 bytes) or private key (64 bytes)
+func NewSigner(key string) (*Signer, error) {
+	data, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("signing key is not base64: %w", err)
+	}
+	switch len(data) {
+	case ed25519.SeedSize:
+		return &Signer{key: ed25519.NewKeyFromSeed(data)}, nil
+	case ed25519.PrivateKeySize:
+		return &Signer{key: ed25519.PrivateKey(data)}, nil
+	}
+	return nil, fmt.Errorf("signing key must be a %d byte Ed25519 seed or a %d byte private key, got %d bytes",
+		ed25519.SeedSize, ed25519.PrivateKeySize, len(data))
+}
+
+// GenerateSigner creates a Signer with a new key
+func GenerateSigner() (*Signer, error) {
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Signer{key: key}, nil
+}
+
+// PublicKey returns the key checkpoints are verified with, base64
+func (s *Signer) PublicKey() string {
+	return base64.StdEncoding.EncodeToString(s.key.Public().(ed25519.PublicKey))
+}
+
+// Checkpoint signs a checkpoint over the given entry hashes, in chain order
+func (s *Signer) Checkpoint(hashes []string, at time.Time) (models.Checkpoint, error) {
+	leaves, err := Leaves(hashes)
+	if err != nil {
+		return models.Checkpoint{}, err
+	}
+	cp := models.Checkpoint{
+		Size:      int64(len(hashes)),
+		Root:      hex.EncodeToString(merkle.Root(leaves)),
+		Head:      GenesisHash,
+		CreatedAt: at.UTC().Truncate(time.Millisecond),
+		PublicKey: s.PublicKey(),
+	}
+	if len(hashes) > 0 {
+		cp.Head = hashes[len(hashes)-1]
+	}
+	cp.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(s.key, message(cp)))
+	return cp, nil
+}
+
+// message is what a checkpoint's signature covers
+func message(cp models.Checkpoint) []byte {
+	return []byte(fmt.Sprintf("ledgerservice checkpoint v1\n%d\n%s\n%s\n%s\n",
+		cp.Size, cp.Root, cp.Head, cp.CreatedAt.UTC().Format(time.RFC3339Nano)))
+}
+
+// VerifyCheckpoint checks a checkpoint's signature. When publicKey is not empty the checkpoint
+// must have been signed with it; otherwise the key the checkpoint names is taken on trust.
+func VerifyCheckpoint(cp models.Checkpoint, publicKey string) error {
+	if publicKey != "" && cp.PublicKey != publicKey {
+		return fmt.Errorf("%w: checkpoint %d was signed with another key", ErrTampered, cp.Size)
+	}
+	key, err := base64.StdEncoding.DecodeString(cp.PublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w: checkpoint %d names an invalid key", ErrTampered, cp.Size)
+	}
+	signature, err := base64.StdEncoding.DecodeString(cp.Signature)
+	if err != nil || !ed25519.Verify(ed25519.PublicKey(key), message(cp), signature) {
+		return fmt.Errorf("%w: checkpoint %d has an invalid signature", ErrTampered, cp.Size)
+	}
+	return nil
+}
+
+// VerifyProof checks that the entry of an inclusion proof hashes as recorded and is a leaf of
+// the tree its checkpoint signed, see VerifyCheckpoint for publicKey
+func VerifyProof(proof models.InclusionProof, publicKey string) error {
+	if err := VerifyCheckpoint(proof.Checkpoint, publicKey); err != nil {
+		return err
+	}
+	entry := proof.Transaction
+	if EntryHash(entry) != entry.Hash {
+		return fmt.Errorf("%w: entry %d does not match its hash", ErrTampered, entry.LedgerSeq)
+	}
+	if proof.TreeSize != proof.Checkpoint.Size || proof.LeafIndex != entry.LedgerSeq-1 {
+		return fmt.Errorf("%w: proof does not place entry %d in checkpoint %d", ErrTampered, entry.LedgerSeq, proof.Checkpoint.Size)
+	}
+
+	leaf, err := Leaf(entry.Hash)
+	if err != nil {
+		return err
+	}
+	root, err := hex.DecodeString(proof.Checkpoint.Root)
+	if err != nil {
+		return fmt.Errorf("%w: checkpoint %d has an invalid root", ErrTampered, proof.Checkpoint.Size)
+	}
+	path := make([][]byte, len(proof.Proof))
+	for i, sibling := range proof.Proof {
+		if path[i], err = hex.DecodeString(sibling); err != nil {
+			return fmt.Errorf("%w: invalid proof hash %q", ErrTampered, sibling)
+		}
+	}
+	if !merkle.Verify(leaf, proof.LeafIndex, proof.TreeSize, path, root) {
+		return fmt.Errorf("%w: entry %d is not in checkpoint %d", ErrTampered, entry.LedgerSeq, proof.Checkpoint.Size)
+	}
+	return nil
+}
+
+// Verifier checks entries read in chain order
+type Verifier struct {
+	Hashes []string // Hashes of the entries checked, in chain order
+	head   string
+}
+
+// NewVerifier creates a Verifier expecting the first entry of the chain
+func NewVerifier() *Verifier {
+	return &Verifier{head: GenesisHash}
+}
+
+// Check checks that entry is the next one of the chain and was not changed since it was inserted.
+// After a failed check the verifier carries on from entry, so that every damaged entry is reported.
+func (v *Verifier) Check(entry models.TransactionLedger) error {
+	next := int64(len(v.Hashes)) + 1
+	var err error
+	switch {
+	case entry.LedgerSeq != next:
+		err = fmt.Errorf("%w: expected entry %d, found %d", ErrTampered, next, entry.LedgerSeq)
+	case entry.PrevHash != v.head:
+		err = fmt.Errorf("%w: entry %d does not link to entry %d", ErrTampered, entry.LedgerSeq, entry.LedgerSeq-1)
+	case EntryHash(entry) != entry.Hash:
+		err = fmt.Errorf("%w: entry %d (transaction %s) was changed", ErrTampered, entry.LedgerSeq, entry.TransactionID)
+	}
+	v.Hashes = append(v.Hashes, entry.Hash)
+	v.head = entry.Hash
+	return err
+}
+
+// CheckCheckpoint checks a checkpoint against the entries checked so far: its signature, and that
+// it commits to the same entries
+func (v *Verifier) CheckCheckpoint(cp models.Checkpoint, publicKey string) error {
+	if err := VerifyCheckpoint(cp, publicKey); err != nil {
+		return err
+	}
+	if cp.Size > int64(len(v.Hashes)) {
+		return fmt.Errorf("%w: checkpoint %d covers entries missing from the ledger, which has %d", ErrTampered, cp.Size, len(v.Hashes))
+	}
+	leaves, err := Leaves(v.Hashes[:cp.Size])
+	if err != nil {
+		return err
+	}
+	if hex.EncodeToString(merkle.Root(leaves)) != cp.Root {
+		return fmt.Errorf("%w: the first %d entries no longer match checkpoint %d", ErrTampered, cp.Size, cp.Size)
+	}
+	return nil
+}
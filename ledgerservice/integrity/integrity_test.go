@@ -0,0 +1,126 @@
+package integrity
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"ledgerservice/merkle"
+	"ledgerservice/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func ledger(t *testing.T, n int) []models.TransactionLedger {
+	t.Helper()
+	var entries []models.TransactionLedger
+	var prev *models.TransactionLedger
+	for i := 0; i < n; i++ {
+		entry := models.TransactionLedger{
+			ID:              bson.NewObjectID(),
+			TransactionID:   fmt.Sprintf("tx-%d", i),
+			FromAccountID:   "ACC123456789",
+			ToAccountID:     "ACC987654321",
+			Amount:          10.5 * float64(i+1),
+			TransactionType: "transfer",
+			CreatedAt:       time.Date(2026, 3, 31, 12, 0, i, 123456789, time.UTC),
+			Status:          "completed",
+			Postings:        []models.Posting{{AccountNumber: "ACC123456789", Amount: -10.5, Cause: "transfer", Balance: 89.5, Version: int64(i + 1)}},
+		}
+		Chain(&entry, prev)
+		entries = append(entries, entry)
+		prev = &entries[len(entries)-1]
+	}
+	return entries
+}
+
+func hashes(entries []models.TransactionLedger) []string {
+	var out []string
+	for _, entry := range entries {
+		out = append(out, entry.Hash)
+	}
+	return out
+}
+
+func proofOf(t *testing.T, entries []models.TransactionLedger, cp models.Checkpoint, i int) models.InclusionProof {
+	t.Helper()
+	leaves, err := Leaves(hashes(entries[:cp.Size]))
+	require.NoError(t, err)
+	proof := models.InclusionProof{Transaction: entries[i], LeafIndex: int64(i), TreeSize: cp.Size, Checkpoint: cp}
+	for _, sibling := range merkle.Proof(leaves, i) {
+		proof.Proof = append(proof.Proof, hex.EncodeToString(sibling))
+	}
+
+	// the proof is checked as a client receives it
+	data, err := json.Marshal(proof)
+	require.NoError(t, err)
+	var received models.InclusionProof
+	require.NoError(t, json.Unmarshal(data, &received))
+	return received
+}
+
+func TestInclusionProof(t *testing.T) {
+	signer, err := GenerateSigner()
+	require.NoError(t, err)
+	entries := ledger(t, 6)
+	cp, err := signer.Checkpoint(hashes(entries[:5]), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, entries[4].Hash, cp.Head)
+
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, VerifyProof(proofOf(t, entries, cp, i), signer.PublicKey()), "entry %d", i)
+	}
+
+	// changes made after an entry was inserted are not part of its hash
+	entries[2].ClearingStatus = "settled"
+	entries[2].ReversalIDs = []string{"tx-9"}
+	assert.NoError(t, VerifyProof(proofOf(t, entries, cp, 2), signer.PublicKey()))
+
+	// a changed amount is detected, and so is a changed amount rehashed
+	proof := proofOf(t, entries, cp, 2)
+	proof.Transaction.Amount = 1000
+	assert.ErrorIs(t, VerifyProof(proof, signer.PublicKey()), ErrTampered)
+	proof.Transaction.Hash = EntryHash(proof.Transaction)
+	assert.ErrorContains(t, VerifyProof(proof, signer.PublicKey()), "is not in checkpoint 5")
+
+	// as is a checkpoint signed with another key
+	other, err := GenerateSigner()
+	require.NoError(t, err)
+	forged, err := other.Checkpoint(hashes(entries[:5]), time.Now())
+	require.NoError(t, err)
+	assert.ErrorContains(t, VerifyProof(proofOf(t, entries, forged, 1), signer.PublicKey()), "signed with another key")
+}
+
+func TestVerifierDetectsRewrittenLedger(t *testing.T) {
+	signer, err := GenerateSigner()
+	require.NoError(t, err)
+	entries := ledger(t, 4)
+	cp, err := signer.Checkpoint(hashes(entries), time.Now())
+	require.NoError(t, err)
+
+	v := NewVerifier()
+	for _, entry := range entries {
+		require.NoError(t, v.Check(entry))
+	}
+	require.NoError(t, v.CheckCheckpoint(cp, signer.PublicKey()))
+
+	// rewriting an entry and rehashing the chain after it keeps the chain consistent, but not the checkpoint
+	entries[1].Amount = 0.01
+	for i := 1; i < len(entries); i++ {
+		Chain(&entries[i], &entries[i-1])
+	}
+	v = NewVerifier()
+	for _, entry := range entries {
+		require.NoError(t, v.Check(entry))
+	}
+	assert.ErrorIs(t, v.CheckCheckpoint(cp, signer.PublicKey()), ErrTampered)
+
+	// a removed entry breaks the chain
+	entries = ledger(t, 4)
+	v = NewVerifier()
+	require.NoError(t, v.Check(entries[0]))
+	assert.ErrorContains(t, v.Check(entries[2]), "expected entry 2, found 3")
+}
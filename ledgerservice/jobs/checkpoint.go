@@ -0,0 +1,54 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"ledgerservice/repositories"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// CheckpointTopic carries the signed ledger checkpoints, keyed by size
+const CheckpointTopic = "ledger-checkpoints"
+
+// Publisher publishes a message as JSON on a Kafka topic
+type Publisher interface {
+	PushToQueue(topic, key string, v any) error
+}
+
+// CheckpointJob signs a checkpoint of the ledger whenever it has grown and publishes it, so that
+// copies of the checkpoints are held outside the ledger's database
+type CheckpointJob struct {
+	repo      repositories.IntegrityRepository
+	publisher Publisher
+	published int64 // Size of the latest checkpoint published
+	loggs     *hclog.Logger
+}
+
+// NewCheckpointJob creates a new CheckpointJob
+func NewCheckpointJob(repo repositories.IntegrityRepository, publisher Publisher, lobbs *hclog.Logger) *CheckpointJob {
+	return &CheckpointJob{
+		repo:      repo,
+		publisher: publisher,
+		loggs:     lobbs,
+	}
+}
+
+// Run checkpoints the ledger and publishes the latest checkpoint unless it already did. A
+// checkpoint that failed to publish is published on the next run; after a restart the latest
+// checkpoint is published again, so consumers of the topic should expect repeated sizes.
+func (j *CheckpointJob) Run(ctx context.Context) error {
+	if _, err := j.repo.Checkpoint(ctx); err != nil {
+		return err
+	}
+	cp, err := j.repo.LatestCheckpoint(ctx)
+	if err != nil || cp == nil || cp.Size <= j.published {
+		return err
+	}
+	if err := j.publisher.PushToQueue(CheckpointTopic, fmt.Sprint(cp.Size), cp); err != nil {
+		return fmt.Errorf("failed to publish checkpoint %d: %w", cp.Size, err)
+	}
+	j.published = cp.Size
+	(*j.loggs).Info("Ledger checkpoint published", "Size", cp.Size)
+	return nil
+}
@@ -0,0 +1,60 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"ledgerservice/audit"
+	"ledgerservice/database"
+	"ledgerservice/repositories"
+	"log"
+	"shared/events"
+
+	"github.com/IBM/sarama"
+	"github.com/hashicorp/go-hclog"
+)
+
+// AuditConsumer chains the entries the services publish on "audit-log" to the audit log
+type AuditConsumer struct {
+	repo  repositories.AuditRepository
+	loggs *hclog.Logger
+}
+
+// NewAuditConsumer creates an AuditConsumer
+func NewAuditConsumer(db database.Database, lobbs *hclog.Logger) *AuditConsumer {
+	return &AuditConsumer{
+		repo:  repositories.NewAuditRepository(db, lobbs),
+		loggs: lobbs,
+	}
+}
+
+func (h AuditConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		var entry audit.Entry
+		_, err := events.Unmarshal(events.TopicID(msg.Topic), msg.Value, eventHeaders(msg.Headers), &entry)
+		if errors.Is(err, events.ErrUnsupportedVersion) {
+			// the offset is not committed, so the entry is chained once this service is upgraded
+			(*h.loggs).Error("Cannot chain audit entry yet", "Partition", msg.Partition, "Offset", msg.Offset, "Error", err)
+			return nil
+		}
+		if err != nil || entry.ID == "" {
+			(*h.loggs).Error("Discarding malformed audit entry", "Partition", msg.Partition, "Offset", msg.Offset, "Error", err)
+			session.MarkMessage(msg, "")
+			continue
+		}
+
+		appended, err := h.repo.Append(context.Background(), entry)
+		if err != nil {
+			// the offset is not committed, so the entry is chained once the group resumes
+			(*h.loggs).Error("Error appending to the audit log", "ID", entry.ID, "Offset", msg.Offset, "Error", err)
+			return nil
+		}
+		if !appended {
+			log.Printf("Audit entry %s is already in the log (offset %d)", entry.ID, msg.Offset)
+		}
+		session.MarkMessage(msg, "")
+	}
+	return nil
+}
+
+func (AuditConsumer) Setup(_ sarama.ConsumerGroupSession) error   { return nil }
+func (AuditConsumer) Cleanup(_ sarama.ConsumerGroupSession) error { return nil }
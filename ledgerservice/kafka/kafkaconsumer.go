@@ -2,12 +2,13 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"ledgerservice/database"
 	"ledgerservice/models"
 	"ledgerservice/repositories"
 	"log"
+	"shared/events"
 
 	"github.com/IBM/sarama"
 	"github.com/hashicorp/go-hclog"
@@ -27,8 +28,19 @@ func NewKafkaConsumer(db database.Database, lobbs *hclog.Logger) *KafkaConsumer
 
 func (h KafkaConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
 	for msg := range claim.Messages() {
+		topic := events.TopicID(msg.Topic)
+		if topic == "clearing-status" {
+			h.consumeClearingStatus(session, msg)
+			continue
+		}
+
 		var trans models.TransactionLedger
-		err := json.Unmarshal(msg.Value, &trans)
+		_, err := events.Unmarshal(topic, msg.Value, eventHeaders(msg.Headers), &trans)
+		if errors.Is(err, events.ErrUnsupportedVersion) {
+			// left for a newer version of this service
+			log.Printf("Cannot consume message (offset %d) yet: %v", msg.Offset, err)
+			return nil
+		}
 		if err != nil {
 			log.Printf("Failed to unmarshal message (offset %d): %v", msg.Offset, err)
 			continue
@@ -36,8 +48,13 @@ func (h KafkaConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim s
 
 		ctx := context.Background()
 		fmt.Println(trans)
-		// Add the transaction into transaction ledger
-		err = h.repo.InsertTransaction(ctx, trans)
+		// Transactions refused by the transaction service are kept apart from the ledger
+		if topic == "dead-ledger" {
+			err = h.repo.InsertRejected(ctx, trans)
+		} else {
+			// Add the transaction into transaction ledger
+			err = h.repo.InsertTransaction(ctx, trans)
+		}
 		if err != nil {
 			fmt.Println(err)
 			return nil
@@ -52,5 +69,20 @@ func (h KafkaConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim s
 	return nil
 }
 
+// consumeClearingStatus records a clearing system report on an external transfer against its ledger entry
+func (h KafkaConsumer) consumeClearingStatus(session sarama.ConsumerGroupSession, msg *sarama.ConsumerMessage) {
+	var status models.ClearingStatus
+	if _, err := events.Unmarshal(events.TopicID(msg.Topic), msg.Value, eventHeaders(msg.Headers), &status); err != nil {
+		log.Printf("Failed to unmarshal clearing status (offset %d): %v", msg.Offset, err)
+		return
+	}
+	if err := h.repo.UpdateClearingStatus(context.Background(), status); err != nil {
+		fmt.Println(err)
+		return
+	}
+	log.Printf("Clearing status of %s is %s (partition %d, offset %d)", status.TransactionID, status.Status, msg.Partition, msg.Offset)
+	session.MarkMessage(msg, "")
+}
+
 func (KafkaConsumer) Setup(_ sarama.ConsumerGroupSession) error   { return nil }
 func (KafkaConsumer) Cleanup(_ sarama.ConsumerGroupSession) error { return nil }
@@ -0,0 +1,110 @@
+package kafka
+
+import (
+	"encoding/json"
+	"fmt"
+	"shared/events"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// KafkaController publishes JSON messages to the cluster at Brokers. Topics are given by their ID
+// in the topic map, which names them on the cluster.
+type KafkaController struct {
+	Brokers []string
+}
+
+// PushToQueue publishes v as JSON on topic, keyed so that messages about one transaction stay in
+// order. It is encoded as an event starting a new trace.
+func (k *KafkaController) PushToQueue(topic, key string, v any) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	message, headers, err := events.Encode(topic, payload, events.Trace{})
+	if err != nil {
+		return fmt.Errorf("failed to encode message for %s: %w", topic, err)
+	}
+
+	name := events.TopicName(topic)
+	producer, err := k.connectProducer(name)
+	if err != nil {
+		return err
+	}
+	defer producer.Close()
+
+	msg := &sarama.ProducerMessage{
+		Topic:   name,
+		Key:     sarama.StringEncoder(key),
+		Value:   sarama.ByteEncoder(message),
+		Headers: recordHeaders(headers),
+	}
+	partition, offset, err := producer.SendMessage(msg)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Message published", name, partition, offset)
+	return nil
+}
+
+func (k *KafkaController) connectProducer(topic string) (sarama.SyncProducer, error) {
+	config := sarama.NewConfig()
+	config.Producer.Return.Successes = true
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Retry.Max = 5
+
+	admin, err := sarama.NewClusterAdmin(k.Brokers, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cluster admin: %w", err)
+	}
+	defer admin.Close()
+
+	if err := k.createTopicIfNotExists(admin, topic, 3, 1); err != nil {
+		return nil, err
+	}
+	return sarama.NewSyncProducer(k.Brokers, config)
+}
+
+// createTopicIfNotExists creates a Kafka topic if it doesn't already exist.
+func (k *KafkaController) createTopicIfNotExists(admin sarama.ClusterAdmin, topicName string, partitions int32, replicationFactor int16) error {
+	topics, err := admin.ListTopics()
+	if err != nil {
+		return fmt.Errorf("failed to list topics: %v", err)
+	}
+	if _, exists := topics[topicName]; exists {
+		return nil
+	}
+
+	retention := "604800000" // 7 days retention
+	topicDetail := &sarama.TopicDetail{
+		NumPartitions:     partitions,
+		ReplicationFactor: replicationFactor,
+		ConfigEntries:     map[string]*string{"retention.ms": &retention},
+	}
+	if err := admin.CreateTopic(topicName, topicDetail, false); err != nil {
+		return fmt.Errorf("failed to create topic %s: %v", topicName, err)
+	}
+
+	fmt.Printf("Topic %s created successfully\n", topicName)
+	time.Sleep(2 * time.Second)
+	return nil
+}
+
+// recordHeaders converts the headers of an event to Kafka record headers
+func recordHeaders(headers events.Headers) []sarama.RecordHeader {
+	records := make([]sarama.RecordHeader, 0, len(headers))
+	for key, value := range headers {
+		records = append(records, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+	}
+	return records
+}
+
+// eventHeaders converts the headers of a consumed Kafka record to event headers
+func eventHeaders(records []*sarama.RecordHeader) events.Headers {
+	headers := make(events.Headers, len(records))
+	for _, record := range records {
+		headers[string(record.Key)] = string(record.Value)
+	}
+	return headers
+}
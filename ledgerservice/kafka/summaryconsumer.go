@@ -0,0 +1,72 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"ledgerservice/database"
+	"ledgerservice/models"
+	"ledgerservice/repositories"
+	"log"
+	"shared/events"
+
+	"github.com/IBM/sarama"
+	"github.com/hashicorp/go-hclog"
+)
+
+// HoldsTopic carries every change of a hold's status, keyed by account number
+const HoldsTopic = "account-holds"
+
+// SummaryConsumer projects the transaction outcomes on "transaction-ledger" and "dead-ledger" and
+// the hold changes on "account-holds" into the per-account summaries
+type SummaryConsumer struct {
+	repo  repositories.SummaryRepository
+	loggs *hclog.Logger
+}
+
+// NewSummaryConsumer creates a SummaryConsumer keeping the last recent transactions of every account
+func NewSummaryConsumer(db database.Database, recent int, lobbs *hclog.Logger) *SummaryConsumer {
+	return &SummaryConsumer{
+		repo:  repositories.NewSummaryRepository(db, recent, lobbs),
+		loggs: lobbs,
+	}
+}
+
+func (h SummaryConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		topic := events.TopicID(msg.Topic)
+		source := models.SourceOffset{Topic: msg.Topic, Partition: msg.Partition, Offset: msg.Offset}
+		ctx := context.Background()
+
+		var err error
+		if topic == HoldsTopic {
+			var hold models.Hold
+			if _, err = events.Unmarshal(topic, msg.Value, eventHeaders(msg.Headers), &hold); err == nil {
+				err = h.repo.ApplyHold(ctx, hold, source)
+			} else if !errors.Is(err, events.ErrUnsupportedVersion) {
+				log.Printf("Failed to unmarshal hold (offset %d): %v", msg.Offset, err)
+				session.MarkMessage(msg, "")
+				continue
+			}
+		} else {
+			var trans models.TransactionLedger
+			if _, err = events.Unmarshal(topic, msg.Value, eventHeaders(msg.Headers), &trans); err == nil {
+				err = h.repo.ApplyTransaction(ctx, trans, topic == "dead-ledger", msg.Timestamp, source)
+			} else if !errors.Is(err, events.ErrUnsupportedVersion) {
+				log.Printf("Failed to unmarshal message (offset %d): %v", msg.Offset, err)
+				session.MarkMessage(msg, "")
+				continue
+			}
+		}
+		if err != nil {
+			// the offset is not committed, so the message is projected again once the group resumes,
+			// or once this service is upgraded to read an event version it does not know yet
+			(*h.loggs).Error("Error projecting account summary", "Topic", msg.Topic, "Partition", msg.Partition, "Offset", msg.Offset, "Error", err)
+			return nil
+		}
+		session.MarkMessage(msg, "")
+	}
+	return nil
+}
+
+func (SummaryConsumer) Setup(_ sarama.ConsumerGroupSession) error   { return nil }
+func (SummaryConsumer) Cleanup(_ sarama.ConsumerGroupSession) error { return nil }
@@ -3,22 +3,32 @@ package main
 import (
 	"context"
 	"fmt"
+	"ledgerservice/audit"
 	"ledgerservice/configurations"
 	"ledgerservice/database"
+	"ledgerservice/handlers"
+	"ledgerservice/integrity"
+	"ledgerservice/jobs"
 	"ledgerservice/kafka"
+	"ledgerservice/repositories"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"shared/events"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/IBM/sarama"
+	"github.com/gorilla/mux"
 	"github.com/hashicorp/go-hclog"
 )
 
 func main() {
 
 	fmt.Println("Starting to develop banking application")
+	events.Producer = "ledgerservice"
 	// logging app file
 	logFile, err := os.OpenFile("app.log", os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
 	if err != nil {
@@ -43,6 +53,42 @@ func main() {
 		os.Exit(1)
 	}
 
+	projectionconfig, err := configurations.NewProjectionConfig()
+	if err != nil {
+		loggs.Error("Not able to create Retrieve Projection Configurations", "Error", err)
+		os.Exit(1)
+	}
+
+	kafkaconfig, err := configurations.NewKafkaConfig()
+	if err != nil {
+		loggs.Error("Not able to create Retrieve Kafka Configurations", "Error", err)
+		os.Exit(1)
+	}
+	if kafkaconfig.TopicMapFile != "" {
+		if err := events.LoadTopics(kafkaconfig.TopicMapFile); err != nil {
+			loggs.Error("Not able to load topic map", "File", kafkaconfig.TopicMapFile, "Error", err)
+			os.Exit(1)
+		}
+	}
+
+	integrityconfig, err := configurations.NewIntegrityConfig()
+	if err != nil {
+		loggs.Error("Not able to create Retrieve Integrity Configurations", "Error", err)
+		os.Exit(1)
+	}
+	var signer *integrity.Signer
+	if integrityconfig.SigningKey != "" {
+		signer, err = integrity.NewSigner(integrityconfig.SigningKey)
+	} else {
+		loggs.Warn("LEDGER_DEV_MODE is set without LEDGER_SIGNING_KEY, checkpoints are signed with a new key every start")
+		signer, err = integrity.GenerateSigner()
+	}
+	if err != nil {
+		loggs.Error("Not able to load the checkpoint signing key", "Error", err)
+		os.Exit(1)
+	}
+	loggs.Info("Checkpoints are signed", "PublicKey", signer.PublicKey())
+
 	mongodb := database.NewMongoDB(mongodbconfig, &loggs)
 	ctx := context.Background()
 
@@ -52,6 +98,17 @@ func main() {
 	}
 	defer mongodb.Disconnect(ctx)
 
+	// ledger entries written before the hash chain was introduced are chained ahead of new ones
+	integrityRepo := repositories.NewIntegrityRepository(mongodb, signer, &loggs)
+	chainedCount, err := integrityRepo.ChainExisting(ctx)
+	if err != nil {
+		loggs.Error("Failed to chain existing ledger entries", "Error", err)
+		os.Exit(1)
+	}
+	if chainedCount > 0 {
+		loggs.Info("Chained existing ledger entries", "Count", chainedCount)
+	}
+
 	// kafka connsumer function
 	kafkaConsumer := kafka.NewKafkaConsumer(mongodb, &loggs)
 
@@ -60,9 +117,9 @@ func main() {
 	config.Consumer.Group.Rebalance.Strategy = sarama.BalanceStrategyRoundRobin
 	config.Consumer.Offsets.Initial = sarama.OffsetNewest
 	config.Consumer.Return.Errors = true
-	brokers := []string{"kafkamongo:9092"}
+	brokers := kafkaconfig.Brokers
 	groupID := "ledger-consumtion-group"
-	topicName := []string{"transaction-ledger"}
+	topicName := events.TopicNames("transaction-ledger", "dead-ledger", "clearing-status")
 	// Create consumer group
 	consumerGroup, err := sarama.NewConsumerGroup(brokers, groupID, config)
 	if err != nil {
@@ -70,10 +127,64 @@ func main() {
 	}
 	defer consumerGroup.Close()
 
+	// The account summaries are projected by a group of their own, from the oldest retained
+	// message, so that a new or rebuilt projection catches up on its own
+	summaryConsumer := kafka.NewSummaryConsumer(mongodb, projectionconfig.RecentTransactions, &loggs)
+	summaryConfig := sarama.NewConfig()
+	summaryConfig.Consumer.Group.Rebalance.Strategy = sarama.BalanceStrategyRoundRobin
+	summaryConfig.Consumer.Offsets.Initial = sarama.OffsetOldest
+	summaryConfig.Consumer.Return.Errors = true
+	summaryTopics := events.TopicNames("transaction-ledger", "dead-ledger", kafka.HoldsTopic)
+	summaryGroup, err := sarama.NewConsumerGroup(brokers, "ledger-summary-group", summaryConfig)
+	if err != nil {
+		log.Fatalf("Failed to start summary consumer group: %v", err)
+	}
+	defer summaryGroup.Close()
+
+	// The audit log is chained by a group of its own from the oldest retained entry, so that no
+	// entry published while the ledger service was down is lost
+	auditConsumer := kafka.NewAuditConsumer(mongodb, &loggs)
+	auditConfig := sarama.NewConfig()
+	auditConfig.Consumer.Offsets.Initial = sarama.OffsetOldest
+	auditConfig.Consumer.Return.Errors = true
+	auditGroup, err := sarama.NewConsumerGroup(brokers, "ledger-audit-group", auditConfig)
+	if err != nil {
+		log.Fatalf("Failed to start audit consumer group: %v", err)
+	}
+	defer auditGroup.Close()
+
 	// Handle graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	wg := &sync.WaitGroup{}
-	wg.Add(1)
+	wg.Add(4)
+
+	// signed checkpoints of the ledger are published next to the topics it is built from
+	checkpointJob := jobs.NewCheckpointJob(integrityRepo, &kafka.KafkaController{Brokers: brokers}, &loggs)
+	go func() {
+		defer wg.Done()
+		jobs.Every(ctx, "ledger-checkpoint", integrityconfig.CheckpointInterval, &loggs, checkpointJob.Run)
+	}()
+
+	router := mux.NewRouter()
+	handlers.NewLedgerHandler(integrityRepo, &loggs).RegisterRoutes(router)
+	opts := hclog.StandardLoggerOptions{
+		InferLevels: true,
+	}
+	httpServer := http.Server{
+		Addr:         integrityconfig.BindAddress,
+		Handler:      router,
+		ErrorLog:     loggs.StandardLogger(&opts),
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 30 * time.Second,
+		IdleTimeout:  120 * time.Second,
+	}
+	go func() {
+		loggs.Info("starting ledger server on ", "Port", integrityconfig.BindAddress)
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			loggs.Error("Error starting ledger server", "Error", err)
+			os.Exit(1)
+		}
+	}()
 
 	go func() {
 		defer wg.Done()
@@ -89,12 +200,46 @@ func main() {
 		}
 	}()
 
+	go func() {
+		defer wg.Done()
+		for {
+			if err := summaryGroup.Consume(ctx, summaryTopics, summaryConsumer); err != nil {
+				log.Printf("Summary consumer error: %v", err)
+			}
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		for {
+			if err := auditGroup.Consume(ctx, events.TopicNames(audit.Topic), auditConsumer); err != nil {
+				log.Printf("Audit consumer error: %v", err)
+			}
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+
 	// Listen for errors
 	go func() {
 		for err := range consumerGroup.Errors() {
 			log.Printf("Consumer group error: %v", err)
 		}
 	}()
+	go func() {
+		for err := range summaryGroup.Errors() {
+			log.Printf("Summary consumer group error: %v", err)
+		}
+	}()
+	go func() {
+		for err := range auditGroup.Errors() {
+			log.Printf("Audit consumer group error: %v", err)
+		}
+	}()
 
 	log.Println("Consumer group started. Waiting for messages...")
 
@@ -104,6 +249,9 @@ func main() {
 	<-sigChan
 
 	log.Println("Shutting down consumer...")
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer shutdownCancel()
+	httpServer.Shutdown(shutdownCtx)
 	cancel()
 	wg.Wait()
 }
@@ -0,0 +1,88 @@
+// Package merkle builds Merkle trees over the ledger the way RFC 6962 (Certificate Transparency)
+// does: leaves and interior nodes are hashed with different prefixes, and a tree of n leaves
+// splits at the largest power of two below n. An inclusion proof is the list of sibling hashes
+// from a leaf up to the root.
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+)
+
+// LeafHash hashes the data of a leaf
+func LeafHash(data []byte) []byte {
+	sum := sha256.Sum256(append([]byte{0x00}, data...))
+	return sum[:]
+}
+
+// nodeHash hashes two subtrees into their parent
+func nodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0x01})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// split returns the largest power of two smaller than n, for n > 1
+func split(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
+
+// Root returns the root of the tree over the given leaf hashes
+func Root(leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		sum := sha256.Sum256(nil)
+		return sum[:]
+	case 1:
+		return leaves[0]
+	}
+	k := split(len(leaves))
+	return nodeHash(Root(leaves[:k]), Root(leaves[k:]))
+}
+
+// Proof returns the inclusion proof of the leaf at index in the tree over the given leaf hashes,
+// from the leaf's sibling up to the root's children
+func Proof(leaves [][]byte, index int) [][]byte {
+	if len(leaves) <= 1 {
+		return nil
+	}
+	k := split(len(leaves))
+	if index < k {
+		return append(Proof(leaves[:k], index), Root(leaves[k:]))
+	}
+	return append(Proof(leaves[k:], index-k), Root(leaves[:k]))
+}
+
+// Verify checks that proof shows the leaf hash at index to be part of the tree of size leaves
+// with the given root
+func Verify(leaf []byte, index, size int64, proof [][]byte, root []byte) bool {
+	if index < 0 || index >= size {
+		return false
+	}
+	fn, sn := index, size-1
+	hash := leaf
+	for _, sibling := range proof {
+		if sn == 0 {
+			return false
+		}
+		if fn&1 == 1 || fn == sn {
+			hash = nodeHash(sibling, hash)
+			// skip the levels where the node has no right sibling
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			hash = nodeHash(hash, sibling)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return sn == 0 && bytes.Equal(hash, root)
+}
@@ -0,0 +1,46 @@
+package merkle
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func leaves(n int) [][]byte {
+	var out [][]byte
+	for i := 0; i < n; i++ {
+		out = append(out, LeafHash([]byte(fmt.Sprintf("entry %d", i))))
+	}
+	return out
+}
+
+func TestProofsVerify(t *testing.T) {
+	for n := 1; n <= 17; n++ {
+		tree := leaves(n)
+		root := Root(tree)
+		for i := 0; i < n; i++ {
+			proof := Proof(tree, i)
+			assert.True(t, Verify(tree[i], int64(i), int64(n), proof, root), "leaf %d of %d", i, n)
+
+			// the proof holds for that leaf and position only
+			other := LeafHash([]byte("forged"))
+			assert.False(t, Verify(other, int64(i), int64(n), proof, root), "forged leaf %d of %d", i, n)
+			if n > 1 {
+				assert.False(t, Verify(tree[i], int64((i+1)%n), int64(n), proof, root), "moved leaf %d of %d", i, n)
+			}
+		}
+	}
+}
+
+func TestRootChangesWithAnyLeaf(t *testing.T) {
+	tree := leaves(7)
+	root := Root(tree)
+	for i := range tree {
+		changed := append([][]byte{}, tree...)
+		changed[i] = LeafHash([]byte("changed"))
+		assert.NotEqual(t, root, Root(changed), "leaf %d", i)
+	}
+	// a tree is not the same as the tree of its first leaves
+	assert.NotEqual(t, root, Root(tree[:6]))
+}
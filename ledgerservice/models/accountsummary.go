@@ -0,0 +1,71 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Posting is one balance movement the transaction service made when it posted a transaction,
+// with the balance it left behind
+type Posting struct {
+	AccountNumber string  `bson:"account_number" json:"account_number"`
+	Amount        float64 `bson:"amount" json:"amount"` // Positive for a credit, negative for a debit
+	Cause         string  `bson:"cause" json:"cause"`
+	Balance       float64 `bson:"balance" json:"balance"` // Balance after the movement
+	Version       int64   `bson:"version" json:"version"` // Version of the movement in the account's event stream
+}
+
+// Hold is published by the transaction service on "account-holds" whenever a hold changes status
+type Hold struct {
+	ID            string    `json:"id"`
+	AccountNumber string    `json:"account_number"`
+	Amount        float64   `json:"amount"`
+	Reference     string    `json:"reference"`
+	Status        string    `json:"status"` // active, captured, released or expired
+	ExpiresAt     time.Time `json:"expires_at"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// RecentTransaction is a transaction as seen from one account in its summary
+type RecentTransaction struct {
+	TransactionID   string    `bson:"transaction_id" json:"transaction_id"`
+	TransactionType string    `bson:"transaction_type" json:"transaction_type"`
+	Amount          float64   `bson:"amount" json:"amount"` // What it did to the account: positive in, negative out
+	Counterparty    string    `bson:"counterparty,omitempty" json:"counterparty,omitempty"`
+	Description     string    `bson:"description,omitempty" json:"description,omitempty"`
+	Status          string    `bson:"status" json:"status"` // completed or failed
+	CreatedAt       time.Time `bson:"created_at" json:"created_at"`
+}
+
+// PendingHold is an active hold in an account summary
+type PendingHold struct {
+	HoldID    string    `bson:"hold_id" json:"hold_id"`
+	Amount    float64   `bson:"amount" json:"amount"`
+	Reference string    `bson:"reference,omitempty" json:"reference,omitempty"`
+	ExpiresAt time.Time `bson:"expires_at" json:"expires_at"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// SummaryChange is what one message changes in the summary of one account
+type SummaryChange struct {
+	AccountNumber string
+	Transaction   *RecentTransaction // Added to the recent transactions
+	Month         string             // Month In and Out count towards, as 2006-01
+	In            float64
+	Out           float64
+	Balance       *Posting     // Latest movement of the account, when the message carries one
+	Hold          *PendingHold // Added to or, with HoldReleased, removed from the pending holds
+	HoldReleased  bool
+}
+
+// SourceOffset identifies the Kafka message a change came from
+type SourceOffset struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+}
+
+// Key names the partition of the source in the summary's offsets
+func (s SourceOffset) Key() string {
+	return fmt.Sprintf("%s-%d", s.Topic, s.Partition)
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Checkpoint commits to the first Size entries of the ledger. It is signed by the ledger service
+// and published on "ledger-checkpoints", so that a copy held elsewhere shows whether the ledger
+// was rewritten since.
+type Checkpoint struct {
+	Size      int64     `bson:"_id" json:"size"`
+	Root      string    `bson:"root" json:"root"` // Merkle root over the hashes of entries 1 to Size, hex
+	Head      string    `bson:"head" json:"head"` // Hash of entry Size
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	PublicKey string    `bson:"public_key" json:"public_key"` // Ed25519 key the checkpoint was signed with, base64
+	Signature string    `bson:"signature" json:"signature"`   // Ed25519 signature of the checkpoint, base64
+}
+
+// InclusionProof shows a ledger entry to be part of the ledger a checkpoint commits to
+type InclusionProof struct {
+	Transaction TransactionLedger `json:"transaction"`
+	LeafIndex   int64             `json:"leaf_index"` // Position of the entry in the tree, LedgerSeq - 1
+	TreeSize    int64             `json:"tree_size"`
+	Proof       []string          `json:"proof"` // Sibling hashes from the entry's leaf up to the root, hex
+	Checkpoint  Checkpoint        `json:"checkpoint"`
+}
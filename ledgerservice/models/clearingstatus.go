@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// ClearingStatus is published by the clearing adapter on "clearing-status" when the clearing
+// system reports on an external transfer
+type ClearingStatus struct {
+	TransactionID string    `json:"transaction_id"`
+	Status        string    `json:"status"` // accepted, settled or rejected
+	ISOStatus     string    `json:"iso_status"`
+	ReasonCode    string    `json:"reason_code,omitempty"`
+	Reason        string    `json:"reason,omitempty"`
+	MessageID     string    `json:"message_id"`
+	ReceivedAt    time.Time `json:"received_at"`
+}
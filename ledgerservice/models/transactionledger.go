@@ -7,12 +7,36 @@ import (
 )
 
 type TransactionLedger struct {
-	ID              bson.ObjectID `bson:"_id"`
-	FromAccountID   string        `bson:"from_account_id" json:"from_account_id"`
-	ToAccountID     string        `bson:"to_account_id" json:"to_account_id"`
-	Amount          float64       `bson:"amount" json:"amount"`
-	TransactionType string        `bson:"transaction_type" json:"transaction_type"`
-	Description     string        `bson:"description" json:"description"`
-	CreatedAt       time.Time     `bson:"created_at" json:"created_at"`
-	Status          string        `bson:"status" json:"status"`
+	ID                    bson.ObjectID `bson:"_id"`
+	TransactionID         string        `bson:"transaction_id" json:"id"`
+	FromAccountID         string        `bson:"from_account_id" json:"from_account_id"`
+	ToAccountID           string        `bson:"to_account_id" json:"to_account_id"`
+	Amount                float64       `bson:"amount" json:"amount"`
+	TransactionType       string        `bson:"transaction_type" json:"transaction_type"`
+	Description           string        `bson:"description" json:"description"`
+	CreatedAt             time.Time     `bson:"created_at" json:"created_at"`
+	Status                string        `bson:"status" json:"status"`
+	OriginalTransactionID string        `bson:"original_transaction_id,omitempty" json:"original_transaction_id,omitempty"` // Set on reversals
+	ReversalIDs           []string      `bson:"reversal_ids,omitempty" json:"reversal_ids,omitempty"`                       // Reversals posted against this entry
+	ReversedAmount        float64       `bson:"reversed_amount,omitempty" json:"reversed_amount,omitempty"`                 // Total refunded by reversals
+	Currency              string        `bson:"currency,omitempty" json:"currency,omitempty"`                               // Currency of Amount
+	FxRate                float64       `bson:"fx_rate,omitempty" json:"fx_rate,omitempty"`                                 // Rate applied to a cross-currency transfer
+	ConvertedAmount       float64       `bson:"converted_amount,omitempty" json:"converted_amount,omitempty"`               // Amount credited to ToAccountID
+	ConvertedCurrency     string        `bson:"converted_currency,omitempty" json:"converted_currency,omitempty"`           // Currency of ConvertedAmount
+	Fees                  []FeeLine     `bson:"fees,omitempty" json:"fees,omitempty"`                                       // Fees charged on top of Amount
+	BatchID               string        `bson:"batch_id,omitempty" json:"batch_id,omitempty"`                               // Bulk payment file the transaction was submitted in
+	ClearingStatus        string        `bson:"clearing_status,omitempty" json:"clearing_status,omitempty"`                 // Progress of an external transfer in the clearing system
+	ClearingReason        string        `bson:"clearing_reason,omitempty" json:"clearing_reason,omitempty"`                 // ISO reason code and text of a clearing rejection
+	Postings              []Posting     `bson:"postings,omitempty" json:"postings,omitempty"`                               // Balance movements made when the transaction was posted
+	LedgerSeq             int64         `bson:"ledger_seq,omitempty" json:"ledger_seq,omitempty"`                           // Position in the ledger's hash chain, from 1
+	PrevHash              string        `bson:"prev_hash,omitempty" json:"prev_hash,omitempty"`                             // Hash of the entry before it in the chain
+	Hash                  string        `bson:"hash,omitempty" json:"hash,omitempty"`                                       // Hash of the entry as inserted, see integrity.EntryHash
+}
+
+// FeeLine is a fee charged to the paying account and credited to the fee income account
+type FeeLine struct {
+	RuleID   int64   `bson:"rule_id" json:"rule_id"`
+	Name     string  `bson:"name" json:"name"`
+	Amount   float64 `bson:"amount" json:"amount"`
+	Currency string  `bson:"currency" json:"currency"`
 }
@@ -0,0 +1,110 @@
+// Package projection works out how transaction outcomes and hold changes move the per-account
+// summaries read by the account dashboards. It only decides what changes; the summaries are
+// stored by the repositories.
+package projection
+
+import (
+	"ledgerservice/models"
+	"time"
+)
+
+// FromTransaction returns the change a transaction outcome makes to each account it touched.
+// A completed transaction counts towards the monthly totals through its postings; a failed one,
+// or one published without postings, only shows up in the recent transactions.
+func FromTransaction(trans models.TransactionLedger, failed bool, at time.Time) []models.SummaryChange {
+	if !trans.CreatedAt.IsZero() {
+		at = trans.CreatedAt
+	}
+	status := "completed"
+	if failed {
+		status = "failed"
+	}
+
+	var changes []models.SummaryChange
+	change := func(accountNumber string) *models.SummaryChange {
+		for i := range changes {
+			if changes[i].AccountNumber == accountNumber {
+				return &changes[i]
+			}
+		}
+		changes = append(changes, models.SummaryChange{
+			AccountNumber: accountNumber,
+			Month:         at.UTC().Format("2006-01"),
+			Transaction: &models.RecentTransaction{
+				TransactionID:   trans.TransactionID,
+				TransactionType: trans.TransactionType,
+				Counterparty:    counterparty(trans, accountNumber),
+				Description:     trans.Description,
+				Status:          status,
+				CreatedAt:       at,
+			},
+		})
+		return &changes[len(changes)-1]
+	}
+
+	if !failed && len(trans.Postings) > 0 {
+		for _, posting := range trans.Postings {
+			c := change(posting.AccountNumber)
+			c.Transaction.Amount += posting.Amount
+			if posting.Amount > 0 {
+				c.In += posting.Amount
+			} else {
+				c.Out -= posting.Amount
+			}
+			if c.Balance == nil || posting.Version > c.Balance.Version {
+				latest := posting
+				c.Balance = &latest
+			}
+		}
+		return changes
+	}
+
+	// without postings the direction comes from the transaction type
+	if trans.FromAccountID != "" {
+		amount := -trans.Amount
+		if credits(trans.TransactionType) {
+			amount = trans.Amount
+		}
+		change(trans.FromAccountID).Transaction.Amount = amount
+	}
+	if trans.ToAccountID != "" && trans.ToAccountID != trans.FromAccountID {
+		amount := trans.Amount
+		if trans.ConvertedAmount > 0 {
+			amount = trans.ConvertedAmount
+		}
+		change(trans.ToAccountID).Transaction.Amount = amount
+	}
+	return changes
+}
+
+// FromHold returns the change a hold's new status makes to its account: an active hold is
+// pending, any other status takes it off the list
+func FromHold(hold models.Hold) models.SummaryChange {
+	return models.SummaryChange{
+		AccountNumber: hold.AccountNumber,
+		Hold: &models.PendingHold{
+			HoldID:    hold.ID,
+			Amount:    hold.Amount,
+			Reference: hold.Reference,
+			ExpiresAt: hold.ExpiresAt,
+			CreatedAt: hold.CreatedAt,
+		},
+		HoldReleased: hold.Status != "active",
+	}
+}
+
+// credits reports whether a transaction type credits its FromAccountID
+func credits(transactionType string) bool {
+	return transactionType == "deposit" || transactionType == "interest"
+}
+
+// counterparty is the other account of a transaction, seen from accountNumber
+func counterparty(trans models.TransactionLedger, accountNumber string) string {
+	switch accountNumber {
+	case trans.FromAccountID:
+		return trans.ToAccountID
+	case trans.ToAccountID:
+		return trans.FromAccountID
+	}
+	return ""
+}
@@ -0,0 +1,78 @@
+package projection
+
+import (
+	"ledgerservice/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+
+// TestTransferWithFeeUsesPostings tests that each account gets its net movement and latest balance
+func TestTransferWithFeeUsesPostings(t *testing.T) {
+	trans := models.TransactionLedger{
+		TransactionID: "t1", FromAccountID: "ACC1", ToAccountID: "ACC2", Amount: 100, TransactionType: "transfer",
+		Postings: []models.Posting{
+			{AccountNumber: "ACC1", Amount: -100, Cause: "transfer", Balance: 400, Version: 7},
+			{AccountNumber: "ACC1", Amount: -2, Cause: "fee", Balance: 398, Version: 8},
+			{AccountNumber: "ACC2", Amount: 100, Cause: "transfer", Balance: 100, Version: 3},
+			{AccountNumber: "FEE-INCOME", Amount: 2, Cause: "fee", Balance: 52, Version: 26},
+		},
+	}
+
+	changes := FromTransaction(trans, false, now)
+	require.Len(t, changes, 3)
+
+	from := changes[0]
+	assert.Equal(t, "ACC1", from.AccountNumber)
+	assert.Equal(t, "2026-10", from.Month)
+	assert.InDelta(t, 102, from.Out, 1e-9)
+	assert.Zero(t, from.In)
+	assert.InDelta(t, -102, from.Transaction.Amount, 1e-9)
+	assert.Equal(t, "ACC2", from.Transaction.Counterparty)
+	assert.Equal(t, int64(8), from.Balance.Version)
+	assert.Equal(t, 398.0, from.Balance.Balance)
+
+	to := changes[1]
+	assert.Equal(t, "ACC2", to.AccountNumber)
+	assert.Equal(t, 100.0, to.In)
+	assert.Equal(t, "ACC1", to.Transaction.Counterparty)
+	assert.Equal(t, "completed", to.Transaction.Status)
+
+	assert.Equal(t, "FEE-INCOME", changes[2].AccountNumber)
+}
+
+// TestFailedTransactionOnlyShowsUp tests that a refused transaction is listed without moving totals or balances
+func TestFailedTransactionOnlyShowsUp(t *testing.T) {
+	trans := models.TransactionLedger{TransactionID: "t2", FromAccountID: "ACC1", ToAccountID: "ACC2", Amount: 50, TransactionType: "transfer"}
+
+	changes := FromTransaction(trans, true, now)
+	require.Len(t, changes, 2)
+	for _, change := range changes {
+		assert.Zero(t, change.In)
+		assert.Zero(t, change.Out)
+		assert.Nil(t, change.Balance)
+		assert.Equal(t, "failed", change.Transaction.Status)
+		assert.Equal(t, now, change.Transaction.CreatedAt)
+	}
+	assert.Equal(t, -50.0, changes[0].Transaction.Amount)
+	assert.Equal(t, 50.0, changes[1].Transaction.Amount)
+
+	deposit := FromTransaction(models.TransactionLedger{TransactionID: "t3", FromAccountID: "ACC1", Amount: 20, TransactionType: "deposit"}, true, now)
+	require.Len(t, deposit, 1)
+	assert.Equal(t, 20.0, deposit[0].Transaction.Amount)
+}
+
+// TestHoldStatus tests that only active holds are pending
+func TestHoldStatus(t *testing.T) {
+	hold := models.Hold{ID: "h1", AccountNumber: "ACC1", Amount: 30, Status: "active"}
+	change := FromHold(hold)
+	assert.False(t, change.HoldReleased)
+	assert.Equal(t, "h1", change.Hold.HoldID)
+
+	hold.Status = "captured"
+	assert.True(t, FromHold(hold).HoldReleased)
+}
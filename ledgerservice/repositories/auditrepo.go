@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"ledgerservice/audit"
+	"ledgerservice/database"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// appendAttempts bounds how often an append is retried when another writer took the sequence number
+const appendAttempts = 3
+
+// AuditRepository appends entries to the hash-chained audit log
+type AuditRepository interface {
+	Append(ctx context.Context, entry audit.Entry) (bool, error)
+}
+
+type AuditRepo struct {
+	mgdb  database.Database
+	mu    sync.Mutex
+	loggs *hclog.Logger
+}
+
+// NewAuditRepository creates an AuditRepository
+func NewAuditRepository(mgdb database.Database, lobbs *hclog.Logger) AuditRepository {
+	return &AuditRepo{
+		mgdb:  mgdb,
+		loggs: lobbs,
+	}
+}
+
+// Append chains an entry to the head of the log. An entry already chained is skipped; Append
+// returns false then.
+func (a *AuditRepo) Append(ctx context.Context, entry audit.Entry) (bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for attempt := 0; attempt < appendAttempts; attempt++ {
+		exists, err := a.mgdb.AuditRecordExists(ctx, entry.ID)
+		if err != nil || exists {
+			return false, err
+		}
+		head, err := a.mgdb.LastAuditRecord(ctx)
+		if err != nil {
+			return false, err
+		}
+
+		record := audit.Chain(entry, head, time.Now())
+		inserted, err := a.mgdb.InsertAuditRecord(ctx, record)
+		if err != nil || inserted {
+			return inserted, err
+		}
+		// the sequence number was taken by another writer since the head was read
+		(*a.loggs).Debug("Audit log head moved, retrying", "ID", entry.ID, "Seq", record.Seq)
+	}
+	return false, fmt.Errorf("failed to append audit entry %s: the head of the log kept moving", entry.ID)
+}
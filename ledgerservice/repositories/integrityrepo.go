@@ -0,0 +1,176 @@
+package repositories
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"ledgerservice/database"
+	"ledgerservice/integrity"
+	"ledgerservice/merkle"
+	"ledgerservice/models"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// chainBatch is how many unchained ledger entries are chained at a time
+const chainBatch = 500
+
+// ErrNotCheckpointed is returned for an entry chained after the checkpoint a proof was asked against
+var ErrNotCheckpointed = errors.New("transaction is not covered by the checkpoint yet")
+
+// ErrNoCheckpoint is returned when a proof is asked against a checkpoint that was not made
+var ErrNoCheckpoint = errors.New("checkpoint not found")
+
+// IntegrityRepository checkpoints the ledger's hash chain and proves entries part of it
+type IntegrityRepository interface {
+	ChainExisting(ctx context.Context) (int, error)
+	Checkpoint(ctx context.Context) (*models.Checkpoint, error)
+	LatestCheckpoint(ctx context.Context) (*models.Checkpoint, error)
+	GetCheckpoint(ctx context.Context, size int64) (*models.Checkpoint, error)
+	Prove(ctx context.Context, transactionID string, size int64) (*models.InclusionProof, error)
+	PublicKey() string
+}
+
+type IntegrityRepo struct {
+	mgdb   database.Database
+	signer *integrity.Signer
+	loggs  *hclog.Logger
+}
+
+// NewIntegrityRepository creates an IntegrityRepository signing checkpoints with signer
+func NewIntegrityRepository(mgdb database.Database, signer *integrity.Signer, lobbs *hclog.Logger) IntegrityRepository {
+	return &IntegrityRepo{
+		mgdb:   mgdb,
+		signer: signer,
+		loggs:  lobbs,
+	}
+}
+
+// ChainExisting chains the ledger entries inserted before the hash chain was introduced, oldest
+// first, and returns how many it chained. It must run before new entries are inserted.
+func (i *IntegrityRepo) ChainExisting(ctx context.Context) (int, error) {
+	head, err := i.mgdb.LastChainedTransaction(ctx)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for {
+		entries, err := i.mgdb.ListUnchainedTransactions(ctx, chainBatch)
+		if err != nil || len(entries) == 0 {
+			return count, err
+		}
+		for n := range entries {
+			integrity.Chain(&entries[n], head)
+			if err := i.mgdb.ChainTransaction(ctx, entries[n]); err != nil {
+				return count, fmt.Errorf("failed to chain transaction %s: %w", entries[n].TransactionID, err)
+			}
+			head = &entries[n]
+			count++
+		}
+	}
+}
+
+// Checkpoint signs and stores a checkpoint over the whole chain, and returns it. It returns nil
+// when the chain has not grown since the latest checkpoint. The chain is checked against the
+// latest checkpoint first, so that a rewritten ledger is never signed.
+func (i *IntegrityRepo) Checkpoint(ctx context.Context) (*models.Checkpoint, error) {
+	head, err := i.mgdb.LastChainedTransaction(ctx)
+	if err != nil || head == nil {
+		return nil, err
+	}
+	latest, err := i.mgdb.LatestCheckpoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil && latest.Size >= head.LedgerSeq {
+		return nil, nil
+	}
+
+	hashes, err := i.mgdb.LedgerHashes(ctx, head.LedgerSeq)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil {
+		leaves, err := integrity.Leaves(hashes[:latest.Size])
+		if err != nil {
+			return nil, err
+		}
+		if hex.EncodeToString(merkle.Root(leaves)) != latest.Root {
+			return nil, fmt.Errorf("%w: the first %d entries no longer match the latest checkpoint", integrity.ErrTampered, latest.Size)
+		}
+	}
+
+	cp, err := i.signer.Checkpoint(hashes, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := i.mgdb.InsertCheckpoint(ctx, cp); err != nil {
+		return nil, err
+	}
+	(*i.loggs).Info("Ledger checkpoint signed", "Size", cp.Size, "Root", cp.Root)
+	return &cp, nil
+}
+
+// LatestCheckpoint returns the largest checkpoint, or nil when there is none
+func (i *IntegrityRepo) LatestCheckpoint(ctx context.Context) (*models.Checkpoint, error) {
+	return i.mgdb.LatestCheckpoint(ctx)
+}
+
+// GetCheckpoint returns the checkpoint over the first size entries, or nil when there is none
+func (i *IntegrityRepo) GetCheckpoint(ctx context.Context, size int64) (*models.Checkpoint, error) {
+	return i.mgdb.GetCheckpoint(ctx, size)
+}
+
+// Prove returns the proof that a transaction's ledger entry is part of the ledger the checkpoint
+// over the first size entries commits to, or the latest checkpoint when size is 0. It returns
+// nil when the transaction is not in the chain. The proof is computed from every hash up to the
+// checkpoint.
+func (i *IntegrityRepo) Prove(ctx context.Context, transactionID string, size int64) (*models.InclusionProof, error) {
+	entry, err := i.mgdb.FindChainedTransaction(ctx, transactionID)
+	if err != nil || entry == nil {
+		return nil, err
+	}
+
+	var cp *models.Checkpoint
+	if size == 0 {
+		cp, err = i.mgdb.LatestCheckpoint(ctx)
+	} else {
+		cp, err = i.mgdb.GetCheckpoint(ctx, size)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cp == nil {
+		return nil, ErrNoCheckpoint
+	}
+	if entry.LedgerSeq > cp.Size {
+		return nil, ErrNotCheckpointed
+	}
+
+	hashes, err := i.mgdb.LedgerHashes(ctx, cp.Size)
+	if err != nil {
+		return nil, err
+	}
+	leaves, err := integrity.Leaves(hashes)
+	if err != nil {
+		return nil, err
+	}
+	proof := &models.InclusionProof{
+		Transaction: *entry,
+		LeafIndex:   entry.LedgerSeq - 1,
+		TreeSize:    cp.Size,
+		Proof:       []string{},
+		Checkpoint:  *cp,
+	}
+	for _, sibling := range merkle.Proof(leaves, int(proof.LeafIndex)) {
+		proof.Proof = append(proof.Proof, hex.EncodeToString(sibling))
+	}
+	return proof, nil
+}
+
+// PublicKey returns the key checkpoints are signed with, base64
+func (i *IntegrityRepo) PublicKey() string {
+	return i.signer.PublicKey()
+}
@@ -3,8 +3,17 @@ package repositories
 import (
 	"context"
 	"ledgerservice/models"
+	"time"
 )
 
 type Repository interface {
 	InsertTransaction(ctx context.Context, ledger models.TransactionLedger) error
+	InsertRejected(ctx context.Context, ledger models.TransactionLedger) error
+	UpdateClearingStatus(ctx context.Context, status models.ClearingStatus) error
+}
+
+// SummaryRepository keeps the per-account summaries read by the account dashboards
+type SummaryRepository interface {
+	ApplyTransaction(ctx context.Context, ledger models.TransactionLedger, failed bool, at time.Time, source models.SourceOffset) error
+	ApplyHold(ctx context.Context, hold models.Hold, source models.SourceOffset) error
 }
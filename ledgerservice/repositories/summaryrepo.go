@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+	"ledgerservice/database"
+	"ledgerservice/models"
+	"ledgerservice/projection"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+type SummaryRepo struct {
+	mgdb   database.Database
+	recent int
+	loggs  *hclog.Logger
+}
+
+// NewSummaryRepository creates a SummaryRepository keeping the last recent transactions of every account
+func NewSummaryRepository(mgdb database.Database, recent int, lobbs *hclog.Logger) SummaryRepository {
+	return &SummaryRepo{
+		mgdb:   mgdb,
+		recent: recent,
+		loggs:  lobbs,
+	}
+}
+
+// ApplyTransaction updates the summary of every account a transaction outcome touched. Outcomes
+// are timestamped at when they were created, or at when the transaction carries no timestamp.
+func (s *SummaryRepo) ApplyTransaction(ctx context.Context, ledger models.TransactionLedger, failed bool, at time.Time, source models.SourceOffset) error {
+	for _, change := range projection.FromTransaction(ledger, failed, at) {
+		if err := s.apply(ctx, change, source); err != nil {
+			return err
+		}
+	}
+	return s.mgdb.SaveSummaryCheckpoint(ctx, source)
+}
+
+// ApplyHold updates the pending holds in the summary of the hold's account
+func (s *SummaryRepo) ApplyHold(ctx context.Context, hold models.Hold, source models.SourceOffset) error {
+	if err := s.apply(ctx, projection.FromHold(hold), source); err != nil {
+		return err
+	}
+	return s.mgdb.SaveSummaryCheckpoint(ctx, source)
+}
+
+func (s *SummaryRepo) apply(ctx context.Context, change models.SummaryChange, source models.SourceOffset) error {
+	applied, err := s.mgdb.ApplySummaryChange(ctx, change, source, s.recent)
+	if err != nil {
+		(*s.loggs).Error("Error updating account summary", "Account", change.AccountNumber, "Error", err)
+		return err
+	}
+	if !applied {
+		(*s.loggs).Debug("Account summary already includes message", "Account", change.AccountNumber, "Partition", source.Key(), "Offset", source.Offset)
+	}
+	return nil
+}
@@ -2,9 +2,13 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"ledgerservice/database"
+	"ledgerservice/integrity"
 	"ledgerservice/models"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/go-hclog"
@@ -13,6 +17,7 @@ import (
 
 type TransactionRepo struct {
 	mgdb  database.Database
+	mu    sync.Mutex // Held while an entry is chained, so entries take consecutive chain positions
 	loggs *hclog.Logger
 }
 
@@ -31,12 +36,69 @@ func (t *TransactionRepo) InsertTransaction(ctx context.Context, ledger models.T
 	ledger.Status = "completed"
 
 	// Insert into ledger
-	obid, err := t.mgdb.InsertTransaction(ctx, ledger)
+	obid, err := t.insertChained(ctx, ledger)
 	if err != nil {
 		(*t.loggs).Error("Error inserting transaction", "Error", err)
 		return err
 	}
 
 	fmt.Println("Transaction inserted successfully with ID: ", obid)
+
+	// link the reversal to the entry it reverses
+	if ledger.TransactionType == "reversal" && ledger.OriginalTransactionID != "" {
+		err = t.mgdb.LinkReversal(ctx, ledger.OriginalTransactionID, ledger.TransactionID, ledger.Amount)
+		if err != nil {
+			(*t.loggs).Error("Error linking reversal", "Error", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// insertChained inserts an entry at the head of the ledger's hash chain. Should another writer
+// take the position first, the entry is chained again after it.
+func (t *TransactionRepo) insertChained(ctx context.Context, ledger models.TransactionLedger) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for attempt := 0; attempt < appendAttempts; attempt++ {
+		head, err := t.mgdb.LastChainedTransaction(ctx)
+		if err != nil {
+			return "", err
+		}
+		integrity.Chain(&ledger, head)
+		obid, err := t.mgdb.InsertTransaction(ctx, ledger)
+		if !errors.Is(err, database.ErrChainPositionTaken) {
+			return obid, err
+		}
+		(*t.loggs).Debug("Ledger chain head moved, retrying", "Transaction", ledger.TransactionID, "Seq", ledger.LedgerSeq)
+	}
+	return "", fmt.Errorf("failed to chain transaction %s: the head of the ledger kept moving", ledger.TransactionID)
+}
+
+// InsertRejected records a transaction that was sent to the dead ledger so that its outcome can be reported
+func (t *TransactionRepo) InsertRejected(ctx context.Context, ledger models.TransactionLedger) error {
+	ledger.ID = bson.NewObjectID()
+	ledger.CreatedAt = time.Now()
+	ledger.Status = "failed"
+
+	obid, err := t.mgdb.InsertRejected(ctx, ledger)
+	if err != nil {
+		(*t.loggs).Error("Error inserting rejected transaction", "Error", err)
+		return err
+	}
+
+	fmt.Println("Rejected transaction recorded with ID: ", obid)
+	return nil
+}
+
+// UpdateClearingStatus records on a ledger entry how the clearing system reported on it
+func (t *TransactionRepo) UpdateClearingStatus(ctx context.Context, status models.ClearingStatus) error {
+	reason := strings.TrimSpace(strings.Join([]string{status.ReasonCode, status.Reason}, " "))
+	err := t.mgdb.UpdateClearingStatus(ctx, status.TransactionID, status.Status, reason)
+	if err != nil {
+		(*t.loggs).Error("Error updating clearing status", "Error", err)
+		return err
+	}
 	return nil
 }
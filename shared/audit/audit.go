@@ -0,0 +1,172 @@
+// Package audit records who changed what. Every state-changing API call and every action a
+// consumer takes on a message is published as an entry on the "audit-log" topic, and the ledger
+// service appends the entries to a hash-chained log in which any later change can be detected.
+// Every service publishes its entries with a Recorder, and the ledger service reads them back as
+// Entry values.
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// Topic carries the audit entries of every service to the ledger service
+const Topic = "audit-log"
+
+// Kinds of entry
+const (
+	KindAPI      = "api"
+	KindConsumer = "consumer"
+)
+
+// Entry is one recorded action, as published on Topic
+type Entry struct {
+	ID         string          `json:"id"` // Unique per action; an entry published twice is only chained once
+	OccurredAt time.Time       `json:"occurred_at"`
+	Service    string          `json:"service"`
+	Kind       string          `json:"kind"`     // "api" or "consumer"
+	Action     string          `json:"action"`   // Route of an API call, e.g. "PUT /admin/tiers/{name}", or what a consumer did
+	Resource   string          `json:"resource"` // Path of an API call, or what a consumer acted on
+	Actor      string          `json:"actor"`
+	RequestID  string          `json:"request_id"` // X-Request-ID of an API call, or the topic-partition-offset of a consumed message
+	SourceIP   string          `json:"source_ip,omitempty"`
+	Outcome    string          `json:"outcome"` // HTTP status of an API call, or the consumer's result
+	Request    json.RawMessage `json:"request,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+}
+
+// Redacted stands in for the value of a sensitive field in an entry
+const Redacted = "[redacted]"
+
+// sensitiveFields are the personal data fields, e.g. of a KYC profile, whose values are kept out
+// of the audit log wherever they appear in a request or state
+var sensitiveFields = map[string]bool{
+	"date_of_birth":      true,
+	"id_document_number": true,
+	"address":            true,
+	"phone":              true,
+}
+
+// Publisher publishes a keyed message on a topic
+type Publisher interface {
+	PushKeyedToQueue(topic, key string, message []byte) error
+}
+
+// Recorder publishes the audit entries of a service
+type Recorder struct {
+	service   string
+	publisher Publisher
+	loaders   map[string]Loader
+	loggs     *hclog.Logger
+}
+
+// NewRecorder creates a Recorder for service
+func NewRecorder(service string, publisher Publisher, lobbs *hclog.Logger) *Recorder {
+	return &Recorder{
+		service:   service,
+		publisher: publisher,
+		loaders:   map[string]Loader{},
+		loggs:     lobbs,
+	}
+}
+
+// Record publishes an entry. The action has already happened by then, so a failure is logged
+// rather than returned.
+func (r *Recorder) Record(entry Entry) {
+	entry.Service = r.service
+	if entry.OccurredAt.IsZero() {
+		entry.OccurredAt = time.Now()
+	}
+	entry.Request, entry.Before, entry.After = redact(entry.Request), redact(entry.Before), redact(entry.After)
+	msg, err := json.Marshal(entry)
+	if err == nil {
+		// Every entry has the same key, so all of them land on one partition and are chained in
+		// the order they were published
+		err = r.publisher.PushKeyedToQueue(Topic, "audit", msg)
+	}
+	if err != nil {
+		(*r.loggs).Error("Failed to publish audit entry", "ID", entry.ID, "Action", entry.Action, "Resource", entry.Resource,
+			"Actor", entry.Actor, "Outcome", entry.Outcome, "Error", err)
+	}
+}
+
+// Consumed records an action a consumer took on the message identified by source (see Source).
+// The entry ID is derived from the message, so a message processed again records nothing new.
+func (r *Recorder) Consumed(source, action, resource, outcome string, before, after any) {
+	r.Record(Entry{
+		ID:        fmt.Sprintf("%s:%s:%s:%s", r.service, source, action, resource),
+		Kind:      KindConsumer,
+		Action:    action,
+		Resource:  resource,
+		Actor:     "system:" + r.service,
+		RequestID: source,
+		Outcome:   outcome,
+		Before:    r.marshal(before),
+		After:     r.marshal(after),
+	})
+}
+
+// Source names a consumed message as topic-partition-offset
+func Source(topic string, partition int32, offset int64) string {
+	return fmt.Sprintf("%s-%d-%d", topic, partition, offset)
+}
+
+// marshal encodes a state for an entry; nil stays empty
+func (r *Recorder) marshal(v any) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		(*r.loggs).Error("Failed to encode audited state", "Error", err)
+		return nil
+	}
+	if string(data) == "null" {
+		return nil
+	}
+	return data
+}
+
+// redact replaces the values of sensitive fields anywhere in a JSON document with Redacted. A
+// document without any is kept byte for byte.
+func redact(data json.RawMessage) json.RawMessage {
+	if len(data) == 0 {
+		return data
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc any
+	if err := decoder.Decode(&doc); err != nil || !redactValue(doc) {
+		return data
+	}
+	redacted, err := json.Marshal(doc)
+	if err != nil {
+		return data
+	}
+	return redacted
+}
+
+// redactValue redacts the sensitive fields of a decoded JSON value in place and reports whether there were any
+func redactValue(v any) bool {
+	redacted := false
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if sensitiveFields[key] {
+				v[key], redacted = Redacted, true
+				continue
+			}
+			redacted = redactValue(value) || redacted
+		}
+	case []any:
+		for _, item := range v {
+			redacted = redactValue(item) || redacted
+		}
+	}
+	return redacted
+}
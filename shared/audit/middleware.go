@@ -0,0 +1,173 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// maxBody is how much of a request or response body is kept in an entry
+const maxBody = 64 << 10
+
+// Loader loads the current state of the resource a route changes, from the route variables. It
+// returns nil when the resource does not exist.
+type Loader func(ctx context.Context, vars map[string]string) (any, error)
+
+// Track registers how to load the resource changed through the route with the given path
+// template, so that its state before and after every call is recorded. Calls to other routes
+// record the response body as the state after.
+func (r *Recorder) Track(template string, load Loader) {
+	r.loaders[template] = load
+}
+
+// Middleware records every call that can change state, i.e. any method but GET, HEAD and OPTIONS.
+// It must be added with Router.Use so that the matched route is known. The caller is the X-Actor
+// header and the source IP the first X-Forwarded-For address, both set by the gateway in front of
+// the services; every response carries the request's X-Request-ID, generated when missing.
+func (r *Recorder) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requestID := req.Header.Get("X-Request-ID")
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+		w.Header().Set("X-Request-ID", requestID)
+		if req.Method == http.MethodGet || req.Method == http.MethodHead || req.Method == http.MethodOptions {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		template := req.URL.Path
+		if route := mux.CurrentRoute(req); route != nil {
+			if t, err := route.GetPathTemplate(); err == nil {
+				template = t
+			}
+		}
+		vars := mux.Vars(req)
+		entry := Entry{
+			ID:         uuid.NewString(),
+			OccurredAt: time.Now(),
+			Kind:       KindAPI,
+			Action:     req.Method + " " + template,
+			Resource:   req.URL.Path,
+			Actor:      actor(req),
+			RequestID:  requestID,
+			SourceIP:   sourceIP(req),
+			Request:    readRequest(req),
+		}
+		load := r.loaders[template]
+		if load != nil {
+			entry.Before = r.load(req.Context(), load, vars)
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, req)
+
+		entry.Outcome = strconv.Itoa(recorder.status)
+		switch {
+		case load != nil:
+			entry.After = r.load(req.Context(), load, vars)
+		case recorder.status < http.StatusMultipleChoices:
+			entry.After = describe(recorder.Header().Get("Content-Type"), recorder.body.Bytes(), recorder.truncated, -1)
+		}
+		r.Record(entry)
+	})
+}
+
+// load runs a loader, logging rather than failing the call when it does not work
+func (r *Recorder) load(ctx context.Context, load Loader, vars map[string]string) json.RawMessage {
+	state, err := load(ctx, vars)
+	if err != nil {
+		(*r.loggs).Error("Failed to load audited resource", "Error", err)
+		return nil
+	}
+	return r.marshal(state)
+}
+
+// readRequest keeps the start of the request body and puts it back for the handler
+func readRequest(req *http.Request) json.RawMessage {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	head, err := io.ReadAll(io.LimitReader(req.Body, maxBody+1))
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), req.Body), req.Body}
+	if err != nil {
+		return nil
+	}
+	truncated := len(head) > maxBody
+	if truncated {
+		head = head[:maxBody]
+	}
+	return describe(req.Header.Get("Content-Type"), head, truncated, req.ContentLength)
+}
+
+// describe keeps a body as it is when it is JSON, and otherwise records what it was
+func describe(contentType string, body []byte, truncated bool, length int64) json.RawMessage {
+	if len(body) == 0 {
+		return nil
+	}
+	if !truncated && json.Valid(body) {
+		return body
+	}
+	if length < 0 {
+		length = int64(len(body))
+	}
+	summary, _ := json.Marshal(map[string]any{"content_type": contentType, "bytes": length, "truncated": truncated})
+	return summary
+}
+
+func actor(req *http.Request) string {
+	if actor := strings.TrimSpace(req.Header.Get("X-Actor")); actor != "" {
+		return actor
+	}
+	return "anonymous"
+}
+
+func sourceIP(req *http.Request) string {
+	if forwarded := req.Header.Get("X-Forwarded-For"); forwarded != "" {
+		first, _, _ := strings.Cut(forwarded, ",")
+		return strings.TrimSpace(first)
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// responseRecorder passes a response through while keeping its status and the start of its body
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	truncated   bool
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status, r.wroteHeader = status, true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	kept := p
+	if room := maxBody - r.body.Len(); len(p) > room {
+		kept, r.truncated = p[:room], true
+	}
+	r.body.Write(kept)
+	return r.ResponseWriter.Write(p)
+}
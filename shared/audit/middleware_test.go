@@ -0,0 +1,124 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type publisher struct {
+	entries []Entry
+}
+
+func (p *publisher) PushKeyedToQueue(topic, key string, message []byte) error {
+	var entry Entry
+	if err := json.Unmarshal(message, &entry); err != nil {
+		return err
+	}
+	p.entries = append(p.entries, entry)
+	return nil
+}
+
+func newRouter(t *testing.T) (*mux.Router, *publisher) {
+	t.Helper()
+	tiers := map[string]string{"gold": "old"}
+	logger := hclog.NewNullLogger()
+	published := &publisher{}
+	recorder := NewRecorder("test", published, &logger)
+	recorder.Track("/tiers/{name}", func(ctx context.Context, vars map[string]string) (any, error) {
+		if value, ok := tiers[vars["name"]]; ok {
+			return map[string]string{"value": value}, nil
+		}
+		return nil, nil
+	})
+
+	router := mux.NewRouter()
+	router.Use(recorder.Middleware)
+	router.HandleFunc("/tiers/{name}", func(w http.ResponseWriter, r *http.Request) {
+		tiers[mux.Vars(r)["name"]] = "new"
+		w.WriteHeader(http.StatusNoContent)
+	}).Methods("PUT")
+	router.HandleFunc("/tiers/{name}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"value":"` + tiers[mux.Vars(r)["name"]] + `"}`))
+	}).Methods("GET")
+	router.HandleFunc("/notes", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":1}`))
+	}).Methods("POST")
+	return router, published
+}
+
+// TestTrackedCallRecordsBeforeAndAfter tests that a call to a tracked route records who made it and the resource on both sides
+func TestTrackedCallRecordsBeforeAndAfter(t *testing.T) {
+	router, published := newRouter(t)
+
+	req := httptest.NewRequest("PUT", "/tiers/gold", strings.NewReader(`{"value":"new"}`))
+	req.Header.Set("X-Actor", "ops@bank")
+	req.Header.Set("X-Request-ID", "req-1")
+	req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Len(t, published.entries, 1)
+	entry := published.entries[0]
+	assert.Equal(t, "test", entry.Service)
+	assert.Equal(t, KindAPI, entry.Kind)
+	assert.Equal(t, "PUT /tiers/{name}", entry.Action)
+	assert.Equal(t, "/tiers/gold", entry.Resource)
+	assert.Equal(t, "ops@bank", entry.Actor)
+	assert.Equal(t, "req-1", entry.RequestID)
+	assert.Equal(t, "203.0.113.7", entry.SourceIP)
+	assert.Equal(t, "204", entry.Outcome)
+	assert.JSONEq(t, `{"value":"new"}`, string(entry.Request))
+	assert.JSONEq(t, `{"value":"old"}`, string(entry.Before))
+	assert.JSONEq(t, `{"value":"new"}`, string(entry.After))
+	assert.Equal(t, "req-1", rec.Header().Get("X-Request-ID"))
+}
+
+// TestUntrackedCallRecordsResponse tests that reads are not recorded and other calls keep their response as the state after
+func TestUntrackedCallRecordsResponse(t *testing.T) {
+	router, published := newRouter(t)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/tiers/gold", nil))
+	assert.Empty(t, published.entries)
+	assert.NotEmpty(t, rec.Header().Get("X-Request-ID"))
+
+	req := httptest.NewRequest("POST", "/notes", strings.NewReader("not json"))
+	req.Header.Set("Content-Type", "text/plain")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	require.Len(t, published.entries, 1)
+	entry := published.entries[0]
+	assert.Equal(t, "anonymous", entry.Actor)
+	assert.Equal(t, "192.0.2.1", entry.SourceIP)
+	assert.Equal(t, "201", entry.Outcome)
+	assert.Nil(t, entry.Before)
+	assert.JSONEq(t, `{"id":1}`, string(entry.After))
+	assert.JSONEq(t, `{"content_type":"text/plain","bytes":8,"truncated":false}`, string(entry.Request))
+}
+
+// TestPersonalDataIsRedacted tests that KYC fields are kept out of the request and states wherever they appear
+func TestPersonalDataIsRedacted(t *testing.T) {
+	router, published := newRouter(t)
+
+	body := `{"full_name":"Jane Doe","date_of_birth":"1990-01-01","documents":[{"id_document_number":"P1234567"}],"amount":12.50}`
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/notes", strings.NewReader(body)))
+	require.Len(t, published.entries, 1)
+	entry := published.entries[0]
+	assert.JSONEq(t, `{"full_name":"Jane Doe","date_of_birth":"[redacted]","documents":[{"id_document_number":"[redacted]"}],"amount":12.50}`, string(entry.Request))
+	assert.NotContains(t, string(entry.Request), "1990-01-01")
+	assert.Equal(t, `{"id":1}`, string(entry.After), "documents without personal data are kept as they are")
+
+	logger := hclog.NewNullLogger()
+	recorder := NewRecorder("test", published, &logger)
+	recorder.Consumed("customers-0-7", "customer.updated", "customer/1", "ok", nil, map[string]string{"phone": "+44 20 7946 0000"})
+	assert.JSONEq(t, `{"phone":"[redacted]"}`, string(published.entries[1].After))
+}
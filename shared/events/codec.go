@@ -0,0 +1,195 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Headers are the headers of a Kafka record
+type Headers map[string]string
+
+// Record headers. A JSON record carries the whole envelope in its value, so it only has the
+// content type; a Protobuf record carries the payload alone and the rest of the envelope here.
+const (
+	ContentTypeHeader = "content-type"
+	TypeHeader        = "event-type"
+	VersionHeader     = "event-version"
+	IDHeader          = "event-id"
+	TimeHeader        = "event-time"
+	ProducerHeader    = "event-producer"
+	TraceParentHeader = "traceparent"
+	TraceStateHeader  = "tracestate"
+)
+
+// ErrNoEncoding is returned by a codec for an event type that has no form in its encoding
+var ErrNoEncoding = errors.New("no encoding for event")
+
+// Codec encodes envelopes into Kafka records and decodes them back
+type Codec interface {
+	// ContentType is the value of the content-type header of the records the codec encodes
+	ContentType() string
+	// Encode encodes an envelope into a record value and its headers
+	Encode(env *Envelope) ([]byte, Headers, error)
+	// Decode decodes a record carrying an event of eventType into an envelope with a JSON payload,
+	// at the version it was published at
+	Decode(eventType string, data []byte, headers Headers) (*Envelope, error)
+}
+
+// codecs are the codecs by the name UseCodec takes
+var codecs = map[string]Codec{
+	"json":     JSONCodec{},
+	"protobuf": ProtobufCodec{},
+}
+
+// codec is the codec events are published with
+var codec Codec = JSONCodec{}
+
+// UseCodec sets the codec events are published with by name, "json" or "protobuf". Events of a type
+// without a form in the codec are published as JSON. Consumers read either whatever the codec.
+func UseCodec(name string) error {
+	c, ok := codecs[name]
+	if !ok {
+		return fmt.Errorf("unknown event codec %q", name)
+	}
+	codec = c
+	return nil
+}
+
+// codecFor returns the codec that encoded a record, by its content-type header
+func codecFor(headers Headers) (Codec, error) {
+	contentType, ok := headers[ContentTypeHeader]
+	if !ok {
+		return JSONCodec{}, nil
+	}
+	for _, c := range codecs {
+		if c.ContentType() == contentType {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: unknown content type %q", ErrInvalid, contentType)
+}
+
+// JSONCodec encodes the envelope as a JSON object, validated against schemas/envelope.json
+type JSONCodec struct{}
+
+// ContentType implements Codec
+func (JSONCodec) ContentType() string { return "application/json" }
+
+// Encode implements Codec
+func (c JSONCodec) Encode(env *Envelope) ([]byte, Headers, error) {
+	data, err := json.Marshal(env)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, Headers{ContentTypeHeader: c.ContentType()}, nil
+}
+
+// Decode implements Codec. A bare payload, published before events had envelopes, is taken as
+// version 0 of eventType.
+func (JSONCodec) Decode(eventType string, data []byte, _ Headers) (*Envelope, error) {
+	var env Envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if env.Type == "" && env.Payload == nil {
+		return &Envelope{Type: eventType, Payload: data}, nil
+	}
+	if err := validate("envelope", 0, data); err != nil {
+		return nil, err
+	}
+	return &env, nil
+}
+
+// messages makes the Protobuf message of each version of the event types with a Protobuf form,
+// generated from schemas/events.v1.proto
+var messages = map[string]map[int]func() proto.Message{
+	AccountOpenRequested: {1: func() proto.Message { return &Account{} }},
+	TransactionRequested: {1: func() proto.Message { return &Transaction{} }},
+	TransactionPosted:    {1: func() proto.Message { return &Transaction{} }},
+}
+
+// ProtobufCodec encodes the payload as its Protobuf message and the rest of the envelope as headers
+type ProtobufCodec struct{}
+
+// ContentType implements Codec
+func (ProtobufCodec) ContentType() string { return "application/x-protobuf" }
+
+// Encode implements Codec. It returns ErrNoEncoding for an event type without a Protobuf form.
+func (c ProtobufCodec) Encode(env *Envelope) ([]byte, Headers, error) {
+	newMessage := messages[env.Type][env.Version]
+	if newMessage == nil {
+		return nil, nil, fmt.Errorf("%w: %s v%d in Protobuf", ErrNoEncoding, env.Type, env.Version)
+	}
+	m := newMessage()
+	if err := fromJSON(m.ProtoReflect(), env.Payload); err != nil {
+		return nil, nil, fmt.Errorf("%w: %s v%d: %v", ErrInvalid, env.Type, env.Version, err)
+	}
+	data, err := proto.Marshal(m)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	headers := Headers{
+		ContentTypeHeader: c.ContentType(),
+		TypeHeader:        env.Type,
+		VersionHeader:     strconv.Itoa(env.Version),
+		IDHeader:          env.ID,
+		TimeHeader:        env.Time.Format(time.RFC3339Nano),
+		ProducerHeader:    env.Producer,
+		TraceParentHeader: env.Trace.Parent,
+	}
+	if env.Trace.State != "" {
+		headers[TraceStateHeader] = env.Trace.State
+	}
+	return data, headers, nil
+}
+
+// Decode implements Codec
+func (ProtobufCodec) Decode(eventType string, data []byte, headers Headers) (*Envelope, error) {
+	env := &Envelope{
+		Type:     headers[TypeHeader],
+		ID:       headers[IDHeader],
+		Producer: headers[ProducerHeader],
+		Trace:    Trace{Parent: headers[TraceParentHeader], State: headers[TraceStateHeader]},
+	}
+	var err error
+	if env.Version, err = strconv.Atoi(headers[VersionHeader]); err != nil {
+		return nil, fmt.Errorf("%w: %s header: %v", ErrInvalid, VersionHeader, err)
+	}
+	if env.Time, err = time.Parse(time.RFC3339Nano, headers[TimeHeader]); err != nil {
+		return nil, fmt.Errorf("%w: %s header: %v", ErrInvalid, TimeHeader, err)
+	}
+	if env.Type != eventType {
+		return env, nil // refused by Decode
+	}
+
+	newMessage := messages[env.Type][env.Version]
+	if newMessage == nil {
+		if env.Version > versions[env.Type] {
+			return env, nil // left to Decode to report
+		}
+		return nil, fmt.Errorf("%w: %s v%d has no Protobuf form", ErrInvalid, env.Type, env.Version)
+	}
+	m := newMessage()
+	if err := proto.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if env.Payload, err = json.Marshal(toJSON(m.ProtoReflect())); err != nil {
+		return nil, err
+	}
+
+	// the headers are held to the same schema as a JSON envelope
+	encoded, err := json.Marshal(env)
+	if err != nil {
+		return nil, err
+	}
+	if err := validate("envelope", 0, encoded); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
@@ -0,0 +1,156 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// The Protobuf messages mirror the JSON payloads field for field, so a payload converts by field
+// name. Optional fields, of explicit presence, are set only when the payload has them; the others
+// are always in the payload, so they are converted back even when zero. Timestamps are RFC 3339
+// strings in JSON. protojson is not used as it writes 64-bit integers as strings.
+
+var timestampName = (&timestamppb.Timestamp{}).ProtoReflect().Descriptor().FullName()
+
+// fromJSON sets the fields of m from a JSON payload
+func fromJSON(m protoreflect.Message, data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var obj map[string]any
+	if err := decoder.Decode(&obj); err != nil {
+		return err
+	}
+	return setFields(m, obj)
+}
+
+func setFields(m protoreflect.Message, obj map[string]any) error {
+	fields := m.Descriptor().Fields()
+	for name, v := range obj {
+		fd := fields.ByName(protoreflect.Name(name))
+		if fd == nil {
+			return fmt.Errorf("no field %s in %s", name, m.Descriptor().FullName())
+		}
+		if v == nil {
+			continue
+		}
+		if !fd.IsList() {
+			value, err := protoValue(m, fd, v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			m.Set(fd, value)
+			continue
+		}
+		items, ok := v.([]any)
+		if !ok {
+			return fmt.Errorf("%s: not an array", name)
+		}
+		list := m.Mutable(fd).List()
+		for _, item := range items {
+			value, err := protoValue(m, fd, item)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			list.Append(value)
+		}
+	}
+	return nil
+}
+
+// protoValue converts a JSON value to a value (or list element) of the field fd of m
+func protoValue(m protoreflect.Message, fd protoreflect.FieldDescriptor, v any) (protoreflect.Value, error) {
+	var err error
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		if s, ok := v.(string); ok {
+			return protoreflect.ValueOfString(s), nil
+		}
+	case protoreflect.BoolKind:
+		if b, ok := v.(bool); ok {
+			return protoreflect.ValueOfBool(b), nil
+		}
+	case protoreflect.DoubleKind:
+		if n, ok := v.(json.Number); ok {
+			var f float64
+			if f, err = n.Float64(); err == nil {
+				return protoreflect.ValueOfFloat64(f), nil
+			}
+		}
+	case protoreflect.Int64Kind:
+		if n, ok := v.(json.Number); ok {
+			var i int64
+			if i, err = n.Int64(); err == nil {
+				return protoreflect.ValueOfInt64(i), nil
+			}
+		}
+	case protoreflect.MessageKind:
+		if fd.Message().FullName() == timestampName {
+			if s, ok := v.(string); ok {
+				var t time.Time
+				if t, err = time.Parse(time.RFC3339Nano, s); err == nil {
+					return protoreflect.ValueOfMessage(timestamppb.New(t).ProtoReflect()), nil
+				}
+			}
+			break
+		}
+		if obj, ok := v.(map[string]any); ok {
+			var sub protoreflect.Message
+			if fd.IsList() {
+				sub = m.Mutable(fd).List().NewElement().Message()
+			} else {
+				sub = m.NewField(fd).Message()
+			}
+			if err = setFields(sub, obj); err == nil {
+				return protoreflect.ValueOfMessage(sub), nil
+			}
+		}
+	default:
+		return protoreflect.Value{}, fmt.Errorf("unsupported field kind %s", fd.Kind())
+	}
+	if err != nil {
+		return protoreflect.Value{}, err
+	}
+	return protoreflect.Value{}, fmt.Errorf("%v is not a %s", v, fd.Kind())
+}
+
+// toJSON converts m back to the JSON payload it mirrors
+func toJSON(m protoreflect.Message) map[string]any {
+	obj := make(map[string]any)
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		switch {
+		case fd.IsList():
+			list := m.Get(fd).List()
+			if list.Len() == 0 {
+				continue
+			}
+			items := make([]any, list.Len())
+			for j := range items {
+				items[j] = jsonValue(fd, list.Get(j))
+			}
+			obj[string(fd.Name())] = items
+		case fd.HasPresence() && !m.Has(fd):
+			continue
+		default:
+			obj[string(fd.Name())] = jsonValue(fd, m.Get(fd))
+		}
+	}
+	return obj
+}
+
+func jsonValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	if fd.Kind() != protoreflect.MessageKind {
+		return v.Interface()
+	}
+	if fd.Message().FullName() == timestampName {
+		t := v.Message().Interface().(*timestamppb.Timestamp)
+		return t.AsTime().Format(time.RFC3339Nano)
+	}
+	return toJSON(v.Message())
+}
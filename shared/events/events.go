@@ -0,0 +1,279 @@
+// Package events wraps every message published on Kafka in a versioned envelope and checks its
+// payload against the JSON Schema of its type and version in schemas/. Each topic carries one
+// event type, as set by the topic map in topics.json. Consumers upcast payloads of older versions to the version they understand, so a
+// producer can move to a new version before its consumers do. Events are encoded as JSON, or as
+// Protobuf for the event types in schemas/events.v1.proto; consumers read both. Every service
+// uses this one package from the shared module.
+package events
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative -I schemas schemas/events.v1.proto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Event types
+const (
+	AccountOpenRequested      = "account.open_requested"
+	TransactionRequested      = "transaction.requested"
+	TransactionPosted         = "transaction.posted"
+	TransactionRejected       = "transaction.rejected"
+	HoldChanged               = "hold.changed"
+	ExternalTransferRequested = "external_transfer.requested"
+	ClearingStatusReported    = "clearing.status_reported"
+	AuditEntryRecorded        = "audit.entry_recorded"
+	LedgerCheckpointSigned    = "ledger.checkpoint_signed"
+)
+
+// versions is the version every event type is published at, and the version consumers upcast to
+var versions = map[string]int{
+	AccountOpenRequested:      1,
+	TransactionRequested:      1,
+	TransactionPosted:         1,
+	TransactionRejected:       1,
+	HoldChanged:               1,
+	ExternalTransferRequested: 1,
+	ClearingStatusReported:    1,
+	AuditEntryRecorded:        1,
+	LedgerCheckpointSigned:    1,
+}
+
+var (
+	// ErrUnknownTopic is returned for a topic that carries no known event type
+	ErrUnknownTopic = errors.New("no event type is published on topic")
+	// ErrInvalid is returned for an event that does not match its schema
+	ErrInvalid = errors.New("invalid event")
+	// ErrUnsupportedVersion is returned for an event newer than the version this service knows.
+	// It can be consumed once the service is upgraded, so it should not be skipped.
+	ErrUnsupportedVersion = errors.New("unsupported event version")
+)
+
+// Producer names the service publishing events; main sets it
+var Producer = filepath.Base(os.Args[0])
+
+// Envelope carries an event
+type Envelope struct {
+	Type     string          `json:"type"`
+	Version  int             `json:"version"`
+	ID       string          `json:"id"`
+	Time     time.Time       `json:"time"`
+	Producer string          `json:"producer"`
+	Trace    Trace           `json:"trace"`
+	Payload  json.RawMessage `json:"payload"`
+}
+
+// Trace is the W3C trace context of the work an event belongs to
+type Trace struct {
+	Parent string `json:"traceparent"`          // version-traceid-spanid-flags
+	State  string `json:"tracestate,omitempty"` // Vendor specific trace data, passed on as is
+}
+
+var traceParent = regexp.MustCompile(`^00-([0-9a-f]{32})-[0-9a-f]{16}-([0-9a-f]{2})$`)
+
+// NewTrace starts a trace
+func NewTrace() Trace {
+	return Trace{Parent: fmt.Sprintf("00-%s-%s-01", randomHex(16), randomHex(8))}
+}
+
+// Child continues the trace in a new span, for the events published while handling an event. An
+// empty or malformed trace starts a new one.
+func (t Trace) Child() Trace {
+	m := traceParent.FindStringSubmatch(t.Parent)
+	if m == nil {
+		return NewTrace()
+	}
+	return Trace{Parent: fmt.Sprintf("00-%s-%s-%s", m[1], randomHex(8), m[2]), State: t.State}
+}
+
+// TraceID returns the ID shared by every span of the trace
+func (t Trace) TraceID() string {
+	if m := traceParent.FindStringSubmatch(t.Parent); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// newID returns a random (version 4) UUID
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// TypeOf returns the type of the events published on the topic with the ID topic
+func TypeOf(topic string) (string, error) {
+	spec, ok := topics[topic]
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownTopic, topic)
+	}
+	return spec.Event, nil
+}
+
+// Encode puts a payload to publish on topic in an envelope at the current version of the topic's
+// event type, after checking it against the schema of that version, and encodes it with the codec
+// set by UseCodec. It returns the record value and its headers. An empty trace starts a new one.
+func Encode(topic string, payload []byte, trace Trace) ([]byte, Headers, error) {
+	eventType, err := TypeOf(topic)
+	if err != nil {
+		return nil, nil, err
+	}
+	version := versions[eventType]
+	if err := validate(eventType, version, payload); err != nil {
+		return nil, nil, err
+	}
+	if trace.Parent == "" {
+		trace = NewTrace()
+	}
+	env := &Envelope{
+		Type:     eventType,
+		Version:  version,
+		ID:       newID(),
+		Time:     time.Now().UTC(),
+		Producer: Producer,
+		Trace:    trace,
+		Payload:  payload,
+	}
+	value, headers, err := codec.Encode(env)
+	if errors.Is(err, ErrNoEncoding) {
+		return JSONCodec{}.Encode(env)
+	}
+	return value, headers, err
+}
+
+// Decode unwraps a message consumed from topic with the codec named by its content-type header,
+// upcasting its payload to the version this service knows and checking it against that version's
+// schema. A message without the header is JSON; a bare payload, published before events had
+// envelopes, is taken as version 0.
+func Decode(topic string, data []byte, headers Headers) (*Envelope, error) {
+	eventType, err := TypeOf(topic)
+	if err != nil {
+		return nil, err
+	}
+	decoder, err := codecFor(headers)
+	if err != nil {
+		return nil, err
+	}
+	env, err := decoder.Decode(eventType, data, headers)
+	if err != nil {
+		return nil, err
+	}
+	if env.Type != eventType {
+		return nil, fmt.Errorf("%w: %s event on %s, which carries %s", ErrInvalid, env.Type, topic, eventType)
+	}
+
+	version := versions[eventType]
+	if env.Version > version {
+		return nil, fmt.Errorf("%w: %s v%d, this service knows up to v%d", ErrUnsupportedVersion, eventType, env.Version, version)
+	}
+	for ; env.Version < version; env.Version++ {
+		upcast := upcasters[eventType][env.Version]
+		if upcast == nil {
+			return nil, fmt.Errorf("%w: no upcast from %s v%d", ErrInvalid, eventType, env.Version)
+		}
+		if env.Payload, err = upcast(env.Payload); err != nil {
+			return nil, fmt.Errorf("%w: upcasting %s v%d: %v", ErrInvalid, eventType, env.Version, err)
+		}
+	}
+	if err := validate(eventType, version, env.Payload); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+// Unmarshal decodes a message consumed from topic into v, see Decode
+func Unmarshal(topic string, data []byte, headers Headers, v any) (*Envelope, error) {
+	env, err := Decode(topic, data, headers)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(env.Payload, v); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	return env, nil
+}
+
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// schemas holds the compiled schemas by file name, e.g. "transaction.posted.v1.json"
+var schemas = compileSchemas()
+
+func compileSchemas() map[string]*jsonschema.Schema {
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+	compiler.AssertFormat = true
+	names, err := fs.Glob(schemaFiles, "schemas/*.json")
+	if err != nil {
+		panic(err)
+	}
+	for _, name := range names {
+		data, err := schemaFiles.ReadFile(name)
+		if err != nil {
+			panic(err)
+		}
+		if err := compiler.AddResource(schemaURL(filepath.Base(name)), bytes.NewReader(data)); err != nil {
+			panic(fmt.Sprintf("events: %s: %v", name, err))
+		}
+	}
+
+	compiled := make(map[string]*jsonschema.Schema)
+	for _, name := range names {
+		schema, err := compiler.Compile(schemaURL(filepath.Base(name)))
+		if err != nil {
+			panic(fmt.Sprintf("events: %s: %v", name, err))
+		}
+		compiled[filepath.Base(name)] = schema
+	}
+	return compiled
+}
+
+func schemaURL(name string) string {
+	return "file:///events/schemas/" + name
+}
+
+// schemaName names the schema file of a version of an event type, or of the envelope
+func schemaName(eventType string, version int) string {
+	if eventType == "envelope" {
+		return "envelope.json"
+	}
+	return fmt.Sprintf("%s.v%d.json", eventType, version)
+}
+
+// validate checks data against the schema of a version of an event type
+func validate(eventType string, version int, data []byte) error {
+	schema, ok := schemas[schemaName(eventType, version)]
+	if !ok {
+		return fmt.Errorf("%w: no schema for %s v%d", ErrInvalid, eventType, version)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber() // as the schemas were read, so that integers are told from numbers
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if err := schema.Validate(v); err != nil {
+		return fmt.Errorf("%w: %s v%d: %v", ErrInvalid, eventType, version, err)
+	}
+	return nil
+}
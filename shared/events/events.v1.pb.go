@@ -0,0 +1,726 @@
+// Protobuf form of the events on "account-creation", "transaction" and "transaction-ledger", for
+// producers publishing with EVENT_CODEC=protobuf. Each message mirrors the v1 JSON Schema of its
+// event type field for field: fields that are optional there are optional here, so that a payload
+// converts between the two forms unchanged. The envelope travels in the record headers.
+//
+// Regenerate events.v1.pb.go with go generate in the events package.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: events.v1.proto
+
+package events
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// An account to open (account.open_requested)
+type Account struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	AccountNumber string                 `protobuf:"bytes,2,opt,name=account_number,json=accountNumber,proto3" json:"account_number,omitempty"`
+	Username      string                 `protobuf:"bytes,3,opt,name=username,proto3" json:"username,omitempty"`
+	Email         string                 `protobuf:"bytes,4,opt,name=email,proto3" json:"email,omitempty"`
+	Balance       float64                `protobuf:"fixed64,5,opt,name=balance,proto3" json:"balance,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	IsActive      bool                   `protobuf:"varint,8,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	Tier          string                 `protobuf:"bytes,9,opt,name=tier,proto3" json:"tier,omitempty"`
+	Currency      string                 `protobuf:"bytes,10,opt,name=currency,proto3" json:"currency,omitempty"`
+	CustomerId    *string                `protobuf:"bytes,11,opt,name=customer_id,json=customerId,proto3,oneof" json:"customer_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Account) Reset() {
+	*x = Account{}
+	mi := &file_events_v1_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Account) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Account) ProtoMessage() {}
+
+func (x *Account) ProtoReflect() protoreflect.Message {
+	mi := &file_events_v1_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Account.ProtoReflect.Descriptor instead.
+func (*Account) Descriptor() ([]byte, []int) {
+	return file_events_v1_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Account) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Account) GetAccountNumber() string {
+	if x != nil {
+		return x.AccountNumber
+	}
+	return ""
+}
+
+func (x *Account) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *Account) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *Account) GetBalance() float64 {
+	if x != nil {
+		return x.Balance
+	}
+	return 0
+}
+
+func (x *Account) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Account) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Account) GetIsActive() bool {
+	if x != nil {
+		return x.IsActive
+	}
+	return false
+}
+
+func (x *Account) GetTier() string {
+	if x != nil {
+		return x.Tier
+	}
+	return ""
+}
+
+func (x *Account) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Account) GetCustomerId() string {
+	if x != nil && x.CustomerId != nil {
+		return *x.CustomerId
+	}
+	return ""
+}
+
+// A transaction as requested (transaction.requested) or posted (transaction.posted)
+type Transaction struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Id                    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	FromAccountId         string                 `protobuf:"bytes,2,opt,name=from_account_id,json=fromAccountId,proto3" json:"from_account_id,omitempty"`
+	ToAccountId           string                 `protobuf:"bytes,3,opt,name=to_account_id,json=toAccountId,proto3" json:"to_account_id,omitempty"`
+	Amount                float64                `protobuf:"fixed64,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	TransactionType       string                 `protobuf:"bytes,5,opt,name=transaction_type,json=transactionType,proto3" json:"transaction_type,omitempty"`
+	Description           string                 `protobuf:"bytes,6,opt,name=description,proto3" json:"description,omitempty"`
+	CreatedAt             *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Status                string                 `protobuf:"bytes,8,opt,name=status,proto3" json:"status,omitempty"`
+	OriginalTransactionId *string                `protobuf:"bytes,9,opt,name=original_transaction_id,json=originalTransactionId,proto3,oneof" json:"original_transaction_id,omitempty"`
+	Currency              *string                `protobuf:"bytes,10,opt,name=currency,proto3,oneof" json:"currency,omitempty"`
+	FxRate                *float64               `protobuf:"fixed64,11,opt,name=fx_rate,json=fxRate,proto3,oneof" json:"fx_rate,omitempty"`
+	ConvertedAmount       *float64               `protobuf:"fixed64,12,opt,name=converted_amount,json=convertedAmount,proto3,oneof" json:"converted_amount,omitempty"`
+	ConvertedCurrency     *string                `protobuf:"bytes,13,opt,name=converted_currency,json=convertedCurrency,proto3,oneof" json:"converted_currency,omitempty"`
+	Fees                  []*Fee                 `protobuf:"bytes,14,rep,name=fees,proto3" json:"fees,omitempty"`
+	InitiatedBy           *string                `protobuf:"bytes,15,opt,name=initiated_by,json=initiatedBy,proto3,oneof" json:"initiated_by,omitempty"`
+	PayeeId               *string                `protobuf:"bytes,16,opt,name=payee_id,json=payeeId,proto3,oneof" json:"payee_id,omitempty"`
+	BatchId               *string                `protobuf:"bytes,17,opt,name=batch_id,json=batchId,proto3,oneof" json:"batch_id,omitempty"`
+	Creditor              *Party                 `protobuf:"bytes,18,opt,name=creditor,proto3" json:"creditor,omitempty"`
+	Debtor                *Party                 `protobuf:"bytes,19,opt,name=debtor,proto3" json:"debtor,omitempty"`
+	Reference             *string                `protobuf:"bytes,20,opt,name=reference,proto3,oneof" json:"reference,omitempty"`
+	ClearingRef           *string                `protobuf:"bytes,21,opt,name=clearing_ref,json=clearingRef,proto3,oneof" json:"clearing_ref,omitempty"`
+	Postings              []*Posting             `protobuf:"bytes,22,rep,name=postings,proto3" json:"postings,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *Transaction) Reset() {
+	*x = Transaction{}
+	mi := &file_events_v1_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Transaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Transaction) ProtoMessage() {}
+
+func (x *Transaction) ProtoReflect() protoreflect.Message {
+	mi := &file_events_v1_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Transaction.ProtoReflect.Descriptor instead.
+func (*Transaction) Descriptor() ([]byte, []int) {
+	return file_events_v1_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Transaction) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Transaction) GetFromAccountId() string {
+	if x != nil {
+		return x.FromAccountId
+	}
+	return ""
+}
+
+func (x *Transaction) GetToAccountId() string {
+	if x != nil {
+		return x.ToAccountId
+	}
+	return ""
+}
+
+func (x *Transaction) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Transaction) GetTransactionType() string {
+	if x != nil {
+		return x.TransactionType
+	}
+	return ""
+}
+
+func (x *Transaction) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Transaction) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Transaction) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Transaction) GetOriginalTransactionId() string {
+	if x != nil && x.OriginalTransactionId != nil {
+		return *x.OriginalTransactionId
+	}
+	return ""
+}
+
+func (x *Transaction) GetCurrency() string {
+	if x != nil && x.Currency != nil {
+		return *x.Currency
+	}
+	return ""
+}
+
+func (x *Transaction) GetFxRate() float64 {
+	if x != nil && x.FxRate != nil {
+		return *x.FxRate
+	}
+	return 0
+}
+
+func (x *Transaction) GetConvertedAmount() float64 {
+	if x != nil && x.ConvertedAmount != nil {
+		return *x.ConvertedAmount
+	}
+	return 0
+}
+
+func (x *Transaction) GetConvertedCurrency() string {
+	if x != nil && x.ConvertedCurrency != nil {
+		return *x.ConvertedCurrency
+	}
+	return ""
+}
+
+func (x *Transaction) GetFees() []*Fee {
+	if x != nil {
+		return x.Fees
+	}
+	return nil
+}
+
+func (x *Transaction) GetInitiatedBy() string {
+	if x != nil && x.InitiatedBy != nil {
+		return *x.InitiatedBy
+	}
+	return ""
+}
+
+func (x *Transaction) GetPayeeId() string {
+	if x != nil && x.PayeeId != nil {
+		return *x.PayeeId
+	}
+	return ""
+}
+
+func (x *Transaction) GetBatchId() string {
+	if x != nil && x.BatchId != nil {
+		return *x.BatchId
+	}
+	return ""
+}
+
+func (x *Transaction) GetCreditor() *Party {
+	if x != nil {
+		return x.Creditor
+	}
+	return nil
+}
+
+func (x *Transaction) GetDebtor() *Party {
+	if x != nil {
+		return x.Debtor
+	}
+	return nil
+}
+
+func (x *Transaction) GetReference() string {
+	if x != nil && x.Reference != nil {
+		return *x.Reference
+	}
+	return ""
+}
+
+func (x *Transaction) GetClearingRef() string {
+	if x != nil && x.ClearingRef != nil {
+		return *x.ClearingRef
+	}
+	return ""
+}
+
+func (x *Transaction) GetPostings() []*Posting {
+	if x != nil {
+		return x.Postings
+	}
+	return nil
+}
+
+// A fee charged on top of a transaction
+type Fee struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RuleId        int64                  `protobuf:"varint,1,opt,name=rule_id,json=ruleId,proto3" json:"rule_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Amount        float64                `protobuf:"fixed64,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	Currency      string                 `protobuf:"bytes,4,opt,name=currency,proto3" json:"currency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Fee) Reset() {
+	*x = Fee{}
+	mi := &file_events_v1_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Fee) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Fee) ProtoMessage() {}
+
+func (x *Fee) ProtoReflect() protoreflect.Message {
+	mi := &file_events_v1_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Fee.ProtoReflect.Descriptor instead.
+func (*Fee) Descriptor() ([]byte, []int) {
+	return file_events_v1_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Fee) GetRuleId() int64 {
+	if x != nil {
+		return x.RuleId
+	}
+	return 0
+}
+
+func (x *Fee) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Fee) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Fee) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+// An account holder at another bank
+type Party struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Iban          *string                `protobuf:"bytes,2,opt,name=iban,proto3,oneof" json:"iban,omitempty"`
+	Account       *string                `protobuf:"bytes,3,opt,name=account,proto3,oneof" json:"account,omitempty"`
+	Bic           *string                `protobuf:"bytes,4,opt,name=bic,proto3,oneof" json:"bic,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Party) Reset() {
+	*x = Party{}
+	mi := &file_events_v1_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Party) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Party) ProtoMessage() {}
+
+func (x *Party) ProtoReflect() protoreflect.Message {
+	mi := &file_events_v1_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Party.ProtoReflect.Descriptor instead.
+func (*Party) Descriptor() ([]byte, []int) {
+	return file_events_v1_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Party) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Party) GetIban() string {
+	if x != nil && x.Iban != nil {
+		return *x.Iban
+	}
+	return ""
+}
+
+func (x *Party) GetAccount() string {
+	if x != nil && x.Account != nil {
+		return *x.Account
+	}
+	return ""
+}
+
+func (x *Party) GetBic() string {
+	if x != nil && x.Bic != nil {
+		return *x.Bic
+	}
+	return ""
+}
+
+// A balance movement made when a transaction was posted
+type Posting struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountNumber string                 `protobuf:"bytes,1,opt,name=account_number,json=accountNumber,proto3" json:"account_number,omitempty"`
+	Amount        float64                `protobuf:"fixed64,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	Cause         string                 `protobuf:"bytes,3,opt,name=cause,proto3" json:"cause,omitempty"`
+	Balance       float64                `protobuf:"fixed64,4,opt,name=balance,proto3" json:"balance,omitempty"`
+	Version       int64                  `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Posting) Reset() {
+	*x = Posting{}
+	mi := &file_events_v1_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Posting) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Posting) ProtoMessage() {}
+
+func (x *Posting) ProtoReflect() protoreflect.Message {
+	mi := &file_events_v1_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Posting.ProtoReflect.Descriptor instead.
+func (*Posting) Descriptor() ([]byte, []int) {
+	return file_events_v1_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Posting) GetAccountNumber() string {
+	if x != nil {
+		return x.AccountNumber
+	}
+	return ""
+}
+
+func (x *Posting) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Posting) GetCause() string {
+	if x != nil {
+		return x.Cause
+	}
+	return ""
+}
+
+func (x *Posting) GetBalance() float64 {
+	if x != nil {
+		return x.Balance
+	}
+	return 0
+}
+
+func (x *Posting) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+var File_events_v1_proto protoreflect.FileDescriptor
+
+const file_events_v1_proto_rawDesc = "" +
+	"\n" +
+	"\x0fevents.v1.proto\x12\x11banking.events.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x85\x03\n" +
+	"\aAccount\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12%\n" +
+	"\x0eaccount_number\x18\x02 \x01(\tR\raccountNumber\x12\x1a\n" +
+	"\busername\x18\x03 \x01(\tR\busername\x12\x14\n" +
+	"\x05email\x18\x04 \x01(\tR\x05email\x12\x18\n" +
+	"\abalance\x18\x05 \x01(\x01R\abalance\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x1b\n" +
+	"\tis_active\x18\b \x01(\bR\bisActive\x12\x12\n" +
+	"\x04tier\x18\t \x01(\tR\x04tier\x12\x1a\n" +
+	"\bcurrency\x18\n" +
+	" \x01(\tR\bcurrency\x12$\n" +
+	"\vcustomer_id\x18\v \x01(\tH\x00R\n" +
+	"customerId\x88\x01\x01B\x0e\n" +
+	"\f_customer_id\"\xab\b\n" +
+	"\vTransaction\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12&\n" +
+	"\x0ffrom_account_id\x18\x02 \x01(\tR\rfromAccountId\x12\"\n" +
+	"\rto_account_id\x18\x03 \x01(\tR\vtoAccountId\x12\x16\n" +
+	"\x06amount\x18\x04 \x01(\x01R\x06amount\x12)\n" +
+	"\x10transaction_type\x18\x05 \x01(\tR\x0ftransactionType\x12 \n" +
+	"\vdescription\x18\x06 \x01(\tR\vdescription\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x16\n" +
+	"\x06status\x18\b \x01(\tR\x06status\x12;\n" +
+	"\x17original_transaction_id\x18\t \x01(\tH\x00R\x15originalTransactionId\x88\x01\x01\x12\x1f\n" +
+	"\bcurrency\x18\n" +
+	" \x01(\tH\x01R\bcurrency\x88\x01\x01\x12\x1c\n" +
+	"\afx_rate\x18\v \x01(\x01H\x02R\x06fxRate\x88\x01\x01\x12.\n" +
+	"\x10converted_amount\x18\f \x01(\x01H\x03R\x0fconvertedAmount\x88\x01\x01\x122\n" +
+	"\x12converted_currency\x18\r \x01(\tH\x04R\x11convertedCurrency\x88\x01\x01\x12*\n" +
+	"\x04fees\x18\x0e \x03(\v2\x16.banking.events.v1.FeeR\x04fees\x12&\n" +
+	"\finitiated_by\x18\x0f \x01(\tH\x05R\vinitiatedBy\x88\x01\x01\x12\x1e\n" +
+	"\bpayee_id\x18\x10 \x01(\tH\x06R\apayeeId\x88\x01\x01\x12\x1e\n" +
+	"\bbatch_id\x18\x11 \x01(\tH\aR\abatchId\x88\x01\x01\x124\n" +
+	"\bcreditor\x18\x12 \x01(\v2\x18.banking.events.v1.PartyR\bcreditor\x120\n" +
+	"\x06debtor\x18\x13 \x01(\v2\x18.banking.events.v1.PartyR\x06debtor\x12!\n" +
+	"\treference\x18\x14 \x01(\tH\bR\treference\x88\x01\x01\x12&\n" +
+	"\fclearing_ref\x18\x15 \x01(\tH\tR\vclearingRef\x88\x01\x01\x126\n" +
+	"\bpostings\x18\x16 \x03(\v2\x1a.banking.events.v1.PostingR\bpostingsB\x1a\n" +
+	"\x18_original_transaction_idB\v\n" +
+	"\t_currencyB\n" +
+	"\n" +
+	"\b_fx_rateB\x13\n" +
+	"\x11_converted_amountB\x15\n" +
+	"\x13_converted_currencyB\x0f\n" +
+	"\r_initiated_byB\v\n" +
+	"\t_payee_idB\v\n" +
+	"\t_batch_idB\f\n" +
+	"\n" +
+	"_referenceB\x0f\n" +
+	"\r_clearing_ref\"f\n" +
+	"\x03Fee\x12\x17\n" +
+	"\arule_id\x18\x01 \x01(\x03R\x06ruleId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x16\n" +
+	"\x06amount\x18\x03 \x01(\x01R\x06amount\x12\x1a\n" +
+	"\bcurrency\x18\x04 \x01(\tR\bcurrency\"\x87\x01\n" +
+	"\x05Party\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x17\n" +
+	"\x04iban\x18\x02 \x01(\tH\x00R\x04iban\x88\x01\x01\x12\x1d\n" +
+	"\aaccount\x18\x03 \x01(\tH\x01R\aaccount\x88\x01\x01\x12\x15\n" +
+	"\x03bic\x18\x04 \x01(\tH\x02R\x03bic\x88\x01\x01B\a\n" +
+	"\x05_ibanB\n" +
+	"\n" +
+	"\b_accountB\x06\n" +
+	"\x04_bic\"\x92\x01\n" +
+	"\aPosting\x12%\n" +
+	"\x0eaccount_number\x18\x01 \x01(\tR\raccountNumber\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x01R\x06amount\x12\x14\n" +
+	"\x05cause\x18\x03 \x01(\tR\x05cause\x12\x18\n" +
+	"\abalance\x18\x04 \x01(\x01R\abalance\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\x03R\aversionB\vZ\t./;eventsb\x06proto3"
+
+var (
+	file_events_v1_proto_rawDescOnce sync.Once
+	file_events_v1_proto_rawDescData []byte
+)
+
+func file_events_v1_proto_rawDescGZIP() []byte {
+	file_events_v1_proto_rawDescOnce.Do(func() {
+		file_events_v1_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_events_v1_proto_rawDesc), len(file_events_v1_proto_rawDesc)))
+	})
+	return file_events_v1_proto_rawDescData
+}
+
+var file_events_v1_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_events_v1_proto_goTypes = []any{
+	(*Account)(nil),               // 0: banking.events.v1.Account
+	(*Transaction)(nil),           // 1: banking.events.v1.Transaction
+	(*Fee)(nil),                   // 2: banking.events.v1.Fee
+	(*Party)(nil),                 // 3: banking.events.v1.Party
+	(*Posting)(nil),               // 4: banking.events.v1.Posting
+	(*timestamppb.Timestamp)(nil), // 5: google.protobuf.Timestamp
+}
+var file_events_v1_proto_depIdxs = []int32{
+	5, // 0: banking.events.v1.Account.created_at:type_name -> google.protobuf.Timestamp
+	5, // 1: banking.events.v1.Account.updated_at:type_name -> google.protobuf.Timestamp
+	5, // 2: banking.events.v1.Transaction.created_at:type_name -> google.protobuf.Timestamp
+	2, // 3: banking.events.v1.Transaction.fees:type_name -> banking.events.v1.Fee
+	3, // 4: banking.events.v1.Transaction.creditor:type_name -> banking.events.v1.Party
+	3, // 5: banking.events.v1.Transaction.debtor:type_name -> banking.events.v1.Party
+	4, // 6: banking.events.v1.Transaction.postings:type_name -> banking.events.v1.Posting
+	7, // [7:7] is the sub-list for method output_type
+	7, // [7:7] is the sub-list for method input_type
+	7, // [7:7] is the sub-list for extension type_name
+	7, // [7:7] is the sub-list for extension extendee
+	0, // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_events_v1_proto_init() }
+func file_events_v1_proto_init() {
+	if File_events_v1_proto != nil {
+		return
+	}
+	file_events_v1_proto_msgTypes[0].OneofWrappers = []any{}
+	file_events_v1_proto_msgTypes[1].OneofWrappers = []any{}
+	file_events_v1_proto_msgTypes[3].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_events_v1_proto_rawDesc), len(file_events_v1_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_events_v1_proto_goTypes,
+		DependencyIndexes: file_events_v1_proto_depIdxs,
+		MessageInfos:      file_events_v1_proto_msgTypes,
+	}.Build()
+	File_events_v1_proto = out.File
+	file_events_v1_proto_goTypes = nil
+	file_events_v1_proto_depIdxs = nil
+}
@@ -0,0 +1,155 @@
+package events
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// transaction is a posted transaction with every field its schema allows set
+func transaction() []byte {
+	return []byte(`{"id":"` + uuid.NewString() + `","from_account_id":"ACC123456789","to_account_id":"ACC987654321",
+		"amount":100,"transaction_type":"transfer","description":"Rent","created_at":"2026-10-19T09:00:00Z","status":"completed",
+		"original_transaction_id":"` + uuid.NewString() + `","currency":"USD","fx_rate":0.92,"converted_amount":92,"converted_currency":"EUR",
+		"fees":[{"rule_id":1,"name":"Transfer fee","amount":0.5,"currency":"USD"}],
+		"initiated_by":"` + uuid.NewString() + `","payee_id":"` + uuid.NewString() + `","batch_id":"BATCH1",
+		"creditor":{"name":"John Smith","iban":"GB33BUKB20201555555555","bic":"BUKBGB22"},"reference":"INV-1",
+		"postings":[{"account_number":"ACC123456789","amount":-100.5,"cause":"transfer","balance":899.5,"version":7}]}`)
+}
+
+func TestRoundTrip(t *testing.T) {
+	payload := transaction()
+
+	for _, name := range []string{"json", "protobuf"} {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, UseCodec(name))
+			defer UseCodec("json")
+
+			parent := NewTrace()
+			data, headers, err := Encode("transaction-ledger", payload, parent.Child())
+			require.NoError(t, err)
+			assert.Equal(t, codecs[name].ContentType(), headers[ContentTypeHeader])
+
+			var received json.RawMessage
+			env, err := Unmarshal("transaction-ledger", data, headers, &received)
+			require.NoError(t, err)
+			assert.Equal(t, TransactionPosted, env.Type)
+			assert.Equal(t, 1, env.Version)
+			assert.Equal(t, Producer, env.Producer)
+			assert.Equal(t, parent.TraceID(), env.Trace.TraceID())
+			assert.NotEqual(t, parent.Parent, env.Trace.Parent)
+			assert.JSONEq(t, string(payload), string(received))
+
+			// the same event on a topic carrying another type is refused
+			_, err = Decode("transaction", data, headers)
+			assert.ErrorIs(t, err, ErrInvalid)
+		})
+	}
+}
+
+func TestProtobufFallsBackToJSON(t *testing.T) {
+	require.NoError(t, UseCodec("protobuf"))
+	defer UseCodec("json")
+
+	// zero values of fields that are always in the payload survive the Protobuf form
+	account := []byte(`{"id":"` + uuid.NewString() + `","account_number":"ACC1","username":"","email":"",
+		"balance":0,"created_at":"2026-10-19T09:00:00Z","updated_at":"2026-10-19T09:00:00Z","is_active":false,"tier":"basic","currency":"USD"}`)
+	data, headers, err := Encode("account-creation", account, Trace{})
+	require.NoError(t, err)
+	assert.Equal(t, "application/x-protobuf", headers[ContentTypeHeader])
+	env, err := Decode("account-creation", data, headers)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(account), string(env.Payload))
+
+	// holds have no Protobuf form
+	data, headers, err = Encode("account-holds", []byte(`{"id":"`+uuid.NewString()+`","account_number":"ACC1","amount":5,
+		"captured_amount":0,"reference":"","status":"active","expires_at":"2026-10-19T09:00:00Z","created_at":"2026-10-19T09:00:00Z","updated_at":"2026-10-19T09:00:00Z"}`), Trace{})
+	require.NoError(t, err)
+	assert.Equal(t, "application/json", headers[ContentTypeHeader])
+	_, err = Decode("account-holds", data, headers)
+	assert.NoError(t, err)
+
+	assert.Error(t, UseCodec("avro"))
+}
+
+func TestLegacyAndNewerVersions(t *testing.T) {
+	id := uuid.NewString()
+	payload := []byte(`{"id":"` + id + `","account_number":"ACC123456789","amount":20,"captured_amount":0,"reference":"",
+		"status":"active","expires_at":"2026-10-19T10:00:00Z","created_at":"2026-10-19T09:00:00Z","updated_at":"2026-10-19T09:00:00Z"}`)
+
+	// a bare payload from before envelopes is upcast from version 0
+	var received struct {
+		ID string `json:"id"`
+	}
+	env, err := Unmarshal("account-holds", payload, nil, &received)
+	require.NoError(t, err)
+	assert.Equal(t, 1, env.Version)
+	assert.Equal(t, id, received.ID)
+
+	// a version this service does not know yet is not skipped
+	data, headers, err := Encode("account-holds", payload, Trace{})
+	require.NoError(t, err)
+	var newer map[string]any
+	require.NoError(t, json.Unmarshal(data, &newer))
+	newer["version"] = 2
+	data, err = json.Marshal(newer)
+	require.NoError(t, err)
+	_, err = Decode("account-holds", data, headers)
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+}
+
+func TestPayloadsAreChecked(t *testing.T) {
+	// a field missing from the schema is refused before it is published
+	_, _, err := Encode("transaction", []byte(`{"id":"`+uuid.NewString()+`","from_account_id":"A","to_account_id":"B","amount":1,
+		"transaction_type":"deposit","description":"","created_at":"2026-03-31T12:00:00Z","status":"pending","priority":"high"}`), Trace{})
+	assert.ErrorIs(t, err, ErrInvalid)
+
+	// so is a payload of the wrong shape
+	_, _, err = Encode("clearing-status", []byte(`{"transaction_id":"T1","status":"lost","iso_status":"","message_id":"M","received_at":"2026-03-31T12:00:00Z"}`), Trace{})
+	assert.ErrorIs(t, err, ErrInvalid)
+
+	_, _, err = Encode("orders", []byte(`{}`), Trace{})
+	assert.ErrorIs(t, err, ErrUnknownTopic)
+}
+
+func TestLoadTopics(t *testing.T) {
+	byID, byName := topics, topicIDs
+	defer func() { topics, topicIDs = byID, byName }()
+
+	renamed := make(map[string]Topic)
+	for id, topic := range topics {
+		topic.Name = "prod." + topic.Name
+		renamed[id] = topic
+	}
+	data, err := json.Marshal(renamed)
+	require.NoError(t, err)
+	file := filepath.Join(t.TempDir(), "topics.json")
+	require.NoError(t, os.WriteFile(file, data, 0o644))
+
+	require.NoError(t, LoadTopics(file))
+	assert.Equal(t, []string{"prod.transaction", "prod.dead-ledger"}, TopicNames("transaction", "dead-ledger"))
+	assert.Equal(t, "dead-ledger", TopicID("prod.dead-ledger"))
+	assert.Equal(t, "orders", TopicID("orders"))
+	eventType, err := TypeOf("dead-ledger")
+	require.NoError(t, err)
+	assert.Equal(t, TransactionRejected, eventType)
+
+	// a map missing a topic, or naming two topics alike, is refused and the map in use is kept
+	delete(renamed, "audit-log")
+	data, err = json.Marshal(renamed)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(file, data, 0o644))
+	assert.ErrorContains(t, LoadTopics(file), "audit-log")
+
+	renamed["audit-log"] = Topic{Name: "prod.transaction", Event: AuditEntryRecorded}
+	data, err = json.Marshal(renamed)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(file, data, 0o644))
+	assert.Error(t, LoadTopics(file))
+	assert.Equal(t, "prod.audit-log", TopicName("audit-log"))
+}
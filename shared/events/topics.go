@@ -0,0 +1,122 @@
+package events
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Topic describes a topic of the topic map
+type Topic struct {
+	Name      string   `json:"name"`      // Name of the topic on the Kafka cluster
+	Event     string   `json:"event"`     // Type of the events it carries
+	Producers []string `json:"producers"` // Services publishing on it
+	Consumers []string `json:"consumers"` // Services consuming it
+}
+
+// The services know each topic by an ID, which the topic map maps to the name of the topic on the
+// cluster. So one cluster can serve several deployments, or topics can follow a naming scheme,
+// without a change to the services. IDs are used everywhere but at the Kafka client: messages are
+// published with TopicName, groups subscribe with TopicNames, and a consumed message's topic is
+// turned back into an ID with TopicID.
+
+//go:embed topics.json
+var defaultTopics []byte
+
+// topics is the topic map by ID, and topicIDs its IDs by name
+var topics, topicIDs = mustParseTopics(defaultTopics)
+
+func mustParseTopics(data []byte) (map[string]Topic, map[string]string) {
+	byID, byName, err := parseTopics(data, nil)
+	if err != nil {
+		panic(fmt.Sprintf("events: topics.json: %v", err))
+	}
+	return byID, byName
+}
+
+// parseTopics reads a topic map, which must have every topic of want
+func parseTopics(data []byte, want map[string]Topic) (map[string]Topic, map[string]string, error) {
+	var byID map[string]Topic
+	if err := json.Unmarshal(data, &byID); err != nil {
+		return nil, nil, err
+	}
+	for id := range want {
+		if _, ok := byID[id]; !ok {
+			return nil, nil, fmt.Errorf("topic %s is missing", id)
+		}
+	}
+	byName := make(map[string]string, len(byID))
+	for id, topic := range byID {
+		if topic.Name == "" {
+			return nil, nil, fmt.Errorf("topic %s has no name", id)
+		}
+		if other, ok := byName[topic.Name]; ok {
+			return nil, nil, fmt.Errorf("topics %s and %s are both named %s", other, id, topic.Name)
+		}
+		if _, ok := versions[topic.Event]; !ok {
+			return nil, nil, fmt.Errorf("topic %s carries unknown event type %q", id, topic.Event)
+		}
+		byName[topic.Name] = id
+	}
+	return byID, byName, nil
+}
+
+// LoadTopics replaces the topic map with the one in file, which must have every topic of the
+// built-in map in topics.json. main calls it at startup when TOPIC_MAP_FILE is set.
+func LoadTopics(file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read topic map: %w", err)
+	}
+	byID, byName, err := parseTopics(data, topics)
+	if err != nil {
+		return fmt.Errorf("invalid topic map %s: %w", file, err)
+	}
+	topics, topicIDs = byID, byName
+	return nil
+}
+
+// Topics returns the IDs of the topics in the topic map, sorted
+func Topics() []string {
+	ids := make([]string, 0, len(topics))
+	for id := range topics {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// TopicOf returns the topic of the topic map with the ID id
+func TopicOf(id string) (Topic, bool) {
+	topic, ok := topics[id]
+	return topic, ok
+}
+
+// TopicName returns the name on the cluster of the topic with the ID id, or id when the topic
+// map has no such topic
+func TopicName(id string) string {
+	if topic, ok := topics[id]; ok {
+		return topic.Name
+	}
+	return id
+}
+
+// TopicNames returns the names on the cluster of the topics with the IDs ids
+func TopicNames(ids ...string) []string {
+	names := make([]string, len(ids))
+	for i, id := range ids {
+		names[i] = TopicName(id)
+	}
+	return names
+}
+
+// TopicID returns the ID of the topic named name on the cluster, or name when the topic map has
+// no such topic
+func TopicID(name string) string {
+	if id, ok := topicIDs[name]; ok {
+		return id
+	}
+	return name
+}
@@ -0,0 +1,189 @@
+package screening
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Decision is the outcome of screening a name
+type Decision string
+
+const (
+	Clear Decision = "clear" // no entry is close enough
+	Flag  Decision = "flag"  // possible match, a compliance officer should look at it
+	Block Decision = "block" // match, the operation must not go ahead
+)
+
+// Thresholds are similarity scores between 0 and 1. A name scoring at least Block against any
+// name on the list is blocked, one scoring at least Flag is flagged.
+type Thresholds struct {
+	Block float64
+	Flag  float64
+}
+
+// Result is the outcome of screening one name, kept as the audit record of the decision
+type Result struct {
+	Name        string   `json:"name"`                   // Name that was screened
+	Decision    Decision `json:"decision"`               // Outcome
+	Score       float64  `json:"score"`                  // Best similarity found, 0 to 1
+	EntryID     string   `json:"entry_id,omitempty"`     // Closest entry on the list
+	MatchedName string   `json:"matched_name,omitempty"` // Name or alias of the closest entry
+	Program     string   `json:"program,omitempty"`      // Sanctions program of the closest entry
+}
+
+// Screener matches names against a loaded watchlist
+type Screener struct {
+	thresholds Thresholds
+	names      []listedName
+}
+
+// listedName is a name or alias of an entry, normalised once at load time
+type listedName struct {
+	entry  *Entry
+	name   string
+	tokens []string
+}
+
+// NewScreener indexes entries for screening
+func NewScreener(entries []Entry, thresholds Thresholds) *Screener {
+	s := &Screener{thresholds: thresholds}
+	for i := range entries {
+		entry := &entries[i]
+		for _, name := range append([]string{entry.Name}, entry.Aliases...) {
+			if tokens := tokenize(name); len(tokens) > 0 {
+				s.names = append(s.names, listedName{entry: entry, name: name, tokens: tokens})
+			}
+		}
+	}
+	return s
+}
+
+// Size returns the number of names and aliases on the list
+func (s *Screener) Size() int {
+	return len(s.names)
+}
+
+// Screen returns the decision for name against the closest listed name
+func (s *Screener) Screen(name string) Result {
+	result := Result{Name: name, Decision: Clear}
+	tokens := tokenize(name)
+	if len(tokens) == 0 {
+		return result
+	}
+
+	var best *listedName
+	for i := range s.names {
+		if score := Similarity(tokens, s.names[i].tokens); score > result.Score {
+			result.Score, best = score, &s.names[i]
+		}
+	}
+	if best != nil {
+		result.EntryID, result.MatchedName, result.Program = best.entry.ID, best.name, best.entry.Program
+	}
+
+	switch {
+	case s.thresholds.Block > 0 && result.Score >= s.thresholds.Block:
+		result.Decision = Block
+	case s.thresholds.Flag > 0 && result.Score >= s.thresholds.Flag:
+		result.Decision = Flag
+	}
+	return result
+}
+
+// Strictest returns the result with the strictest decision, the higher score deciding between
+// equal ones, or a clear result when there are none
+func Strictest(results ...Result) Result {
+	rank := map[Decision]int{Clear: 0, Flag: 1, Block: 2}
+	strictest := Result{Decision: Clear}
+	for _, result := range results {
+		if r, s := rank[result.Decision], rank[strictest.Decision]; r > s || (r == s && result.Score > strictest.Score) {
+			strictest = result
+		}
+	}
+	return strictest
+}
+
+// tokenize lower-cases a name and splits it into words, dropping punctuation, so that
+// "AL-QAIDA, Osama" and "osama al qaida" share the same tokens
+func tokenize(name string) []string {
+	return strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Similarity scores two tokenised names between 0 and 1. Word order is ignored: the names are
+// compared with Jaro-Winkler both as written and with their words sorted, and the better score
+// wins.
+func Similarity(a, b []string) float64 {
+	asWritten := jaroWinkler(strings.Join(a, " "), strings.Join(b, " "))
+	sorted := jaroWinkler(sortedJoin(a), sortedJoin(b))
+	if sorted > asWritten {
+		return sorted
+	}
+	return asWritten
+}
+
+// sortedJoin joins a sorted copy of tokens
+func sortedJoin(tokens []string) string {
+	sorted := append([]string(nil), tokens...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, " ")
+}
+
+// jaroWinkler returns the Jaro-Winkler similarity of a and b
+func jaroWinkler(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+
+	window := max(len(ra), len(rb))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+	matchedA := make([]bool, len(ra))
+	matchedB := make([]bool, len(rb))
+	matches := 0
+	for i := range ra {
+		lo, hi := max(0, i-window), min(len(rb), i+window+1)
+		for j := lo; j < hi; j++ {
+			if !matchedB[j] && ra[i] == rb[j] {
+				matchedA[i], matchedB[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	// Count matched characters that appear in a different order
+	transpositions, j := 0, 0
+	for i := range ra {
+		if !matchedA[i] {
+			continue
+		}
+		for !matchedB[j] {
+			j++
+		}
+		if ra[i] != rb[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(ra)) + m/float64(len(rb)) + (m-float64(transpositions/2))/m) / 3
+
+	// Boost names sharing a prefix of up to four characters
+	prefix := 0
+	for prefix < min(4, len(ra), len(rb)) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
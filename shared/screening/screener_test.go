@@ -0,0 +1,85 @@
+package screening
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleCSV = `# id,name,type,program,aliases
+ent_num,SDN_Name,SDN_Type,Program
+36,"AEROCARIBBEAN AIRLINES",-0-,CUBA,AERO-CARIBBEAN
+173,"AL-QAIDA, Osama",individual,SDGT,Usama bin Ladin;Osama bin Laden
+`
+
+const sampleXML = `<?xml version="1.0" standalone="yes"?>
+<sdnList>
+  <sdnEntry>
+    <uid>306</uid>
+    <firstName>Ivan</firstName>
+    <lastName>PETROV</lastName>
+    <sdnType>Individual</sdnType>
+    <programList><program>UKRAINE-EO13661</program><program>RUSSIA-EO14024</program></programList>
+    <akaList><aka><uid>1</uid><firstName>Ivan</firstName><lastName>PETROFF</lastName></aka></akaList>
+  </sdnEntry>
+</sdnList>`
+
+func TestReadCSV(t *testing.T) {
+	entries, err := ReadCSV(strings.NewReader(sampleCSV))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, Entry{ID: "36", Name: "AEROCARIBBEAN AIRLINES", Program: "CUBA", Aliases: []string{"AERO-CARIBBEAN"}}, entries[0])
+	assert.Equal(t, []string{"Usama bin Ladin", "Osama bin Laden"}, entries[1].Aliases)
+}
+
+func TestReadXML(t *testing.T) {
+	entries, err := ReadXML(strings.NewReader(sampleXML))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, Entry{ID: "306", Name: "Ivan PETROV", Program: "UKRAINE-EO13661;RUSSIA-EO14024", Aliases: []string{"Ivan PETROFF"}}, entries[0])
+}
+
+func TestScreen(t *testing.T) {
+	entries, err := ReadCSV(strings.NewReader(sampleCSV))
+	require.NoError(t, err)
+	screener := NewScreener(entries, Thresholds{Block: 0.95, Flag: 0.85})
+	assert.Equal(t, 5, screener.Size())
+
+	cases := []struct {
+		name     string
+		decision Decision
+		entryID  string
+	}{
+		{"Osama Al-Qaida", Block, "173"},   // word order and punctuation are ignored
+		{"osama bin laden", Block, "173"},  // alias
+		{"Osama bin Ladenn", Block, "173"}, // typo
+		{"Usman bin Laden", Flag, "173"},   // close enough to check
+		{"Oscar Laden", Clear, ""},
+		{"Aero Caribbean", Block, "36"}, // alias without the hyphen
+		{"Jane Smith", Clear, ""},       // nothing close
+		{"", Clear, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			result := screener.Screen(c.name)
+			assert.Equal(t, c.decision, result.Decision, "score %.3f against %q", result.Score, result.MatchedName)
+			if c.entryID != "" {
+				assert.Equal(t, c.entryID, result.EntryID)
+			}
+		})
+	}
+}
+
+func TestStrictest(t *testing.T) {
+	clean := Result{Name: "Jane Smith", Decision: Clear, Score: 0.4}
+	flag := Result{Name: "Usman bin Laden", Decision: Flag, Score: 0.9}
+	block := Result{Name: "Osama bin Laden", Decision: Block, Score: 0.96}
+	closer := Result{Name: "Osama Bin Laden", Decision: Block, Score: 1}
+
+	assert.Equal(t, block, Strictest(clean, block, flag))
+	assert.Equal(t, closer, Strictest(block, closer))
+	assert.Equal(t, flag, Strictest(flag, clean))
+	assert.Equal(t, Clear, Strictest().Decision)
+}
@@ -0,0 +1,139 @@
+// Package screening matches names against a sanctions watchlist such as the OFAC SDN list.
+// The package has no dependencies on the rest of the service so the same copy is used by
+// every service that screens names.
+package screening
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Entry is one listed party
+type Entry struct {
+	ID      string   `json:"id"`      // Identifier on the source list (ent_num / uid for OFAC)
+	Name    string   `json:"name"`    // Primary name
+	Aliases []string `json:"aliases"` // Also-known-as names
+	Program string   `json:"program"` // Sanctions program(s), e.g. "SDGT"
+}
+
+// LoadFile reads a watchlist from a CSV or XML file, chosen by extension.
+//
+// CSV rows are id,name[,type[,program[,aliases]]], which covers the first columns of the OFAC
+// sdn.csv export; aliases are separated by ";". Blank lines and lines starting with "#" are
+// skipped, as is a header row whose first column is "id" or "ent_num".
+//
+// XML files use the OFAC sdn.xml layout (sdnList/sdnEntry with firstName, lastName,
+// programList and akaList).
+func LoadFile(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open watchlist: %w", err)
+	}
+	defer file.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".xml") {
+		return ReadXML(file)
+	}
+	return ReadCSV(file)
+}
+
+// ReadCSV parses a CSV watchlist, see LoadFile
+func ReadCSV(r io.Reader) ([]Entry, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	entries := []Entry{}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid watchlist line %d: %w", line, err)
+		}
+		if len(record) < 2 {
+			return nil, fmt.Errorf("invalid watchlist line %d: expected id,name", line)
+		}
+		id := strings.TrimSpace(record[0])
+		if line == 1 && (strings.EqualFold(id, "id") || strings.EqualFold(id, "ent_num")) {
+			continue
+		}
+		entry := Entry{ID: id, Name: cleanField(record[1])}
+		if len(record) > 3 {
+			entry.Program = cleanField(record[3])
+		}
+		if len(record) > 4 {
+			for _, alias := range strings.Split(record[4], ";") {
+				if alias = cleanField(alias); alias != "" {
+					entry.Aliases = append(entry.Aliases, alias)
+				}
+			}
+		}
+		if entry.Name == "" {
+			return nil, fmt.Errorf("invalid watchlist line %d: empty name", line)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// cleanField trims a CSV field and drops the "-0-" placeholder OFAC uses for empty values
+func cleanField(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "-0-" {
+		return ""
+	}
+	return s
+}
+
+// sdnList mirrors the parts of the OFAC sdn.xml export that are screened
+type sdnList struct {
+	Entries []struct {
+		UID       string   `xml:"uid"`
+		FirstName string   `xml:"firstName"`
+		LastName  string   `xml:"lastName"`
+		Programs  []string `xml:"programList>program"`
+		Akas      []struct {
+			FirstName string `xml:"firstName"`
+			LastName  string `xml:"lastName"`
+		} `xml:"akaList>aka"`
+	} `xml:"sdnEntry"`
+}
+
+// ReadXML parses an OFAC sdn.xml watchlist
+func ReadXML(r io.Reader) ([]Entry, error) {
+	var list sdnList
+	if err := xml.NewDecoder(r).Decode(&list); err != nil {
+		return nil, fmt.Errorf("invalid watchlist xml: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(list.Entries))
+	for _, sdn := range list.Entries {
+		entry := Entry{
+			ID:      sdn.UID,
+			Name:    joinName(sdn.FirstName, sdn.LastName),
+			Program: strings.Join(sdn.Programs, ";"),
+		}
+		for _, aka := range sdn.Akas {
+			if alias := joinName(aka.FirstName, aka.LastName); alias != "" {
+				entry.Aliases = append(entry.Aliases, alias)
+			}
+		}
+		if entry.Name != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// joinName builds "First Last", leaving out whichever part is empty
+func joinName(first, last string) string {
+	return strings.TrimSpace(strings.TrimSpace(first) + " " + strings.TrimSpace(last))
+}
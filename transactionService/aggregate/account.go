@@ -0,0 +1,86 @@
+// Package aggregate holds the event-sourced account aggregate. An account's state is never read
+// from anywhere but its events: the latest snapshot, if any, with the later events replayed on
+// top. usersschema.accounts is a projection of this state, kept in step as events are appended.
+package aggregate
+
+import (
+	"fmt"
+	"math"
+	"time"
+	"transactionService/models"
+)
+
+// Account is the state of an account after the events applied to it
+type Account struct {
+	AccountNumber string
+	Version       int64
+	Balance       float64
+	UpdatedAt     time.Time
+}
+
+// FromSnapshot starts an account at a snapshot
+func FromSnapshot(snapshot models.AccountSnapshot) *Account {
+	return &Account{
+		AccountNumber: snapshot.AccountNumber,
+		Version:       snapshot.Version,
+		Balance:       snapshot.Balance,
+		UpdatedAt:     snapshot.TakenAt,
+	}
+}
+
+// Apply moves the account on by one event. Events must be applied in version order with no gaps.
+func (a *Account) Apply(event models.AccountEvent) error {
+	if event.AccountNumber != a.AccountNumber {
+		return fmt.Errorf("event of %s applied to %s", event.AccountNumber, a.AccountNumber)
+	}
+	if event.Version != a.Version+1 {
+		return fmt.Errorf("event version %d of %s does not follow %d", event.Version, a.AccountNumber, a.Version)
+	}
+	switch event.EventType {
+	case models.AccountOpened, models.AccountCredited:
+		a.Balance += event.Amount
+	case models.AccountDebited:
+		a.Balance -= event.Amount
+	default:
+		return fmt.Errorf("unknown account event type %q", event.EventType)
+	}
+	a.Version, a.UpdatedAt = event.Version, event.OccurredAt
+	return nil
+}
+
+// OverdraftUsed is how far the balance is below zero
+func (a *Account) OverdraftUsed() float64 {
+	return math.Max(-a.Balance, 0)
+}
+
+// Snapshot captures the current state
+func (a *Account) Snapshot() models.AccountSnapshot {
+	return models.AccountSnapshot{
+		AccountNumber: a.AccountNumber,
+		Version:       a.Version,
+		Balance:       a.Balance,
+		TakenAt:       a.UpdatedAt,
+	}
+}
+
+// Replay rebuilds an account from a snapshot, or from its first event when snapshot is nil
+func Replay(accountNumber string, snapshot *models.AccountSnapshot, events []models.AccountEvent) (*Account, error) {
+	account := &Account{AccountNumber: accountNumber}
+	if snapshot != nil {
+		account = FromSnapshot(*snapshot)
+	}
+	for _, event := range events {
+		if err := account.Apply(event); err != nil {
+			return nil, err
+		}
+	}
+	return account, nil
+}
+
+// Movement turns a signed balance change into the event type and positive amount recording it
+func Movement(delta float64) (string, float64) {
+	if delta < 0 {
+		return models.AccountDebited, -delta
+	}
+	return models.AccountCredited, delta
+}
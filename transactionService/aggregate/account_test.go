@@ -0,0 +1,66 @@
+package aggregate
+
+import (
+	"testing"
+	"time"
+	"transactionService/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+
+func stream(deltas ...float64) []models.AccountEvent {
+	events := make([]models.AccountEvent, len(deltas))
+	for i, delta := range deltas {
+		eventType, amount := Movement(delta)
+		if i == 0 {
+			eventType = models.AccountOpened
+		}
+		events[i] = models.AccountEvent{
+			AccountNumber: "ACC1",
+			Version:       int64(i + 1),
+			EventType:     eventType,
+			Amount:        amount,
+			OccurredAt:    start.Add(time.Duration(i) * time.Minute),
+		}
+	}
+	return events
+}
+
+// TestReplayFromSnapshotMatchesFullReplay tests that starting at a snapshot gives the same state as replaying every event
+func TestReplayFromSnapshotMatchesFullReplay(t *testing.T) {
+	events := stream(100, -30, 20, -150, 10)
+
+	full, err := Replay("ACC1", nil, events)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), full.Version)
+	assert.InDelta(t, -50, full.Balance, 1e-9)
+	assert.InDelta(t, 50, full.OverdraftUsed(), 1e-9)
+	assert.Equal(t, events[4].OccurredAt, full.UpdatedAt)
+
+	partial, err := Replay("ACC1", nil, events[:3])
+	require.NoError(t, err)
+	snapshot := partial.Snapshot()
+	fromSnapshot, err := Replay("ACC1", &snapshot, events[3:])
+	require.NoError(t, err)
+	assert.Equal(t, full, fromSnapshot)
+}
+
+// TestApplyRejectsOutOfOrderEvents tests that a gap, a repeat or another account's event stops the replay
+func TestApplyRejectsOutOfOrderEvents(t *testing.T) {
+	events := stream(100, -30, 20)
+
+	_, err := Replay("ACC1", nil, []models.AccountEvent{events[0], events[2]})
+	assert.Error(t, err, "gap")
+	_, err = Replay("ACC1", nil, []models.AccountEvent{events[0], events[0]})
+	assert.Error(t, err, "repeat")
+	_, err = Replay("ACC2", nil, events)
+	assert.Error(t, err, "other account")
+
+	event := events[0]
+	event.EventType = "adjusted"
+	_, err = Replay("ACC1", nil, []models.AccountEvent{event})
+	assert.Error(t, err, "unknown type")
+}
@@ -0,0 +1,56 @@
+// Package businessday works out when business days close. A business day runs from midnight to
+// midnight in the bank's timezone, and its end-of-day balance includes every event that occurred
+// before the following midnight there. Business dates are carried as midnight UTC of that date,
+// which is how Postgres date columns are scanned.
+package businessday
+
+import (
+	"fmt"
+	"time"
+)
+
+// DateLayout is how business dates are written in the API
+const DateLayout = "2006-01-02"
+
+// Calendar closes business days in a timezone
+type Calendar struct {
+	Location *time.Location
+	Grace    time.Duration // How long a day is held open after midnight for transactions still in flight
+}
+
+// Parse reads a business date written as 2006-01-02
+func (c Calendar) Parse(value string) (time.Time, error) {
+	date, err := time.Parse(DateLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid business date %q, expected YYYY-MM-DD", value)
+	}
+	return date, nil
+}
+
+// Date is the business date a moment falls on
+func (c Calendar) Date(t time.Time) time.Time {
+	local := t.In(c.location())
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Close is the moment a business date ends: the following midnight in the calendar's timezone
+func (c Calendar) Close(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day()+1, 0, 0, 0, 0, c.location())
+}
+
+// LastClosed is the latest business date that had closed, grace period included, at now
+func (c Calendar) LastClosed(now time.Time) time.Time {
+	return c.Date(now.Add(-c.Grace)).AddDate(0, 0, -1)
+}
+
+// IsClosed reports whether a business date had closed, grace period included, at now
+func (c Calendar) IsClosed(date, now time.Time) bool {
+	return !date.After(c.LastClosed(now))
+}
+
+func (c Calendar) location() *time.Location {
+	if c.Location == nil {
+		return time.UTC
+	}
+	return c.Location
+}
@@ -0,0 +1,40 @@
+package businessday
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCloseFollowsTimezone tests that a day closes at the next local midnight, across a DST change
+func TestCloseFollowsTimezone(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	calendar := Calendar{Location: berlin, Grace: 30 * time.Minute}
+
+	date, err := calendar.Parse("2026-03-28")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 3, 28, 23, 0, 0, 0, time.UTC), calendar.Close(date).UTC())
+
+	// clocks go forward on the 29th, so that day closes an hour earlier in UTC
+	assert.Equal(t, time.Date(2026, 3, 29, 22, 0, 0, 0, time.UTC), calendar.Close(date.AddDate(0, 0, 1)).UTC())
+
+	// 23:30 UTC on the 28th is already the 29th in Berlin
+	assert.Equal(t, date.AddDate(0, 0, 1), calendar.Date(time.Date(2026, 3, 28, 23, 30, 0, 0, time.UTC)))
+
+	_, err = calendar.Parse("31/03/2026")
+	assert.Error(t, err)
+}
+
+// TestLastClosedWaitsForGrace tests that a day only counts as closed once the grace period has passed
+func TestLastClosedWaitsForGrace(t *testing.T) {
+	calendar := Calendar{Location: time.UTC, Grace: 30 * time.Minute}
+	march31 := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, march31.AddDate(0, 0, -1), calendar.LastClosed(time.Date(2026, 4, 1, 0, 29, 0, 0, time.UTC)))
+	assert.False(t, calendar.IsClosed(march31, time.Date(2026, 4, 1, 0, 29, 0, 0, time.UTC)))
+	assert.Equal(t, march31, calendar.LastClosed(time.Date(2026, 4, 1, 0, 30, 0, 0, time.UTC)))
+	assert.True(t, calendar.IsClosed(march31, time.Date(2026, 4, 1, 0, 30, 0, 0, time.UTC)))
+}
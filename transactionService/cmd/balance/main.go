@@ -0,0 +1,140 @@
+// Command balance answers as-of balance queries against the transaction service, for audits and
+// reconciliations.
+//
+//	balance ACCOUNT                                 current balance
+//	balance -date 2026-03-31 ACCOUNT                balance at the close of a business day
+//	balance -asof 2026-03-31T12:00:00Z ACCOUNT      balance at a moment
+//	balance -eod -from 2026-03-01 -to 2026-03-31 ACCOUNT
+//	                                                recorded end-of-day balances over a range
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+func main() {
+	server := flag.String("url", envOr("BALANCE_URL", "http://localhost:9093"), "Base URL of the transaction service")
+	date := flag.String("date", "", "Business day (YYYY-MM-DD) to give the closing balance of")
+	asOf := flag.String("asof", "", "Moment (RFC 3339) to give the balance at")
+	eod := flag.Bool("eod", false, "List the recorded end-of-day balances instead")
+	from := flag.String("from", "", "With -eod, the first business day (YYYY-MM-DD)")
+	to := flag.String("to", "", "With -eod, the last business day (YYYY-MM-DD)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: balance [-date DAY | -asof TIME] ACCOUNT | balance -eod [-from DAY] [-to DAY] ACCOUNT\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 || (*date != "" && *asOf != "") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	account := flag.Arg(0)
+	var err error
+	if *eod {
+		err = listEndOfDay(client, *server, account, *from, *to)
+	} else {
+		err = pointInTime(client, *server, account, *date, *asOf)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "balance:", err)
+		os.Exit(1)
+	}
+}
+
+// pointInTime prints the balance of an account at the close of date, at asOf, or now
+func pointInTime(client *http.Client, server, account, date, asOf string) error {
+	query := url.Values{}
+	if date != "" {
+		query.Set("date", date)
+	}
+	if asOf != "" {
+		query.Set("asOf", asOf)
+	}
+	var balance struct {
+		AccountNumber string    `json:"account_number"`
+		Balance       float64   `json:"balance"`
+		Currency      string    `json:"currency"`
+		Version       int64     `json:"version"`
+		AsOf          time.Time `json:"as_of"`
+		BusinessDate  string    `json:"business_date"`
+		StartedFrom   string    `json:"started_from"`
+		Replayed      int       `json:"replayed"`
+	}
+	if err := get(client, server+"/accounts/"+url.PathEscape(account)+"/balance?"+query.Encode(), &balance); err != nil {
+		return err
+	}
+
+	when := balance.AsOf.Format(time.RFC3339)
+	if balance.BusinessDate != "" {
+		when = "close of " + balance.BusinessDate
+	}
+	startedFrom := balance.StartedFrom
+	if startedFrom == "" {
+		startedFrom = "first event"
+	}
+	fmt.Printf("%s at %s: %.2f %s\n", balance.AccountNumber, when, balance.Balance, balance.Currency)
+	fmt.Printf("  version %d, %d events replayed from %s\n", balance.Version, balance.Replayed, startedFrom)
+	return nil
+}
+
+// listEndOfDay prints the recorded end-of-day balances of an account
+func listEndOfDay(client *http.Client, server, account, from, to string) error {
+	query := url.Values{}
+	if from != "" {
+		query.Set("from", from)
+	}
+	if to != "" {
+		query.Set("to", to)
+	}
+	var balances []struct {
+		BusinessDate string    `json:"business_date"`
+		Balance      float64   `json:"balance"`
+		Version      int64     `json:"version"`
+		ClosedAt     time.Time `json:"closed_at"`
+	}
+	if err := get(client, server+"/accounts/"+url.PathEscape(account)+"/eod-balances?"+query.Encode(), &balances); err != nil {
+		return err
+	}
+	if len(balances) == 0 {
+		fmt.Println("no end-of-day balances recorded in range")
+		return nil
+	}
+	for _, balance := range balances {
+		fmt.Printf("%s  %14.2f  v%-8d closed %s\n", balance.BusinessDate, balance.Balance, balance.Version, balance.ClosedAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// get decodes the JSON answer to a GET, or returns the error the server gave
+func get(client *http.Client, target string, out any) error {
+	resp, err := client.Get(target)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return json.Unmarshal(body, out)
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
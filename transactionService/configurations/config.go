@@ -0,0 +1,172 @@
+package configurations
+
+import (
+	"fmt"
+	"shared/screening"
+	"strings"
+	"time"
+	_ "time/tzdata" // the runtime image has no zoneinfo
+	"transactionService/businessday"
+	"transactionService/models"
+	"transactionService/risk"
+
+	"github.com/nicholasjackson/env"
+)
+
+var appConfig *appConfigs
+var goUri *string = env.String("GO_URI", false, "0.0.0.0:9093", "Bind address for the admin server")
+var fxRatesFile *string = env.String("FX_RATES_FILE", false, "", "CSV file of base,quote,rate lines loaded at startup")
+var feeIncomeAccount *string = env.String("FEE_INCOME_ACCOUNT", false, "FEE-INCOME", "Account number transaction fees are credited to")
+var clearingSuspenseAccount *string = env.String("CLEARING_SUSPENSE_ACCOUNT", false, "CLEARING-SUSPENSE", "Account number external transfers are held in until settled or returned")
+var riskReviewAmount *float64 = env.Float64("RISK_REVIEW_AMOUNT", false, 10000, "Transactions at or above this amount are held for review, 0 to disable")
+var riskRejectAmount *float64 = env.Float64("RISK_REJECT_AMOUNT", false, 100000, "Transactions at or above this amount are rejected, 0 to disable")
+var riskNewPayeeAmount *float64 = env.Float64("RISK_NEW_PAYEE_AMOUNT", false, 2000, "Transfers at or above this amount to a first-time payee are held, 0 to disable")
+var riskRapidDebits *int = env.Int("RISK_RAPID_DEBITS", false, 5, "Debits allowed within RISK_RAPID_WINDOW before the next one is held, 0 to disable")
+var riskRapidWindow *time.Duration = env.Duration("RISK_RAPID_WINDOW", false, 10*time.Minute, "Window for RISK_RAPID_DEBITS")
+var riskDormantAfter *time.Duration = env.Duration("RISK_DORMANT_AFTER", false, 180*24*time.Hour, "Idle time after which an account is dormant, 0 to disable")
+var watchlistFile *string = env.String("WATCHLIST_FILE", false, "", "Sanctions watchlist (CSV or OFAC sdn.xml) transfers are screened against, empty to disable")
+var screeningBlockScore *float64 = env.Float64("SCREENING_BLOCK_SCORE", false, 0.95, "Name similarity at or above which a transfer is rejected")
+var screeningFlagScore *float64 = env.Float64("SCREENING_FLAG_SCORE", false, 0.85, "Name similarity at or above which a transfer is held for review")
+var riskCurrency *string = env.String("RISK_CURRENCY", false, "USD", "Currency the RISK_*_AMOUNT thresholds are stated in")
+var riskDormantAmount *float64 = env.Float64("RISK_DORMANT_AMOUNT", false, 500, "Debits at or above this amount from a dormant account are held")
+var payeeCoolingOff *time.Duration = env.Duration("PAYEE_COOLING_OFF", false, 24*time.Hour, "Time a newly added payee waits before it can receive transfers")
+var payeeVerifiedLimit *float64 = env.Float64("PAYEE_VERIFIED_LIMIT", false, 0, "Transfers above this amount may only go to verified payees, 0 to disable")
+var snapshotEvery *int = env.Int("SNAPSHOT_EVERY", false, 100, "Events appended to an account between balance snapshots, 0 to disable")
+var eodTimezone *string = env.String("EOD_TIMEZONE", false, "UTC", "IANA timezone whose midnight closes the business day")
+var eodGrace *time.Duration = env.Duration("EOD_GRACE", false, 15*time.Minute, "Time after midnight before end-of-day balances are taken, for transactions still in flight")
+var eventCodec *string = env.String("EVENT_CODEC", false, "json", "Encoding of the events published on transaction and transaction-ledger: json or protobuf")
+var kafkaBroker *string = env.String("KAFKA_BROKER", false, "kafka:9092", "Comma-separated Kafka brokers every topic is consumed from and published to")
+var topicMapFile *string = env.String("TOPIC_MAP_FILE", false, "", "JSON topic map naming the topics on the cluster, empty for the built-in names")
+
+type appConfigs struct {
+	appURI           string
+	fxRatesFile      string
+	feeIncomeAccount string
+	suspenseAccount  string
+	riskThresholds   risk.Thresholds
+	watchlistFile    string
+	screening        screening.Thresholds
+	payeeCoolingOff  time.Duration
+	payeeLimit       float64
+	snapshotEvery    int
+	businessDays     businessday.Calendar
+	eventCodec       string
+	kafkaBrokers     []string
+	topicMapFile     string
+}
+
+func NewAppConfig() (*appConfigs, error) {
+	// return if the instance already exists
+	if appConfig != nil {
+		return appConfig, nil
+	}
+	// creat new instance
+	if err := env.Parse(); err != nil {
+		return nil, err
+	}
+	location, err := time.LoadLocation(*eodTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EOD_TIMEZONE: %w", err)
+	}
+	currency, err := models.NormalizeCurrency(*riskCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RISK_CURRENCY: %w", err)
+	}
+
+	appConfig = &appConfigs{
+		appURI:           *goUri,
+		fxRatesFile:      *fxRatesFile,
+		feeIncomeAccount: *feeIncomeAccount,
+		suspenseAccount:  *clearingSuspenseAccount,
+		riskThresholds: risk.Thresholds{
+			Currency:       currency,
+			ReviewAmount:   *riskReviewAmount,
+			RejectAmount:   *riskRejectAmount,
+			NewPayeeAmount: *riskNewPayeeAmount,
+			RapidDebits:    *riskRapidDebits,
+			RapidWindow:    *riskRapidWindow,
+			DormantAfter:   *riskDormantAfter,
+			DormantAmount:  *riskDormantAmount,
+		},
+		watchlistFile:   *watchlistFile,
+		screening:       screening.Thresholds{Block: *screeningBlockScore, Flag: *screeningFlagScore},
+		payeeCoolingOff: *payeeCoolingOff,
+		payeeLimit:      *payeeVerifiedLimit,
+		snapshotEvery:   *snapshotEvery,
+		businessDays:    businessday.Calendar{Location: location, Grace: *eodGrace},
+		eventCodec:      *eventCodec,
+		kafkaBrokers:    strings.Split(*kafkaBroker, ","),
+		topicMapFile:    *topicMapFile,
+	}
+	return appConfig, nil
+}
+
+// gets the URI
+func (apconfig *appConfigs) GetAppURI() *string {
+	return &apconfig.appURI
+}
+
+// gets the exchange rate file, empty when rates are only managed through the admin API
+func (apconfig *appConfigs) GetFxRatesFile() string {
+	return apconfig.fxRatesFile
+}
+
+// gets the account number transaction fees are credited to
+func (apconfig *appConfigs) GetFeeIncomeAccount() string {
+	return apconfig.feeIncomeAccount
+}
+
+// gets the account number external transfers are held in until the clearing system settles them
+func (apconfig *appConfigs) GetClearingSuspenseAccount() string {
+	return apconfig.suspenseAccount
+}
+
+// gets the thresholds of the built-in risk rules
+func (apconfig *appConfigs) GetRiskThresholds() risk.Thresholds {
+	return apconfig.riskThresholds
+}
+
+// gets the sanctions watchlist file, empty when screening is disabled
+func (apconfig *appConfigs) GetWatchlistFile() string {
+	return apconfig.watchlistFile
+}
+
+// gets the similarity scores at which screened names are blocked or flagged
+func (apconfig *appConfigs) GetScreeningThresholds() screening.Thresholds {
+	return apconfig.screening
+}
+
+// gets how long a new payee waits before it can receive transfers
+func (apconfig *appConfigs) GetPayeeCoolingOff() time.Duration {
+	return apconfig.payeeCoolingOff
+}
+
+// gets the amount above which transfers need a verified payee, 0 when disabled
+func (apconfig *appConfigs) GetPayeeVerifiedLimit() float64 {
+	return apconfig.payeeLimit
+}
+
+// gets how many events an account's stream grows by between snapshots, 0 when disabled
+func (apconfig *appConfigs) GetSnapshotEvery() int {
+	return apconfig.snapshotEvery
+}
+
+// gets the calendar whose days end-of-day balances are taken for
+func (apconfig *appConfigs) GetBusinessDays() businessday.Calendar {
+	return apconfig.businessDays
+}
+
+// gets the name of the codec events are published with
+func (apconfig *appConfigs) GetEventCodec() string {
+	return apconfig.eventCodec
+}
+
+// gets the brokers of the Kafka cluster
+func (apconfig *appConfigs) GetKafkaBrokers() []string {
+	return apconfig.kafkaBrokers
+}
+
+// gets the topic map file, empty when the topics keep their built-in names
+func (apconfig *appConfigs) GetTopicMapFile() string {
+	return apconfig.topicMapFile
+}
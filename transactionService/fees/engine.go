@@ -0,0 +1,57 @@
+// Package fees works out the charges applied to a transaction from the configured fee rules.
+package fees
+
+import (
+	"math"
+	"transactionService/models"
+)
+
+// Compute returns the fee a single rule charges on amount, rounded to cents
+func Compute(rule models.FeeRule, amount float64) float64 {
+	fee := 0.0
+	switch rule.FeeType {
+	case "flat":
+		fee = rule.Amount
+	case "percentage":
+		fee = amount * rule.Rate
+		if fee < rule.MinFee {
+			fee = rule.MinFee
+		}
+		if rule.MaxFee > 0 && fee > rule.MaxFee {
+			fee = rule.MaxFee
+		}
+	case "tiered":
+		for _, band := range rule.Bands {
+			if band.UpTo == 0 || amount <= band.UpTo {
+				fee = band.Fee
+				break
+			}
+		}
+	}
+	return math.Round(fee*100) / 100
+}
+
+// Evaluate applies every active rule to a transaction of amount in currency. usedThisMonth is how
+// many transactions of the same type the account already made this month; rules whose free
+// allowance covers this one charge nothing.
+func Evaluate(rules []models.FeeRule, amount float64, currency string, usedThisMonth int) []models.Fee {
+	charged := []models.Fee{}
+	for _, rule := range rules {
+		if !rule.Active || usedThisMonth < rule.FreePerMonth {
+			continue
+		}
+		if fee := Compute(rule, amount); fee > 0 {
+			charged = append(charged, models.Fee{RuleID: rule.ID, Name: rule.Name, Amount: fee, Currency: currency})
+		}
+	}
+	return charged
+}
+
+// Total sums the fees
+func Total(charged []models.Fee) float64 {
+	total := 0.0
+	for _, fee := range charged {
+		total += fee.Amount
+	}
+	return math.Round(total*100) / 100
+}
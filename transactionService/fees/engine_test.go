@@ -0,0 +1,43 @@
+package fees
+
+import (
+	"testing"
+	"transactionService/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCompute tests each fee type
+func TestCompute(t *testing.T) {
+	flat := models.FeeRule{FeeType: "flat", Amount: 2.5}
+	assert.Equal(t, 2.5, Compute(flat, 1000))
+
+	percentage := models.FeeRule{FeeType: "percentage", Rate: 0.01, MinFee: 1, MaxFee: 20}
+	assert.Equal(t, 1.0, Compute(percentage, 50))
+	assert.Equal(t, 5.55, Compute(percentage, 555))
+	assert.Equal(t, 20.0, Compute(percentage, 10000))
+
+	tiered := models.FeeRule{FeeType: "tiered", Bands: []models.FeeBand{{UpTo: 100, Fee: 0.5}, {UpTo: 1000, Fee: 2}, {Fee: 10}}}
+	assert.Equal(t, 0.5, Compute(tiered, 100))
+	assert.Equal(t, 2.0, Compute(tiered, 100.01))
+	assert.Equal(t, 10.0, Compute(tiered, 5000))
+}
+
+// TestEvaluateFreeAllowance tests that the first N transactions of the month are free
+func TestEvaluateFreeAllowance(t *testing.T) {
+	rules := []models.FeeRule{
+		{ID: 1, Name: "ATM", FeeType: "flat", Amount: 2, FreePerMonth: 3, Active: true},
+		{ID: 2, Name: "FX", FeeType: "percentage", Rate: 0.01, Active: true},
+		{ID: 3, Name: "Disabled", FeeType: "flat", Amount: 100, Active: false},
+	}
+
+	charged := Evaluate(rules, 200, "USD", 2)
+	assert.Len(t, charged, 1)
+	assert.Equal(t, int64(2), charged[0].RuleID)
+	assert.Equal(t, 2.0, Total(charged))
+
+	charged = Evaluate(rules, 200, "USD", 3)
+	assert.Len(t, charged, 2)
+	assert.Equal(t, 4.0, Total(charged))
+	assert.Equal(t, "USD", charged[0].Currency)
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"transactionService/database"
+	"transactionService/repositories"
+
+	"github.com/gorilla/mux"
+	"github.com/hashicorp/go-hclog"
+)
+
+// AccountHandler serves the account read model
+type AccountHandler struct {
+	repo  repositories.Repository
+	loggs *hclog.Logger
+}
+
+// NewAccountHandler creates a new AccountHandler instance
+func NewAccountHandler(db *database.PostgresPoolDB, lobbs *hclog.Logger) *AccountHandler {
+	return &AccountHandler{
+		repo:  repositories.NewUserRepository(db),
+		loggs: lobbs,
+	}
+}
+
+// GetAccount returns the account with its ledger balance and available balance
+func (h *AccountHandler) GetAccount(w http.ResponseWriter, r *http.Request) {
+	accountNumber := mux.Vars(r)["accountNumber"]
+
+	account, err := h.repo.GetAccount(r.Context(), accountNumber)
+	if err != nil {
+		(*h.loggs).Error("Error fetching account", "Account", accountNumber, "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to get account: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if account == nil {
+		http.Error(w, "Account not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, account)
+}
+
+// RegisterRoutes wires the account endpoints onto the router
+func (h *AccountHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/accounts/{accountNumber}", h.GetAccount).Methods("GET")
+}
@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"transactionService/database"
+	"transactionService/kafka"
+	"transactionService/models"
+	"transactionService/repositories"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/hashicorp/go-hclog"
+)
+
+// ApprovalHandler serves the approval rules of joint accounts and the decisions of their holders
+type ApprovalHandler struct {
+	approvalrepo repositories.ApprovalRepo
+	transactions *kafka.KafkaController // Cluster carrying the "transaction" topic
+	ledger       *kafka.KafkaController
+	loggs        *hclog.Logger
+}
+
+// NewApprovalHandler creates a new ApprovalHandler. Approved transactions are resubmitted to the
+// "transaction" topic on brokers.
+func NewApprovalHandler(db *database.PostgresPoolDB, brokers []string, lobbs *hclog.Logger) *ApprovalHandler {
+	return &ApprovalHandler{
+		approvalrepo: repositories.NewApprovalRepository(db),
+		transactions: &kafka.KafkaController{Brokers: brokers},
+		ledger:       &kafka.KafkaController{},
+		loggs:        lobbs,
+	}
+}
+
+// ListRules returns the approval rules of an account
+func (h *ApprovalHandler) ListRules(w http.ResponseWriter, r *http.Request) {
+	accountNumber := mux.Vars(r)["accountNumber"]
+
+	rules, err := h.approvalrepo.ListRules(r.Context(), accountNumber)
+	if err != nil {
+		(*h.loggs).Error("Error listing approval rules", "Account", accountNumber, "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to list approval rules: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, rules)
+}
+
+// UpsertRule sets how many holders must approve a transaction type at or above an amount
+func (h *ApprovalHandler) UpsertRule(w http.ResponseWriter, r *http.Request) {
+	var rule models.ApprovalRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	rule.AccountNumber, rule.TransactionType = mux.Vars(r)["accountNumber"], mux.Vars(r)["type"]
+	if err := rule.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.approvalrepo.UpsertRule(r.Context(), &rule); err != nil {
+		(*h.loggs).Error("Error saving approval rule", "Account", rule.AccountNumber, "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to save approval rule: %v", err), http.StatusBadRequest)
+		return
+	}
+	(*h.loggs).Info("Approval rule updated", "Account", rule.AccountNumber, "Type", rule.TransactionType,
+		"MinAmount", rule.MinAmount, "RequiredApprovals", rule.RequiredApprovals)
+	writeJSON(w, http.StatusOK, rule)
+}
+
+// DeleteRule removes an approval rule
+func (h *ApprovalHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	accountNumber, transactionType := mux.Vars(r)["accountNumber"], mux.Vars(r)["type"]
+
+	if err := h.approvalrepo.DeleteRule(r.Context(), accountNumber, transactionType); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to delete approval rule: %v", err), http.StatusNotFound)
+		return
+	}
+	(*h.loggs).Info("Approval rule deleted", "Account", accountNumber, "Type", transactionType)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"msg":     fmt.Sprintf("%s approval rule on account %s deleted", transactionType, accountNumber),
+	})
+}
+
+// ListApprovals returns the approval requests of an account, optionally filtered with ?status=pending
+func (h *ApprovalHandler) ListApprovals(w http.ResponseWriter, r *http.Request) {
+	accountNumber := mux.Vars(r)["accountNumber"]
+
+	approvals, err := h.approvalrepo.ListApprovals(r.Context(), accountNumber, r.URL.Query().Get("status"))
+	if err != nil {
+		(*h.loggs).Error("Error listing pending approvals", "Account", accountNumber, "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to list pending approvals: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, approvals)
+}
+
+// GetApproval returns an approval request with the decisions so far
+func (h *ApprovalHandler) GetApproval(w http.ResponseWriter, r *http.Request) {
+	id, ok := approvalID(w, r)
+	if !ok {
+		return
+	}
+
+	pending, err := h.approvalrepo.GetApproval(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get pending approval: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if pending == nil {
+		http.Error(w, "Pending approval not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, pending)
+}
+
+// Approve records a holder's approval and resubmits the transaction once enough holders approved
+func (h *ApprovalHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	id, ok := approvalID(w, r)
+	if !ok {
+		return
+	}
+	body, ok := decodeHolderDecision(w, r)
+	if !ok {
+		return
+	}
+
+	pending, err := h.approvalrepo.Approve(r.Context(), id, body.CustomerID)
+	if err != nil {
+		writeApprovalError(w, "approve", err)
+		return
+	}
+	(*h.loggs).Info("Transaction approved by holder", "Approval", id, "Customer", body.CustomerID, "Status", pending.Status)
+
+	if pending.Status == "approved" {
+		if err := h.transactions.PushToQueue("transaction", &pending.Transaction); err != nil {
+			(*h.loggs).Error("Failed to resubmit approved transaction", "Approval", id, "Transaction", pending.Transaction.ID, "Error", err)
+			http.Error(w, fmt.Sprintf("Approved, but the transaction could not be submitted, approve again to retry: %v", err), http.StatusBadGateway)
+			return
+		}
+		(*h.loggs).Info("Approved transaction resubmitted", "Approval", id, "Transaction", pending.Transaction.ID)
+	}
+	writeJSON(w, http.StatusOK, pending)
+}
+
+// Reject cancels a pending transaction on behalf of one of the holders
+func (h *ApprovalHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	id, ok := approvalID(w, r)
+	if !ok {
+		return
+	}
+	body, ok := decodeHolderDecision(w, r)
+	if !ok {
+		return
+	}
+
+	pending, err := h.approvalrepo.Reject(r.Context(), id, body.CustomerID, body.Note)
+	if err != nil {
+		writeApprovalError(w, "reject", err)
+		return
+	}
+	(*h.loggs).Info("Transaction rejected by holder", "Approval", id, "Customer", body.CustomerID)
+
+	trans := pending.Transaction
+	trans.Status = "failed"
+	if err := h.ledger.PushToQueue("dead-ledger", &trans); err != nil {
+		(*h.loggs).Error("Failed to push transaction to dead ledger", "Transaction", trans.ID, "Error", err)
+	}
+	writeJSON(w, http.StatusOK, pending)
+}
+
+// RegisterRoutes wires the approval endpoints onto the router
+func (h *ApprovalHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/accounts/{accountNumber}/approval-rules", h.ListRules).Methods("GET")
+	router.HandleFunc("/accounts/{accountNumber}/approval-rules/{type}", h.UpsertRule).Methods("PUT")
+	router.HandleFunc("/accounts/{accountNumber}/approval-rules/{type}", h.DeleteRule).Methods("DELETE")
+	router.HandleFunc("/accounts/{accountNumber}/approvals", h.ListApprovals).Methods("GET")
+	router.HandleFunc("/approvals/{id}", h.GetApproval).Methods("GET")
+	router.HandleFunc("/approvals/{id}/approve", h.Approve).Methods("POST")
+	router.HandleFunc("/approvals/{id}/reject", h.Reject).Methods("POST")
+}
+
+// holderDecision is the body of the approve and reject endpoints
+type holderDecision struct {
+	CustomerID string `json:"customer_id"`
+	Note       string `json:"note"`
+}
+
+// decodeHolderDecision reads the deciding holder and an optional note
+func decodeHolderDecision(w http.ResponseWriter, r *http.Request) (holderDecision, bool) {
+	var body holderDecision
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.CustomerID == "" {
+		http.Error(w, "Invalid request body, customer_id is required", http.StatusBadRequest)
+		return body, false
+	}
+	return body, true
+}
+
+// writeApprovalError maps approval errors onto HTTP statuses
+func writeApprovalError(w http.ResponseWriter, action string, err error) {
+	status := http.StatusUnprocessableEntity
+	if errors.Is(err, repositories.ErrApprovalNotFound) {
+		status = http.StatusNotFound
+	}
+	http.Error(w, fmt.Sprintf("Failed to %s transaction: %v", action, err), status)
+}
+
+// approvalID reads and validates the {id} path variable
+func approvalID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := mux.Vars(r)["id"]
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(w, "Invalid approval id", http.StatusBadRequest)
+		return "", false
+	}
+	return id, true
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"context"
+	"shared/audit"
+	"strconv"
+	"strings"
+	"transactionService/database"
+	"transactionService/models"
+	"transactionService/repositories"
+)
+
+// TrackResources tells the audit recorder how to load the resources the admin API changes, so that
+// their state before and after every change is recorded
+func TrackResources(recorder *audit.Recorder, db *database.PostgresPoolDB) {
+	accounts := repositories.NewUserRepository(db)
+	approvals := repositories.NewApprovalRepository(db)
+	tiers := repositories.NewTierRepository(db)
+	fx := repositories.NewFxRepository(db)
+	interest := repositories.NewInterestRepository(db)
+	fees := repositories.NewFeeRepository(db)
+	holds := repositories.NewHoldRepository(db)
+	payees := repositories.NewPayeeRepository(db)
+	risk := repositories.NewRiskRepository(db)
+
+	account := func(ctx context.Context, vars map[string]string) (any, error) {
+		return accounts.GetAccount(ctx, vars["accountNumber"])
+	}
+	for _, template := range []string{
+		"/admin/accounts/{accountNumber}/tier",
+		"/admin/accounts/{accountNumber}/overdraft",
+		"/admin/accounts/{accountNumber}/interest-plan",
+		"/admin/accounts/{accountNumber}/rebuild",
+	} {
+		recorder.Track(template, account)
+	}
+
+	recorder.Track("/accounts/{accountNumber}/approval-rules/{type}", func(ctx context.Context, vars map[string]string) (any, error) {
+		rules, err := approvals.ListRules(ctx, vars["accountNumber"])
+		return find(rules, err, func(rule models.ApprovalRule) bool { return rule.TransactionType == vars["type"] })
+	})
+	approval := func(ctx context.Context, vars map[string]string) (any, error) {
+		return approvals.GetApproval(ctx, vars["id"])
+	}
+	recorder.Track("/approvals/{id}/approve", approval)
+	recorder.Track("/approvals/{id}/reject", approval)
+
+	recorder.Track("/admin/tiers/{name}", func(ctx context.Context, vars map[string]string) (any, error) {
+		return tiers.GetTier(ctx, vars["name"])
+	})
+	recorder.Track("/admin/fx-rates/{base}/{quote}", func(ctx context.Context, vars map[string]string) (any, error) {
+		rates, err := fx.ListRates(ctx)
+		return find(rates, err, func(rate models.FxRate) bool {
+			return strings.EqualFold(rate.Base, vars["base"]) && strings.EqualFold(rate.Quote, vars["quote"])
+		})
+	})
+	recorder.Track("/admin/interest-plans/{name}", func(ctx context.Context, vars map[string]string) (any, error) {
+		plans, err := interest.ListPlans(ctx)
+		return find(plans, err, func(plan models.InterestPlan) bool { return plan.Name == vars["name"] })
+	})
+	recorder.Track("/admin/fee-rules/{id}", func(ctx context.Context, vars map[string]string) (any, error) {
+		id, err := strconv.ParseInt(vars["id"], 10, 64)
+		if err != nil {
+			return nil, nil
+		}
+		rules, err := fees.ListRules(ctx)
+		return find(rules, err, func(rule models.FeeRule) bool { return rule.ID == id })
+	})
+
+	hold := func(ctx context.Context, vars map[string]string) (any, error) {
+		return holds.GetHold(ctx, vars["id"])
+	}
+	recorder.Track("/holds/{id}/capture", hold)
+	recorder.Track("/holds/{id}/release", hold)
+
+	payee := func(ctx context.Context, vars map[string]string) (any, error) {
+		return payees.GetPayee(ctx, vars["id"])
+	}
+	recorder.Track("/payees/{id}", payee)
+	recorder.Track("/admin/payees/{id}/verification", payee)
+
+	review := func(ctx context.Context, vars map[string]string) (any, error) {
+		return risk.GetReview(ctx, vars["id"])
+	}
+	recorder.Track("/admin/risk-reviews/{id}/approve", review)
+	recorder.Track("/admin/risk-reviews/{id}/reject", review)
+}
+
+// find returns the first item that matches, or nil
+func find[T any](items []T, err error, match func(T) bool) (any, error) {
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		if match(items[i]) {
+			return &items[i], nil
+		}
+	}
+	return nil, nil
+}
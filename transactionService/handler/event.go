@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+	"transactionService/businessday"
+	"transactionService/database"
+	"transactionService/models"
+	"transactionService/repositories"
+
+	"github.com/gorilla/mux"
+	"github.com/hashicorp/go-hclog"
+)
+
+// EventHandler serves the event-sourced history of account balances
+type EventHandler struct {
+	eventrepo repositories.EventRepo
+	loggs     *hclog.Logger
+}
+
+// NewEventHandler creates a new EventHandler instance
+func NewEventHandler(db *database.PostgresPoolDB, lobbs *hclog.Logger) *EventHandler {
+	return &EventHandler{
+		eventrepo: repositories.NewEventRepository(db),
+		loggs:     lobbs,
+	}
+}
+
+// GetBalance returns the balance of an account rebuilt from its events as of ?asOf= (RFC 3339),
+// at the close of business day ?date= (YYYY-MM-DD), or now when neither is given
+func (h *EventHandler) GetBalance(w http.ResponseWriter, r *http.Request) {
+	accountNumber := mux.Vars(r)["accountNumber"]
+	query := r.URL.Query()
+	if query.Get("asOf") != "" && query.Get("date") != "" {
+		http.Error(w, "Give either asOf or date, not both", http.StatusBadRequest)
+		return
+	}
+
+	var balance *models.PointInTimeBalance
+	var err error
+	if raw := query.Get("date"); raw != "" {
+		date, ok := closedDate(w, raw)
+		if !ok {
+			return
+		}
+		balance, err = h.eventrepo.BalanceAtEndOfDay(r.Context(), accountNumber, date)
+	} else {
+		asOf := time.Now()
+		if raw := query.Get("asOf"); raw != "" {
+			parsed, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				http.Error(w, "Invalid asOf, expected an RFC 3339 timestamp", http.StatusBadRequest)
+				return
+			}
+			asOf = parsed
+		}
+		balance, err = h.eventrepo.BalanceAsOf(r.Context(), accountNumber, asOf)
+	}
+	if err != nil {
+		(*h.loggs).Error("Error replaying account events", "Account", accountNumber, "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to get balance: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if balance == nil {
+		http.Error(w, "Account not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, balance)
+}
+
+// ListEndOfDay returns the end-of-day balances of an account from ?from= to ?to= (YYYY-MM-DD,
+// inclusive). The range defaults to the 31 days up to the last closed business day.
+func (h *EventHandler) ListEndOfDay(w http.ResponseWriter, r *http.Request) {
+	accountNumber := mux.Vars(r)["accountNumber"]
+	query := r.URL.Query()
+	to := repositories.BusinessDays.LastClosed(time.Now())
+	if raw := query.Get("to"); raw != "" {
+		parsed, err := repositories.BusinessDays.Parse(raw)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		to = parsed
+	}
+	from := to.AddDate(0, 0, -30)
+	if raw := query.Get("from"); raw != "" {
+		parsed, err := repositories.BusinessDays.Parse(raw)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		from = parsed
+	}
+	if from.After(to) {
+		http.Error(w, "from is after to", http.StatusBadRequest)
+		return
+	}
+
+	balances, err := h.eventrepo.ListEndOfDay(r.Context(), accountNumber, from, to)
+	if err != nil {
+		(*h.loggs).Error("Error listing end-of-day balances", "Account", accountNumber, "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to list end-of-day balances: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, balances)
+}
+
+// SnapshotEndOfDay records the end-of-day balances of a closed business day ?date= that the job
+// has not, e.g. one before the job was first deployed. Balances already recorded are kept.
+func (h *EventHandler) SnapshotEndOfDay(w http.ResponseWriter, r *http.Request) {
+	date, ok := closedDate(w, r.URL.Query().Get("date"))
+	if !ok {
+		return
+	}
+
+	recorded, err := h.eventrepo.SnapshotEndOfDay(r.Context(), date)
+	if err != nil {
+		(*h.loggs).Error("Error recording end-of-day balances", "Date", date.Format(businessday.DateLayout), "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to record end-of-day balances: %v", err), http.StatusInternalServerError)
+		return
+	}
+	(*h.loggs).Info("End-of-day balances backfilled", "Date", date.Format(businessday.DateLayout), "Accounts", recorded)
+	writeJSON(w, http.StatusOK, map[string]any{"business_date": date.Format(businessday.DateLayout), "recorded": recorded})
+}
+
+// closedDate parses a business date and checks that the day has closed, answering the request
+// itself when either fails
+func closedDate(w http.ResponseWriter, raw string) (time.Time, bool) {
+	date, err := repositories.BusinessDays.Parse(raw)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return time.Time{}, false
+	}
+	if !repositories.BusinessDays.IsClosed(date, time.Now()) {
+		http.Error(w, "Business day has not closed yet", http.StatusBadRequest)
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// ListEvents returns the events of an account after version ?after=, oldest first, up to ?limit=
+func (h *EventHandler) ListEvents(w http.ResponseWriter, r *http.Request) {
+	accountNumber := mux.Vars(r)["accountNumber"]
+	query := r.URL.Query()
+	after := int64(0)
+	if raw := query.Get("after"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed < 0 {
+			http.Error(w, "Invalid after", http.StatusBadRequest)
+			return
+		}
+		after = parsed
+	}
+	limit := 100
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	events, err := h.eventrepo.ListEvents(r.Context(), accountNumber, after, limit)
+	if err != nil {
+		(*h.loggs).Error("Error listing account events", "Account", accountNumber, "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to list events: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, events)
+}
+
+// RebuildProjection replays an account's events and overwrites its projected balance
+func (h *EventHandler) RebuildProjection(w http.ResponseWriter, r *http.Request) {
+	accountNumber := mux.Vars(r)["accountNumber"]
+
+	rebuild, err := h.eventrepo.RebuildProjection(r.Context(), accountNumber)
+	if err != nil {
+		(*h.loggs).Error("Error rebuilding projection", "Account", accountNumber, "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to rebuild projection: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if rebuild == nil {
+		http.Error(w, "Account not found", http.StatusNotFound)
+		return
+	}
+	if rebuild.Balance != rebuild.PreviousBalance {
+		(*h.loggs).Warn("Projected balance corrected", "Account", accountNumber, "Previous", rebuild.PreviousBalance, "Balance", rebuild.Balance)
+	}
+	writeJSON(w, http.StatusOK, rebuild)
+}
+
+// RebuildAllProjections replays the events of every account and returns those whose projected
+// balance had drifted
+func (h *EventHandler) RebuildAllProjections(w http.ResponseWriter, r *http.Request) {
+	changed, err := h.eventrepo.RebuildAllProjections(r.Context())
+	if err != nil {
+		(*h.loggs).Error("Error rebuilding projections", "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to rebuild projections: %v", err), http.StatusInternalServerError)
+		return
+	}
+	(*h.loggs).Info("Projections rebuilt", "Corrected", len(changed))
+	writeJSON(w, http.StatusOK, changed)
+}
+
+// RegisterRoutes wires the account event endpoints onto the router
+func (h *EventHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/accounts/{accountNumber}/balance", h.GetBalance).Methods("GET")
+	router.HandleFunc("/accounts/{accountNumber}/events", h.ListEvents).Methods("GET")
+	router.HandleFunc("/accounts/{accountNumber}/eod-balances", h.ListEndOfDay).Methods("GET")
+	router.HandleFunc("/admin/accounts/{accountNumber}/rebuild", h.RebuildProjection).Methods("POST")
+	router.HandleFunc("/admin/projections/rebuild", h.RebuildAllProjections).Methods("POST")
+	router.HandleFunc("/admin/eod-balances", h.SnapshotEndOfDay).Methods("POST")
+}
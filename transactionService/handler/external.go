@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"transactionService/database"
+	"transactionService/models"
+	"transactionService/repositories"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/hashicorp/go-hclog"
+)
+
+// ExternalHandler serves the status of transfers to other banks
+type ExternalHandler struct {
+	externalrepo repositories.ExternalRepo
+	loggs        *hclog.Logger
+}
+
+// NewExternalHandler creates a new ExternalHandler instance
+func NewExternalHandler(db *database.PostgresPoolDB, lobbs *hclog.Logger) *ExternalHandler {
+	return &ExternalHandler{
+		externalrepo: repositories.NewExternalRepository(db),
+		loggs:        lobbs,
+	}
+}
+
+// ListExternalTransfers returns the external transfers of an account, optionally filtered by ?status=
+func (h *ExternalHandler) ListExternalTransfers(w http.ResponseWriter, r *http.Request) {
+	accountNumber := mux.Vars(r)["accountNumber"]
+	status := r.URL.Query().Get("status")
+	if status != "" && status != models.ExternalInitiated && status != models.ExternalSubmitted &&
+		status != models.ExternalSettled && status != models.ExternalReturned {
+		http.Error(w, "Invalid status filter", http.StatusBadRequest)
+		return
+	}
+
+	transfers, err := h.externalrepo.ListExternalTransfers(r.Context(), accountNumber, status)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list external transfers: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, transfers)
+}
+
+// GetExternalTransfer returns an external transfer with its clearing state
+func (h *ExternalHandler) GetExternalTransfer(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(w, "Invalid external transfer id", http.StatusBadRequest)
+		return
+	}
+
+	transfer, err := h.externalrepo.GetExternalTransfer(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get external transfer: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if transfer == nil {
+		http.Error(w, "External transfer not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, transfer)
+}
+
+// RegisterRoutes wires the external transfer endpoints onto the router
+func (h *ExternalHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/accounts/{accountNumber}/external-transfers", h.ListExternalTransfers).Methods("GET")
+	router.HandleFunc("/external-transfers/{id}", h.GetExternalTransfer).Methods("GET")
+}
@@ -1 +1,362 @@
 package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"transactionService/database"
+	"transactionService/models"
+	"transactionService/repositories"
+
+	"github.com/gorilla/mux"
+	"github.com/hashicorp/go-hclog"
+)
+
+// AdminHandler serves the back-office endpoints of the transaction service
+type AdminHandler struct {
+	tierrepo      repositories.TierRepo
+	overdraftrepo repositories.OverdraftRepo
+	fxrepo        repositories.FxRepo
+	interestrepo  repositories.InterestRepo
+	feerepo       repositories.FeeRepo
+	loggs         *hclog.Logger
+}
+
+// NewAdminHandler creates a new AdminHandler instance
+func NewAdminHandler(db *database.PostgresPoolDB, lobbs *hclog.Logger) *AdminHandler {
+	return &AdminHandler{
+		tierrepo:      repositories.NewTierRepository(db),
+		overdraftrepo: repositories.NewOverdraftRepository(db),
+		fxrepo:        repositories.NewFxRepository(db),
+		interestrepo:  repositories.NewInterestRepository(db),
+		feerepo:       repositories.NewFeeRepository(db),
+		loggs:         lobbs,
+	}
+}
+
+// ListTiers returns every account tier with its limits
+func (h *AdminHandler) ListTiers(w http.ResponseWriter, r *http.Request) {
+	tiers, err := h.tierrepo.ListTiers(r.Context())
+	if err != nil {
+		(*h.loggs).Error("Error listing tiers", "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to list tiers: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, tiers)
+}
+
+// GetTier returns a single account tier
+func (h *AdminHandler) GetTier(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	tier, err := h.tierrepo.GetTier(r.Context(), name)
+	if err != nil {
+		(*h.loggs).Error("Error fetching tier", "Tier", name, "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to get tier: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if tier == nil {
+		http.Error(w, "Tier not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, tier)
+}
+
+// UpsertTier creates a tier or replaces its limits
+func (h *AdminHandler) UpsertTier(w http.ResponseWriter, r *http.Request) {
+	var tier models.AccountTier
+	if err := json.NewDecoder(r.Body).Decode(&tier); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	tier.Name = mux.Vars(r)["name"]
+	if err := tier.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.tierrepo.UpsertTier(r.Context(), &tier); err != nil {
+		(*h.loggs).Error("Error saving tier", "Tier", tier.Name, "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to save tier: %v", err), http.StatusInternalServerError)
+		return
+	}
+	(*h.loggs).Info("Tier limits updated", "Tier", tier.Name)
+	writeJSON(w, http.StatusOK, tier)
+}
+
+// AssignTier moves an account onto another tier
+func (h *AdminHandler) AssignTier(w http.ResponseWriter, r *http.Request) {
+	accountNumber := mux.Vars(r)["accountNumber"]
+
+	var body struct {
+		Tier string `json:"tier"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Tier == "" {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	tier, err := h.tierrepo.GetTier(r.Context(), body.Tier)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get tier: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if tier == nil {
+		http.Error(w, "Tier not found", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.tierrepo.AssignTier(r.Context(), accountNumber, body.Tier); err != nil {
+		(*h.loggs).Error("Error assigning tier", "Account", accountNumber, "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to assign tier: %v", err), http.StatusInternalServerError)
+		return
+	}
+	(*h.loggs).Info("Account tier changed", "Account", accountNumber, "Tier", body.Tier)
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"msg":     fmt.Sprintf("account %s moved to tier %s", accountNumber, body.Tier),
+	})
+}
+
+// SetOverdraft configures the overdraft facility of an account
+func (h *AdminHandler) SetOverdraft(w http.ResponseWriter, r *http.Request) {
+	accountNumber := mux.Vars(r)["accountNumber"]
+
+	var body struct {
+		Limit        float64 `json:"limit"`
+		InterestRate float64 `json:"interest_rate"` // Annual rate, e.g. 0.18 for 18%
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if body.Limit < 0 || body.InterestRate < 0 {
+		http.Error(w, "Overdraft limit and interest rate cannot be negative", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.overdraftrepo.SetOverdraft(r.Context(), accountNumber, body.Limit, body.InterestRate); err != nil {
+		(*h.loggs).Error("Error setting overdraft", "Account", accountNumber, "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to set overdraft: %v", err), http.StatusBadRequest)
+		return
+	}
+	(*h.loggs).Info("Overdraft updated", "Account", accountNumber, "Limit", body.Limit, "Rate", body.InterestRate)
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"msg":     fmt.Sprintf("overdraft of %.2f set on account %s", body.Limit, accountNumber),
+	})
+}
+
+// ListFxRates returns every configured exchange rate
+func (h *AdminHandler) ListFxRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.fxrepo.ListRates(r.Context())
+	if err != nil {
+		(*h.loggs).Error("Error listing fx rates", "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to list fx rates: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, rates)
+}
+
+// UpsertFxRate creates or replaces the rate of a currency pair
+func (h *AdminHandler) UpsertFxRate(w http.ResponseWriter, r *http.Request) {
+	var rate models.FxRate
+	if err := json.NewDecoder(r.Body).Decode(&rate); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	rate.Base, rate.Quote = mux.Vars(r)["base"], mux.Vars(r)["quote"]
+	if err := rate.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.fxrepo.UpsertRate(r.Context(), &rate); err != nil {
+		(*h.loggs).Error("Error saving fx rate", "Base", rate.Base, "Quote", rate.Quote, "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to save fx rate: %v", err), http.StatusInternalServerError)
+		return
+	}
+	(*h.loggs).Info("FX rate updated", "Base", rate.Base, "Quote", rate.Quote, "Rate", rate.Rate)
+	writeJSON(w, http.StatusOK, rate)
+}
+
+// ListInterestPlans returns every savings interest plan
+func (h *AdminHandler) ListInterestPlans(w http.ResponseWriter, r *http.Request) {
+	plans, err := h.interestrepo.ListPlans(r.Context())
+	if err != nil {
+		(*h.loggs).Error("Error listing interest plans", "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to list interest plans: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, plans)
+}
+
+// UpsertInterestPlan creates an interest plan or replaces its terms
+func (h *AdminHandler) UpsertInterestPlan(w http.ResponseWriter, r *http.Request) {
+	var plan models.InterestPlan
+	if err := json.NewDecoder(r.Body).Decode(&plan); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	plan.Name = mux.Vars(r)["name"]
+	if err := plan.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.interestrepo.UpsertPlan(r.Context(), &plan); err != nil {
+		(*h.loggs).Error("Error saving interest plan", "Plan", plan.Name, "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to save interest plan: %v", err), http.StatusInternalServerError)
+		return
+	}
+	(*h.loggs).Info("Interest plan updated", "Plan", plan.Name)
+	writeJSON(w, http.StatusOK, plan)
+}
+
+// AssignInterestPlan puts an account on a savings plan, or takes it off with an empty plan
+func (h *AdminHandler) AssignInterestPlan(w http.ResponseWriter, r *http.Request) {
+	accountNumber := mux.Vars(r)["accountNumber"]
+
+	var body struct {
+		Plan string `json:"plan"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.interestrepo.AssignPlan(r.Context(), accountNumber, body.Plan); err != nil {
+		(*h.loggs).Error("Error assigning interest plan", "Account", accountNumber, "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to assign interest plan: %v", err), http.StatusBadRequest)
+		return
+	}
+	(*h.loggs).Info("Account interest plan changed", "Account", accountNumber, "Plan", body.Plan)
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"msg":     fmt.Sprintf("account %s interest plan set to %q", accountNumber, body.Plan),
+	})
+}
+
+// ListAccruals returns the daily interest accrued by an account
+func (h *AdminHandler) ListAccruals(w http.ResponseWriter, r *http.Request) {
+	accountNumber := mux.Vars(r)["accountNumber"]
+
+	accruals, err := h.interestrepo.ListAccruals(r.Context(), accountNumber)
+	if err != nil {
+		(*h.loggs).Error("Error listing accruals", "Account", accountNumber, "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to list accruals: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, accruals)
+}
+
+// ListFeeRules returns every fee rule
+func (h *AdminHandler) ListFeeRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.feerepo.ListRules(r.Context())
+	if err != nil {
+		(*h.loggs).Error("Error listing fee rules", "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to list fee rules: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, rules)
+}
+
+// CreateFeeRule adds a fee rule
+func (h *AdminHandler) CreateFeeRule(w http.ResponseWriter, r *http.Request) {
+	var rule models.FeeRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := rule.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.feerepo.CreateRule(r.Context(), &rule); err != nil {
+		(*h.loggs).Error("Error creating fee rule", "Rule", rule.Name, "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to create fee rule: %v", err), http.StatusInternalServerError)
+		return
+	}
+	(*h.loggs).Info("Fee rule created", "ID", rule.ID, "Rule", rule.Name)
+	writeJSON(w, http.StatusCreated, rule)
+}
+
+// UpdateFeeRule replaces a fee rule
+func (h *AdminHandler) UpdateFeeRule(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid fee rule id", http.StatusBadRequest)
+		return
+	}
+	var rule models.FeeRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	rule.ID = id
+	if err := rule.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.feerepo.UpdateRule(r.Context(), &rule); err != nil {
+		(*h.loggs).Error("Error updating fee rule", "ID", id, "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to update fee rule: %v", err), http.StatusBadRequest)
+		return
+	}
+	(*h.loggs).Info("Fee rule updated", "ID", id)
+	writeJSON(w, http.StatusOK, rule)
+}
+
+// DeleteFeeRule removes a fee rule
+func (h *AdminHandler) DeleteFeeRule(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid fee rule id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.feerepo.DeleteRule(r.Context(), id); err != nil {
+		(*h.loggs).Error("Error deleting fee rule", "ID", id, "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to delete fee rule: %v", err), http.StatusBadRequest)
+		return
+	}
+	(*h.loggs).Info("Fee rule deleted", "ID", id)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"msg":     fmt.Sprintf("fee rule %d deleted", id),
+	})
+}
+
+// RegisterRoutes wires the admin endpoints onto the router
+func (h *AdminHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/admin/tiers", h.ListTiers).Methods("GET")
+	router.HandleFunc("/admin/tiers/{name}", h.GetTier).Methods("GET")
+	router.HandleFunc("/admin/tiers/{name}", h.UpsertTier).Methods("PUT")
+	router.HandleFunc("/admin/accounts/{accountNumber}/tier", h.AssignTier).Methods("PUT")
+	router.HandleFunc("/admin/accounts/{accountNumber}/overdraft", h.SetOverdraft).Methods("PUT")
+	router.HandleFunc("/admin/fx-rates", h.ListFxRates).Methods("GET")
+	router.HandleFunc("/admin/fx-rates/{base}/{quote}", h.UpsertFxRate).Methods("PUT")
+	router.HandleFunc("/admin/interest-plans", h.ListInterestPlans).Methods("GET")
+	router.HandleFunc("/admin/interest-plans/{name}", h.UpsertInterestPlan).Methods("PUT")
+	router.HandleFunc("/admin/accounts/{accountNumber}/interest-plan", h.AssignInterestPlan).Methods("PUT")
+	router.HandleFunc("/admin/accounts/{accountNumber}/accruals", h.ListAccruals).Methods("GET")
+	router.HandleFunc("/admin/fee-rules", h.ListFeeRules).Methods("GET")
+	router.HandleFunc("/admin/fee-rules", h.CreateFeeRule).Methods("POST")
+	router.HandleFunc("/admin/fee-rules/{id}", h.UpdateFeeRule).Methods("PUT")
+	router.HandleFunc("/admin/fee-rules/{id}", h.DeleteFeeRule).Methods("DELETE")
+}
+
+// writeJSON encodes body as the JSON response
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		fmt.Println(err)
+	}
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+	"transactionService/database"
+	"transactionService/kafka"
+	"transactionService/models"
+	"transactionService/repositories"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/hashicorp/go-hclog"
+)
+
+// defaultHoldTTL is used when a hold request does not specify its own TTL
+const defaultHoldTTL = 7 * 24 * time.Hour
+
+// HoldHandler serves the two-phase debit endpoints: create, capture and release holds
+type HoldHandler struct {
+	holdrepo repositories.HoldRepo
+	kafkactl *kafka.KafkaController
+	loggs    *hclog.Logger
+}
+
+// NewHoldHandler creates a new HoldHandler instance
+func NewHoldHandler(db *database.PostgresPoolDB, lobbs *hclog.Logger) *HoldHandler {
+	return &HoldHandler{
+		holdrepo: repositories.NewHoldRepository(db),
+		kafkactl: &kafka.KafkaController{},
+		loggs:    lobbs,
+	}
+}
+
+// CreateHold reserves funds on an account
+func (h *HoldHandler) CreateHold(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		AccountNumber string  `json:"account_number"`
+		Amount        float64 `json:"amount"`
+		Reference     string  `json:"reference"`
+		TTLSeconds    int64   `json:"ttl_seconds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.AccountNumber == "" {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if body.Amount <= 0 || body.TTLSeconds < 0 {
+		http.Error(w, "Amount must be positive and TTL cannot be negative", http.StatusBadRequest)
+		return
+	}
+
+	ttl := defaultHoldTTL
+	if body.TTLSeconds > 0 {
+		ttl = time.Duration(body.TTLSeconds) * time.Second
+	}
+	hold := &models.Hold{
+		AccountNumber: body.AccountNumber,
+		Amount:        body.Amount,
+		Reference:     body.Reference,
+		ExpiresAt:     time.Now().Add(ttl),
+	}
+
+	if err := h.holdrepo.CreateHold(r.Context(), hold); err != nil {
+		(*h.loggs).Error("Error creating hold", "Account", body.AccountNumber, "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to create hold: %v", err), http.StatusUnprocessableEntity)
+		return
+	}
+	(*h.loggs).Info("Hold created", "Hold", hold.ID, "Account", hold.AccountNumber, "Amount", hold.Amount)
+	h.publish(hold)
+	writeJSON(w, http.StatusCreated, hold)
+}
+
+// GetHold returns a hold
+func (h *HoldHandler) GetHold(w http.ResponseWriter, r *http.Request) {
+	id, ok := holdID(w, r)
+	if !ok {
+		return
+	}
+
+	hold, err := h.holdrepo.GetHold(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get hold: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if hold == nil {
+		http.Error(w, "Hold not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, hold)
+}
+
+// CaptureHold posts the debit for all or part of a hold
+func (h *HoldHandler) CaptureHold(w http.ResponseWriter, r *http.Request) {
+	id, ok := holdID(w, r)
+	if !ok {
+		return
+	}
+
+	// An empty body or zero amount captures the full hold
+	var body struct {
+		Amount float64 `json:"amount"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	hold, trans, err := h.holdrepo.CaptureHold(r.Context(), id, body.Amount)
+	if err != nil {
+		(*h.loggs).Error("Error capturing hold", "Hold", id, "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to capture hold: %v", err), http.StatusUnprocessableEntity)
+		return
+	}
+	(*h.loggs).Info("Hold captured", "Hold", id, "Amount", hold.CapturedAmount)
+
+	// push the captured debit into the ledger
+	trans.CreatedAt, trans.Status = time.Now(), "completed"
+	if err := h.kafkactl.PushToQueue("transaction-ledger", trans); err != nil {
+		(*h.loggs).Error("Failed to push captured hold to ledger", "Hold", id, "Error", err)
+	}
+	h.publish(hold)
+	writeJSON(w, http.StatusOK, hold)
+}
+
+// ReleaseHold cancels a hold without debiting the account
+func (h *HoldHandler) ReleaseHold(w http.ResponseWriter, r *http.Request) {
+	id, ok := holdID(w, r)
+	if !ok {
+		return
+	}
+
+	hold, err := h.holdrepo.ReleaseHold(r.Context(), id)
+	if err != nil {
+		(*h.loggs).Error("Error releasing hold", "Hold", id, "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to release hold: %v", err), http.StatusUnprocessableEntity)
+		return
+	}
+	(*h.loggs).Info("Hold released", "Hold", id)
+	h.publish(hold)
+	writeJSON(w, http.StatusOK, hold)
+}
+
+// publish tells the read models about the hold's new status. The hold is already stored, so a
+// failure is only logged.
+func (h *HoldHandler) publish(hold *models.Hold) {
+	if err := h.kafkactl.PushHoldToQueue(hold); err != nil {
+		(*h.loggs).Error("Failed to publish hold", "Hold", hold.ID, "Error", err)
+	}
+}
+
+// RegisterRoutes wires the hold endpoints onto the router
+func (h *HoldHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/holds", h.CreateHold).Methods("POST")
+	router.HandleFunc("/holds/{id}", h.GetHold).Methods("GET")
+	router.HandleFunc("/holds/{id}/capture", h.CaptureHold).Methods("POST")
+	router.HandleFunc("/holds/{id}/release", h.ReleaseHold).Methods("POST")
+}
+
+// holdID reads and validates the {id} path variable
+func holdID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := mux.Vars(r)["id"]
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(w, "Invalid hold id", http.StatusBadRequest)
+		return "", false
+	}
+	return id, true
+}
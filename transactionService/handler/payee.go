@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"transactionService/database"
+	"transactionService/models"
+	"transactionService/repositories"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/hashicorp/go-hclog"
+)
+
+// PayeeHandler serves the payee registry endpoints
+type PayeeHandler struct {
+	payeerepo repositories.PayeeRepo
+	loggs     *hclog.Logger
+}
+
+// NewPayeeHandler creates a new PayeeHandler instance
+func NewPayeeHandler(db *database.PostgresPoolDB, lobbs *hclog.Logger) *PayeeHandler {
+	return &PayeeHandler{
+		payeerepo: repositories.NewPayeeRepository(db),
+		loggs:     lobbs,
+	}
+}
+
+// ListPayees returns the payees registered on an account
+func (h *PayeeHandler) ListPayees(w http.ResponseWriter, r *http.Request) {
+	accountNumber := mux.Vars(r)["accountNumber"]
+
+	payees, err := h.payeerepo.ListPayees(r.Context(), accountNumber)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list payees: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, payees)
+}
+
+// CreatePayee registers a payee on an account
+func (h *PayeeHandler) CreatePayee(w http.ResponseWriter, r *http.Request) {
+	var payee models.Payee
+	if err := json.NewDecoder(r.Body).Decode(&payee); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	payee.AccountNumber = mux.Vars(r)["accountNumber"]
+
+	if err := h.payeerepo.CreatePayee(r.Context(), &payee); err != nil {
+		(*h.loggs).Error("Error creating payee", "Account", payee.AccountNumber, "Error", err)
+		writePayeeError(w, "create", err)
+		return
+	}
+	(*h.loggs).Info("Payee created", "Payee", payee.ID, "Account", payee.AccountNumber, "Status", payee.Status)
+	writeJSON(w, http.StatusCreated, payee)
+}
+
+// GetPayee returns a payee
+func (h *PayeeHandler) GetPayee(w http.ResponseWriter, r *http.Request) {
+	id, ok := payeeID(w, r)
+	if !ok {
+		return
+	}
+
+	payee, err := h.payeerepo.GetPayee(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get payee: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if payee == nil {
+		http.Error(w, "Payee not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, payee)
+}
+
+// DeletePayee removes a payee
+func (h *PayeeHandler) DeletePayee(w http.ResponseWriter, r *http.Request) {
+	id, ok := payeeID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.payeerepo.DeletePayee(r.Context(), id); err != nil {
+		writePayeeError(w, "delete", err)
+		return
+	}
+	(*h.loggs).Info("Payee deleted", "Payee", id)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"msg":     fmt.Sprintf("payee %s deleted", id),
+	})
+}
+
+// SetPayeeStatus records a manual verification of a payee
+func (h *PayeeHandler) SetPayeeStatus(w http.ResponseWriter, r *http.Request) {
+	id, ok := payeeID(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	payee, err := h.payeerepo.SetPayeeStatus(r.Context(), id, body.Status)
+	if err != nil {
+		writePayeeError(w, "update", err)
+		return
+	}
+	(*h.loggs).Info("Payee verification updated", "Payee", id, "Status", payee.Status)
+	writeJSON(w, http.StatusOK, payee)
+}
+
+// RegisterRoutes wires the payee endpoints onto the router
+func (h *PayeeHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/accounts/{accountNumber}/payees", h.ListPayees).Methods("GET")
+	router.HandleFunc("/accounts/{accountNumber}/payees", h.CreatePayee).Methods("POST")
+	router.HandleFunc("/payees/{id}", h.GetPayee).Methods("GET")
+	router.HandleFunc("/payees/{id}", h.DeletePayee).Methods("DELETE")
+	router.HandleFunc("/admin/payees/{id}/verification", h.SetPayeeStatus).Methods("PUT")
+}
+
+// writePayeeError maps payee errors onto HTTP statuses
+func writePayeeError(w http.ResponseWriter, action string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, repositories.ErrPayeeNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, repositories.ErrPayeeNotAllowed):
+		status = http.StatusUnprocessableEntity
+	}
+	http.Error(w, fmt.Sprintf("Failed to %s payee: %v", action, err), status)
+}
+
+// payeeID reads and validates the {id} path variable
+func payeeID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := mux.Vars(r)["id"]
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(w, "Invalid payee id", http.StatusBadRequest)
+		return "", false
+	}
+	return id, true
+}
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"transactionService/database"
+	"transactionService/kafka"
+	"transactionService/repositories"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/hashicorp/go-hclog"
+)
+
+// RiskHandler serves the manual review queue of transactions held by the risk checks and the
+// sanctions screening audit trail
+type RiskHandler struct {
+	riskrepo      repositories.RiskRepo
+	screeningrepo repositories.ScreeningRepo
+	transactions  *kafka.KafkaController // Cluster carrying the "transaction" topic
+	kafkactl      *kafka.KafkaController
+	loggs         *hclog.Logger
+}
+
+// NewRiskHandler creates a new RiskHandler instance. Approved transactions are resubmitted to the
+// "transaction" topic on brokers.
+func NewRiskHandler(db *database.PostgresPoolDB, brokers []string, lobbs *hclog.Logger) *RiskHandler {
+	return &RiskHandler{
+		riskrepo:      repositories.NewRiskRepository(db),
+		screeningrepo: repositories.NewScreeningRepository(db),
+		transactions:  &kafka.KafkaController{Brokers: brokers},
+		kafkactl:      &kafka.KafkaController{},
+		loggs:         lobbs,
+	}
+}
+
+// reviewDecision is the body of the approve and reject endpoints
+type reviewDecision struct {
+	Reviewer string `json:"reviewer"`
+	Note     string `json:"note"`
+}
+
+// ListReviews returns the reviews, optionally filtered with ?status=pending
+func (h *RiskHandler) ListReviews(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+
+	reviews, err := h.riskrepo.ListReviews(r.Context(), status)
+	if err != nil {
+		(*h.loggs).Error("Error listing risk reviews", "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to list risk reviews: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, reviews)
+}
+
+// GetReview returns a single review
+func (h *RiskHandler) GetReview(w http.ResponseWriter, r *http.Request) {
+	id, ok := reviewID(w, r)
+	if !ok {
+		return
+	}
+
+	review, err := h.riskrepo.GetReview(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get risk review: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if review == nil {
+		http.Error(w, "Risk review not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, review)
+}
+
+// ApproveReview releases a held transaction: it is resubmitted to the "transaction" topic, where
+// the approved review lets it past the risk checks and it is applied like any other
+func (h *RiskHandler) ApproveReview(w http.ResponseWriter, r *http.Request) {
+	id, ok := reviewID(w, r)
+	if !ok {
+		return
+	}
+	body, ok := decodeDecision(w, r)
+	if !ok {
+		return
+	}
+
+	review, err := h.riskrepo.DecideReview(r.Context(), id, "approved", body.Reviewer, body.Note)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to approve risk review: %v", err), http.StatusConflict)
+		return
+	}
+	(*h.loggs).Info("Risk review approved", "Review", id, "Transaction", review.Transaction.ID, "Reviewer", body.Reviewer)
+
+	if err := h.transactions.PushToQueue("transaction", &review.Transaction); err != nil {
+		(*h.loggs).Error("Failed to resubmit approved transaction", "Review", id, "Transaction", review.Transaction.ID, "Error", err)
+		http.Error(w, fmt.Sprintf("Approved, but the transaction could not be submitted, approve again to retry: %v", err), http.StatusBadGateway)
+		return
+	}
+	(*h.loggs).Info("Approved transaction resubmitted", "Review", id, "Transaction", review.Transaction.ID)
+	writeJSON(w, http.StatusOK, review)
+}
+
+// RejectReview refuses a held transaction; no balance moves
+func (h *RiskHandler) RejectReview(w http.ResponseWriter, r *http.Request) {
+	id, ok := reviewID(w, r)
+	if !ok {
+		return
+	}
+	body, ok := decodeDecision(w, r)
+	if !ok {
+		return
+	}
+
+	review, err := h.riskrepo.DecideReview(r.Context(), id, "rejected", body.Reviewer, body.Note)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to reject risk review: %v", err), http.StatusConflict)
+		return
+	}
+	(*h.loggs).Info("Risk review rejected", "Review", id, "Transaction", review.Transaction.ID, "Reviewer", body.Reviewer)
+
+	trans := review.Transaction
+	trans.Status = "failed"
+	if err := h.kafkactl.PushToQueue("dead-ledger", &trans); err != nil {
+		(*h.loggs).Error("Failed to push transaction to dead ledger", "Transaction", trans.ID, "Error", err)
+	}
+	writeJSON(w, http.StatusOK, review)
+}
+
+// ListScreenings returns the sanctions screening results for ?reference= (an account number or
+// transaction ID), or the latest results, optionally filtered with ?decision=block|flag|clear
+func (h *RiskHandler) ListScreenings(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit := 100
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	results, err := h.screeningrepo.ListScreenings(r.Context(), query.Get("reference"), query.Get("decision"), limit)
+	if err != nil {
+		(*h.loggs).Error("Error listing screening results", "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to list screening results: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+// RegisterRoutes wires the risk review endpoints onto the router
+func (h *RiskHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/admin/risk-reviews", h.ListReviews).Methods("GET")
+	router.HandleFunc("/admin/risk-reviews/{id}", h.GetReview).Methods("GET")
+	router.HandleFunc("/admin/risk-reviews/{id}/approve", h.ApproveReview).Methods("POST")
+	router.HandleFunc("/admin/risk-reviews/{id}/reject", h.RejectReview).Methods("POST")
+	router.HandleFunc("/admin/screening-results", h.ListScreenings).Methods("GET")
+}
+
+// reviewID reads and validates the {id} path variable
+func reviewID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := mux.Vars(r)["id"]
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(w, "Invalid risk review id", http.StatusBadRequest)
+		return "", false
+	}
+	return id, true
+}
+
+// decodeDecision reads the optional reviewer and note of a decision
+func decodeDecision(w http.ResponseWriter, r *http.Request) (reviewDecision, bool) {
+	var body reviewDecision
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return body, false
+		}
+	}
+	return body, true
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	"transactionService/database"
+	"transactionService/kafka"
+	"transactionService/models"
+	"transactionService/repositories"
+	"transactionService/saga"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/hashicorp/go-hclog"
+)
+
+// SagaHandler starts multi-step transactions and shows how far they got
+type SagaHandler struct {
+	sagarepo     repositories.SagaRepo
+	transactions *kafka.KafkaController // Cluster carrying the "transaction" topic
+	loggs        *hclog.Logger
+}
+
+// NewSagaHandler creates a new SagaHandler. Saga steps are published to the "transaction" topic on brokers.
+func NewSagaHandler(db *database.PostgresPoolDB, brokers []string, lobbs *hclog.Logger) *SagaHandler {
+	return &SagaHandler{
+		sagarepo:     repositories.NewSagaRepository(db),
+		transactions: &kafka.KafkaController{Brokers: brokers},
+		loggs:        lobbs,
+	}
+}
+
+// StartSaga plans a saga, stores it and publishes its first step. A request repeating the ID of
+// a saga already started returns that saga.
+func (h *SagaHandler) StartSaga(w http.ResponseWriter, r *http.Request) {
+	var req models.SagaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	s, err := saga.Plan(req, repositories.FeeIncomeAccount, time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.sagarepo.CreateSaga(r.Context(), s)
+	if errors.Is(err, repositories.ErrDuplicateSaga) {
+		existing, err := h.sagarepo.GetSaga(r.Context(), s.ID.String())
+		if err != nil || existing == nil {
+			http.Error(w, fmt.Sprintf("Failed to get saga: %v", err), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, existing)
+		return
+	}
+	if err != nil {
+		(*h.loggs).Error("Error creating saga", "Type", req.Type, "Error", err)
+		http.Error(w, fmt.Sprintf("Failed to create saga: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// the saga is stored, so a step that cannot be published now is published by the recovery job
+	if err := kafka.PublishSaga(r.Context(), h.sagarepo, h.transactions, s, time.Time{}); err != nil {
+		(*h.loggs).Error("Error publishing saga step", "Saga", s.ID, "Error", err)
+	}
+	(*h.loggs).Info("Saga started", "Saga", s.ID, "Type", s.Type, "Steps", len(s.Steps))
+	writeJSON(w, http.StatusCreated, s)
+}
+
+// ListSagas returns the most recent sagas, optionally filtered by ?status=
+func (h *SagaHandler) ListSagas(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", models.SagaRunning, models.SagaCompensating, models.SagaCompleted, models.SagaCompensated, models.SagaFailed:
+	default:
+		http.Error(w, "Invalid status filter", http.StatusBadRequest)
+		return
+	}
+
+	sagas, err := h.sagarepo.ListSagas(r.Context(), status)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list sagas: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, sagas)
+}
+
+// GetSaga returns a saga with the state of every step
+func (h *SagaHandler) GetSaga(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(w, "Invalid saga id", http.StatusBadRequest)
+		return
+	}
+
+	s, err := h.sagarepo.GetSaga(r.Context(), id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get saga: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if s == nil {
+		http.Error(w, "Saga not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, s)
+}
+
+// RegisterRoutes wires the saga endpoints onto the router
+func (h *SagaHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/sagas", h.StartSaga).Methods("POST")
+	router.HandleFunc("/sagas", h.ListSagas).Methods("GET")
+	router.HandleFunc("/sagas/{id}", h.GetSaga).Methods("GET")
+}
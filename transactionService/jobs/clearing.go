@@ -0,0 +1,48 @@
+package jobs
+
+import (
+	"context"
+	"time"
+	"transactionService/database"
+	"transactionService/kafka"
+	"transactionService/repositories"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// submissionGrace is how long a transfer may stay initiated before the job resubmits it, so that
+// transfers the consumer is submitting right now are left alone
+const submissionGrace = time.Minute
+
+// ExternalSubmissionJob hands external transfers to the clearing adapter when the consumer could
+// not, e.g. because the broker was down or the transfer was approved after a risk review
+type ExternalSubmissionJob struct {
+	repo     repositories.ExternalRepo
+	kafkactl *kafka.KafkaController
+	loggs    *hclog.Logger
+}
+
+// NewExternalSubmissionJob creates a new ExternalSubmissionJob
+func NewExternalSubmissionJob(db *database.PostgresPoolDB, lobbs *hclog.Logger) *ExternalSubmissionJob {
+	return &ExternalSubmissionJob{
+		repo:     repositories.NewExternalRepository(db),
+		kafkactl: &kafka.KafkaController{},
+		loggs:    lobbs,
+	}
+}
+
+// Run submits every transfer still initiated after the grace period. The clearing adapter keys
+// its messages on the transaction ID, so a transfer submitted twice is sent under the same UETR.
+func (j *ExternalSubmissionJob) Run(ctx context.Context) error {
+	transfers, err := j.repo.UnsubmittedTransfers(ctx, time.Now().Add(-submissionGrace))
+	if err != nil {
+		return err
+	}
+	for i := range transfers {
+		if err := kafka.SubmitExternal(ctx, j.repo, j.kafkactl, &transfers[i]); err != nil {
+			return err
+		}
+		(*j.loggs).Info("External transfer resubmitted", "Transfer", transfers[i].ID)
+	}
+	return nil
+}
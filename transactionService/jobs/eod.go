@@ -0,0 +1,44 @@
+package jobs
+
+import (
+	"context"
+	"time"
+	"transactionService/businessday"
+	"transactionService/database"
+	"transactionService/repositories"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// EndOfDayJob records every account's balance at the close of each business day
+type EndOfDayJob struct {
+	repo  repositories.EventRepo
+	loggs *hclog.Logger
+}
+
+// NewEndOfDayJob creates a new EndOfDayJob
+func NewEndOfDayJob(db *database.PostgresPoolDB, lobbs *hclog.Logger) *EndOfDayJob {
+	return &EndOfDayJob{
+		repo:  repositories.NewEventRepository(db),
+		loggs: lobbs,
+	}
+}
+
+// Run snapshots every business day that has closed since the latest one snapshotted, so days
+// missed while the service was down are caught up. Balances already recorded are never
+// rewritten, so the job can safely run several times a day or on several replicas.
+func (j *EndOfDayJob) Run(ctx context.Context) error {
+	next, err := j.repo.NextEndOfDay(ctx)
+	if err != nil || next == nil {
+		return err
+	}
+	last := repositories.BusinessDays.LastClosed(time.Now())
+	for date := *next; !date.After(last); date = date.AddDate(0, 0, 1) {
+		recorded, err := j.repo.SnapshotEndOfDay(ctx, date)
+		if err != nil {
+			return err
+		}
+		(*j.loggs).Info("End-of-day balances recorded", "Date", date.Format(businessday.DateLayout), "Accounts", recorded)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package jobs
+
+import (
+	"context"
+	"time"
+	"transactionService/database"
+	"transactionService/kafka"
+	"transactionService/repositories"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// HoldExpiryJob expires holds that were neither captured nor released within their TTL
+type HoldExpiryJob struct {
+	repo     repositories.HoldRepo
+	kafkactl *kafka.KafkaController
+	loggs    *hclog.Logger
+}
+
+// NewHoldExpiryJob creates a new HoldExpiryJob
+func NewHoldExpiryJob(db *database.PostgresPoolDB, lobbs *hclog.Logger) *HoldExpiryJob {
+	return &HoldExpiryJob{
+		repo:     repositories.NewHoldRepository(db),
+		kafkactl: &kafka.KafkaController{},
+		loggs:    lobbs,
+	}
+}
+
+// Run expires every active hold past its TTL. Expired holds stop reserving funds as soon as
+// their TTL passes; this job only brings their status up to date.
+func (j *HoldExpiryJob) Run(ctx context.Context) error {
+	expired, err := j.repo.ExpireHolds(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	for i := range expired {
+		if err := j.kafkactl.PushHoldToQueue(&expired[i]); err != nil {
+			(*j.loggs).Error("Failed to publish expired hold", "Hold", expired[i].ID, "Error", err)
+		}
+	}
+	if len(expired) > 0 {
+		(*j.loggs).Info("Holds expired", "Count", len(expired))
+	}
+	return nil
+}
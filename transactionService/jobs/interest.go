@@ -0,0 +1,84 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"transactionService/businessday"
+	"transactionService/database"
+	"transactionService/kafka"
+	"transactionService/models"
+	"transactionService/repositories"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// InterestAccrualJob accrues daily savings interest and pays it out once a month
+type InterestAccrualJob struct {
+	repo     repositories.InterestRepo
+	kafkactl *kafka.KafkaController
+	loggs    *hclog.Logger
+}
+
+// NewInterestAccrualJob creates a new InterestAccrualJob. Monthly credits are published to the
+// "transaction" topic on brokers so they are applied and ledgered like any other deposit.
+func NewInterestAccrualJob(db *database.PostgresPoolDB, brokers []string, lobbs *hclog.Logger) *InterestAccrualJob {
+	return &InterestAccrualJob{
+		repo:     repositories.NewInterestRepository(db),
+		kafkactl: &kafka.KafkaController{Brokers: brokers},
+		loggs:    lobbs,
+	}
+}
+
+// Run accrues interest on the end-of-day balances of every business day snapshotted since the
+// last accrual, so days missed while the service was down are back-filled, and posts every month
+// that has closed. Accruals are stored once per account and day, and each monthly credit has a
+// deterministic transaction ID, so the job can safely run several times a day or on several replicas.
+func (j *InterestAccrualJob) Run(ctx context.Context) error {
+	from, last, err := j.repo.AccrualRange(ctx)
+	if err != nil || from == nil {
+		return err
+	}
+	next := *from
+	for ; !next.After(*last); next = next.AddDate(0, 0, 1) {
+		accrued, err := j.repo.Accrue(ctx, next)
+		if err != nil {
+			return err
+		}
+		if accrued > 0 {
+			(*j.loggs).Info("Interest accrued", "Date", next.Format(businessday.DateLayout), "Accounts", accrued)
+		}
+	}
+
+	// Accruals dated before the first of the month of the next day to accrue belong to closed
+	// months, so a month is only posted once every one of its days has accrued
+	periodEnd := time.Date(next.Year(), next.Month(), 1, 0, 0, 0, 0, time.UTC)
+	postings, err := j.repo.PendingPostings(ctx, periodEnd)
+	if err != nil {
+		return err
+	}
+	for i := range postings {
+		posting := &postings[i]
+		trans := &models.Transaction{
+			ID:              posting.TransactionID,
+			FromAccountID:   posting.AccountNumber,
+			Amount:          posting.Amount,
+			TransactionType: "interest",
+			Description:     fmt.Sprintf("Interest for %s", posting.Period),
+			CreatedAt:       time.Now(),
+			Status:          "pending",
+		}
+		if err := j.kafkactl.PushToQueue("transaction", trans); err != nil {
+			(*j.loggs).Error("Failed to publish interest credit", "Account", posting.AccountNumber, "Error", err)
+			continue
+		}
+		// If marking fails the credit is published again next run under the same ID and ignored
+		if err := j.repo.MarkPosted(ctx, posting, periodEnd); err != nil {
+			(*j.loggs).Error("Failed to mark interest posted", "Account", posting.AccountNumber, "Error", err)
+		}
+	}
+	if len(postings) > 0 {
+		(*j.loggs).Info("Interest posted", "Period", postings[0].Period, "Accounts", len(postings))
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"transactionService/database"
+	"transactionService/kafka"
+	"transactionService/models"
+	"transactionService/repositories"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// OverdraftInterestJob charges daily interest on overdrawn accounts
+type OverdraftInterestJob struct {
+	repo     repositories.OverdraftRepo
+	kafkactl *kafka.KafkaController
+	loggs    *hclog.Logger
+}
+
+// NewOverdraftInterestJob creates a new OverdraftInterestJob
+func NewOverdraftInterestJob(db *database.PostgresPoolDB, lobbs *hclog.Logger) *OverdraftInterestJob {
+	return &OverdraftInterestJob{
+		repo:     repositories.NewOverdraftRepository(db),
+		kafkactl: &kafka.KafkaController{},
+		loggs:    lobbs,
+	}
+}
+
+// Run charges today's interest. The repository skips accounts already charged for the day,
+// so the job can safely run several times a day or on several replicas.
+func (j *OverdraftInterestJob) Run(ctx context.Context) error {
+	chargeDate := time.Now().UTC().Truncate(24 * time.Hour)
+
+	charges, err := j.repo.ChargeInterest(ctx, chargeDate)
+	if err != nil {
+		return err
+	}
+
+	// Record every charge in the ledger so it shows up in the account history
+	for _, charge := range charges {
+		trans := &models.Transaction{
+			ID:              charge.TransactionID,
+			FromAccountID:   charge.AccountNumber,
+			Amount:          charge.Amount,
+			TransactionType: "overdraft_interest",
+			Description:     fmt.Sprintf("Overdraft interest for %s at %.2f%% p.a.", chargeDate.Format("2006-01-02"), charge.AnnualRate*100),
+			CreatedAt:       time.Now(),
+			Status:          "completed",
+			Postings:        charge.Postings,
+		}
+		if err := j.kafkactl.PushToQueue("transaction-ledger", trans); err != nil {
+			(*j.loggs).Error("Failed to push overdraft interest to ledger", "Account", charge.AccountNumber, "Error", err)
+		}
+	}
+	(*j.loggs).Info("Overdraft interest charged", "Date", chargeDate.Format("2006-01-02"), "Accounts", len(charges))
+	return nil
+}
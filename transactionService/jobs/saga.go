@@ -0,0 +1,83 @@
+package jobs
+
+import (
+	"context"
+	"time"
+	"transactionService/database"
+	"transactionService/kafka"
+	"transactionService/models"
+	"transactionService/repositories"
+	"transactionService/saga"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// sagaRetryAfter is how long a saga may wait on a step before the job looks at it, so that
+// commands the consumer is publishing right now are left alone
+const sagaRetryAfter = 2 * time.Minute
+
+// SagaRecoveryJob keeps sagas moving after a restart or a lost message. It applies outcomes that
+// are in the database but never reached the saga consumer, and publishes again the commands whose
+// outcome has not arrived.
+type SagaRecoveryJob struct {
+	repo     repositories.SagaRepo
+	kafkactl *kafka.KafkaController
+	loggs    *hclog.Logger
+}
+
+// NewSagaRecoveryJob creates a new SagaRecoveryJob publishing to the "transaction" topic on brokers
+func NewSagaRecoveryJob(db *database.PostgresPoolDB, brokers []string, lobbs *hclog.Logger) *SagaRecoveryJob {
+	return &SagaRecoveryJob{
+		repo:     repositories.NewSagaRepository(db),
+		kafkactl: &kafka.KafkaController{Brokers: brokers},
+		loggs:    lobbs,
+	}
+}
+
+// Run recovers every saga that has been waiting longer than sagaRetryAfter
+func (j *SagaRecoveryJob) Run(ctx context.Context) error {
+	before := time.Now().Add(-sagaRetryAfter)
+	sagas, err := j.repo.UnfinishedSagas(ctx, before)
+	if err != nil {
+		return err
+	}
+	for i := range sagas {
+		s, err := j.catchUp(ctx, &sagas[i])
+		if err != nil {
+			return err
+		}
+		if err := kafka.PublishSaga(ctx, j.repo, j.kafkactl, s, before); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// catchUp applies the outcomes the saga is waiting for that are already posted, one step at a time
+func (j *SagaRecoveryJob) catchUp(ctx context.Context, s *models.Saga) (*models.Saga, error) {
+	for range s.Steps {
+		advanced := false
+		for _, id := range saga.Awaited(s) {
+			posted, err := j.repo.TransactionPosted(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			if !posted {
+				continue
+			}
+			updated, err := j.repo.ApplySagaEvent(ctx, saga.Event{TransactionID: id})
+			if err != nil {
+				return nil, err
+			}
+			if updated != nil {
+				(*j.loggs).Info("Saga caught up", "Saga", s.ID, "Transaction", id, "Status", updated.Status)
+				s, advanced = updated, true
+			}
+			break
+		}
+		if !advanced {
+			break
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,26 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// Every runs fn immediately and then on every tick of interval until ctx is cancelled.
+// Errors are logged and the job keeps running on the next tick.
+func Every(ctx context.Context, name string, interval time.Duration, loggs *hclog.Logger, fn func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := fn(ctx); err != nil {
+			(*loggs).Error("Scheduled job failed", "Job", name, "Error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
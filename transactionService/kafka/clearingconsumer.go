@@ -0,0 +1,95 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"log"
+	"shared/audit"
+	"shared/events"
+	"transactionService/database"
+	"transactionService/models"
+	"transactionService/repositories"
+
+	"github.com/IBM/sarama"
+)
+
+// ClearingOutboundTopic carries external transfers to the clearing adapter, which answers on ClearingStatusTopic
+const (
+	ClearingOutboundTopic = "clearing-outbound"
+	ClearingStatusTopic   = "clearing-status"
+)
+
+// SubmitExternal hands an external transfer to the clearing adapter and marks it submitted
+func SubmitExternal(ctx context.Context, repo repositories.ExternalRepo, kafkapush *KafkaController, trans *models.Transaction) error {
+	if err := kafkapush.PushToQueue(ClearingOutboundTopic, trans); err != nil {
+		return err
+	}
+	return repo.MarkSubmitted(ctx, trans.ID)
+}
+
+// ClearingConsumer settles or returns external transfers as the clearing adapter reports on them
+type ClearingConsumer struct {
+	externalrepo repositories.ExternalRepo
+	recorder     *audit.Recorder
+}
+
+// NewClearingConsumer creates a consumer for the "clearing-status" topic
+func NewClearingConsumer(db *database.PostgresPoolDB, recorder *audit.Recorder) *ClearingConsumer {
+	return &ClearingConsumer{
+		externalrepo: repositories.NewExternalRepository(db),
+		recorder:     recorder,
+	}
+}
+
+func (h ClearingConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		var status models.ClearingStatus
+		env, err := events.Unmarshal(events.TopicID(msg.Topic), msg.Value, eventHeaders(msg.Headers), &status)
+		if errors.Is(err, events.ErrUnsupportedVersion) {
+			log.Printf("Cannot consume clearing status (offset %d) yet: %v", msg.Offset, err)
+			return err
+		}
+		if err != nil {
+			log.Printf("Failed to unmarshal clearing status (offset %d): %v", msg.Offset, err)
+			continue
+		}
+
+		ctx := context.Background()
+		// the state before is only needed for the audit log, so a failed lookup does not stop the status
+		before, err := h.externalrepo.GetExternalTransfer(ctx, status.TransactionID)
+		if err != nil {
+			log.Printf("Failed to load external transfer %s for the audit log: %v", status.TransactionID, err)
+		}
+		transfer, movement, err := h.externalrepo.ApplyClearingStatus(ctx, &status)
+		if errors.Is(err, repositories.ErrExternalTransferNotFound) || errors.Is(err, repositories.ErrInvalidExternalTransition) {
+			// reports on transfers this service did not send, or that contradict a final state, are only logged
+			log.Printf("Ignoring clearing status %s for %s: %v", status.Status, status.TransactionID, err)
+			session.MarkMessage(msg, "")
+			continue
+		}
+		if err != nil {
+			log.Printf("Failed to apply clearing status for %s: %v", status.TransactionID, err)
+			return err
+		}
+
+		// the settlement or return is ledgered like any other movement; until it is, the status is
+		// not marked, so its redelivery publishes the movement again
+		if movement != nil {
+			kafkapush := KafkaController{Trace: env.Trace.Child()}
+			if err := kafkapush.PushToQueue("transaction-ledger", movement); err != nil {
+				log.Printf("Failed to push %s of %s to the ledger: %v", movement.TransactionType, transfer.ID, err)
+				return err
+			}
+		}
+
+		h.recorder.Consumed(audit.Source(msg.Topic, msg.Partition, msg.Offset), "external_transfer.clearing_status",
+			"external-transfer/"+transfer.ID.String(), transfer.Status, before, transfer)
+		log.Printf("External transfer %s is %s (clearing status %s, partition %d, offset %d)", transfer.ID, transfer.Status, status.ISOStatus, msg.Partition, msg.Offset)
+		session.MarkMessage(msg, "")
+	}
+
+	return nil
+}
+
+func (ClearingConsumer) Setup(_ sarama.ConsumerGroupSession) error   { return nil }
+func (ClearingConsumer) Cleanup(_ sarama.ConsumerGroupSession) error { return nil }
@@ -2,30 +2,54 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"shared/audit"
+	"shared/events"
 	"transactionService/database"
 	"transactionService/models"
 	"transactionService/repositories"
+	"transactionService/risk"
 
 	"github.com/IBM/sarama"
+	"github.com/google/uuid"
 )
 
 type KafkaConsumer struct {
-	repo repositories.Repository
+	repo         repositories.Repository
+	riskrepo     repositories.RiskRepo
+	approvalrepo repositories.ApprovalRepo
+	payeerepo    repositories.PayeeRepo
+	externalrepo repositories.ExternalRepo
+	checker      risk.Checker
+	recorder     *audit.Recorder
 }
 
-func NewKafkaConsumer(db *database.PostgresPoolDB) *KafkaConsumer {
+// NewKafkaConsumer creates a consumer that holds transactions awaiting the approval of joint
+// account holders and runs every deposit, withdrawal and transfer past checker before applying it.
+// What it does with each transaction is recorded in the audit log.
+func NewKafkaConsumer(db *database.PostgresPoolDB, checker risk.Checker, recorder *audit.Recorder) *KafkaConsumer {
 	return &KafkaConsumer{
-		repo: repositories.NewUserRepository(db),
+		repo:         repositories.NewUserRepository(db),
+		riskrepo:     repositories.NewRiskRepository(db),
+		approvalrepo: repositories.NewApprovalRepository(db),
+		payeerepo:    repositories.NewPayeeRepository(db),
+		externalrepo: repositories.NewExternalRepository(db),
+		checker:      checker,
+		recorder:     recorder,
 	}
 }
 
 func (h KafkaConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
 	for msg := range claim.Messages() {
 		var trans models.Transaction
-		err := json.Unmarshal(msg.Value, &trans)
+		env, err := events.Unmarshal(events.TopicID(msg.Topic), msg.Value, eventHeaders(msg.Headers), &trans)
+		if errors.Is(err, events.ErrUnsupportedVersion) {
+			// left for a newer version of this service
+			log.Printf("Cannot consume message (offset %d) yet: %v", msg.Offset, err)
+			return err
+		}
 		if err != nil {
 			log.Printf("Failed to unmarshal message (offset %d): %v", msg.Offset, err)
 			continue
@@ -33,11 +57,73 @@ func (h KafkaConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim s
 
 		ctx := context.Background()
 		fmt.Println(trans)
+		// everything published while handling the transaction belongs to its trace
+		kafkapush := KafkaController{Trace: env.Trace.Child()}
+
+		if trans.ID == uuid.Nil {
+			trans.ID = uuid.New()
+		}
+		source := audit.Source(msg.Topic, msg.Partition, msg.Offset)
+		resource := "transaction/" + trans.ID.String()
+
+		// Transfers to a payee are resolved first so that every later check sees the beneficiary
+		err = h.payeerepo.ResolvePayee(ctx, &trans)
+		if errors.Is(err, repositories.ErrPayeeNotFound) || errors.Is(err, repositories.ErrPayeeNotAllowed) {
+			log.Printf("Transaction %s refused: %v", trans.ID, err)
+			trans.Status = "failed"
+			h.recorder.Consumed(source, "transaction.refused", resource, err.Error(), nil, &trans)
+			if err := kafkapush.PushToQueue("dead-ledger", &trans); err != nil {
+				fmt.Println(err)
+				return err
+			}
+			session.MarkMessage(msg, "")
+			continue
+		}
+		if err != nil {
+			log.Printf("Payee check failed for transaction %s: %v", trans.ID, err)
+			return err
+		}
+
+		// Transactions needing more holders' approval wait until they are approved and resubmitted
+		held, err := h.approvalrepo.Gate(ctx, &trans)
+		if err != nil {
+			log.Printf("Approval check failed for transaction %s: %v", trans.ID, err)
+			return err
+		}
+		if held {
+			log.Printf("Transaction %s is waiting for account holder approval", trans.ID)
+			h.recorder.Consumed(source, "transaction.held_for_approval", resource, "held", nil, &trans)
+			session.MarkMessage(msg, "")
+			continue
+		}
+
+		// Risk checks run before any balance moves
+		held, err = h.screen(ctx, source, &kafkapush, &trans)
+		if err != nil {
+			log.Printf("Risk check failed for transaction %s: %v", trans.ID, err)
+			return err
+		}
+		if held {
+			session.MarkMessage(msg, "")
+			continue
+		}
+
 		// Process the transaction
-		kafkapush := KafkaController{}
 		err = h.repo.TransactionRouter(ctx, &trans)
+		if errors.Is(err, repositories.ErrDuplicateTransaction) {
+			// already applied and sent to the ledger, so only the offset needs committing
+			log.Printf("Skipping duplicate transaction %s (partition %d, offset %d)", trans.ID, msg.Partition, msg.Offset)
+			session.MarkMessage(msg, "")
+			continue
+		}
 		if err != nil {
 			fmt.Println(err)
+			// the balances it moved before failing were rolled back
+			trans.Postings = nil
+			if ferr := h.riskrepo.FailReview(ctx, trans.ID.String(), err.Error()); ferr != nil {
+				log.Printf("Failed to mark risk review of transaction %s failed: %v", trans.ID, ferr)
+			}
+			h.recorder.Consumed(source, "transaction.failed", resource, err.Error(), nil, &trans)
 			//push to dead order queue
 			err = kafkapush.PushToQueue("dead-ledger", &trans)
 			if err != nil {
@@ -46,6 +132,10 @@ func (h KafkaConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim s
 			}
 			return nil
 		}
+		before, after := balances(trans.Postings)
+		h.recorder.Consumed(source, "transaction.posted", resource, "completed",
+			map[string]any{"balances": before}, map[string]any{"balances": after, "transaction": &trans})
+
 		// push transacation into mongo ledger service
 		err = kafkapush.PushToQueue("transaction-ledger", &trans)
 		if err != nil {
@@ -59,6 +149,14 @@ func (h KafkaConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim s
 			return nil
 		}
 
+		// External transfers sit in the suspense account until the clearing system reports on them.
+		// A failed hand-over leaves the transfer initiated for the resubmission job to pick up.
+		if trans.TransactionType == "external_transfer" {
+			if err := SubmitExternal(ctx, h.externalrepo, &kafkapush, &trans); err != nil {
+				log.Printf("Failed to submit external transfer %s to clearing: %v", trans.ID, err)
+			}
+		}
+
 		log.Printf("Processed trnsaction: %+v (partition %d, offset %d)", trans.FromAccountID, msg.Partition, msg.Offset)
 		fmt.Println("Account is saved in postgres")
 		// Mark message as processed (commit offset)
@@ -68,5 +166,80 @@ func (h KafkaConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim s
 	return nil
 }
 
+// screen runs the risk checks on trans and reports whether it was stopped. Held transactions
+// wait for an operator on the admin API, who resubmits them once approved; the approved review is
+// what lets them through here without being checked again, and trans is replaced with the
+// transaction as it was reviewed, whatever the resubmitted message says. Rejected ones are
+// recorded and sent to the dead ledger.
+func (h KafkaConsumer) screen(ctx context.Context, source string, kafkapush *KafkaController, trans *models.Transaction) (bool, error) {
+	if h.checker == nil {
+		return false, nil
+	}
+	// A transaction stopped before keeps the outcome of its review, whatever type the message claims
+	review, err := h.riskrepo.GetReviewByTransaction(ctx, trans.ID.String())
+	if err != nil {
+		return false, err
+	}
+	if review != nil {
+		if review.Status != "approved" {
+			return true, nil
+		}
+		*trans = review.Transaction
+		return false, nil
+	}
+	if !risk.Checked(trans.TransactionType) {
+		return false, nil
+	}
+
+	assessment, err := h.checker.Check(ctx, trans)
+	if err != nil {
+		return false, err
+	}
+	if assessment.Decision == risk.Approve {
+		return false, nil
+	}
+
+	review = &models.RiskReview{
+		Transaction: *trans,
+		Decision:    string(assessment.Decision),
+		Score:       assessment.Score,
+		Reasons:     assessment.Reasons,
+		Status:      "pending",
+	}
+	if assessment.Decision == risk.Reject {
+		review.Status = "rejected"
+	}
+	err = h.riskrepo.CreateReview(ctx, review)
+	if errors.Is(err, repositories.ErrDuplicateTransaction) {
+		// stopped on an earlier delivery
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	log.Printf("Transaction %s stopped by risk checks (%s, score %d): %v", trans.ID, assessment.Decision, assessment.Score, assessment.Reasons)
+	h.recorder.Consumed(source, "transaction.risk_"+review.Status, "transaction/"+trans.ID.String(), string(assessment.Decision), nil, review)
+
+	if assessment.Decision == risk.Reject {
+		trans.Status = "failed"
+		if err := kafkapush.PushToQueue("dead-ledger", trans); err != nil {
+			log.Printf("Failed to push rejected transaction %s to dead ledger: %v", trans.ID, err)
+		}
+	}
+	return true, nil
+}
+
+// balances gives the balance of every account the postings moved, before and after them
+func balances(postings []models.Posting) (map[string]float64, map[string]float64) {
+	before, after := map[string]float64{}, map[string]float64{}
+	for _, posting := range postings {
+		if _, seen := before[posting.AccountNumber]; !seen {
+			before[posting.AccountNumber] = posting.Balance - posting.Amount
+		}
+		after[posting.AccountNumber] = posting.Balance
+	}
+	return before, after
+}
+
 func (KafkaConsumer) Setup(_ sarama.ConsumerGroupSession) error   { return nil }
 func (KafkaConsumer) Cleanup(_ sarama.ConsumerGroupSession) error { return nil }
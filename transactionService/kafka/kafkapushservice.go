@@ -4,13 +4,23 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"shared/events"
 	"time"
 	"transactionService/models"
 
 	"github.com/IBM/sarama"
 )
 
+// DefaultBrokers is the Kafka cluster a KafkaController without Brokers publishes to; main sets it
+// from KAFKA_BROKER
+var DefaultBrokers = []string{"kafka:9092"}
+
+// KafkaController publishes messages. Brokers defaults to DefaultBrokers. Topics are given by
+// their ID in the topic map, which names them on the cluster. Every message is wrapped in an event
+// envelope carrying Trace; an empty Trace starts a new trace.
 type KafkaController struct {
+	Brokers []string
+	Trace   events.Trace
 }
 
 func (k *KafkaController) PushToQueue(topic string, trans *models.Transaction) error {
@@ -22,10 +32,40 @@ func (k *KafkaController) PushToQueue(topic string, trans *models.Transaction) e
 
 }
 
+// HoldsTopic carries every change of a hold's status, keyed by account number so that the changes
+// of an account's holds are consumed in order
+const HoldsTopic = "account-holds"
+
+// PushHoldToQueue publishes the current state of a hold on HoldsTopic
+func (k *KafkaController) PushHoldToQueue(hold *models.Hold) error {
+	msg, err := json.Marshal(hold)
+	if err != nil {
+		return err
+	}
+	return k.pushMessage(HoldsTopic, sarama.StringEncoder(hold.AccountNumber), msg)
+}
+
 func (k *KafkaController) PushOrderToQueue(topic string, message []byte) error {
+	return k.pushMessage(topic, nil, message)
+}
+
+// PushKeyedToQueue publishes a message with a key, so that messages sharing the key are consumed in order
+func (k *KafkaController) PushKeyedToQueue(topic, key string, message []byte) error {
+	return k.pushMessage(topic, sarama.StringEncoder(key), message)
+}
 
-	brokers := []string{"kafkamongo:9092"}
-	producer, err := k.connectProducer(brokers, topic)
+func (k *KafkaController) pushMessage(topic string, key sarama.Encoder, message []byte) error {
+	message, headers, err := events.Encode(topic, message, k.Trace)
+	if err != nil {
+		return fmt.Errorf("failed to encode message for %s: %w", topic, err)
+	}
+
+	brokers := k.Brokers
+	if len(brokers) == 0 {
+		brokers = DefaultBrokers
+	}
+	name := events.TopicName(topic)
+	producer, err := k.connectProducer(brokers, name)
 	if err != nil {
 		return err
 	}
@@ -34,8 +74,10 @@ func (k *KafkaController) PushOrderToQueue(topic string, message []byte) error {
 
 	// create new kafka message
 	msg := &sarama.ProducerMessage{
-		Topic: topic,
-		Value: sarama.StringEncoder(message),
+		Topic:   name,
+		Key:     key,
+		Value:   sarama.ByteEncoder(message),
+		Headers: recordHeaders(headers),
 	}
 
 	// send message
@@ -43,7 +85,7 @@ func (k *KafkaController) PushOrderToQueue(topic string, message []byte) error {
 	if err != nil {
 		return err
 	}
-	fmt.Println("Transaction stored in Ledger service", topic, partition, offset)
+	fmt.Println("Transaction stored in Ledger service", name, partition, offset)
 	return nil
 
 }
@@ -81,7 +123,7 @@ func (k *KafkaController) createTopicIfNotExists(admin sarama.ClusterAdmin, topi
 	topics, err := admin.ListTopics()
 	fmt.Println(topics)
 	if err != nil {
-		fmt.Errorf("failed to list topics: %v", err)
+		return fmt.Errorf("failed to list topics: %v", err)
 	}
 
 	// Check if the topic already exists
@@ -114,3 +156,21 @@ func (k *KafkaController) createTopicIfNotExists(admin sarama.ClusterAdmin, topi
 func (k *KafkaController) stringPtr(s string) *string {
 	return &s
 }
+
+// recordHeaders converts the headers of an event to Kafka record headers
+func recordHeaders(headers events.Headers) []sarama.RecordHeader {
+	records := make([]sarama.RecordHeader, 0, len(headers))
+	for key, value := range headers {
+		records = append(records, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+	}
+	return records
+}
+
+// eventHeaders converts the headers of a consumed Kafka record to event headers
+func eventHeaders(records []*sarama.RecordHeader) events.Headers {
+	headers := make(events.Headers, len(records))
+	for _, record := range records {
+		headers[string(record.Key)] = string(record.Value)
+	}
+	return headers
+}
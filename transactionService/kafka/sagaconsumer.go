@@ -0,0 +1,100 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"log"
+	"shared/audit"
+	"shared/events"
+	"time"
+	"transactionService/database"
+	"transactionService/models"
+	"transactionService/repositories"
+	"transactionService/saga"
+
+	"github.com/IBM/sarama"
+)
+
+// PublishSaga publishes the commands of a saga not published since before to the "transaction"
+// topic. Every command carries a transaction ID derived from the saga, so publishing one twice is
+// harmless.
+func PublishSaga(ctx context.Context, repo repositories.SagaRepo, transactions *KafkaController, s *models.Saga, before time.Time) error {
+	for _, command := range saga.Commands(s, before) {
+		if err := transactions.PushToQueue("transaction", &command.Transaction); err != nil {
+			return err
+		}
+		if err := repo.MarkStepPublished(ctx, s.ID, command.Step); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SagaConsumer drives sagas from the outcomes of their transactions on the "transaction-ledger"
+// and "dead-ledger" topics
+type SagaConsumer struct {
+	sagarepo     repositories.SagaRepo
+	transactions *KafkaController // Cluster carrying the "transaction" topic
+	recorder     *audit.Recorder
+}
+
+// NewSagaConsumer creates a saga consumer that publishes the next steps to the "transaction" topic on brokers
+func NewSagaConsumer(db *database.PostgresPoolDB, brokers []string, recorder *audit.Recorder) *SagaConsumer {
+	return &SagaConsumer{
+		sagarepo:     repositories.NewSagaRepository(db),
+		transactions: &KafkaController{Brokers: brokers},
+		recorder:     recorder,
+	}
+}
+
+func (h SagaConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		var trans models.Transaction
+		topic := events.TopicID(msg.Topic)
+		_, err := events.Unmarshal(topic, msg.Value, eventHeaders(msg.Headers), &trans)
+		if errors.Is(err, events.ErrUnsupportedVersion) {
+			log.Printf("Cannot consume ledger message (offset %d) yet: %v", msg.Offset, err)
+			return err
+		}
+		if err != nil {
+			log.Printf("Failed to unmarshal ledger message (offset %d): %v", msg.Offset, err)
+			continue
+		}
+
+		ctx := context.Background()
+		event := saga.Event{TransactionID: trans.ID, Failed: topic == "dead-ledger"}
+		if event.Failed {
+			// a transaction the ledger could not be told about is still applied
+			posted, err := h.sagarepo.TransactionPosted(ctx, trans.ID)
+			if err != nil {
+				log.Printf("Failed to look up transaction %s: %v", trans.ID, err)
+				return err
+			}
+			event.Failed = !posted
+		}
+
+		s, err := h.sagarepo.ApplySagaEvent(ctx, event)
+		if err != nil {
+			log.Printf("Failed to apply outcome of %s to its saga: %v", trans.ID, err)
+			return err
+		}
+		if s == nil {
+			session.MarkMessage(msg, "")
+			continue
+		}
+
+		// a command that cannot be published now is picked up by the saga recovery job
+		if err := PublishSaga(ctx, h.sagarepo, h.transactions, s, time.Time{}); err != nil {
+			log.Printf("Failed to publish next step of saga %s: %v", s.ID, err)
+		}
+
+		h.recorder.Consumed(audit.Source(msg.Topic, msg.Partition, msg.Offset), "saga.step_finished", "saga/"+s.ID.String(), s.Status, nil, s)
+		log.Printf("Saga %s is %s after %s (partition %d, offset %d)", s.ID, s.Status, trans.ID, msg.Partition, msg.Offset)
+		session.MarkMessage(msg, "")
+	}
+
+	return nil
+}
+
+func (SagaConsumer) Setup(_ sarama.ConsumerGroupSession) error   { return nil }
+func (SagaConsumer) Cleanup(_ sarama.ConsumerGroupSession) error { return nil }
@@ -4,20 +4,32 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"shared/audit"
+	"shared/events"
+	"shared/screening"
 	"sync"
 	"syscall"
+	"time"
+	"transactionService/configurations"
 	"transactionService/database"
+	"transactionService/handler"
+	"transactionService/jobs"
 	"transactionService/kafka"
+	"transactionService/repositories"
+	"transactionService/risk"
 
 	"github.com/IBM/sarama"
+	"github.com/gorilla/mux"
 	"github.com/hashicorp/go-hclog"
 )
 
 func main() {
 
 	fmt.Println("Starting to develop banking application")
+	events.Producer = "transactionService"
 	// logging app file
 	logFile, err := os.OpenFile("app.log", os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
 	if err != nil {
@@ -46,17 +58,59 @@ func main() {
 	}
 	defer db.Close(ctx)
 
+	uri, err := configurations.NewAppConfig()
+	if err != nil {
+		loggs.Error("Not able to create Retrieve App Configurations")
+		os.Exit(1)
+	}
+
+	// Payee rules apply to transfers from the consumer and the admin API alike
+	repositories.PayeeCoolingOff = uri.GetPayeeCoolingOff()
+	repositories.PayeeVerifiedLimit = uri.GetPayeeVerifiedLimit()
+	repositories.FeeIncomeAccount = uri.GetFeeIncomeAccount()
+	repositories.ClearingSuspenseAccount = uri.GetClearingSuspenseAccount()
+	repositories.SnapshotEvery = int64(uri.GetSnapshotEvery())
+	repositories.BusinessDays = uri.GetBusinessDays()
+	if err := events.UseCodec(uri.GetEventCodec()); err != nil {
+		loggs.Error("Invalid EVENT_CODEC", "Error", err)
+		os.Exit(1)
+	}
+	if path := uri.GetTopicMapFile(); path != "" {
+		if err := events.LoadTopics(path); err != nil {
+			loggs.Error("Not able to load topic map", "File", path, "Error", err)
+			os.Exit(1)
+		}
+	}
+	// Every topic lives on the one cluster
+	brokers := uri.GetKafkaBrokers()
+	kafka.DefaultBrokers = brokers
+
 	// handlers
-	kafkaConsumer := kafka.NewKafkaConsumer(db)
+	checker := risk.Chain{risk.NewRules(uri.GetRiskThresholds(), repositories.NewRiskRepository(db))}
+
+	// Screen transfers against the sanctions watchlist
+	if path := uri.GetWatchlistFile(); path != "" {
+		entries, err := screening.LoadFile(path)
+		if err != nil {
+			loggs.Error("Not able to load watchlist", "File", path, "Error", err)
+			os.Exit(1)
+		}
+		screener := screening.NewScreener(entries, uri.GetScreeningThresholds())
+		checker = append(checker, risk.NewSanctions(screener, repositories.NewScreeningRepository(db)))
+		loggs.Info("Loaded watchlist", "File", path, "Entries", len(entries), "Names", screener.Size())
+	}
+
+	// Audit entries are chained by the ledger service
+	recorder := audit.NewRecorder("transactionService", &kafka.KafkaController{}, &loggs)
+	kafkaConsumer := kafka.NewKafkaConsumer(db, checker, recorder)
 
 	// Kafka configuration
 	config := sarama.NewConfig()
 	config.Consumer.Group.Rebalance.Strategy = sarama.BalanceStrategyRoundRobin
 	config.Consumer.Offsets.Initial = sarama.OffsetNewest
 	config.Consumer.Return.Errors = true
-	brokers := []string{"kafka:9092"}
 	groupID := "transaction-group"
-	topics := []string{"transaction"}
+	topics := events.TopicNames("transaction")
 
 	// Create consumer group
 	consumerGroup, err := sarama.NewConsumerGroup(brokers, groupID, config)
@@ -91,14 +145,151 @@ func main() {
 		}
 	}()
 
+	// The clearing adapter reports on external transfers
+	clearingGroup, err := sarama.NewConsumerGroup(brokers, "transaction-clearing-group", config)
+	if err != nil {
+		log.Fatalf("Failed to start clearing consumer group: %v", err)
+	}
+	defer clearingGroup.Close()
+	clearingConsumer := kafka.NewClearingConsumer(db, recorder)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			err := clearingGroup.Consume(ctx, events.TopicNames(kafka.ClearingStatusTopic), clearingConsumer)
+			if err != nil {
+				log.Printf("Clearing consumer error: %v", err)
+			}
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+	go func() {
+		for err := range clearingGroup.Errors() {
+			log.Printf("Clearing consumer group error: %v", err)
+		}
+	}()
+
+	// Sagas move on as their transactions reach the ledger or the dead ledger
+	sagaGroup, err := sarama.NewConsumerGroup(brokers, "transaction-saga-group", config)
+	if err != nil {
+		log.Fatalf("Failed to start saga consumer group: %v", err)
+	}
+	defer sagaGroup.Close()
+	sagaConsumer := kafka.NewSagaConsumer(db, brokers, recorder)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			err := sagaGroup.Consume(ctx, events.TopicNames("transaction-ledger", "dead-ledger"), sagaConsumer)
+			if err != nil {
+				log.Printf("Saga consumer error: %v", err)
+			}
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+	go func() {
+		for err := range sagaGroup.Errors() {
+			log.Printf("Saga consumer group error: %v", err)
+		}
+	}()
+
 	log.Println("Consumer group started. Waiting for messages...")
 
+	// Scheduled jobs
+	overdraftJob := jobs.NewOverdraftInterestJob(db, &loggs)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		jobs.Every(ctx, "overdraft-interest", 1*time.Hour, &loggs, overdraftJob.Run)
+	}()
+	interestJob := jobs.NewInterestAccrualJob(db, brokers, &loggs)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		jobs.Every(ctx, "interest-accrual", 1*time.Hour, &loggs, interestJob.Run)
+	}()
+	holdExpiryJob := jobs.NewHoldExpiryJob(db, &loggs)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		jobs.Every(ctx, "hold-expiry", 1*time.Minute, &loggs, holdExpiryJob.Run)
+	}()
+	externalSubmissionJob := jobs.NewExternalSubmissionJob(db, &loggs)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		jobs.Every(ctx, "external-submission", 1*time.Minute, &loggs, externalSubmissionJob.Run)
+	}()
+	sagaRecoveryJob := jobs.NewSagaRecoveryJob(db, brokers, &loggs)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		jobs.Every(ctx, "saga-recovery", 1*time.Minute, &loggs, sagaRecoveryJob.Run)
+	}()
+	endOfDayJob := jobs.NewEndOfDayJob(db, &loggs)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		jobs.Every(ctx, "end-of-day", 5*time.Minute, &loggs, endOfDayJob.Run)
+	}()
+
+	// Seed the exchange rates used for cross-currency transfers
+	if path := uri.GetFxRatesFile(); path != "" {
+		loaded, err := repositories.NewFxRepository(db).LoadRatesFile(ctx, path)
+		if err != nil {
+			loggs.Error("Not able to load fx rates", "File", path, "Error", err)
+			os.Exit(1)
+		}
+		loggs.Info("Loaded fx rates", "File", path, "Rates", loaded)
+	}
+
+	// Admin API
+	router := mux.NewRouter()
+	router.Use(recorder.Middleware)
+	handler.TrackResources(recorder, db)
+	handler.NewAdminHandler(db, &loggs).RegisterRoutes(router)
+	handler.NewAccountHandler(db, &loggs).RegisterRoutes(router)
+	handler.NewEventHandler(db, &loggs).RegisterRoutes(router)
+	handler.NewHoldHandler(db, &loggs).RegisterRoutes(router)
+	handler.NewRiskHandler(db, brokers, &loggs).RegisterRoutes(router)
+	handler.NewApprovalHandler(db, brokers, &loggs).RegisterRoutes(router)
+	handler.NewPayeeHandler(db, &loggs).RegisterRoutes(router)
+	handler.NewExternalHandler(db, &loggs).RegisterRoutes(router)
+	handler.NewSagaHandler(db, brokers, &loggs).RegisterRoutes(router)
+
+	opts := hclog.StandardLoggerOptions{
+		InferLevels: true,
+	}
+	httpServer := http.Server{
+		Addr:         *uri.GetAppURI(),
+		Handler:      router,
+		ErrorLog:     loggs.StandardLogger(&opts),
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		IdleTimeout:  120 * time.Second,
+	}
+
+	go func() {
+		loggs.Info("starting admin server on ", "Port", *uri.GetAppURI())
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			loggs.Error("Error starting admin server", "Error", err)
+			os.Exit(1)
+		}
+	}()
+
 	// Handle SIGINT/SIGTERM for shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 	<-sigChan
 
 	log.Println("Shutting down consumer...")
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer shutdownCancel()
+	httpServer.Shutdown(shutdownCtx)
 	cancel()
 	wg.Wait()
 }
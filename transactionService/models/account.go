@@ -7,14 +7,23 @@ import (
 )
 
 type Account struct {
-	ID            uuid.UUID `json:"id"`             // Unique identifier for the account
-	AccountNumber string    `json:"account_number"` // Unique account number
-	Username      string    `json:"username"`       // Account username
-	Email         string    `json:"email"`          // Account email address
-	Balance       float64   `json:"balance"`        // Account balance (e.g., for financial apps)
-	CreatedAt     time.Time `json:"created_at"`     // Timestamp of account creation
-	UpdatedAt     time.Time `json:"updated_at"`     // Timestamp of last update
-	IsActive      bool      `json:"is_active"`      // Account status (active or inactive)
+	ID                    uuid.UUID  `json:"id"`                      // Unique identifier for the account
+	AccountNumber         string     `json:"account_number"`          // Unique account number
+	Username              string     `json:"username"`                // Account username
+	Email                 string     `json:"email"`                   // Account email address
+	Balance               float64    `json:"balance"`                 // Ledger balance: the posted balance of the account
+	AvailableBalance      float64    `json:"available_balance"`       // Ledger balance plus unused overdraft minus active holds
+	HeldAmount            float64    `json:"held_amount"`             // Funds reserved by active holds
+	OverdraftLimit        float64    `json:"overdraft_limit"`         // How far the balance may go below zero
+	OverdraftInterestRate float64    `json:"overdraft_interest_rate"` // Annual rate charged on the overdrawn amount
+	OverdraftUsed         float64    `json:"overdraft_used"`          // Amount currently drawn on the overdraft
+	CreatedAt             time.Time  `json:"created_at"`              // Timestamp of account creation
+	UpdatedAt             time.Time  `json:"updated_at"`              // Timestamp of last update
+	IsActive              bool       `json:"is_active"`               // Account status (active or inactive)
+	Tier                  string     `json:"tier"`                    // Limit tier (e.g., "basic", "premium", "business")
+	Currency              string     `json:"currency"`                // ISO 4217 code the balance is held in
+	InterestPlan          string     `json:"interest_plan,omitempty"` // Savings plan the account earns interest under
+	CustomerID            *uuid.UUID `json:"customer_id,omitempty"`   // Customer holding the account
 }
 
 // NewAccount creates a new Account instance with default values
@@ -27,5 +36,7 @@ func NewAccount(username, email, password string) *Account {
 		CreatedAt: now,
 		UpdatedAt: now,
 		IsActive:  true, // Default to active
+		Tier:      "basic",
+		Currency:  "USD",
 	}
 }
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Account event types. An account's stream starts with opened when it is created with money in it;
+// every later movement is credited or debited.
+const (
+	AccountOpened   = "opened"
+	AccountCredited = "credited"
+	AccountDebited  = "debited"
+)
+
+// AccountEvent is one entry in the append-only stream of an account's balance movements
+type AccountEvent struct {
+	AccountNumber string     `json:"account_number"`
+	Version       int64      `json:"version"`                  // Position in the account's stream, from 1
+	EventType     string     `json:"event_type"`               // "opened", "credited" or "debited"
+	Amount        float64    `json:"amount"`                   // Always positive; the event type gives the direction
+	TransactionID *uuid.UUID `json:"transaction_id,omitempty"` // Transaction that moved the money
+	Cause         string     `json:"cause"`                    // Transaction type of the movement, or "fee" or "fee_refund"
+	OccurredAt    time.Time  `json:"occurred_at"`
+}
+
+// AccountSnapshot is an account's balance after the event at Version, so a replay can start there
+type AccountSnapshot struct {
+	AccountNumber string    `json:"account_number"`
+	Version       int64     `json:"version"`
+	Balance       float64   `json:"balance"`
+	TakenAt       time.Time `json:"taken_at"` // When the event at Version occurred
+}
+
+// PointInTimeBalance is an account's balance rebuilt from its events as of a moment
+type PointInTimeBalance struct {
+	AccountNumber string    `json:"account_number"`
+	Balance       float64   `json:"balance"`
+	OverdraftUsed float64   `json:"overdraft_used"`
+	Currency      string    `json:"currency"`
+	Version       int64     `json:"version"`                 // Last event included
+	AsOf          time.Time `json:"as_of"`                   // Moment the balance is given for
+	BusinessDate  string    `json:"business_date,omitempty"` // Business day the balance closed, when asked for one
+	StartedFrom   string    `json:"started_from,omitempty"`  // "snapshot" or "end_of_day"; empty when replayed from the first event
+	Replayed      int       `json:"replayed"`                // Events replayed on top of the starting point
+}
+
+// EndOfDayBalance is an account's balance at the close of a business day
+type EndOfDayBalance struct {
+	AccountNumber string    `json:"account_number"`
+	BusinessDate  string    `json:"business_date"` // 2006-01-02
+	Balance       float64   `json:"balance"`
+	Version       int64     `json:"version"`   // Last event included
+	ClosedAt      time.Time `json:"closed_at"` // Events before this moment are included
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ProjectionRebuild reports an account whose projected balance was rebuilt from its events
+type ProjectionRebuild struct {
+	AccountNumber   string  `json:"account_number"`
+	Version         int64   `json:"version"`
+	PreviousBalance float64 `json:"previous_balance"` // Balance in usersschema.accounts before the rebuild
+	Balance         float64 `json:"balance"`          // Balance replayed from the events
+}
+
+// Posting is one balance movement a transaction made. Postings are published with the transaction
+// so that read models can follow balances without querying usersschema.accounts.
+type Posting struct {
+	AccountNumber string  `json:"account_number"`
+	Amount        float64 `json:"amount"`  // Positive for a credit, negative for a debit
+	Cause         string  `json:"cause"`   // Transaction type of the movement, or "fee" or "fee_refund"
+	Balance       float64 `json:"balance"` // Balance after the movement
+	Version       int64   `json:"version"` // Version of the movement's event in the account's stream
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ApprovalRule requires several account holders to approve transactions of a type at or above
+// an amount (e.g. both holders of a joint account for transfers over 5000)
+type ApprovalRule struct {
+	AccountNumber     string    `json:"account_number"`     // Account the rule protects
+	TransactionType   string    `json:"transaction_type"`   // "withdrawal", "transfer" or "external_transfer"
+	MinAmount         float64   `json:"min_amount"`         // Transactions at or above this amount need approval
+	RequiredApprovals int       `json:"required_approvals"` // Distinct owners or joint holders that must approve
+	UpdatedAt         time.Time `json:"updated_at"`         // Timestamp of last update
+}
+
+// Validate checks the rule is usable
+func (r *ApprovalRule) Validate() error {
+	if r.TransactionType != "withdrawal" && r.TransactionType != "transfer" && r.TransactionType != "external_transfer" {
+		return fmt.Errorf("approval rules apply to withdrawals, transfers and external transfers, not %q", r.TransactionType)
+	}
+	if r.MinAmount < 0 {
+		return fmt.Errorf("min amount cannot be negative: %.2f", r.MinAmount)
+	}
+	if r.RequiredApprovals < 1 {
+		return fmt.Errorf("required approvals must be at least 1: %d", r.RequiredApprovals)
+	}
+	return nil
+}
+
+// Approval is one holder's decision on a pending transaction
+type Approval struct {
+	CustomerID uuid.UUID `json:"customer_id"`    // Holder who decided
+	Decision   string    `json:"decision"`       // "approve" or "reject"
+	Note       string    `json:"note,omitempty"` // Optional comment
+	CreatedAt  time.Time `json:"created_at"`     // Timestamp of the decision
+}
+
+// PendingApproval is a transaction waiting for the holders of its account to approve it
+type PendingApproval struct {
+	ID                uuid.UUID   `json:"id"`                   // Unique identifier for the request
+	AccountNumber     string      `json:"account_number"`       // Account whose rule applied
+	Transaction       Transaction `json:"transaction"`          // Transaction as it was received
+	RequiredApprovals int         `json:"required_approvals"`   // Approvals needed to execute it
+	Approvals         []Approval  `json:"approvals"`            // Decisions so far
+	Status            string      `json:"status"`               // "pending", "approved", "rejected" or "expired"
+	ExpiresAt         time.Time   `json:"expires_at"`           // Time after which it can no longer be approved
+	CreatedAt         time.Time   `json:"created_at"`           // Timestamp the transaction was held
+	DecidedAt         *time.Time  `json:"decided_at,omitempty"` // Timestamp it was approved, rejected or expired
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// External transfer states. A transfer is initiated when the customer is debited into the
+// clearing suspense account and submitted once it is handed to the clearing adapter. It ends
+// settled, when the suspense funds are paid out, or returned, when they are credited back.
+const (
+	ExternalInitiated = "initiated"
+	ExternalSubmitted = "submitted"
+	ExternalSettled   = "settled"
+	ExternalReturned  = "returned"
+)
+
+// externalTransitions lists the states each state can move to. Statuses from the clearing system
+// can overtake the submission, so initiated transfers may settle or return directly.
+var externalTransitions = map[string][]string{
+	ExternalInitiated: {ExternalSubmitted, ExternalSettled, ExternalReturned},
+	ExternalSubmitted: {ExternalSettled, ExternalReturned},
+}
+
+// CanTransitionExternal reports whether an external transfer may move from one state to another
+func CanTransitionExternal(from, to string) bool {
+	for _, next := range externalTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ClearingMovementID is the ID of the transaction that settles or returns an external transfer.
+// It is derived from the transfer so a repeated report cannot post it twice.
+func ClearingMovementID(transferID uuid.UUID, state string) uuid.UUID {
+	return uuid.NewSHA1(transferID, []byte(state))
+}
+
+var (
+	bicPattern  = regexp.MustCompile(`^[A-Z]{6}[A-Z0-9]{2}([A-Z0-9]{3})?$`)
+	ibanPattern = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{11,30}$`)
+)
+
+// Party is an account holder at another bank
+type Party struct {
+	Name    string `json:"name"`              // Account holder name
+	IBAN    string `json:"iban,omitempty"`    // International account number
+	Account string `json:"account,omitempty"` // Proprietary account number, when there is no IBAN
+	BIC     string `json:"bic"`               // Bank holding the account
+}
+
+// Validate checks the party can be named in a pacs.008
+func (p *Party) Validate() error {
+	p.Name = strings.TrimSpace(p.Name)
+	p.IBAN = strings.ToUpper(strings.ReplaceAll(p.IBAN, " ", ""))
+	p.BIC = strings.ToUpper(strings.TrimSpace(p.BIC))
+	p.Account = strings.TrimSpace(p.Account)
+	if p.Name == "" || len(p.Name) > 140 {
+		return fmt.Errorf("creditor name is required and at most 140 characters")
+	}
+	if p.IBAN == "" && p.Account == "" {
+		return fmt.Errorf("creditor iban or account is required")
+	}
+	if len(p.IBAN) > 34 {
+		return fmt.Errorf("invalid creditor iban %q", p.IBAN)
+	}
+	if !bicPattern.MatchString(p.BIC) {
+		return fmt.Errorf("invalid creditor bic %q", p.BIC)
+	}
+	return nil
+}
+
+// AccountNumber returns the IBAN, or the proprietary number when there is none
+func (p *Party) AccountNumber() string {
+	if p.IBAN != "" {
+		return p.IBAN
+	}
+	return p.Account
+}
+
+// ExternalTransfer is a transfer to an account at another bank, paid out through the clearing system
+type ExternalTransfer struct {
+	ID                      uuid.UUID  `json:"id"`                                  // Transaction that debited the customer
+	AccountNumber           string     `json:"account_number"`                      // Account debited
+	Amount                  float64    `json:"amount"`                              // Amount debited and sent
+	Currency                string     `json:"currency"`                            // Currency of Amount
+	SuspenseAmount          float64    `json:"suspense_amount"`                     // Amount held in the suspense account, in its currency
+	Creditor                Party      `json:"creditor"`                            // Beneficiary
+	Reference               string     `json:"reference,omitempty"`                 // End-to-end reference
+	Status                  string     `json:"status"`                              // "initiated", "submitted", "settled" or "returned"
+	ClearingStatus          string     `json:"clearing_status,omitempty"`           // Last ISO status reported, e.g. "ACSP"
+	Reason                  string     `json:"reason,omitempty"`                    // Why the transfer was returned
+	SettlementTransactionID *uuid.UUID `json:"settlement_transaction_id,omitempty"` // Transaction that settled or returned the funds
+	CreatedAt               time.Time  `json:"created_at"`                          // Timestamp of the debit
+	UpdatedAt               time.Time  `json:"updated_at"`                          // Timestamp of the last state change
+}
+
+// ClearingStatus is published by the clearing adapter on "clearing-status" when the clearing
+// system reports on an external transfer
+type ClearingStatus struct {
+	TransactionID string    `json:"transaction_id"`        // External transfer reported on
+	Status        string    `json:"status"`                // "accepted", "settled" or "rejected"
+	ISOStatus     string    `json:"iso_status"`            // TxSts code as reported, e.g. "ACSC"
+	ReasonCode    string    `json:"reason_code,omitempty"` // ISO reason code of a rejection
+	Reason        string    `json:"reason,omitempty"`      // Additional information from the report
+	MessageID     string    `json:"message_id"`            // pacs.002 the status came from
+	ReceivedAt    time.Time `json:"received_at"`           // Timestamp the report was read
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestExternalTransitions tests that settled and returned transfers are final
+func TestExternalTransitions(t *testing.T) {
+	assert.True(t, CanTransitionExternal(ExternalInitiated, ExternalSubmitted))
+	assert.True(t, CanTransitionExternal(ExternalSubmitted, ExternalReturned))
+	assert.True(t, CanTransitionExternal(ExternalInitiated, ExternalSettled))
+	assert.False(t, CanTransitionExternal(ExternalSubmitted, ExternalInitiated))
+	assert.False(t, CanTransitionExternal(ExternalSettled, ExternalReturned))
+	assert.False(t, CanTransitionExternal(ExternalReturned, ExternalSettled))
+}
+
+// TestPartyValidate tests that creditors are normalised and need a name, an account and a BIC
+func TestPartyValidate(t *testing.T) {
+	party := Party{Name: " John Smith ", IBAN: "gb33 bukb 2020 1555 5555 55", BIC: "bukbgb22"}
+	assert.NoError(t, party.Validate())
+	assert.Equal(t, "GB33BUKB20201555555555", party.IBAN)
+	assert.Equal(t, "BUKBGB22", party.BIC)
+
+	assert.Error(t, (&Party{Name: "John Smith", BIC: "BUKBGB22"}).Validate())
+	assert.Error(t, (&Party{Name: "John Smith", Account: "12345678", BIC: "BUKB"}).Validate())
+	assert.Error(t, (&Party{IBAN: "GB33BUKB20201555555555", BIC: "BUKBGB22"}).Validate())
+}
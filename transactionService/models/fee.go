@@ -0,0 +1,78 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// FeeBand is one band of a tiered fee: transactions up to UpTo pay Fee. An UpTo of zero
+// means no upper bound.
+type FeeBand struct {
+	UpTo float64 `json:"up_to"` // Largest amount the band covers, 0 for the last band
+	Fee  float64 `json:"fee"`   // Flat fee charged for amounts in the band
+}
+
+// FeeRule charges a fee on transactions of one type, optionally only for one account tier
+type FeeRule struct {
+	ID              int64     `json:"id"`               // Unique identifier for the rule
+	Name            string    `json:"name"`             // Label shown on the fee line (e.g., "ATM withdrawal fee")
+	TransactionType string    `json:"transaction_type"` // "deposit", "withdrawal" or "transfer"
+	Tier            string    `json:"tier,omitempty"`   // Account tier the rule applies to, empty for every tier
+	FeeType         string    `json:"fee_type"`         // "flat", "percentage" or "tiered"
+	Amount          float64   `json:"amount"`           // Fee for flat rules
+	Rate            float64   `json:"rate"`             // Fraction of the amount for percentage rules (e.g., 0.01 for 1%)
+	MinFee          float64   `json:"min_fee"`          // Smallest percentage fee, 0 for none
+	MaxFee          float64   `json:"max_fee"`          // Largest percentage fee, 0 for no cap
+	Bands           []FeeBand `json:"bands,omitempty"`  // Amount bands for tiered rules, in ascending order
+	FreePerMonth    int       `json:"free_per_month"`   // Transactions per calendar month exempt from the rule
+	Active          bool      `json:"active"`           // Whether the rule is applied
+	UpdatedAt       time.Time `json:"updated_at"`       // Timestamp of last update
+}
+
+// Fee is a charge applied to a transaction and credited to the fee income account
+type Fee struct {
+	RuleID   int64   `json:"rule_id"`  // Rule that produced the fee
+	Name     string  `json:"name"`     // Label of the rule
+	Amount   float64 `json:"amount"`   // Fee taken from the paying account, in its currency
+	Currency string  `json:"currency"` // Currency of Amount
+}
+
+// Validate checks the rule is complete and its amounts are not negative
+func (f *FeeRule) Validate() error {
+	if f.Name == "" {
+		return fmt.Errorf("fee rule name is required")
+	}
+	if f.TransactionType != "deposit" && f.TransactionType != "withdrawal" && f.TransactionType != "transfer" {
+		return fmt.Errorf("unknown transaction type %q, expected deposit, withdrawal or transfer", f.TransactionType)
+	}
+	if f.Amount < 0 || f.Rate < 0 || f.MinFee < 0 || f.MaxFee < 0 || f.FreePerMonth < 0 {
+		return fmt.Errorf("fee amounts cannot be negative")
+	}
+	if f.MaxFee > 0 && f.MinFee > f.MaxFee {
+		return fmt.Errorf("min_fee cannot exceed max_fee")
+	}
+	switch f.FeeType {
+	case "flat", "percentage":
+		f.Bands = nil
+	case "tiered":
+		if len(f.Bands) == 0 {
+			return fmt.Errorf("tiered fees need at least one band")
+		}
+		previous := 0.0
+		for i, band := range f.Bands {
+			if band.Fee < 0 {
+				return fmt.Errorf("band %d fee cannot be negative", i+1)
+			}
+			if band.UpTo == 0 && i != len(f.Bands)-1 {
+				return fmt.Errorf("only the last band can be unbounded")
+			}
+			if band.UpTo != 0 && band.UpTo <= previous {
+				return fmt.Errorf("band bounds must be ascending")
+			}
+			previous = band.UpTo
+		}
+	default:
+		return fmt.Errorf("unknown fee type %q, expected flat, percentage or tiered", f.FeeType)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// FxRate is the price of one unit of Base in units of Quote
+type FxRate struct {
+	Base      string    `json:"base_currency"`  // ISO 4217 code being converted from
+	Quote     string    `json:"quote_currency"` // ISO 4217 code being converted to
+	Rate      float64   `json:"rate"`           // Units of Quote per unit of Base
+	UpdatedAt time.Time `json:"updated_at"`     // Timestamp of last update
+}
+
+// Validate normalises the currency codes and checks the rate is usable
+func (f *FxRate) Validate() error {
+	var err error
+	if f.Base, err = NormalizeCurrency(f.Base); err != nil {
+		return err
+	}
+	if f.Quote, err = NormalizeCurrency(f.Quote); err != nil {
+		return err
+	}
+	if f.Base == f.Quote {
+		return fmt.Errorf("base and quote currency must differ: %s", f.Base)
+	}
+	if f.Rate <= 0 || math.IsInf(f.Rate, 0) || math.IsNaN(f.Rate) {
+		return fmt.Errorf("rate must be positive: %v", f.Rate)
+	}
+	return nil
+}
+
+// NormalizeCurrency upper-cases a three letter ISO 4217 code and rejects anything else
+func NormalizeCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 3 {
+		return "", fmt.Errorf("invalid currency code %q", code)
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return "", fmt.Errorf("invalid currency code %q", code)
+		}
+	}
+	return code, nil
+}
+
+// ConvertAmount applies rate to amount and rounds to cents
+func ConvertAmount(amount, rate float64) float64 {
+	return math.Round(amount*rate*100) / 100
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Hold reserves funds on an account until they are captured, released or the hold expires
+type Hold struct {
+	ID                   uuid.UUID  `json:"id"`                               // Unique identifier for the hold
+	AccountNumber        string     `json:"account_number"`                   // Account the funds are reserved on
+	Amount               float64    `json:"amount"`                           // Amount reserved
+	CapturedAmount       float64    `json:"captured_amount"`                  // Amount debited when the hold was captured
+	CaptureTransactionID *uuid.UUID `json:"capture_transaction_id,omitempty"` // Transaction that posted the captured debit
+	Reference            string     `json:"reference"`                        // Optional merchant or authorization reference
+	Status               string     `json:"status"`                           // Hold status ("active", "captured", "released", "expired")
+	ExpiresAt            time.Time  `json:"expires_at"`                       // Time after which the hold no longer reserves funds
+	CreatedAt            time.Time  `json:"created_at"`                       // Timestamp of hold creation
+	UpdatedAt            time.Time  `json:"updated_at"`                       // Timestamp of last update
+}
@@ -0,0 +1,141 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InterestTier is one band of a tiered plan. The band covers the part of the balance above the
+// previous band's UpTo and up to its own; an UpTo of zero means no upper bound.
+type InterestTier struct {
+	UpTo float64 `json:"up_to"` // Upper bound of the band, 0 for the last band
+	Rate float64 `json:"rate"`  // Annual rate paid on the part of the balance inside the band
+}
+
+// InterestPlan describes how savings interest accrues on the accounts assigned to it
+type InterestPlan struct {
+	Name       string         `json:"name"`            // Plan name (e.g., "easy-saver")
+	Type       string         `json:"type"`            // "simple", "tiered" or "compound_monthly"
+	AnnualRate float64        `json:"annual_rate"`     // Nominal annual rate for simple and compound_monthly plans
+	Tiers      []InterestTier `json:"tiers,omitempty"` // Rate bands for tiered plans, in ascending order
+	DayCount   string         `json:"day_count"`       // Day-count convention: "ACT/365" or "30/360"
+	UpdatedAt  time.Time      `json:"updated_at"`      // Timestamp of last update
+}
+
+// InterestAccrual is one day of interest earned by an account, posted at the end of the month
+type InterestAccrual struct {
+	ID            uuid.UUID  `json:"id"`                       // Unique identifier for the accrual
+	AccountNumber string     `json:"account_number"`           // Account earning the interest
+	AccrualDate   time.Time  `json:"accrual_date"`             // Day the interest covers
+	Plan          string     `json:"plan"`                     // Plan the interest was computed under
+	Balance       float64    `json:"balance"`                  // Balance the interest was computed on
+	Amount        float64    `json:"amount"`                   // Unrounded interest for the day
+	TransactionID *uuid.UUID `json:"transaction_id,omitempty"` // Monthly credit that paid the accrual out
+}
+
+// InterestPosting is the credit that pays out an account's unposted accruals
+type InterestPosting struct {
+	AccountNumber string    `json:"account_number"` // Account being credited
+	Period        string    `json:"period"`         // Month being paid out (YYYY-MM)
+	Amount        float64   `json:"amount"`         // Sum of the accruals, rounded to cents
+	TransactionID uuid.UUID `json:"transaction_id"` // Deterministic ID of the credit
+}
+
+// interestNamespace seeds the deterministic IDs of monthly interest credits
+var interestNamespace = uuid.MustParse("2d8e6c4a-9b1f-4f3e-8a5d-7c6b5a4e3d2f")
+
+// InterestTransactionID returns the ID of the credit paying out an account's interest for a period,
+// so that posting the same month twice produces the same transaction
+func InterestTransactionID(accountNumber, period string) uuid.UUID {
+	return uuid.NewSHA1(interestNamespace, []byte(accountNumber+"/"+period))
+}
+
+// Validate checks the plan type, day-count convention and rates
+func (p *InterestPlan) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("plan name is required")
+	}
+	if p.DayCount != "ACT/365" && p.DayCount != "30/360" {
+		return fmt.Errorf("unknown day count %q, expected ACT/365 or 30/360", p.DayCount)
+	}
+	switch p.Type {
+	case "simple", "compound_monthly":
+		if p.AnnualRate < 0 {
+			return fmt.Errorf("annual rate cannot be negative")
+		}
+		p.Tiers = nil
+	case "tiered":
+		if len(p.Tiers) == 0 {
+			return fmt.Errorf("tiered plans need at least one tier")
+		}
+		previous := 0.0
+		for i, tier := range p.Tiers {
+			if tier.Rate < 0 {
+				return fmt.Errorf("tier %d rate cannot be negative", i+1)
+			}
+			last := i == len(p.Tiers)-1
+			if tier.UpTo == 0 && !last {
+				return fmt.Errorf("only the last tier can be unbounded")
+			}
+			if tier.UpTo != 0 && tier.UpTo <= previous {
+				return fmt.Errorf("tier bounds must be ascending")
+			}
+			previous = tier.UpTo
+		}
+		p.AnnualRate = 0
+	default:
+		return fmt.Errorf("unknown plan type %q, expected simple, tiered or compound_monthly", p.Type)
+	}
+	return nil
+}
+
+// DayCountFraction returns the fraction of a year that a single day contributes under the
+// convention. Under 30/360 every month counts as 30 days: the 31st contributes nothing and the
+// last day of February makes up the days February is short.
+func DayCountFraction(convention string, day time.Time) float64 {
+	if convention != "30/360" {
+		return 1.0 / 365
+	}
+	if day.Day() == 31 {
+		return 0
+	}
+	if day.Month() == time.February && day.AddDate(0, 0, 1).Month() != time.February {
+		return float64(30-day.Day()+1) / 360
+	}
+	return 1.0 / 360
+}
+
+// DailyAccrual returns the unrounded interest earned on balance for day. Simple and tiered plans
+// pay the annual rate pro rata; compound_monthly plans pay the day's share of a nominal rate
+// compounded monthly. Negative balances earn nothing.
+func (p *InterestPlan) DailyAccrual(balance float64, day time.Time) float64 {
+	if balance <= 0 {
+		return 0
+	}
+	fraction := DayCountFraction(p.DayCount, day)
+	switch p.Type {
+	case "compound_monthly":
+		return balance * (math.Pow(1+p.AnnualRate/12, 12*fraction) - 1)
+	case "tiered":
+		interest, lower := 0.0, 0.0
+		for _, tier := range p.Tiers {
+			upper := tier.UpTo
+			if upper == 0 || upper > balance {
+				upper = balance
+			}
+			if upper > lower {
+				interest += (upper - lower) * tier.Rate * fraction
+			}
+			if upper >= balance {
+				break
+			}
+			lower = upper
+		}
+		return interest
+	default:
+		return balance * p.AnnualRate * fraction
+	}
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func day(value string) time.Time {
+	t, _ := time.Parse("2006-01-02", value)
+	return t
+}
+
+// TestDayCountFraction tests that a 30/360 month always adds up to 30 days
+func TestDayCountFraction(t *testing.T) {
+	for _, month := range []string{"2025-01", "2025-02", "2024-02", "2025-04"} {
+		total := 0.0
+		for d := day(month + "-01"); d.Format("2006-01") == month; d = d.AddDate(0, 0, 1) {
+			total += DayCountFraction("30/360", d)
+		}
+		assert.InDelta(t, 30.0/360, total, 1e-12, month)
+	}
+	assert.InDelta(t, 1.0/365, DayCountFraction("ACT/365", day("2025-01-31")), 1e-12)
+}
+
+// TestDailyAccrual tests the three plan types
+func TestDailyAccrual(t *testing.T) {
+	simple := InterestPlan{Name: "s", Type: "simple", AnnualRate: 0.0365, DayCount: "ACT/365"}
+	assert.NoError(t, simple.Validate())
+	assert.InDelta(t, 1.0, simple.DailyAccrual(10000, day("2025-03-10")), 1e-9)
+	assert.Equal(t, 0.0, simple.DailyAccrual(-50, day("2025-03-10")))
+
+	tiered := InterestPlan{Name: "t", Type: "tiered", DayCount: "ACT/365", Tiers: []InterestTier{
+		{UpTo: 1000, Rate: 0.0365},
+		{Rate: 0.073},
+	}}
+	assert.NoError(t, tiered.Validate())
+	// 1000 at 3.65% plus 2000 at 7.3% for one day
+	assert.InDelta(t, 0.1+0.4, tiered.DailyAccrual(3000, day("2025-03-10")), 1e-9)
+	assert.InDelta(t, 0.05, tiered.DailyAccrual(500, day("2025-03-10")), 1e-9)
+
+	// Thirty days of monthly compounding on 30/360 earn exactly one month at r/12
+	compound := InterestPlan{Name: "c", Type: "compound_monthly", AnnualRate: 0.12, DayCount: "30/360"}
+	assert.NoError(t, compound.Validate())
+	growth := 1.0
+	for d := day("2025-04-01"); d.Month() == time.April; d = d.AddDate(0, 0, 1) {
+		growth += compound.DailyAccrual(growth, d) // accruals compound daily here only to check the rate
+	}
+	assert.InDelta(t, 1.01, growth, 1e-9)
+
+	bad := InterestPlan{Name: "b", Type: "tiered", DayCount: "ACT/365", Tiers: []InterestTier{{Rate: 0.01}, {UpTo: 10, Rate: 0.02}}}
+	assert.Error(t, bad.Validate())
+	assert.Error(t, (&InterestPlan{Name: "x", Type: "simple", DayCount: "ACT/360"}).Validate())
+}
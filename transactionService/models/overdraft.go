@@ -0,0 +1,28 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OverdraftCharge is the interest taken from an overdrawn account for a single day
+type OverdraftCharge struct {
+	ID              uuid.UUID `json:"id"`                 // Unique identifier for the charge
+	AccountNumber   string    `json:"account_number"`     // Account that was charged
+	ChargeDate      time.Time `json:"charge_date"`        // Day the interest covers
+	OverdrawnAmount float64   `json:"overdrawn_amount"`   // Overdraft used when the charge was taken
+	AnnualRate      float64   `json:"annual_rate"`        // Annual overdraft interest rate applied
+	Amount          float64   `json:"amount"`             // Interest debited from the account
+	TransactionID   uuid.UUID `json:"transaction_id"`     // Transaction that posted the charge
+	Postings        []Posting `json:"postings,omitempty"` // Balance movement the charge made
+}
+
+// DailyOverdraftInterest returns one day of interest on the overdrawn amount
+func DailyOverdraftInterest(overdrawn, annualRate float64) float64 {
+	if overdrawn <= 0 || annualRate <= 0 {
+		return 0
+	}
+	return math.Round(overdrawn*annualRate/365*100) / 100
+}
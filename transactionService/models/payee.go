@@ -0,0 +1,40 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Payee is a beneficiary registered on an account, either an account of this bank paid by
+// transfers or, with a PayeeBIC, an account at another bank paid by external transfers. Payments
+// to a payee are refused until its cooling-off period ends at ActiveFrom.
+type Payee struct {
+	ID            uuid.UUID  `json:"id"`                    // Unique identifier for the payee
+	AccountNumber string     `json:"account_number"`        // Account the payee is registered on
+	Nickname      string     `json:"nickname"`              // Name the customer knows the payee by
+	PayeeAccount  string     `json:"payee_account"`         // Account transfers to the payee are credited to, or IBAN or account number at PayeeBIC
+	PayeeBIC      string     `json:"payee_bic,omitempty"`   // Bank of a payee at another bank
+	PayeeName     string     `json:"payee_name,omitempty"`  // Beneficiary name given by the customer
+	Status        string     `json:"status"`                // "unverified" or "verified"
+	ActiveFrom    time.Time  `json:"active_from"`           // End of the cooling-off period
+	VerifiedAt    *time.Time `json:"verified_at,omitempty"` // Timestamp the payee was verified
+	CreatedAt     time.Time  `json:"created_at"`            // Timestamp the payee was added
+}
+
+// Creditor returns the party external transfers to a payee at another bank are made to
+func (p *Payee) Creditor() *Party {
+	creditor := &Party{Name: p.PayeeName, BIC: p.PayeeBIC}
+	if iban := strings.ToUpper(strings.ReplaceAll(p.PayeeAccount, " ", "")); ibanPattern.MatchString(iban) {
+		creditor.IBAN = iban
+	} else {
+		creditor.Account = strings.TrimSpace(p.PayeeAccount)
+	}
+	return creditor
+}
+
+// ValidPayeeStatus reports whether status is a payee verification status
+func ValidPayeeStatus(status string) bool {
+	return status == "unverified" || status == "verified"
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RiskReview is a transaction stopped by the risk checks. Held transactions wait in "pending"
+// until an operator approves or rejects them; rejected ones are kept for the record.
+type RiskReview struct {
+	ID          uuid.UUID   `json:"id"`                   // Unique identifier for the review
+	Transaction Transaction `json:"transaction"`          // Transaction as it was received
+	Decision    string      `json:"decision"`             // Decision of the risk checks ("review" or "reject")
+	Score       int         `json:"score"`                // Risk score of the transaction
+	Reasons     []string    `json:"reasons"`              // Rules that fired
+	Status      string      `json:"status"`               // Review status ("pending", "approved", "rejected", "failed")
+	Reviewer    string      `json:"reviewer,omitempty"`   // Operator who decided the review
+	Note        string      `json:"note,omitempty"`       // Operator note, or why an approved transaction could not be applied
+	CreatedAt   time.Time   `json:"created_at"`           // Timestamp the transaction was stopped
+	DecidedAt   *time.Time  `json:"decided_at,omitempty"` // Timestamp of the operator decision
+}
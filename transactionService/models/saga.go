@@ -0,0 +1,105 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Saga types. A transfer with fee charges the fee first and then moves the amount; an external
+// transfer also waits for the clearing system to settle the payment.
+const (
+	SagaTransferWithFee  = "transfer_with_fee"
+	SagaExternalTransfer = "external_transfer"
+)
+
+// Saga states. A saga runs its steps in order and completes when the last one does. When a step
+// fails the completed steps before it are compensated in reverse order and the saga ends
+// compensated, or failed when a compensation is refused and an operator has to step in.
+const (
+	SagaRunning      = "running"
+	SagaCompensating = "compensating"
+	SagaCompleted    = "completed"
+	SagaCompensated  = "compensated"
+	SagaFailed       = "failed"
+)
+
+// Saga step states
+const (
+	StepPending      = "pending"
+	StepRunning      = "running"
+	StepCompleted    = "completed"
+	StepFailed       = "failed"
+	StepCompensating = "compensating"
+	StepCompensated  = "compensated"
+)
+
+// SagaRequest starts a saga
+type SagaRequest struct {
+	ID            uuid.UUID `json:"id"`                     // Optional; a request repeated with the same ID starts the saga once
+	Type          string    `json:"type"`                   // "transfer_with_fee" or "external_transfer"
+	FromAccountID string    `json:"from_account_id"`        // Account paying the amount and the fee
+	ToAccountID   string    `json:"to_account_id"`          // Destination of a transfer with fee
+	Amount        float64   `json:"amount"`                 // Amount transferred
+	Fee           float64   `json:"fee"`                    // Fee charged to FromAccountID before the transfer; may be 0
+	Currency      string    `json:"currency,omitempty"`     // Currency of Amount and Fee; defaults to that of FromAccountID
+	Description   string    `json:"description,omitempty"`  // Description of the transfer
+	InitiatedBy   string    `json:"initiated_by,omitempty"` // Customer requesting the transfer
+	Creditor      *Party    `json:"creditor,omitempty"`     // Beneficiary of an external transfer
+	Reference     string    `json:"reference,omitempty"`    // End-to-end reference of an external transfer
+}
+
+// Validate checks the request can be planned
+func (r *SagaRequest) Validate() error {
+	if r.FromAccountID == "" {
+		return fmt.Errorf("from_account_id is required")
+	}
+	if r.Amount <= 0 {
+		return fmt.Errorf("amount must be positive")
+	}
+	if r.Fee < 0 {
+		return fmt.Errorf("fee cannot be negative")
+	}
+	switch r.Type {
+	case SagaTransferWithFee:
+		if r.ToAccountID == "" || r.ToAccountID == r.FromAccountID {
+			return fmt.Errorf("to_account_id is required and must differ from from_account_id")
+		}
+	case SagaExternalTransfer:
+		if r.Creditor == nil {
+			return fmt.Errorf("creditor is required")
+		}
+		return r.Creditor.Validate()
+	default:
+		return fmt.Errorf("invalid saga type %q", r.Type)
+	}
+	return nil
+}
+
+// Saga is a multi-step transaction whose steps are posted one at a time over Kafka
+type Saga struct {
+	ID        uuid.UUID   `json:"id"`
+	Type      string      `json:"type"`
+	Status    string      `json:"status"`          // "running", "compensating", "completed", "compensated" or "failed"
+	Request   SagaRequest `json:"request"`         // Request the saga was planned from
+	Error     string      `json:"error,omitempty"` // Why the saga did not complete
+	Steps     []SagaStep  `json:"steps,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// SagaStep is one step of a saga. It is done when the transaction TransactionID reaches the ledger.
+type SagaStep struct {
+	Index          int          `json:"index"`
+	Name           string       `json:"name"`
+	Status         string       `json:"status"`                    // "pending", "running", "completed", "failed", "compensating" or "compensated"
+	Command        *Transaction `json:"command,omitempty"`         // Published to the "transaction" topic; nil for steps that wait on another service
+	TransactionID  uuid.UUID    `json:"transaction_id"`            // Movement that completes the step
+	FailureID      *uuid.UUID   `json:"failure_id,omitempty"`      // Movement that fails a step without a command
+	CompensationID *uuid.UUID   `json:"compensation_id,omitempty"` // Reversal undoing the step; nil when it needs none
+	Attempts       int          `json:"attempts"`                  // Times the command or compensation was published
+	PublishedAt    *time.Time   `json:"published_at,omitempty"`    // Last time it was published
+	Error          string       `json:"error,omitempty"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// AccountTier holds the limits enforced on every account assigned to the tier
+type AccountTier struct {
+	Name                   string    `json:"name"`                     // Tier name (e.g., "basic", "premium", "business")
+	MaxPerTransaction      float64   `json:"max_per_transaction"`      // Largest single withdrawal or transfer
+	DailyWithdrawalLimit   float64   `json:"daily_withdrawal_limit"`   // Total withdrawals allowed per calendar day
+	MonthlyWithdrawalLimit float64   `json:"monthly_withdrawal_limit"` // Total withdrawals allowed per calendar month
+	DailyTransferLimit     float64   `json:"daily_transfer_limit"`     // Total outgoing transfers allowed per calendar day
+	MonthlyTransferLimit   float64   `json:"monthly_transfer_limit"`   // Total outgoing transfers allowed per calendar month
+	MinBalance             float64   `json:"min_balance"`              // Balance that must remain after a debit
+	Currency               string    `json:"currency"`                 // ISO 4217 code the limits are expressed in, USD when empty
+	UpdatedAt              time.Time `json:"updated_at"`               // Timestamp of last update
+}
+
+// Validate checks that none of the limits are negative and normalises the currency
+func (t *AccountTier) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("tier name is required")
+	}
+	if t.Currency == "" {
+		t.Currency = "USD"
+	}
+	currency, err := NormalizeCurrency(t.Currency)
+	if err != nil {
+		return err
+	}
+	t.Currency = currency
+	if t.MaxPerTransaction < 0 || t.DailyWithdrawalLimit < 0 || t.MonthlyWithdrawalLimit < 0 ||
+		t.DailyTransferLimit < 0 || t.MonthlyTransferLimit < 0 || t.MinBalance < 0 {
+		return fmt.Errorf("tier limits cannot be negative")
+	}
+	return nil
+}
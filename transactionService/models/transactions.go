@@ -2,15 +2,31 @@ package models
 
 import (
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type Transaction struct {
-	// ID              uuid.UUID `json:"id"`               // Unique identifier for the transaction
-	FromAccountID   string    `json:"from_account_id"`  // Foreign key referencing the sender's Account.ID
-	ToAccountID     string    `json:"to_account_id"`    // Foreign key referencing the recipient's Account.ID
-	Amount          float64   `json:"amount"`           // Transaction amount
-	TransactionType string    `json:"transaction_type"` // Type of transaction (e.g., "transfer", "deposit", "withdrawal")
-	Description     string    `json:"description"`      // Optional description of the transaction
-	CreatedAt       time.Time `json:"created_at"`       // Timestamp of transaction creation
-	Status          string    `json:"status"`           // Transaction status (e.g., "pending", "completed", "failed")
+	ID                    uuid.UUID `json:"id"`                                // Unique identifier for the transaction
+	FromAccountID         string    `json:"from_account_id"`                   // Foreign key referencing the sender's Account.ID
+	ToAccountID           string    `json:"to_account_id"`                     // Foreign key referencing the recipient's Account.ID
+	Amount                float64   `json:"amount"`                            // Transaction amount
+	TransactionType       string    `json:"transaction_type"`                  // Type of transaction (e.g., "transfer", "deposit", "withdrawal", "reversal", "external_transfer")
+	Description           string    `json:"description"`                       // Optional description of the transaction
+	CreatedAt             time.Time `json:"created_at"`                        // Timestamp of transaction creation
+	Status                string    `json:"status"`                            // Transaction status (e.g., "pending", "completed", "failed")
+	OriginalTransactionID string    `json:"original_transaction_id,omitempty"` // Transaction undone by a reversal (reversals debit FromAccountID, if it differs from ToAccountID, and credit ToAccountID)
+	Currency              string    `json:"currency,omitempty"`                // Currency of Amount; defaults to that of FromAccountID
+	FxRate                float64   `json:"fx_rate,omitempty"`                 // Rate applied when ToAccountID holds another currency
+	ConvertedAmount       float64   `json:"converted_amount,omitempty"`        // Amount credited to ToAccountID in ConvertedCurrency
+	ConvertedCurrency     string    `json:"converted_currency,omitempty"`      // Currency of ToAccountID when it differs from Currency
+	Fees                  []Fee     `json:"fees,omitempty"`                    // Fees charged to FromAccountID on top of Amount
+	InitiatedBy           string    `json:"initiated_by,omitempty"`            // Customer who requested the transaction; counts as the first approval on joint accounts
+	PayeeID               string    `json:"payee_id,omitempty"`                // Registered payee of a transfer; sets ToAccountID
+	BatchID               string    `json:"batch_id,omitempty"`                // Bulk payment file the transfer was submitted in
+	Creditor              *Party    `json:"creditor,omitempty"`                // Beneficiary at another bank of an external transfer
+	Debtor                *Party    `json:"debtor,omitempty"`                  // Payer at another bank of an inbound credit transfer
+	Reference             string    `json:"reference,omitempty"`               // End-to-end reference of an external transfer or inbound credit transfer
+	ClearingRef           string    `json:"clearing_ref,omitempty"`            // Message and transaction ID in the clearing system of an inbound credit transfer
+	Postings              []Posting `json:"postings,omitempty"`                // Balance movements made when the transaction was posted
 }
@@ -0,0 +1,454 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"transactionService/database"
+	"transactionService/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrApprovalNotFound is returned when a pending approval ID does not exist
+var ErrApprovalNotFound = errors.New("pending approval not found")
+
+// ApprovalTTL is how long a held transaction waits for its approvals before it expires
+var ApprovalTTL = 7 * 24 * time.Hour
+
+// ApprovalRepository implements ApprovalRepo for multi-holder approval of transactions
+type ApprovalRepository struct {
+	db *database.PostgresPoolDB
+}
+
+// NewApprovalRepository creates a new ApprovalRepository
+func NewApprovalRepository(db *database.PostgresPoolDB) *ApprovalRepository {
+	return &ApprovalRepository{db: db}
+}
+
+const pendingApprovalColumns = "id, account_number, transaction, required_approvals, status, expires_at, created_at, decided_at"
+
+// ListRules returns the approval rules of an account
+func (r *ApprovalRepository) ListRules(ctx context.Context, accountNumber string) ([]models.ApprovalRule, error) {
+	query := `
+        SELECT account_number, transaction_type, min_amount, required_approvals, updated_at
+        FROM usersschema.approval_rules
+        WHERE account_number = $1
+        ORDER BY transaction_type`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, query, accountNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list approval rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []models.ApprovalRule{}
+	for rows.Next() {
+		var rule models.ApprovalRule
+		if err := rows.Scan(&rule.AccountNumber, &rule.TransactionType, &rule.MinAmount, &rule.RequiredApprovals, &rule.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan approval rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating approval rule rows: %w", err)
+	}
+	return rules, nil
+}
+
+// UpsertRule creates or replaces the rule for a transaction type on an account
+func (r *ApprovalRepository) UpsertRule(ctx context.Context, rule *models.ApprovalRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	query := `
+        INSERT INTO usersschema.approval_rules (account_number, transaction_type, min_amount, required_approvals, updated_at)
+        VALUES ($1, $2, $3, $4, NOW())
+        ON CONFLICT (account_number, transaction_type) DO UPDATE
+        SET min_amount = EXCLUDED.min_amount,
+            required_approvals = EXCLUDED.required_approvals,
+            updated_at = NOW()
+        RETURNING updated_at`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	err = conn.QueryRow(ctx, query, rule.AccountNumber, rule.TransactionType, rule.MinAmount, rule.RequiredApprovals).Scan(&rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save approval rule: %w", err)
+	}
+	return nil
+}
+
+// DeleteRule removes the rule for a transaction type on an account
+func (r *ApprovalRepository) DeleteRule(ctx context.Context, accountNumber, transactionType string) error {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	result, err := conn.Exec(ctx, "DELETE FROM usersschema.approval_rules WHERE account_number = $1 AND transaction_type = $2", accountNumber, transactionType)
+	if err != nil {
+		return fmt.Errorf("failed to delete approval rule: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("no %s approval rule on account %s", transactionType, accountNumber)
+	}
+	return nil
+}
+
+// Gate decides whether trans may be applied now. A withdrawal or transfer caught by an approval
+// rule of its source account is stored as a pending approval and held; the initiator, if they
+// are an owner or joint holder, counts as the first approval. Once the holders have approved, a
+// message with the same transaction ID passes the gate and trans is replaced with the transaction
+// as it was stored, so only what the holders approved is applied. Returns true while the
+// transaction must not be applied.
+func (r *ApprovalRepository) Gate(ctx context.Context, trans *models.Transaction) (bool, error) {
+	if trans.TransactionType != "withdrawal" && trans.TransactionType != "transfer" && trans.TransactionType != "external_transfer" {
+		return false, nil
+	}
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	// A transaction seen before keeps the outcome of its approval
+	var status string
+	var stored []byte
+	query := "SELECT status, transaction FROM usersschema.pending_approvals WHERE transaction_id = $1"
+	err = tx.QueryRow(ctx, query, trans.ID).Scan(&status, &stored)
+	if err == nil {
+		if status == "approved" {
+			var approved models.Transaction
+			if err = json.Unmarshal(stored, &approved); err != nil {
+				return false, fmt.Errorf("failed to decode approved transaction: %w", err)
+			}
+			*trans = approved
+		}
+		err = tx.Commit(ctx)
+		return status != "approved", err
+	}
+	if err != pgx.ErrNoRows {
+		return false, fmt.Errorf("failed to check pending approval: %w", err)
+	}
+
+	var required int
+	err = tx.QueryRow(ctx, `
+        SELECT required_approvals
+        FROM usersschema.approval_rules
+        WHERE account_number = $1 AND transaction_type = $2 AND min_amount <= $3`,
+		trans.FromAccountID, trans.TransactionType, trans.Amount).Scan(&required)
+	if err == pgx.ErrNoRows {
+		err = tx.Commit(ctx)
+		return false, err
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to load approval rule: %w", err)
+	}
+
+	payload, err := json.Marshal(trans)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode transaction: %w", err)
+	}
+	var id string
+	err = tx.QueryRow(ctx, `
+        INSERT INTO usersschema.pending_approvals (transaction_id, account_number, transaction, required_approvals, expires_at)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (transaction_id) DO NOTHING
+        RETURNING id`,
+		trans.ID, trans.FromAccountID, payload, required, time.Now().Add(ApprovalTTL)).Scan(&id)
+	if err == pgx.ErrNoRows {
+		// another consumer stored it first
+		err = tx.Commit(ctx)
+		return true, err
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to store pending approval: %w", err)
+	}
+
+	if trans.InitiatedBy != "" {
+		if signErr := canSign(ctx, tx, trans.FromAccountID, trans.InitiatedBy); signErr == nil {
+			if err = recordDecision(ctx, tx, id, trans.InitiatedBy, "approve", "initiated"); err != nil {
+				return false, err
+			}
+		}
+	}
+	approved, err := settleApproval(ctx, tx, id, required)
+	if err != nil {
+		return false, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return !approved, nil
+}
+
+// GetApproval returns a pending approval with its decisions, or nil if it does not exist
+func (r *ApprovalRepository) GetApproval(ctx context.Context, id string) (*models.PendingApproval, error) {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	pending, err := scanPendingApproval(conn.QueryRow(ctx, "SELECT "+pendingApprovalColumns+" FROM usersschema.pending_approvals WHERE id = $1", id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get pending approval: %w", err)
+	}
+	if pending.Approvals, err = loadDecisions(ctx, conn, id); err != nil {
+		return nil, err
+	}
+	return pending, nil
+}
+
+// ListApprovals returns the approvals of an account with the given status, or all of them if
+// status is empty, newest first
+func (r *ApprovalRepository) ListApprovals(ctx context.Context, accountNumber, status string) ([]models.PendingApproval, error) {
+	query := `
+        SELECT ` + pendingApprovalColumns + `
+        FROM usersschema.pending_approvals
+        WHERE account_number = $1 AND ($2 = '' OR status = $2)
+        ORDER BY created_at DESC`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, query, accountNumber, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending approvals: %w", err)
+	}
+	approvals := []models.PendingApproval{}
+	for rows.Next() {
+		pending, err := scanPendingApproval(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan pending approval: %w", err)
+		}
+		approvals = append(approvals, *pending)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pending approval rows: %w", err)
+	}
+
+	for i := range approvals {
+		if approvals[i].Approvals, err = loadDecisions(ctx, conn, approvals[i].ID.String()); err != nil {
+			return nil, err
+		}
+	}
+	return approvals, nil
+}
+
+// Approve records a holder's approval. Once enough owners and joint holders have approved,
+// the request moves to "approved" and the caller resubmits the transaction. Approving a
+// request that is already approved returns it unchanged so a failed resubmission can be retried.
+func (r *ApprovalRepository) Approve(ctx context.Context, id, customerID string) (*models.PendingApproval, error) {
+	return r.decide(ctx, id, customerID, "approve", "")
+}
+
+// Reject records a holder's rejection; a single rejection cancels the transaction
+func (r *ApprovalRepository) Reject(ctx context.Context, id, customerID, note string) (*models.PendingApproval, error) {
+	return r.decide(ctx, id, customerID, "reject", note)
+}
+
+// decide records an approve or reject decision and settles the request
+func (r *ApprovalRepository) decide(ctx context.Context, id, customerID, decision, note string) (*models.PendingApproval, error) {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	pending, err := scanPendingApproval(tx.QueryRow(ctx, "SELECT "+pendingApprovalColumns+" FROM usersschema.pending_approvals WHERE id = $1 FOR UPDATE", id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("%w: %s", ErrApprovalNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to lock pending approval: %w", err)
+	}
+
+	if pending.Status == "pending" && !pending.ExpiresAt.After(time.Now()) {
+		if _, err = tx.Exec(ctx, "UPDATE usersschema.pending_approvals SET status = 'expired', decided_at = NOW() WHERE id = $1", id); err != nil {
+			return nil, fmt.Errorf("failed to expire pending approval: %w", err)
+		}
+		if err = tx.Commit(ctx); err != nil {
+			return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return nil, fmt.Errorf("pending approval %s expired at %s", id, pending.ExpiresAt.Format(time.RFC3339))
+	}
+	if pending.Status == "approved" && decision == "approve" {
+		err = tx.Commit(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		pending.Approvals, err = loadDecisions(ctx, conn, id)
+		return pending, err
+	}
+	if pending.Status != "pending" {
+		err = fmt.Errorf("pending approval %s is %s", id, pending.Status)
+		return nil, err
+	}
+
+	if err = canSign(ctx, tx, pending.AccountNumber, customerID); err != nil {
+		return nil, err
+	}
+	if err = recordDecision(ctx, tx, id, customerID, decision, note); err != nil {
+		return nil, err
+	}
+	if decision == "reject" {
+		if _, err = tx.Exec(ctx, "UPDATE usersschema.pending_approvals SET status = 'rejected', decided_at = NOW() WHERE id = $1", id); err != nil {
+			return nil, fmt.Errorf("failed to reject pending approval: %w", err)
+		}
+	} else if _, err = settleApproval(ctx, tx, id, pending.RequiredApprovals); err != nil {
+		return nil, err
+	}
+
+	pending, err = scanPendingApproval(tx.QueryRow(ctx, "SELECT "+pendingApprovalColumns+" FROM usersschema.pending_approvals WHERE id = $1", id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to reload pending approval: %w", err)
+	}
+	if pending.Approvals, err = loadDecisions(ctx, tx, id); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return pending, nil
+}
+
+// canSign fails unless the customer is a verified owner or joint holder of the account.
+// Viewers can see the account but not approve its transactions.
+func canSign(ctx context.Context, q rowQuerier, accountNumber, customerID string) error {
+	query := `
+        SELECT h.role, c.kyc_status
+        FROM usersschema.account_holders h
+        JOIN usersschema.customers c ON c.id = h.customer_id
+        WHERE h.account_number = $1 AND h.customer_id::text = $2`
+	var role, kycStatus string
+	if err := q.QueryRow(ctx, query, accountNumber, customerID).Scan(&role, &kycStatus); err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("customer %s is not a holder of account %s", customerID, accountNumber)
+		}
+		return fmt.Errorf("failed to load account holder: %w", err)
+	}
+	if role != "owner" && role != "joint" {
+		return fmt.Errorf("customer %s is a %s of account %s and cannot approve transactions", customerID, role, accountNumber)
+	}
+	if kycStatus != "verified" {
+		return fmt.Errorf("customer %s is %s, only verified customers can approve transactions", customerID, kycStatus)
+	}
+	return nil
+}
+
+// recordDecision stores a holder's decision; a later decision by the same holder replaces it
+func recordDecision(ctx context.Context, tx pgx.Tx, id, customerID, decision, note string) error {
+	query := `
+        INSERT INTO usersschema.approval_decisions (pending_approval_id, customer_id, decision, note)
+        VALUES ($1, $2, $3, NULLIF($4, ''))
+        ON CONFLICT (pending_approval_id, customer_id) DO UPDATE
+        SET decision = EXCLUDED.decision, note = EXCLUDED.note, created_at = NOW()`
+	if _, err := tx.Exec(ctx, query, id, customerID, decision, note); err != nil {
+		return fmt.Errorf("failed to record approval decision: %w", err)
+	}
+	return nil
+}
+
+// settleApproval marks the request approved once it has enough approvals and reports whether it is
+func settleApproval(ctx context.Context, tx pgx.Tx, id string, required int) (bool, error) {
+	var approvals int
+	query := "SELECT COUNT(*) FROM usersschema.approval_decisions WHERE pending_approval_id = $1 AND decision = 'approve'"
+	if err := tx.QueryRow(ctx, query, id).Scan(&approvals); err != nil {
+		return false, fmt.Errorf("failed to count approvals: %w", err)
+	}
+	if approvals < required {
+		return false, nil
+	}
+	if _, err := tx.Exec(ctx, "UPDATE usersschema.pending_approvals SET status = 'approved', decided_at = NOW() WHERE id = $1", id); err != nil {
+		return false, fmt.Errorf("failed to approve pending approval: %w", err)
+	}
+	return true, nil
+}
+
+// rowsQuerier is satisfied by both pooled connections and open transactions
+type rowsQuerier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// loadDecisions returns the decisions recorded on a pending approval, oldest first
+func loadDecisions(ctx context.Context, q rowsQuerier, id string) ([]models.Approval, error) {
+	query := `
+        SELECT customer_id, decision, COALESCE(note, ''), created_at
+        FROM usersschema.approval_decisions
+        WHERE pending_approval_id = $1
+        ORDER BY created_at`
+	rows, err := q.Query(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load approval decisions: %w", err)
+	}
+	defer rows.Close()
+
+	approvals := []models.Approval{}
+	for rows.Next() {
+		var approval models.Approval
+		if err := rows.Scan(&approval.CustomerID, &approval.Decision, &approval.Note, &approval.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan approval decision: %w", err)
+		}
+		approvals = append(approvals, approval)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating approval decision rows: %w", err)
+	}
+	return approvals, nil
+}
+
+// scanPendingApproval scans a row selected with pendingApprovalColumns
+func scanPendingApproval(row pgx.Row) (*models.PendingApproval, error) {
+	pending := &models.PendingApproval{}
+	var payload []byte
+	err := row.Scan(&pending.ID, &pending.AccountNumber, &payload, &pending.RequiredApprovals, &pending.Status,
+		&pending.ExpiresAt, &pending.CreatedAt, &pending.DecidedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(payload, &pending.Transaction); err != nil {
+		return nil, fmt.Errorf("invalid transaction on pending approval %s: %w", pending.ID, err)
+	}
+	return pending, nil
+}
@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"transactionService/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGateAppliesApprovedTransaction tests that a message resubmitted under an approved
+// transaction's ID is replaced with the transaction the holders approved
+func TestGateAppliesApprovedTransaction(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	seedAccounts(t, db, map[string]float64{"ACC1": 1000, "ACC2": 0})
+	_, err := db.Pool().Exec(ctx, `
+        INSERT INTO usersschema.approval_rules (account_number, transaction_type, min_amount, required_approvals)
+        VALUES ('ACC1', 'transfer', 100, 2)`)
+	require.NoError(t, err)
+
+	repo := NewApprovalRepository(db)
+	trans := models.Transaction{ID: uuid.New(), FromAccountID: "ACC1", ToAccountID: "ACC2", Amount: 150, TransactionType: "transfer"}
+	held, err := repo.Gate(ctx, &trans)
+	require.NoError(t, err)
+	assert.True(t, held)
+
+	_, err = db.Pool().Exec(ctx, "UPDATE usersschema.pending_approvals SET status = 'approved' WHERE transaction_id = $1", trans.ID)
+	require.NoError(t, err)
+
+	replayed := models.Transaction{ID: trans.ID, FromAccountID: "ACC1", ToAccountID: "ACC9", Amount: 900, TransactionType: "transfer"}
+	held, err = repo.Gate(ctx, &replayed)
+	require.NoError(t, err)
+	assert.False(t, held)
+	assert.Equal(t, "ACC2", replayed.ToAccountID)
+	assert.Equal(t, 150.0, replayed.Amount)
+}
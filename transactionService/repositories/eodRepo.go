@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"transactionService/businessday"
+	"transactionService/models"
+)
+
+// BusinessDays closes the business days end-of-day balances are taken for. It is set from
+// configuration at startup.
+var BusinessDays = businessday.Calendar{Location: time.UTC}
+
+// NextEndOfDay returns the business date after the latest one snapshotted, or the date of the first
+// event when none has been. It returns nil when there are no events yet.
+func (r *EventRepository) NextEndOfDay(ctx context.Context) (*time.Time, error) {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	var latest *time.Time
+	if err := conn.QueryRow(ctx, "SELECT MAX(business_date) FROM usersschema.eod_balances").Scan(&latest); err != nil {
+		return nil, fmt.Errorf("failed to get latest end-of-day date: %w", err)
+	}
+	if latest != nil {
+		next := latest.AddDate(0, 0, 1)
+		return &next, nil
+	}
+
+	var first *time.Time
+	if err := conn.QueryRow(ctx, "SELECT MIN(occurred_at) FROM usersschema.account_events").Scan(&first); err != nil {
+		return nil, fmt.Errorf("failed to get first event: %w", err)
+	}
+	if first == nil {
+		return nil, nil
+	}
+	date := BusinessDays.Date(*first)
+	return &date, nil
+}
+
+// SnapshotEndOfDay records the balance at the close of a business date of every account with
+// events by then, and returns how many were recorded. Each balance is the previous end-of-day
+// balance of the account plus its events since, so days can be taken in any order. Balances
+// already recorded are left as they are.
+func (r *EventRepository) SnapshotEndOfDay(ctx context.Context, date time.Time) (int64, error) {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	query := `
+        INSERT INTO usersschema.eod_balances (account_number, business_date, balance, version, closed_at)
+        SELECT a.account_number, $1::date,
+               COALESCE(prev.balance, 0) + COALESCE(SUM(CASE WHEN e.event_type = 'debited' THEN -e.amount ELSE e.amount END), 0),
+               COALESCE(MAX(e.version), prev.version),
+               $2
+        FROM usersschema.accounts a
+        LEFT JOIN LATERAL (
+            SELECT p.balance, p.version
+            FROM usersschema.eod_balances p
+            WHERE p.account_number = a.account_number AND p.business_date < $1::date
+            ORDER BY p.business_date DESC
+            LIMIT 1
+        ) prev ON true
+        LEFT JOIN usersschema.account_events e
+            ON e.account_number = a.account_number AND e.version > COALESCE(prev.version, 0) AND e.occurred_at < $2
+        WHERE EXISTS (
+            SELECT 1 FROM usersschema.account_events f
+            WHERE f.account_number = a.account_number AND f.occurred_at < $2
+        )
+        GROUP BY a.account_number, prev.balance, prev.version
+        ON CONFLICT (account_number, business_date) DO NOTHING`
+	tag, err := conn.Exec(ctx, query, date, BusinessDays.Close(date))
+	if err != nil {
+		return 0, fmt.Errorf("failed to snapshot end of day %s: %w", date.Format(businessday.DateLayout), err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// BalanceAtEndOfDay returns an account's balance at the close of a business date. It returns nil
+// if the account does not exist.
+func (r *EventRepository) BalanceAtEndOfDay(ctx context.Context, accountNumber string, date time.Time) (*models.PointInTimeBalance, error) {
+	// Timestamps are stored to the microsecond, so this is the last moment before the close
+	balance, err := r.BalanceAsOf(ctx, accountNumber, BusinessDays.Close(date).Add(-time.Microsecond))
+	if err != nil || balance == nil {
+		return nil, err
+	}
+	balance.BusinessDate = date.Format(businessday.DateLayout)
+	return balance, nil
+}
+
+// ListEndOfDay returns the end-of-day balances recorded for an account between two business dates
+// inclusive, oldest first
+func (r *EventRepository) ListEndOfDay(ctx context.Context, accountNumber string, from, to time.Time) ([]models.EndOfDayBalance, error) {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	query := `
+        SELECT account_number, business_date::text, balance, version, closed_at, created_at
+        FROM usersschema.eod_balances
+        WHERE account_number = $1 AND business_date BETWEEN $2::date AND $3::date
+        ORDER BY business_date`
+	rows, err := conn.Query(ctx, query, accountNumber, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list end-of-day balances: %w", err)
+	}
+	defer rows.Close()
+
+	balances := []models.EndOfDayBalance{}
+	for rows.Next() {
+		var balance models.EndOfDayBalance
+		if err := rows.Scan(&balance.AccountNumber, &balance.BusinessDate, &balance.Balance, &balance.Version,
+			&balance.ClosedAt, &balance.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan end-of-day balance: %w", err)
+		}
+		balances = append(balances, balance)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating end-of-day balance rows: %w", err)
+	}
+	return balances, nil
+}
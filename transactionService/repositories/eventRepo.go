@@ -0,0 +1,337 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"transactionService/aggregate"
+	"transactionService/database"
+	"transactionService/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// SnapshotEvery is how many events an account stream grows by between snapshots. It is set from
+// configuration at startup.
+var SnapshotEvery int64 = 100
+
+const accountEventColumns = `account_number, version, event_type, amount, transaction_id, cause, occurred_at`
+
+// moveBalance appends a credit (positive delta) or debit (negative delta) made by trans to the
+// account's event stream and applies it to the usersschema.accounts projection in the same database
+// transaction. The projection row is updated first, which locks it and hands out the next version.
+// The movement is added to trans.Postings so it is published with the transaction.
+func moveBalance(ctx context.Context, tx pgx.Tx, trans *models.Transaction, accountNumber string, delta float64, cause string) error {
+	if delta == 0 {
+		return nil
+	}
+	updateQuery := `
+        UPDATE usersschema.accounts
+        SET balance = balance + $1,
+            overdraft_used = GREATEST(-(balance + $1), 0),
+            version = version + 1,
+            updated_at = NOW()
+        WHERE account_number = $2
+        RETURNING balance, version`
+	var balance float64
+	var version int64
+	if err := tx.QueryRow(ctx, updateQuery, delta, accountNumber).Scan(&balance, &version); err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("account not found: %s", accountNumber)
+		}
+		return fmt.Errorf("failed to update balance of %s: %w", accountNumber, err)
+	}
+
+	var id *uuid.UUID
+	if trans.ID != uuid.Nil {
+		id = &trans.ID
+	}
+	eventType, amount := aggregate.Movement(delta)
+	insertQuery := `
+        INSERT INTO usersschema.account_events (account_number, version, event_type, amount, transaction_id, cause)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING occurred_at`
+	var occurredAt time.Time
+	if err := tx.QueryRow(ctx, insertQuery, accountNumber, version, eventType, amount, id, cause).Scan(&occurredAt); err != nil {
+		return fmt.Errorf("failed to append event to %s: %w", accountNumber, err)
+	}
+
+	if SnapshotEvery > 0 && version%SnapshotEvery == 0 {
+		snapshotQuery := `
+            INSERT INTO usersschema.account_snapshots (account_number, version, balance, taken_at)
+            VALUES ($1, $2, $3, $4)`
+		if _, err := tx.Exec(ctx, snapshotQuery, accountNumber, version, balance, occurredAt); err != nil {
+			return fmt.Errorf("failed to snapshot %s: %w", accountNumber, err)
+		}
+	}
+	trans.Postings = append(trans.Postings, models.Posting{
+		AccountNumber: accountNumber,
+		Amount:        delta,
+		Cause:         cause,
+		Balance:       balance,
+		Version:       version,
+	})
+	return nil
+}
+
+// loadPostings rebuilds the postings trans made from the account events it appended, for a
+// transaction that has to be published again. The balance after each movement is the current
+// balance less every later movement of the account.
+func loadPostings(ctx context.Context, q rowsQuerier, trans *models.Transaction) error {
+	query := `
+        SELECT e.account_number, CASE WHEN e.event_type = 'debited' THEN -e.amount ELSE e.amount END, e.cause, e.version,
+               a.balance - COALESCE((
+                   SELECT SUM(CASE WHEN l.event_type = 'debited' THEN -l.amount ELSE l.amount END)
+                   FROM usersschema.account_events l
+                   WHERE l.account_number = e.account_number AND l.version > e.version), 0)
+        FROM usersschema.account_events e
+        JOIN usersschema.accounts a ON a.account_number = e.account_number
+        WHERE e.transaction_id = $1
+        ORDER BY e.occurred_at, e.account_number`
+	rows, err := q.Query(ctx, query, trans.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load postings of %s: %w", trans.ID, err)
+	}
+	defer rows.Close()
+	trans.Postings = nil
+	for rows.Next() {
+		var posting models.Posting
+		if err := rows.Scan(&posting.AccountNumber, &posting.Amount, &posting.Cause, &posting.Version, &posting.Balance); err != nil {
+			return fmt.Errorf("failed to scan posting: %w", err)
+		}
+		trans.Postings = append(trans.Postings, posting)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating posting rows: %w", err)
+	}
+	return nil
+}
+
+// EventRepository implements EventRepo, the event-sourced view of account balances
+type EventRepository struct {
+	db *database.PostgresPoolDB
+}
+
+// NewEventRepository creates a new EventRepository
+func NewEventRepository(db *database.PostgresPoolDB) *EventRepository {
+	return &EventRepository{db: db}
+}
+
+// BalanceAsOf replays an account's events up to asOf, starting from the latest snapshot or
+// end-of-day balance taken by then. It returns nil if the account does not exist.
+func (r *EventRepository) BalanceAsOf(ctx context.Context, accountNumber string, asOf time.Time) (*models.PointInTimeBalance, error) {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	var currency string
+	err = conn.QueryRow(ctx, "SELECT currency FROM usersschema.accounts WHERE account_number = $1", accountNumber).Scan(&currency)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+
+	snapshot, startedFrom, err := startingPoint(ctx, conn, accountNumber, asOf)
+	if err != nil {
+		return nil, err
+	}
+	after := int64(0)
+	if snapshot != nil {
+		after = snapshot.Version
+	}
+	eventsQuery := `
+        SELECT ` + accountEventColumns + `
+        FROM usersschema.account_events
+        WHERE account_number = $1 AND version > $2 AND occurred_at <= $3
+        ORDER BY version`
+	events, err := queryAccountEvents(ctx, conn, eventsQuery, accountNumber, after, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := aggregate.Replay(accountNumber, snapshot, events)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PointInTimeBalance{
+		AccountNumber: accountNumber,
+		Balance:       account.Balance,
+		OverdraftUsed: account.OverdraftUsed(),
+		Currency:      currency,
+		Version:       account.Version,
+		AsOf:          asOf,
+		StartedFrom:   startedFrom,
+		Replayed:      len(events),
+	}, nil
+}
+
+// ListEvents returns up to limit events of an account after a version, oldest first
+func (r *EventRepository) ListEvents(ctx context.Context, accountNumber string, after int64, limit int) ([]models.AccountEvent, error) {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	query := `
+        SELECT ` + accountEventColumns + `
+        FROM usersschema.account_events
+        WHERE account_number = $1 AND version > $2
+        ORDER BY version
+        LIMIT $3`
+	return queryAccountEvents(ctx, conn, query, accountNumber, after, limit)
+}
+
+// RebuildProjection replays every event of an account from the start and writes the result to
+// usersschema.accounts. Snapshots are not used, since they were taken from the projection.
+func (r *EventRepository) RebuildProjection(ctx context.Context, accountNumber string) (*models.ProjectionRebuild, error) {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	// Lock the projection so no event is appended while the stream is replayed
+	rebuild := &models.ProjectionRebuild{AccountNumber: accountNumber}
+	err = tx.QueryRow(ctx, "SELECT balance FROM usersschema.accounts WHERE account_number = $1 FOR UPDATE", accountNumber).Scan(&rebuild.PreviousBalance)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to lock account: %w", err)
+	}
+
+	query := `
+        SELECT ` + accountEventColumns + `
+        FROM usersschema.account_events
+        WHERE account_number = $1
+        ORDER BY version`
+	events, err := queryAccountEvents(ctx, tx, query, accountNumber)
+	if err != nil {
+		return nil, err
+	}
+	account, err := aggregate.Replay(accountNumber, nil, events)
+	if err != nil {
+		return nil, err
+	}
+
+	updateQuery := `
+        UPDATE usersschema.accounts
+        SET balance = $1, overdraft_used = $2, version = $3, updated_at = NOW()
+        WHERE account_number = $4`
+	if _, err = tx.Exec(ctx, updateQuery, account.Balance, account.OverdraftUsed(), account.Version, accountNumber); err != nil {
+		return nil, fmt.Errorf("failed to update projection: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	rebuild.Version, rebuild.Balance = account.Version, account.Balance
+	return rebuild, nil
+}
+
+// RebuildAllProjections rebuilds every account and returns those whose balance changed
+func (r *EventRepository) RebuildAllProjections(ctx context.Context) ([]models.ProjectionRebuild, error) {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	rows, err := conn.Query(ctx, "SELECT account_number FROM usersschema.accounts ORDER BY account_number")
+	if err != nil {
+		conn.Release()
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+	accounts, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	conn.Release()
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan account: %w", err)
+	}
+
+	changed := []models.ProjectionRebuild{}
+	for _, accountNumber := range accounts {
+		rebuild, err := r.RebuildProjection(ctx, accountNumber)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rebuild %s: %w", accountNumber, err)
+		}
+		if rebuild != nil && rebuild.Balance != rebuild.PreviousBalance {
+			changed = append(changed, *rebuild)
+		}
+	}
+	return changed, nil
+}
+
+// startingPoint finds the latest state of an account recorded by asOf, either a snapshot or an
+// end-of-day balance, and says which it is. It returns nil if there is none.
+func startingPoint(ctx context.Context, q rowQuerier, accountNumber string, asOf time.Time) (*models.AccountSnapshot, string, error) {
+	snapshotQuery := `
+        SELECT account_number, version, balance, taken_at
+        FROM usersschema.account_snapshots
+        WHERE account_number = $1 AND taken_at <= $2
+        ORDER BY version DESC
+        LIMIT 1`
+	var snapshot models.AccountSnapshot
+	err := q.QueryRow(ctx, snapshotQuery, accountNumber, asOf).Scan(&snapshot.AccountNumber, &snapshot.Version, &snapshot.Balance, &snapshot.TakenAt)
+	if err != nil && err != pgx.ErrNoRows {
+		return nil, "", fmt.Errorf("failed to get snapshot: %w", err)
+	}
+	found := err == nil
+
+	// An end-of-day balance holds the events before closed_at, so it can be used from the
+	// last moment such an event can have
+	eodQuery := `
+        SELECT account_number, version, balance, closed_at
+        FROM usersschema.eod_balances
+        WHERE account_number = $1 AND closed_at - interval '1 microsecond' <= $2
+        ORDER BY business_date DESC
+        LIMIT 1`
+	var eod models.AccountSnapshot
+	err = q.QueryRow(ctx, eodQuery, accountNumber, asOf).Scan(&eod.AccountNumber, &eod.Version, &eod.Balance, &eod.TakenAt)
+	switch {
+	case err == nil && (!found || eod.Version > snapshot.Version):
+		return &eod, "end_of_day", nil
+	case err != nil && err != pgx.ErrNoRows:
+		return nil, "", fmt.Errorf("failed to get end-of-day balance: %w", err)
+	case found:
+		return &snapshot, "snapshot", nil
+	}
+	return nil, "", nil
+}
+
+// queryAccountEvents scans the events selected by query
+func queryAccountEvents(ctx context.Context, q rowsQuerier, query string, args ...any) ([]models.AccountEvent, error) {
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load account events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.AccountEvent{}
+	for rows.Next() {
+		var event models.AccountEvent
+		err := rows.Scan(&event.AccountNumber, &event.Version, &event.EventType, &event.Amount, &event.TransactionID,
+			&event.Cause, &event.OccurredAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan account event: %w", err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating account event rows: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,358 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"transactionService/database"
+	"transactionService/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	// ErrExternalTransferNotFound is returned when a clearing status refers to no known external transfer
+	ErrExternalTransferNotFound = errors.New("external transfer not found")
+	// ErrInvalidExternalTransition is returned when a clearing status would move a transfer out of a final state
+	ErrInvalidExternalTransition = errors.New("invalid external transfer transition")
+)
+
+// ClearingSuspenseAccount is the internal account external transfers are held in between the
+// customer's debit and the clearing system's settlement. It is set from configuration at startup.
+var ClearingSuspenseAccount = "CLEARING-SUSPENSE"
+
+const externalColumns = `id, account_number, amount, currency, suspense_amount, creditor_name, COALESCE(creditor_iban, ''),
+    COALESCE(creditor_account, ''), creditor_bic, COALESCE(reference, ''), status, COALESCE(clearing_status, ''),
+    COALESCE(reason, ''), settlement_transaction_id, created_at, updated_at`
+
+// ExternalTransfer debits the customer into the clearing suspense account and records the
+// transfer as initiated. The caller hands it to the clearing adapter once this has committed.
+func (r *TransactionRepository) ExternalTransfer(ctx context.Context, trans *models.Transaction) error {
+	if trans.Creditor == nil {
+		return fmt.Errorf("external transfer has no creditor")
+	}
+	if err := trans.Creditor.Validate(); err != nil {
+		return err
+	}
+	if len(trans.Reference) > 35 {
+		return fmt.Errorf("reference is longer than 35 characters")
+	}
+	if trans.FromAccountID == ClearingSuspenseAccount {
+		return fmt.Errorf("cannot send an external transfer from %s", ClearingSuspenseAccount)
+	}
+	trans.ToAccountID = ClearingSuspenseAccount
+
+	return r.transfer(ctx, trans, func(tx pgx.Tx) error {
+		suspenseAmount := trans.Amount
+		if trans.ConvertedAmount > 0 {
+			suspenseAmount = trans.ConvertedAmount
+		}
+		query := `
+            INSERT INTO usersschema.external_transfers (id, account_number, amount, currency, suspense_amount, creditor_name,
+                                                        creditor_iban, creditor_account, creditor_bic, reference, status)
+            VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), $9, NULLIF($10, ''), 'initiated')`
+		_, err := tx.Exec(ctx, query, trans.ID, trans.FromAccountID, trans.Amount, trans.Currency, suspenseAmount,
+			trans.Creditor.Name, trans.Creditor.IBAN, trans.Creditor.Account, trans.Creditor.BIC, trans.Reference)
+		if err != nil {
+			return fmt.Errorf("failed to record external transfer: %w", err)
+		}
+		return nil
+	})
+}
+
+// ExternalRepository implements ExternalRepo for transfers to other banks
+type ExternalRepository struct {
+	db *database.PostgresPoolDB
+}
+
+// NewExternalRepository creates a new ExternalRepository
+func NewExternalRepository(db *database.PostgresPoolDB) *ExternalRepository {
+	return &ExternalRepository{db: db}
+}
+
+// GetExternalTransfer returns an external transfer, or nil if it does not exist
+func (r *ExternalRepository) GetExternalTransfer(ctx context.Context, id string) (*models.ExternalTransfer, error) {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	transfer, err := scanExternalTransfer(conn.QueryRow(ctx, "SELECT "+externalColumns+" FROM usersschema.external_transfers WHERE id = $1", id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get external transfer: %w", err)
+	}
+	return transfer, nil
+}
+
+// ListExternalTransfers returns the external transfers of an account, newest first, optionally filtered by status
+func (r *ExternalRepository) ListExternalTransfers(ctx context.Context, accountNumber, status string) ([]models.ExternalTransfer, error) {
+	query := `
+        SELECT ` + externalColumns + `
+        FROM usersschema.external_transfers
+        WHERE account_number = $1 AND ($2 = '' OR status = $2)
+        ORDER BY created_at DESC`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, query, accountNumber, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list external transfers: %w", err)
+	}
+	defer rows.Close()
+
+	transfers := []models.ExternalTransfer{}
+	for rows.Next() {
+		transfer, err := scanExternalTransfer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan external transfer: %w", err)
+		}
+		transfers = append(transfers, *transfer)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating external transfer rows: %w", err)
+	}
+	return transfers, nil
+}
+
+// MarkSubmitted records that an initiated transfer was handed to the clearing adapter
+func (r *ExternalRepository) MarkSubmitted(ctx context.Context, id uuid.UUID) error {
+	query := `
+        UPDATE usersschema.external_transfers
+        SET status = 'submitted', updated_at = NOW()
+        WHERE id = $1 AND status = 'initiated'`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to mark external transfer submitted: %w", err)
+	}
+	return nil
+}
+
+// UnsubmittedTransfers returns the transfers still initiated since before the given time, rebuilt
+// as the message that is published to the clearing adapter
+func (r *ExternalRepository) UnsubmittedTransfers(ctx context.Context, before time.Time) ([]models.Transaction, error) {
+	query := `
+        SELECT e.id, e.account_number, e.amount, e.currency, e.creditor_name, COALESCE(e.creditor_iban, ''),
+               COALESCE(e.creditor_account, ''), e.creditor_bic, COALESCE(e.reference, ''), COALESCE(t.description, ''), e.created_at
+        FROM usersschema.external_transfers e
+        JOIN usersschema.transactions t ON t.id = e.id
+        WHERE e.status = 'initiated' AND e.updated_at <= $1
+        ORDER BY e.created_at`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, query, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unsubmitted external transfers: %w", err)
+	}
+	defer rows.Close()
+
+	var transfers []models.Transaction
+	for rows.Next() {
+		trans := models.Transaction{
+			ToAccountID:     ClearingSuspenseAccount,
+			TransactionType: "external_transfer",
+			Status:          "completed",
+			Creditor:        &models.Party{},
+		}
+		err := rows.Scan(&trans.ID, &trans.FromAccountID, &trans.Amount, &trans.Currency, &trans.Creditor.Name, &trans.Creditor.IBAN,
+			&trans.Creditor.Account, &trans.Creditor.BIC, &trans.Reference, &trans.Description, &trans.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan external transfer: %w", err)
+		}
+		transfers = append(transfers, trans)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating external transfer rows: %w", err)
+	}
+	return transfers, nil
+}
+
+// ApplyClearingStatus moves an external transfer along as the clearing system reports on it.
+// Settlement pays the suspense funds out; a rejection returns them to the customer together with
+// the fees charged on the transfer. The movement is returned so it can be sent to the ledger, and
+// is nil when no money moved. Repeated and out-of-date reports leave the transfer as it is; a
+// repeated settlement or rejection returns the movement it made again, so a report redelivered
+// because the ledger could not be reached publishes it once more.
+func (r *ExternalRepository) ApplyClearingStatus(ctx context.Context, status *models.ClearingStatus) (*models.ExternalTransfer, *models.Transaction, error) {
+	var target string
+	switch status.Status {
+	case "accepted":
+		target = models.ExternalSubmitted
+	case "settled":
+		target = models.ExternalSettled
+	case "rejected":
+		target = models.ExternalReturned
+	default:
+		return nil, nil, fmt.Errorf("unknown clearing status %q", status.Status)
+	}
+	id, err := uuid.Parse(status.TransactionID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrExternalTransferNotFound, status.TransactionID)
+	}
+
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	// Lock the customer and suspense accounts in the same order as transfers, then the transfer
+	var accountNumber string
+	err = tx.QueryRow(ctx, "SELECT account_number FROM usersschema.external_transfers WHERE id = $1", id).Scan(&accountNumber)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			err = fmt.Errorf("%w: %s", ErrExternalTransferNotFound, id)
+			return nil, nil, err
+		}
+		return nil, nil, fmt.Errorf("failed to get external transfer: %w", err)
+	}
+	lockQuery := "SELECT 1 FROM usersschema.accounts WHERE account_number IN ($1, $2) ORDER BY account_number FOR UPDATE"
+	if _, err = tx.Exec(ctx, lockQuery, accountNumber, ClearingSuspenseAccount); err != nil {
+		return nil, nil, fmt.Errorf("failed to lock accounts: %w", err)
+	}
+	transfer, err := scanExternalTransfer(tx.QueryRow(ctx, "SELECT "+externalColumns+" FROM usersschema.external_transfers WHERE id = $1 FOR UPDATE", id))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to lock external transfer: %w", err)
+	}
+
+	if !models.CanTransitionExternal(transfer.Status, target) {
+		// a late acceptance or a repeated report changes nothing
+		if transfer.Status == target || target == models.ExternalSubmitted {
+			var movement *models.Transaction
+			if transfer.Status == target && transfer.SettlementTransactionID != nil {
+				movement = clearingMovement(transfer, target, "")
+				if err = loadPostings(ctx, tx, movement); err != nil {
+					return nil, nil, err
+				}
+				query := "SELECT currency, description, created_at, status FROM usersschema.transactions WHERE id = $1"
+				err = tx.QueryRow(ctx, query, movement.ID).Scan(&movement.Currency, &movement.Description, &movement.CreatedAt, &movement.Status)
+				if err != nil {
+					return nil, nil, fmt.Errorf("failed to get %s of %s: %w", movement.TransactionType, transfer.ID, err)
+				}
+			}
+			err = tx.Rollback(ctx)
+			return transfer, movement, err
+		}
+		err = fmt.Errorf("%w: %s is %s and cannot become %s", ErrInvalidExternalTransition, id, transfer.Status, target)
+		return nil, nil, err
+	}
+
+	reason := strings.TrimSpace(status.ReasonCode + " " + status.Reason)
+	movement := clearingMovement(transfer, target, reason)
+	switch target {
+	case models.ExternalSettled:
+		// the clearing system has paid the beneficiary, so the funds leave the suspense account
+		if err = moveBalance(ctx, tx, movement, ClearingSuspenseAccount, -transfer.SuspenseAmount, movement.TransactionType); err != nil {
+			return nil, nil, err
+		}
+	case models.ExternalReturned:
+		// the transfer will not be paid, so the customer gets the full amount and its fees back
+		if err = moveBalance(ctx, tx, movement, ClearingSuspenseAccount, -transfer.SuspenseAmount, movement.TransactionType); err != nil {
+			return nil, nil, err
+		}
+		if err = moveBalance(ctx, tx, movement, transfer.AccountNumber, transfer.Amount, movement.TransactionType); err != nil {
+			return nil, nil, err
+		}
+		if err = refundFees(ctx, tx, movement, transfer.ID.String(), transfer.AccountNumber, 1, 0, true); err != nil {
+			return nil, nil, err
+		}
+	}
+	if movement != nil {
+		if err = recordTransaction(ctx, tx, movement); err != nil {
+			return nil, nil, err
+		}
+		movement.CreatedAt = time.Now()
+		movement.Status = "completed"
+	}
+
+	var settlementID *uuid.UUID
+	if movement != nil {
+		settlementID = &movement.ID
+	}
+	updateQuery := `
+        UPDATE usersschema.external_transfers
+        SET status = $2, clearing_status = NULLIF($3, ''), reason = COALESCE(NULLIF($4, ''), reason),
+            settlement_transaction_id = COALESCE($5, settlement_transaction_id), updated_at = NOW()
+        WHERE id = $1
+        RETURNING ` + externalColumns
+	transfer, err = scanExternalTransfer(tx.QueryRow(ctx, updateQuery, id, target, status.ISOStatus, reason, settlementID))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to update external transfer: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return transfer, movement, nil
+}
+
+// clearingMovement is the transaction that settles or returns transfer, or nil when target moves
+// no money. Its ID is derived from the transfer so a repeated report finds the same movement.
+func clearingMovement(transfer *models.ExternalTransfer, target, reason string) *models.Transaction {
+	switch target {
+	case models.ExternalSettled:
+		return &models.Transaction{
+			ID:              models.ClearingMovementID(transfer.ID, target),
+			FromAccountID:   ClearingSuspenseAccount,
+			Amount:          transfer.SuspenseAmount,
+			TransactionType: "clearing_settlement",
+			Description:     fmt.Sprintf("Settlement of external transfer %s to %s", transfer.ID, transfer.Creditor.Name),
+		}
+	case models.ExternalReturned:
+		movement := &models.Transaction{
+			ID:              models.ClearingMovementID(transfer.ID, target),
+			FromAccountID:   ClearingSuspenseAccount,
+			ToAccountID:     transfer.AccountNumber,
+			Amount:          transfer.SuspenseAmount,
+			TransactionType: "clearing_return",
+			Description:     strings.TrimSpace(fmt.Sprintf("Return of external transfer %s %s", transfer.ID, reason)),
+		}
+		if transfer.SuspenseAmount != transfer.Amount {
+			movement.FxRate = transfer.Amount / transfer.SuspenseAmount
+			movement.ConvertedAmount = transfer.Amount
+			movement.ConvertedCurrency = transfer.Currency
+		}
+		return movement
+	}
+	return nil
+}
+
+// scanExternalTransfer scans a row selected with externalColumns
+func scanExternalTransfer(row pgx.Row) (*models.ExternalTransfer, error) {
+	transfer := &models.ExternalTransfer{}
+	err := row.Scan(&transfer.ID, &transfer.AccountNumber, &transfer.Amount, &transfer.Currency, &transfer.SuspenseAmount,
+		&transfer.Creditor.Name, &transfer.Creditor.IBAN, &transfer.Creditor.Account, &transfer.Creditor.BIC, &transfer.Reference,
+		&transfer.Status, &transfer.ClearingStatus, &transfer.Reason, &transfer.SettlementTransactionID, &transfer.CreatedAt, &transfer.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
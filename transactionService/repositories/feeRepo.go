@@ -0,0 +1,240 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"transactionService/database"
+	"transactionService/fees"
+	"transactionService/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// FeeIncomeAccount is the account every fee is credited to. It is set from configuration at startup.
+var FeeIncomeAccount = "FEE-INCOME"
+
+const feeRuleColumns = "id, name, transaction_type, COALESCE(tier, ''), fee_type, amount, rate, min_fee, max_fee, bands, free_per_month, active, updated_at"
+
+// FeeRepository implements FeeRepo for fee rules
+type FeeRepository struct {
+	db *database.PostgresPoolDB
+}
+
+// NewFeeRepository creates a new FeeRepository
+func NewFeeRepository(db *database.PostgresPoolDB) *FeeRepository {
+	return &FeeRepository{db: db}
+}
+
+// ListRules returns every fee rule ordered by ID
+func (r *FeeRepository) ListRules(ctx context.Context) ([]models.FeeRule, error) {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, "SELECT "+feeRuleColumns+" FROM usersschema.fee_rules ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fee rules: %w", err)
+	}
+	return scanFeeRules(rows)
+}
+
+// CreateRule stores a new fee rule and assigns its ID
+func (r *FeeRepository) CreateRule(ctx context.Context, rule *models.FeeRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	bands, err := encodeBands(rule)
+	if err != nil {
+		return err
+	}
+	query := `
+        INSERT INTO usersschema.fee_rules (name, transaction_type, tier, fee_type, amount, rate, min_fee, max_fee, bands, free_per_month, active)
+        VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, $9, $10, $11)
+        RETURNING id, updated_at`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	err = conn.QueryRow(ctx, query, rule.Name, rule.TransactionType, rule.Tier, rule.FeeType, rule.Amount, rule.Rate,
+		rule.MinFee, rule.MaxFee, bands, rule.FreePerMonth, rule.Active).Scan(&rule.ID, &rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create fee rule: %w", err)
+	}
+	return nil
+}
+
+// UpdateRule replaces an existing fee rule
+func (r *FeeRepository) UpdateRule(ctx context.Context, rule *models.FeeRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	bands, err := encodeBands(rule)
+	if err != nil {
+		return err
+	}
+	query := `
+        UPDATE usersschema.fee_rules
+        SET name = $2, transaction_type = $3, tier = NULLIF($4, ''), fee_type = $5, amount = $6, rate = $7,
+            min_fee = $8, max_fee = $9, bands = $10, free_per_month = $11, active = $12, updated_at = NOW()
+        WHERE id = $1
+        RETURNING updated_at`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	err = conn.QueryRow(ctx, query, rule.ID, rule.Name, rule.TransactionType, rule.Tier, rule.FeeType, rule.Amount, rule.Rate,
+		rule.MinFee, rule.MaxFee, bands, rule.FreePerMonth, rule.Active).Scan(&rule.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("fee rule not found: %d", rule.ID)
+		}
+		return fmt.Errorf("failed to update fee rule: %w", err)
+	}
+	return nil
+}
+
+// DeleteRule removes a fee rule. Fee lines already charged keep their name and amount.
+func (r *FeeRepository) DeleteRule(ctx context.Context, id int64) error {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	result, err := conn.Exec(ctx, "DELETE FROM usersschema.fee_rules WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete fee rule: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("fee rule not found: %d", id)
+	}
+	return nil
+}
+
+// encodeBands returns the JSON stored in the bands column
+func encodeBands(rule *models.FeeRule) (string, error) {
+	if len(rule.Bands) == 0 {
+		return "[]", nil
+	}
+	bands, err := json.Marshal(rule.Bands)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode bands: %w", err)
+	}
+	return string(bands), nil
+}
+
+// scanFeeRules scans rows selected with feeRuleColumns and closes them
+func scanFeeRules(rows pgx.Rows) ([]models.FeeRule, error) {
+	defer rows.Close()
+
+	rules := []models.FeeRule{}
+	for rows.Next() {
+		var rule models.FeeRule
+		var bands []byte
+		if err := rows.Scan(&rule.ID, &rule.Name, &rule.TransactionType, &rule.Tier, &rule.FeeType, &rule.Amount, &rule.Rate,
+			&rule.MinFee, &rule.MaxFee, &bands, &rule.FreePerMonth, &rule.Active, &rule.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan fee rule: %w", err)
+		}
+		if err := json.Unmarshal(bands, &rule.Bands); err != nil {
+			return nil, fmt.Errorf("invalid bands on fee rule %d: %w", rule.ID, err)
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating fee rule rows: %w", err)
+	}
+	return rules, nil
+}
+
+// assessFees evaluates the active rules for the paying account's tier and stores the fees on
+// trans. It must run inside the transaction holding the account row lock so that the free
+// allowance cannot be used twice. Returns the total the account pays on top of the principal.
+func assessFees(ctx context.Context, tx pgx.Tx, trans *models.Transaction) (float64, error) {
+	trans.Fees = nil
+	// fees paid into the fee income account, such as saga fee steps, carry no fee themselves
+	if trans.FromAccountID == FeeIncomeAccount || trans.ToAccountID == FeeIncomeAccount {
+		return 0, nil
+	}
+
+	query := `
+        SELECT ` + feeRuleColumns + `
+        FROM usersschema.fee_rules
+        WHERE active
+          AND transaction_type = $2
+          AND (tier IS NULL OR tier = (SELECT tier FROM usersschema.accounts WHERE account_number = $1))
+        ORDER BY id`
+	rows, err := tx.Query(ctx, query, trans.FromAccountID, trans.TransactionType)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load fee rules: %w", err)
+	}
+	rules, err := scanFeeRules(rows)
+	if err != nil {
+		return 0, err
+	}
+	if len(rules) == 0 {
+		return 0, nil
+	}
+
+	usageQuery := `
+        SELECT COUNT(*)
+        FROM usersschema.transactions
+        WHERE from_account_id = $1
+          AND transaction_type = $2
+          AND status = 'completed'
+          AND created_at >= date_trunc('month', NOW())`
+	var used int
+	if err := tx.QueryRow(ctx, usageQuery, trans.FromAccountID, trans.TransactionType).Scan(&used); err != nil {
+		return 0, fmt.Errorf("failed to count %s usage: %w", trans.TransactionType, err)
+	}
+
+	charged := fees.Evaluate(rules, trans.Amount, trans.Currency, used)
+	if len(charged) > 0 {
+		trans.Fees = charged
+	}
+	return fees.Total(charged), nil
+}
+
+// postFees credits the fees on trans to the fee income account, converting them if it holds
+// another currency, and stores the fee lines. It runs after recordTransaction in the same
+// database transaction so the fees commit or roll back with the principal. Every fee lands on
+// the one fee income row, so it is not locked up front: the credit is an additive update, which
+// callers make their last write so the row is only held for the commit.
+func postFees(ctx context.Context, tx pgx.Tx, trans *models.Transaction) error {
+	if len(trans.Fees) == 0 {
+		return nil
+	}
+
+	var feeCurrency string
+	err := tx.QueryRow(ctx, "SELECT currency FROM usersschema.accounts WHERE account_number = $1", FeeIncomeAccount).Scan(&feeCurrency)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("fee income account not found: %s", FeeIncomeAccount)
+		}
+		return fmt.Errorf("failed to get fee income account: %w", err)
+	}
+	rate, err := lookupRate(ctx, tx, trans.Currency, feeCurrency)
+	if err != nil {
+		return err
+	}
+
+	insertQuery := `
+        INSERT INTO usersschema.transaction_fees (transaction_id, rule_id, name, amount, currency, fee_account, credited_amount)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	total := 0.0
+	for _, fee := range trans.Fees {
+		credited := models.ConvertAmount(fee.Amount, rate)
+		if _, err := tx.Exec(ctx, insertQuery, trans.ID, fee.RuleID, fee.Name, fee.Amount, fee.Currency, FeeIncomeAccount, credited); err != nil {
+			return fmt.Errorf("failed to record fee %s: %w", fee.Name, err)
+		}
+		total += credited
+	}
+
+	return moveBalance(ctx, tx, trans, FeeIncomeAccount, total, "fee")
+}
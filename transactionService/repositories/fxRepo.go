@@ -0,0 +1,177 @@
+package repositories
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"transactionService/database"
+	"transactionService/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrNoRate is returned when no fx rate converts between two currencies
+var ErrNoRate = errors.New("no fx rate configured")
+
+// FxRepository implements FxRepo for the exchange rate table
+type FxRepository struct {
+	db *database.PostgresPoolDB
+}
+
+// NewFxRepository creates a new FxRepository
+func NewFxRepository(db *database.PostgresPoolDB) *FxRepository {
+	return &FxRepository{db: db}
+}
+
+// ListRates returns every configured rate ordered by currency pair
+func (r *FxRepository) ListRates(ctx context.Context) ([]models.FxRate, error) {
+	query := `
+        SELECT base_currency, quote_currency, rate, updated_at
+        FROM usersschema.fx_rates
+        ORDER BY base_currency, quote_currency`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fx rates: %w", err)
+	}
+	defer rows.Close()
+
+	rates := []models.FxRate{}
+	for rows.Next() {
+		var rate models.FxRate
+		if err := rows.Scan(&rate.Base, &rate.Quote, &rate.Rate, &rate.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan fx rate: %w", err)
+		}
+		rates = append(rates, rate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating fx rate rows: %w", err)
+	}
+	return rates, nil
+}
+
+// UpsertRate creates or replaces the rate of a currency pair
+func (r *FxRepository) UpsertRate(ctx context.Context, rate *models.FxRate) error {
+	if err := rate.Validate(); err != nil {
+		return err
+	}
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	return upsertRate(ctx, conn, rate)
+}
+
+// LoadRatesFile upserts every rate in a CSV file of base,quote,rate lines in one transaction.
+// Blank lines, lines starting with # and a base,quote,rate header are skipped.
+func (r *FxRepository) LoadRatesFile(ctx context.Context, path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open fx rates file: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	rates := []models.FxRate{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read fx rates file: %w", err)
+		}
+		if strings.EqualFold(record[0], "base") {
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		value, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid rate on line %d: %w", line, err)
+		}
+		rate := models.FxRate{Base: record[0], Quote: record[1], Rate: value}
+		if err := rate.Validate(); err != nil {
+			return 0, fmt.Errorf("invalid rate on line %d: %w", line, err)
+		}
+		rates = append(rates, rate)
+	}
+
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	for i := range rates {
+		if err = upsertRate(ctx, tx, &rates[i]); err != nil {
+			return 0, err
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return len(rates), nil
+}
+
+// upsertRate writes a validated rate
+func upsertRate(ctx context.Context, q rowQuerier, rate *models.FxRate) error {
+	query := `
+        INSERT INTO usersschema.fx_rates (base_currency, quote_currency, rate, updated_at)
+        VALUES ($1, $2, $3, NOW())
+        ON CONFLICT (base_currency, quote_currency) DO UPDATE
+        SET rate = EXCLUDED.rate,
+            updated_at = NOW()
+        RETURNING updated_at`
+	if err := q.QueryRow(ctx, query, rate.Base, rate.Quote, rate.Rate).Scan(&rate.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to save fx rate %s/%s: %w", rate.Base, rate.Quote, err)
+	}
+	return nil
+}
+
+// lookupRate returns the rate converting from into to, using the inverse of the opposite pair
+// when only that one is configured
+func lookupRate(ctx context.Context, q rowQuerier, from, to string) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	query := `
+        SELECT CASE WHEN base_currency = $1 THEN rate ELSE 1 / rate END
+        FROM usersschema.fx_rates
+        WHERE (base_currency = $1 AND quote_currency = $2) OR (base_currency = $2 AND quote_currency = $1)
+        ORDER BY base_currency = $1 DESC
+        LIMIT 1`
+	var rate float64
+	if err := q.QueryRow(ctx, query, from, to).Scan(&rate); err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, fmt.Errorf("%w for %s/%s", ErrNoRate, from, to)
+		}
+		return 0, fmt.Errorf("failed to get fx rate %s/%s: %w", from, to, err)
+	}
+	return rate, nil
+}
@@ -0,0 +1,297 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"transactionService/database"
+	"transactionService/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// HoldRepository implements HoldRepo for balance holds
+type HoldRepository struct {
+	db *database.PostgresPoolDB
+}
+
+// NewHoldRepository creates a new HoldRepository
+func NewHoldRepository(db *database.PostgresPoolDB) *HoldRepository {
+	return &HoldRepository{db: db}
+}
+
+const holdColumns = "id, account_number, amount, captured_amount, capture_transaction_id, COALESCE(reference, ''), status, expires_at, created_at, updated_at"
+
+// CreateHold reserves hold.Amount on the account if the available balance covers it
+func (r *HoldRepository) CreateHold(ctx context.Context, hold *models.Hold) error {
+	if hold.Amount <= 0 {
+		return fmt.Errorf("hold amount must be positive: %.2f", hold.Amount)
+	}
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	// Lock the account so concurrent debits and holds see each other
+	var balance, overdraftLimit float64
+	var currency string
+	err = tx.QueryRow(ctx, "SELECT balance, overdraft_limit, currency FROM usersschema.accounts WHERE account_number = $1 FOR UPDATE", hold.AccountNumber).Scan(&balance, &overdraftLimit, &currency)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("account not found: %s", hold.AccountNumber)
+		}
+		return fmt.Errorf("failed to get current balance: %w", err)
+	}
+
+	if err = requireVerifiedCustomer(ctx, tx, hold.AccountNumber); err != nil {
+		return err
+	}
+
+	held, err := activeHolds(ctx, tx, hold.AccountNumber)
+	if err != nil {
+		return err
+	}
+	if balance+overdraftLimit-held < hold.Amount {
+		err = fmt.Errorf("insufficient available funds: available balance %.2f, attempted hold %.2f",
+			balance+overdraftLimit-held, hold.Amount)
+		return err
+	}
+
+	// A hold is a future withdrawal, so it must respect the tier limits now
+	if err = enforceTierLimits(ctx, tx, hold.AccountNumber, currency, "withdrawal", hold.Amount, balance-held-hold.Amount, overdraftLimit); err != nil {
+		return err
+	}
+
+	query := `
+        INSERT INTO usersschema.holds (account_number, amount, reference, status, expires_at)
+        VALUES ($1, $2, NULLIF($3, ''), 'active', $4)
+        RETURNING id, status, created_at, updated_at`
+	err = tx.QueryRow(ctx, query, hold.AccountNumber, hold.Amount, hold.Reference, hold.ExpiresAt).
+		Scan(&hold.ID, &hold.Status, &hold.CreatedAt, &hold.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create hold: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetHold returns a hold, or nil if it does not exist
+func (r *HoldRepository) GetHold(ctx context.Context, id string) (*models.Hold, error) {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	hold, err := scanHold(conn.QueryRow(ctx, "SELECT "+holdColumns+" FROM usersschema.holds WHERE id = $1", id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get hold: %w", err)
+	}
+	return hold, nil
+}
+
+// CaptureHold debits amount from the account and closes the hold. An amount of zero captures the
+// full hold; a smaller amount captures part of it and releases the remainder. The debit is
+// returned with the hold.
+func (r *HoldRepository) CaptureHold(ctx context.Context, id string, amount float64) (*models.Hold, *models.Transaction, error) {
+	if amount < 0 {
+		return nil, nil, fmt.Errorf("capture amount cannot be negative: %.2f", amount)
+	}
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	hold, err := lockHold(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if amount == 0 {
+		amount = hold.Amount
+	}
+	if amount > hold.Amount {
+		err = fmt.Errorf("capture amount %.2f exceeds held amount %.2f", amount, hold.Amount)
+		return nil, nil, err
+	}
+
+	// The funds were reserved when the hold was placed, so the debit is not checked again
+	trans := &models.Transaction{
+		ID:              uuid.New(),
+		FromAccountID:   hold.AccountNumber,
+		Amount:          amount,
+		TransactionType: "withdrawal",
+		Description:     strings.TrimSpace(fmt.Sprintf("Capture of hold %s %s", hold.ID, hold.Reference)),
+	}
+	if err = moveBalance(ctx, tx, trans, hold.AccountNumber, -amount, trans.TransactionType); err != nil {
+		return nil, nil, err
+	}
+	if err = recordTransaction(ctx, tx, trans); err != nil {
+		return nil, nil, err
+	}
+
+	hold, err = scanHold(tx.QueryRow(ctx, `
+        UPDATE usersschema.holds
+        SET status = 'captured', captured_amount = $1, capture_transaction_id = $2, updated_at = NOW()
+        WHERE id = $3
+        RETURNING `+holdColumns, amount, trans.ID, id))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to capture hold: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return hold, trans, nil
+}
+
+// ReleaseHold gives the reserved funds back to the available balance without a debit
+func (r *HoldRepository) ReleaseHold(ctx context.Context, id string) (*models.Hold, error) {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	if _, err = lockHold(ctx, tx, id); err != nil {
+		return nil, err
+	}
+
+	hold, err := scanHold(tx.QueryRow(ctx, `
+        UPDATE usersschema.holds
+        SET status = 'released', updated_at = NOW()
+        WHERE id = $1
+        RETURNING `+holdColumns, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to release hold: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return hold, nil
+}
+
+// ExpireHolds marks every active hold past its TTL as expired and returns the holds it expired
+func (r *HoldRepository) ExpireHolds(ctx context.Context, now time.Time) ([]models.Hold, error) {
+	query := `
+        UPDATE usersschema.holds
+        SET status = 'expired', updated_at = NOW()
+        WHERE status = 'active' AND expires_at <= $1
+        RETURNING ` + holdColumns
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, query, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expire holds: %w", err)
+	}
+	defer rows.Close()
+
+	expired := []models.Hold{}
+	for rows.Next() {
+		hold, err := scanHold(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan expired hold: %w", err)
+		}
+		expired = append(expired, *hold)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating expired hold rows: %w", err)
+	}
+	return expired, nil
+}
+
+// lockHold locks the account of an active hold and then the hold itself, in the same order
+// as debits lock accounts, and fails if the hold can no longer be captured or released.
+func lockHold(ctx context.Context, tx pgx.Tx, id string) (*models.Hold, error) {
+	var accountNumber string
+	err := tx.QueryRow(ctx, "SELECT account_number FROM usersschema.holds WHERE id = $1", id).Scan(&accountNumber)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("hold not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to get hold: %w", err)
+	}
+	if _, err := tx.Exec(ctx, "SELECT 1 FROM usersschema.accounts WHERE account_number = $1 FOR UPDATE", accountNumber); err != nil {
+		return nil, fmt.Errorf("failed to lock account: %w", err)
+	}
+
+	hold, err := scanHold(tx.QueryRow(ctx, "SELECT "+holdColumns+" FROM usersschema.holds WHERE id = $1 FOR UPDATE", id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock hold: %w", err)
+	}
+	if hold.Status != "active" {
+		return nil, fmt.Errorf("hold %s is %s", id, hold.Status)
+	}
+	if !hold.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("hold %s has expired", id)
+	}
+	return hold, nil
+}
+
+// activeHolds returns the total amount reserved by unexpired active holds on the account
+func activeHolds(ctx context.Context, q rowQuerier, accountNumber string) (float64, error) {
+	query := `
+        SELECT COALESCE(SUM(amount), 0)
+        FROM usersschema.holds
+        WHERE account_number = $1 AND status = 'active' AND expires_at > NOW()`
+	var held float64
+	if err := q.QueryRow(ctx, query, accountNumber).Scan(&held); err != nil {
+		return 0, fmt.Errorf("failed to sum active holds: %w", err)
+	}
+	return held, nil
+}
+
+// scanHold scans a row selected with holdColumns
+func scanHold(row pgx.Row) (*models.Hold, error) {
+	hold := &models.Hold{}
+	err := row.Scan(&hold.ID, &hold.AccountNumber, &hold.Amount, &hold.CapturedAmount, &hold.CaptureTransactionID, &hold.Reference,
+		&hold.Status, &hold.ExpiresAt, &hold.CreatedAt, &hold.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
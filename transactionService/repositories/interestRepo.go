@@ -0,0 +1,293 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+	"transactionService/database"
+	"transactionService/models"
+)
+
+// InterestRepository implements InterestRepo for savings interest
+type InterestRepository struct {
+	db *database.PostgresPoolDB
+}
+
+// NewInterestRepository creates a new InterestRepository
+func NewInterestRepository(db *database.PostgresPoolDB) *InterestRepository {
+	return &InterestRepository{db: db}
+}
+
+// ListPlans returns every interest plan ordered by name
+func (r *InterestRepository) ListPlans(ctx context.Context) ([]models.InterestPlan, error) {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, "SELECT name, plan_type, annual_rate, tiers, day_count, updated_at FROM usersschema.interest_plans ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list interest plans: %w", err)
+	}
+	defer rows.Close()
+
+	plans := []models.InterestPlan{}
+	for rows.Next() {
+		var plan models.InterestPlan
+		var tiers []byte
+		if err := rows.Scan(&plan.Name, &plan.Type, &plan.AnnualRate, &tiers, &plan.DayCount, &plan.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan interest plan: %w", err)
+		}
+		if err := json.Unmarshal(tiers, &plan.Tiers); err != nil {
+			return nil, fmt.Errorf("invalid tiers on plan %s: %w", plan.Name, err)
+		}
+		plans = append(plans, plan)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating interest plan rows: %w", err)
+	}
+	return plans, nil
+}
+
+// UpsertPlan creates a plan or replaces its terms. Accruals already stored keep the old terms.
+func (r *InterestRepository) UpsertPlan(ctx context.Context, plan *models.InterestPlan) error {
+	if err := plan.Validate(); err != nil {
+		return err
+	}
+	tiers, err := json.Marshal(plan.Tiers)
+	if err != nil {
+		return fmt.Errorf("failed to encode tiers: %w", err)
+	}
+	if plan.Tiers == nil {
+		tiers = []byte("[]")
+	}
+	query := `
+        INSERT INTO usersschema.interest_plans (name, plan_type, annual_rate, tiers, day_count, updated_at)
+        VALUES ($1, $2, $3, $4, $5, NOW())
+        ON CONFLICT (name) DO UPDATE
+        SET plan_type = EXCLUDED.plan_type,
+            annual_rate = EXCLUDED.annual_rate,
+            tiers = EXCLUDED.tiers,
+            day_count = EXCLUDED.day_count,
+            updated_at = NOW()
+        RETURNING updated_at`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if err = conn.QueryRow(ctx, query, plan.Name, plan.Type, plan.AnnualRate, string(tiers), plan.DayCount).Scan(&plan.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to save interest plan: %w", err)
+	}
+	return nil
+}
+
+// AssignPlan puts an account on a plan; an empty plan name stops it earning interest
+func (r *InterestRepository) AssignPlan(ctx context.Context, accountNumber, planName string) error {
+	query := `
+        UPDATE usersschema.accounts
+        SET interest_plan = NULLIF($1, ''),
+            updated_at = NOW()
+        WHERE account_number = $2`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	result, err := conn.Exec(ctx, query, planName, accountNumber)
+	if err != nil {
+		return fmt.Errorf("failed to assign interest plan: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("account not found: %s", accountNumber)
+	}
+	return nil
+}
+
+// ListAccruals returns the accruals of an account, most recent first
+func (r *InterestRepository) ListAccruals(ctx context.Context, accountNumber string) ([]models.InterestAccrual, error) {
+	query := `
+        SELECT id, account_number, accrual_date, plan, balance, amount, transaction_id
+        FROM usersschema.interest_accruals
+        WHERE account_number = $1
+        ORDER BY accrual_date DESC`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, query, accountNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accruals: %w", err)
+	}
+	defer rows.Close()
+
+	accruals := []models.InterestAccrual{}
+	for rows.Next() {
+		var accrual models.InterestAccrual
+		if err := rows.Scan(&accrual.ID, &accrual.AccountNumber, &accrual.AccrualDate, &accrual.Plan,
+			&accrual.Balance, &accrual.Amount, &accrual.TransactionID); err != nil {
+			return nil, fmt.Errorf("failed to scan accrual: %w", err)
+		}
+		accruals = append(accruals, accrual)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating accrual rows: %w", err)
+	}
+	return accruals, nil
+}
+
+// AccrualRange returns the business dates still to accrue: from next, the day after the latest
+// accrual, through last, the latest date with end-of-day balances. Nothing is left to accrue when
+// next is after last. When nothing has accrued yet next is last, so assigning a plan does not
+// back-date interest. Both are nil if no end-of-day balances have been recorded.
+func (r *InterestRepository) AccrualRange(ctx context.Context) (next, last *time.Time, err error) {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	var lastAccrued *time.Time
+	query := `
+        SELECT (SELECT MAX(accrual_date) FROM usersschema.interest_accruals),
+               (SELECT MAX(business_date) FROM usersschema.eod_balances)`
+	if err := conn.QueryRow(ctx, query).Scan(&lastAccrued, &last); err != nil {
+		return nil, nil, fmt.Errorf("failed to get accrual dates: %w", err)
+	}
+	if last == nil {
+		return nil, nil, nil
+	}
+	if lastAccrued == nil {
+		return last, last, nil
+	}
+	following := lastAccrued.AddDate(0, 0, 1)
+	return &following, last, nil
+}
+
+// Accrue stores one day of interest for every account on a plan, computed on its end-of-day
+// balance for accrualDate, so it must only be called once that date has been snapshotted.
+// Each account accrues at most once per date, so running it again for the same day is a no-op.
+// Returns how many accruals were stored.
+func (r *InterestRepository) Accrue(ctx context.Context, accrualDate time.Time) (int, error) {
+	query := `
+        SELECT a.account_number, e.balance, p.name, p.plan_type, p.annual_rate, p.tiers, p.day_count
+        FROM usersschema.accounts a
+        JOIN usersschema.interest_plans p ON p.name = a.interest_plan
+        JOIN usersschema.eod_balances e ON e.account_number = a.account_number AND e.business_date = $1::date
+        WHERE e.balance > 0
+        ORDER BY a.account_number`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, query, accrualDate)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load savings accounts: %w", err)
+	}
+	accruals := []models.InterestAccrual{}
+	for rows.Next() {
+		var accrual models.InterestAccrual
+		var plan models.InterestPlan
+		var tiers []byte
+		if err = rows.Scan(&accrual.AccountNumber, &accrual.Balance, &plan.Name, &plan.Type, &plan.AnnualRate, &tiers, &plan.DayCount); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan account data: %w", err)
+		}
+		if err = json.Unmarshal(tiers, &plan.Tiers); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("invalid tiers on plan %s: %w", plan.Name, err)
+		}
+		accrual.Plan = plan.Name
+		accrual.AccrualDate = accrualDate
+		accrual.Amount = plan.DailyAccrual(accrual.Balance, accrualDate)
+		accruals = append(accruals, accrual)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating account rows: %w", err)
+	}
+
+	insertQuery := `
+        INSERT INTO usersschema.interest_accruals (account_number, accrual_date, plan, balance, amount)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (account_number, accrual_date) DO NOTHING`
+	stored := 0
+	for _, accrual := range accruals {
+		result, err := conn.Exec(ctx, insertQuery, accrual.AccountNumber, accrualDate, accrual.Plan, accrual.Balance, accrual.Amount)
+		if err != nil {
+			return stored, fmt.Errorf("failed to store accrual for %s: %w", accrual.AccountNumber, err)
+		}
+		stored += int(result.RowsAffected())
+	}
+	return stored, nil
+}
+
+// PendingPostings sums the unposted accruals dated before periodEnd for every account. Totals under
+// a cent are left to roll into the next period. The period is the month of the last day before periodEnd.
+func (r *InterestRepository) PendingPostings(ctx context.Context, periodEnd time.Time) ([]models.InterestPosting, error) {
+	query := `
+        SELECT account_number, SUM(amount)
+        FROM usersschema.interest_accruals
+        WHERE transaction_id IS NULL AND accrual_date < $1
+        GROUP BY account_number
+        ORDER BY account_number`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, query, periodEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum accruals: %w", err)
+	}
+	defer rows.Close()
+
+	period := periodEnd.AddDate(0, 0, -1).Format("2006-01")
+	postings := []models.InterestPosting{}
+	for rows.Next() {
+		var posting models.InterestPosting
+		var total float64
+		if err := rows.Scan(&posting.AccountNumber, &total); err != nil {
+			return nil, fmt.Errorf("failed to scan accrual total: %w", err)
+		}
+		posting.Amount = math.Round(total*100) / 100
+		if posting.Amount < 0.01 {
+			continue
+		}
+		posting.Period = period
+		posting.TransactionID = models.InterestTransactionID(posting.AccountNumber, period)
+		postings = append(postings, posting)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating accrual totals: %w", err)
+	}
+	return postings, nil
+}
+
+// MarkPosted links the accruals paid out by a posting to its credit
+func (r *InterestRepository) MarkPosted(ctx context.Context, posting *models.InterestPosting, periodEnd time.Time) error {
+	query := `
+        UPDATE usersschema.interest_accruals
+        SET transaction_id = $1
+        WHERE account_number = $2 AND transaction_id IS NULL AND accrual_date < $3`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err = conn.Exec(ctx, query, posting.TransactionID, posting.AccountNumber, periodEnd); err != nil {
+		return fmt.Errorf("failed to mark accruals posted: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"transactionService/database"
+	"transactionService/models"
+
+	"github.com/google/uuid"
+)
+
+// OverdraftRepository implements OverdraftRepo for overdraft facilities
+type OverdraftRepository struct {
+	db *database.PostgresPoolDB
+}
+
+// NewOverdraftRepository creates a new OverdraftRepository
+func NewOverdraftRepository(db *database.PostgresPoolDB) *OverdraftRepository {
+	return &OverdraftRepository{db: db}
+}
+
+// SetOverdraft configures the overdraft limit and annual interest rate of an account
+func (r *OverdraftRepository) SetOverdraft(ctx context.Context, accountNumber string, limit, annualRate float64) error {
+	if limit < 0 || annualRate < 0 {
+		return fmt.Errorf("overdraft limit and interest rate cannot be negative")
+	}
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	// A limit cannot be lowered below what is already drawn
+	var balance float64
+	err = tx.QueryRow(ctx, "SELECT balance FROM usersschema.accounts WHERE account_number = $1 FOR UPDATE", accountNumber).Scan(&balance)
+	if err != nil {
+		return fmt.Errorf("account not found: %s", accountNumber)
+	}
+	if balance < -limit {
+		err = fmt.Errorf("overdraft limit %.2f is below the amount already drawn %.2f", limit, -balance)
+		return err
+	}
+
+	query := `
+        UPDATE usersschema.accounts
+        SET overdraft_limit = $1,
+            overdraft_interest_rate = $2,
+            updated_at = NOW()
+        WHERE account_number = $3`
+	if _, err = tx.Exec(ctx, query, limit, annualRate, accountNumber); err != nil {
+		return fmt.Errorf("failed to set overdraft: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// ChargeInterest debits one day of overdraft interest from every overdrawn account. Each account is
+// charged at most once per charge date, so running the job again on the same day is a no-op.
+func (r *OverdraftRepository) ChargeInterest(ctx context.Context, chargeDate time.Time) ([]models.OverdraftCharge, error) {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	query := `
+        SELECT account_number, balance, overdraft_interest_rate
+        FROM usersschema.accounts
+        WHERE balance < 0 AND overdraft_interest_rate > 0
+        ORDER BY account_number
+        FOR UPDATE`
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load overdrawn accounts: %w", err)
+	}
+	candidates := []models.OverdraftCharge{}
+	for rows.Next() {
+		var charge models.OverdraftCharge
+		var balance float64
+		if err = rows.Scan(&charge.AccountNumber, &balance, &charge.AnnualRate); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan account data: %w", err)
+		}
+		charge.ChargeDate = chargeDate
+		charge.OverdrawnAmount = -balance
+		charge.Amount = models.DailyOverdraftInterest(charge.OverdrawnAmount, charge.AnnualRate)
+		if charge.Amount > 0 {
+			candidates = append(candidates, charge)
+		}
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating account rows: %w", err)
+	}
+
+	charges := []models.OverdraftCharge{}
+	for _, charge := range candidates {
+		insertQuery := `
+            INSERT INTO usersschema.overdraft_interest_charges (account_number, charge_date, overdrawn_amount, annual_rate, amount, transaction_id)
+            VALUES ($1, $2, $3, $4, $5, $6)
+            ON CONFLICT (account_number, charge_date) DO NOTHING
+            RETURNING id`
+		charge.TransactionID = uuid.New()
+		rows, err = tx.Query(ctx, insertQuery, charge.AccountNumber, chargeDate, charge.OverdrawnAmount, charge.AnnualRate, charge.Amount, charge.TransactionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert overdraft charge: %w", err)
+		}
+		inserted := rows.Next()
+		if inserted {
+			err = rows.Scan(&charge.ID)
+		}
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert overdraft charge: %w", err)
+		}
+		if !inserted {
+			continue // already charged for this date
+		}
+
+		trans := &models.Transaction{
+			ID:              charge.TransactionID,
+			FromAccountID:   charge.AccountNumber,
+			Amount:          charge.Amount,
+			TransactionType: "overdraft_interest",
+			Description:     fmt.Sprintf("Overdraft interest for %s at %.2f%% p.a.", chargeDate.Format("2006-01-02"), charge.AnnualRate*100),
+		}
+		if err = moveBalance(ctx, tx, trans, charge.AccountNumber, -charge.Amount, "overdraft_interest"); err != nil {
+			return nil, err
+		}
+		if err = recordTransaction(ctx, tx, trans); err != nil {
+			return nil, err
+		}
+		charge.Postings = trans.Postings
+		charges = append(charges, charge)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return charges, nil
+}
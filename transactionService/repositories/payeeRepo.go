@@ -0,0 +1,303 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"transactionService/database"
+	"transactionService/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	// ErrPayeeNotFound is returned when a payee ID does not exist on the paying account
+	ErrPayeeNotFound = errors.New("payee not found")
+	// ErrPayeeNotAllowed is returned when a transfer may not go to its beneficiary yet
+	ErrPayeeNotAllowed = errors.New("payee not allowed")
+)
+
+// PayeeCoolingOff is how long a newly added payee waits before it can receive transfers.
+// PayeeVerifiedLimit is the amount above which transfers may only go to verified payees past
+// their cooling-off period; 0 disables it. Both are set from configuration at startup.
+var (
+	PayeeCoolingOff    = 24 * time.Hour
+	PayeeVerifiedLimit = 0.0
+)
+
+const payeeColumns = "id, account_number, nickname, payee_account, COALESCE(payee_bic, ''), COALESCE(payee_name, ''), status, active_from, verified_at, created_at"
+
+// PayeeRepository implements PayeeRepo for the beneficiary registry
+type PayeeRepository struct {
+	db *database.PostgresPoolDB
+}
+
+// NewPayeeRepository creates a new PayeeRepository
+func NewPayeeRepository(db *database.PostgresPoolDB) *PayeeRepository {
+	return &PayeeRepository{db: db}
+}
+
+// ListPayees returns the payees of an account ordered by nickname
+func (r *PayeeRepository) ListPayees(ctx context.Context, accountNumber string) ([]models.Payee, error) {
+	query := "SELECT " + payeeColumns + " FROM usersschema.payees WHERE account_number = $1 ORDER BY nickname"
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, query, accountNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payees: %w", err)
+	}
+	defer rows.Close()
+
+	payees := []models.Payee{}
+	for rows.Next() {
+		payee, err := scanPayee(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan payee: %w", err)
+		}
+		payees = append(payees, *payee)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating payee rows: %w", err)
+	}
+	return payees, nil
+}
+
+// GetPayee returns a payee, or nil if it does not exist
+func (r *PayeeRepository) GetPayee(ctx context.Context, id string) (*models.Payee, error) {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	payee, err := scanPayee(conn.QueryRow(ctx, "SELECT "+payeeColumns+" FROM usersschema.payees WHERE id = $1", id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get payee: %w", err)
+	}
+	return payee, nil
+}
+
+// CreatePayee registers a payee on an account. Its cooling-off period starts now. When the name
+// given matches the holder of the payee account the payee is verified straight away; payees at
+// another bank can only be verified by an operator.
+func (r *PayeeRepository) CreatePayee(ctx context.Context, payee *models.Payee) error {
+	payee.Nickname = strings.TrimSpace(payee.Nickname)
+	if payee.Nickname == "" || payee.PayeeAccount == "" {
+		return fmt.Errorf("%w: nickname and payee_account are required", ErrPayeeNotAllowed)
+	}
+	if payee.PayeeAccount == payee.AccountNumber {
+		return fmt.Errorf("%w: an account cannot be its own payee", ErrPayeeNotAllowed)
+	}
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	payee.Status = "unverified"
+	if payee.PayeeBIC != "" {
+		// a payee at another bank is paid with a pacs.008, so it needs what that names the creditor with
+		creditor := payee.Creditor()
+		if err := creditor.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrPayeeNotAllowed, err)
+		}
+		payee.PayeeAccount, payee.PayeeBIC, payee.PayeeName = creditor.AccountNumber(), creditor.BIC, creditor.Name
+	} else {
+		// confirmation of payee: the name must be the payee account's customer or username
+		var holderNames []string
+		query := `
+            SELECT ARRAY_REMOVE(ARRAY[a.username, c.full_name], NULL)
+            FROM usersschema.accounts a
+            LEFT JOIN usersschema.customers c ON c.id = a.customer_id
+            WHERE a.account_number = $1`
+		if err := conn.QueryRow(ctx, query, payee.PayeeAccount).Scan(&holderNames); err != nil {
+			if err == pgx.ErrNoRows {
+				return fmt.Errorf("%w: account %s does not exist", ErrPayeeNotFound, payee.PayeeAccount)
+			}
+			return fmt.Errorf("failed to look up payee account: %w", err)
+		}
+		for _, name := range holderNames {
+			if payee.PayeeName != "" && sameName(name, payee.PayeeName) {
+				payee.Status = "verified"
+			}
+		}
+	}
+
+	insertQuery := `
+        INSERT INTO usersschema.payees (account_number, nickname, payee_account, payee_bic, payee_name, status, active_from, verified_at)
+        VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6, $7, CASE WHEN $6 = 'verified' THEN NOW() END)
+        RETURNING ` + payeeColumns
+	created, err := scanPayee(conn.QueryRow(ctx, insertQuery, payee.AccountNumber, payee.Nickname, payee.PayeeAccount,
+		payee.PayeeBIC, payee.PayeeName, payee.Status, time.Now().Add(PayeeCoolingOff)))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505":
+				return fmt.Errorf("%w: the account already has a payee with this account or nickname", ErrPayeeNotAllowed)
+			case "23503":
+				return fmt.Errorf("%w: account %s does not exist", ErrPayeeNotFound, payee.AccountNumber)
+			}
+		}
+		return fmt.Errorf("failed to create payee: %w", err)
+	}
+	*payee = *created
+	return nil
+}
+
+// SetPayeeStatus records the outcome of a manual payee verification
+func (r *PayeeRepository) SetPayeeStatus(ctx context.Context, id, status string) (*models.Payee, error) {
+	if !models.ValidPayeeStatus(status) {
+		return nil, fmt.Errorf("%w: invalid payee status %q", ErrPayeeNotAllowed, status)
+	}
+	query := `
+        UPDATE usersschema.payees
+        SET status = $2,
+            verified_at = CASE WHEN $2 = 'verified' THEN NOW() END
+        WHERE id = $1
+        RETURNING ` + payeeColumns
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	payee, err := scanPayee(conn.QueryRow(ctx, query, id, status))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("%w: %s", ErrPayeeNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to update payee: %w", err)
+	}
+	return payee, nil
+}
+
+// DeletePayee removes a payee. Transfers already made to it are unaffected.
+func (r *PayeeRepository) DeletePayee(ctx context.Context, id string) error {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	result, err := conn.Exec(ctx, "DELETE FROM usersschema.payees WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete payee: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %s", ErrPayeeNotFound, id)
+	}
+	return nil
+}
+
+// ResolvePayee fills in the beneficiary of a transfer or external transfer made to a payee and
+// checks that it may be paid. It runs before the risk checks so that they see the beneficiary.
+func (r *PayeeRepository) ResolvePayee(ctx context.Context, trans *models.Transaction) error {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+	return checkPayee(ctx, conn, trans)
+}
+
+// checkPayee resolves trans.PayeeID into trans.ToAccountID of a transfer or trans.Creditor of an
+// external transfer, refuses payees still cooling off and, above PayeeVerifiedLimit, payments to
+// anything but a verified payee of the paying account. Transfers only go to payees of this bank
+// and external transfers to payees at another bank.
+func checkPayee(ctx context.Context, q rowQuerier, trans *models.Transaction) error {
+	external := trans.TransactionType == "external_transfer"
+	if trans.TransactionType != "transfer" && !external {
+		return nil
+	}
+	if trans.PayeeID == "" && (PayeeVerifiedLimit <= 0 || trans.Amount <= PayeeVerifiedLimit) {
+		return nil
+	}
+
+	var payee models.Payee
+	var active bool
+	var err error
+	if trans.PayeeID != "" {
+		query := `
+            SELECT payee_account, COALESCE(payee_bic, ''), COALESCE(payee_name, ''), status, active_from <= NOW()
+            FROM usersschema.payees
+            WHERE id::text = $1 AND account_number = $2`
+		err = q.QueryRow(ctx, query, trans.PayeeID, trans.FromAccountID).Scan(&payee.PayeeAccount, &payee.PayeeBIC, &payee.PayeeName, &payee.Status, &active)
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("%w: %s is not a payee of %s", ErrPayeeNotFound, trans.PayeeID, trans.FromAccountID)
+		}
+	} else {
+		beneficiary := trans.ToAccountID
+		if external {
+			if trans.Creditor == nil {
+				return fmt.Errorf("%w: external transfer has no creditor", ErrPayeeNotAllowed)
+			}
+			beneficiary = creditorAccount(trans.Creditor)
+		}
+		query := `
+            SELECT payee_account, COALESCE(payee_bic, ''), COALESCE(payee_name, ''), status, active_from <= NOW()
+            FROM usersschema.payees
+            WHERE account_number = $1 AND payee_account = $2 AND (payee_bic IS NOT NULL) = $3`
+		err = q.QueryRow(ctx, query, trans.FromAccountID, beneficiary, external).Scan(&payee.PayeeAccount, &payee.PayeeBIC, &payee.PayeeName, &payee.Status, &active)
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("%w: payments above %.2f must go to a verified payee", ErrPayeeNotAllowed, PayeeVerifiedLimit)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up payee: %w", err)
+	}
+
+	switch {
+	case external != (payee.PayeeBIC != ""):
+		return fmt.Errorf("%w: payee %s cannot be paid by %s", ErrPayeeNotAllowed, trans.PayeeID, trans.TransactionType)
+	case external && trans.Creditor == nil:
+		trans.Creditor = payee.Creditor()
+	case external && creditorAccount(trans.Creditor) != payee.PayeeAccount:
+		return fmt.Errorf("%w: payee %s pays %s, not %s", ErrPayeeNotAllowed, trans.PayeeID, payee.PayeeAccount, creditorAccount(trans.Creditor))
+	case !external && trans.ToAccountID == "":
+		trans.ToAccountID = payee.PayeeAccount
+	case !external && trans.ToAccountID != payee.PayeeAccount:
+		return fmt.Errorf("%w: payee %s pays %s, not %s", ErrPayeeNotAllowed, trans.PayeeID, payee.PayeeAccount, trans.ToAccountID)
+	}
+	if !active {
+		return fmt.Errorf("%w: payee %s is still in its cooling-off period", ErrPayeeNotAllowed, payee.PayeeAccount)
+	}
+	if PayeeVerifiedLimit > 0 && trans.Amount > PayeeVerifiedLimit && payee.Status != "verified" {
+		return fmt.Errorf("%w: payments above %.2f must go to a verified payee", ErrPayeeNotAllowed, PayeeVerifiedLimit)
+	}
+	return nil
+}
+
+// creditorAccount is the account of an external transfer's creditor as payees store it
+func creditorAccount(creditor *models.Party) string {
+	if creditor.IBAN != "" {
+		return strings.ToUpper(strings.ReplaceAll(creditor.IBAN, " ", ""))
+	}
+	return strings.TrimSpace(creditor.Account)
+}
+
+// sameName compares names ignoring case and spacing
+func sameName(a, b string) bool {
+	return strings.EqualFold(strings.Join(strings.Fields(a), " "), strings.Join(strings.Fields(b), " "))
+}
+
+// scanPayee scans a row selected with payeeColumns
+func scanPayee(row pgx.Row) (*models.Payee, error) {
+	payee := &models.Payee{}
+	err := row.Scan(&payee.ID, &payee.AccountNumber, &payee.Nickname, &payee.PayeeAccount, &payee.PayeeBIC, &payee.PayeeName,
+		&payee.Status, &payee.ActiveFrom, &payee.VerifiedAt, &payee.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return payee, nil
+}
@@ -2,13 +2,128 @@ package repositories
 
 import (
 	"context"
+	"time"
 	"transactionService/models"
+	"transactionService/saga"
+
+	"github.com/google/uuid"
 )
 
 type Repository interface {
 	TransactionRouter(ctx context.Context, transmodel *models.Transaction) error
 	CheckAccountExists(ctx context.Context, accountNumber string) (bool, error)
-	UpdateBalance(ctx context.Context, accountNumber string, amount float64, isCredit bool) error
-	Debit(ctx context.Context, accountNumber string, amount float64) error
-	Credit(ctx context.Context, accountNumber string, amount float64) error
+	GetAccount(ctx context.Context, accountNumber string) (*models.Account, error)
+	UpdateBalance(ctx context.Context, trans *models.Transaction, isCredit bool) error
+	Debit(ctx context.Context, trans *models.Transaction) error
+	Credit(ctx context.Context, trans *models.Transaction) error
+	TransferAmount(ctx context.Context, trans *models.Transaction) error
+	ExternalTransfer(ctx context.Context, trans *models.Transaction) error
+	Reverse(ctx context.Context, trans *models.Transaction) error
+}
+
+type TierRepo interface {
+	ListTiers(ctx context.Context) ([]models.AccountTier, error)
+	GetTier(ctx context.Context, name string) (*models.AccountTier, error)
+	UpsertTier(ctx context.Context, tier *models.AccountTier) error
+	AssignTier(ctx context.Context, accountNumber, tierName string) error
+}
+
+type OverdraftRepo interface {
+	SetOverdraft(ctx context.Context, accountNumber string, limit, annualRate float64) error
+	ChargeInterest(ctx context.Context, chargeDate time.Time) ([]models.OverdraftCharge, error)
+}
+
+type HoldRepo interface {
+	CreateHold(ctx context.Context, hold *models.Hold) error
+	GetHold(ctx context.Context, id string) (*models.Hold, error)
+	CaptureHold(ctx context.Context, id string, amount float64) (*models.Hold, *models.Transaction, error)
+	ReleaseHold(ctx context.Context, id string) (*models.Hold, error)
+	ExpireHolds(ctx context.Context, now time.Time) ([]models.Hold, error)
+}
+
+type FxRepo interface {
+	ListRates(ctx context.Context) ([]models.FxRate, error)
+	UpsertRate(ctx context.Context, rate *models.FxRate) error
+	LoadRatesFile(ctx context.Context, path string) (int, error)
+}
+
+type InterestRepo interface {
+	ListPlans(ctx context.Context) ([]models.InterestPlan, error)
+	UpsertPlan(ctx context.Context, plan *models.InterestPlan) error
+	AssignPlan(ctx context.Context, accountNumber, planName string) error
+	ListAccruals(ctx context.Context, accountNumber string) ([]models.InterestAccrual, error)
+	AccrualRange(ctx context.Context) (next, last *time.Time, err error)
+	Accrue(ctx context.Context, accrualDate time.Time) (int, error)
+	PendingPostings(ctx context.Context, periodEnd time.Time) ([]models.InterestPosting, error)
+	MarkPosted(ctx context.Context, posting *models.InterestPosting, periodEnd time.Time) error
+}
+
+type FeeRepo interface {
+	ListRules(ctx context.Context) ([]models.FeeRule, error)
+	CreateRule(ctx context.Context, rule *models.FeeRule) error
+	UpdateRule(ctx context.Context, rule *models.FeeRule) error
+	DeleteRule(ctx context.Context, id int64) error
+}
+
+type RiskRepo interface {
+	CreateReview(ctx context.Context, review *models.RiskReview) error
+	ListReviews(ctx context.Context, status string) ([]models.RiskReview, error)
+	GetReview(ctx context.Context, id string) (*models.RiskReview, error)
+	GetReviewByTransaction(ctx context.Context, transactionID string) (*models.RiskReview, error)
+	DecideReview(ctx context.Context, id, status, reviewer, note string) (*models.RiskReview, error)
+	FailReview(ctx context.Context, transactionID string, reason string) error
+}
+
+type ScreeningRepo interface {
+	RecordScreening(ctx context.Context, result *models.ScreeningResult) error
+	ListScreenings(ctx context.Context, reference, decision string, limit int) ([]models.ScreeningResult, error)
+}
+
+type ApprovalRepo interface {
+	ListRules(ctx context.Context, accountNumber string) ([]models.ApprovalRule, error)
+	UpsertRule(ctx context.Context, rule *models.ApprovalRule) error
+	DeleteRule(ctx context.Context, accountNumber, transactionType string) error
+	Gate(ctx context.Context, trans *models.Transaction) (bool, error)
+	GetApproval(ctx context.Context, id string) (*models.PendingApproval, error)
+	ListApprovals(ctx context.Context, accountNumber, status string) ([]models.PendingApproval, error)
+	Approve(ctx context.Context, id, customerID string) (*models.PendingApproval, error)
+	Reject(ctx context.Context, id, customerID, note string) (*models.PendingApproval, error)
+}
+
+type PayeeRepo interface {
+	ListPayees(ctx context.Context, accountNumber string) ([]models.Payee, error)
+	GetPayee(ctx context.Context, id string) (*models.Payee, error)
+	CreatePayee(ctx context.Context, payee *models.Payee) error
+	SetPayeeStatus(ctx context.Context, id, status string) (*models.Payee, error)
+	DeletePayee(ctx context.Context, id string) error
+	ResolvePayee(ctx context.Context, trans *models.Transaction) error
+}
+
+type ExternalRepo interface {
+	GetExternalTransfer(ctx context.Context, id string) (*models.ExternalTransfer, error)
+	ListExternalTransfers(ctx context.Context, accountNumber, status string) ([]models.ExternalTransfer, error)
+	MarkSubmitted(ctx context.Context, id uuid.UUID) error
+	UnsubmittedTransfers(ctx context.Context, before time.Time) ([]models.Transaction, error)
+	ApplyClearingStatus(ctx context.Context, status *models.ClearingStatus) (*models.ExternalTransfer, *models.Transaction, error)
+}
+
+type SagaRepo interface {
+	CreateSaga(ctx context.Context, s *models.Saga) error
+	GetSaga(ctx context.Context, id string) (*models.Saga, error)
+	ListSagas(ctx context.Context, status string) ([]models.Saga, error)
+	ApplySagaEvent(ctx context.Context, event saga.Event) (*models.Saga, error)
+	MarkStepPublished(ctx context.Context, sagaID uuid.UUID, step int) error
+	UnfinishedSagas(ctx context.Context, before time.Time) ([]models.Saga, error)
+	TransactionPosted(ctx context.Context, id uuid.UUID) (bool, error)
+}
+
+type EventRepo interface {
+	BalanceAsOf(ctx context.Context, accountNumber string, asOf time.Time) (*models.PointInTimeBalance, error)
+	ListEvents(ctx context.Context, accountNumber string, after int64, limit int) ([]models.AccountEvent, error)
+	RebuildProjection(ctx context.Context, accountNumber string) (*models.ProjectionRebuild, error)
+	RebuildAllProjections(ctx context.Context) ([]models.ProjectionRebuild, error)
+	NextEndOfDay(ctx context.Context) (*time.Time, error)
+	SnapshotEndOfDay(ctx context.Context, date time.Time) (int64, error)
+	BalanceAtEndOfDay(ctx context.Context, accountNumber string, date time.Time) (*models.PointInTimeBalance, error)
+	ListEndOfDay(ctx context.Context, accountNumber string, from, to time.Time) ([]models.EndOfDayBalance, error)
 }
@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"context"
+	"os"
+	"testing"
+	"transactionService/database"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testDB connects to the database named by TEST_POSTGRES_DSN and loads init.sql into it, which
+// drops every table first. Tests that need it are skipped when the variable is not set.
+func testDB(t *testing.T) *database.PostgresPoolDB {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+	ctx := context.Background()
+	db := database.NewPostgresPoolDB(dsn, 4, 1)
+	require.NoError(t, db.Connect(ctx))
+	t.Cleanup(func() { db.Close(ctx) })
+
+	schema, err := os.ReadFile("../../init.sql")
+	require.NoError(t, err)
+	_, err = db.Pool().Exec(ctx, string(schema))
+	require.NoError(t, err)
+	return db
+}
+
+// seedAccounts opens accounts with the given balances for one verified customer
+func seedAccounts(t *testing.T, db *database.PostgresPoolDB, balances map[string]float64) {
+	t.Helper()
+	ctx := context.Background()
+	var customerID string
+	query := `
+        INSERT INTO usersschema.customers (full_name, email, date_of_birth, nationality, address, id_document_type,
+                                           id_document_number, kyc_status, verified_at)
+        VALUES ('Jane Doe', 'jane@example.com', '1990-01-01', 'GB', '1 High Street', 'passport', 'P1234567', 'verified', NOW())
+        RETURNING id`
+	require.NoError(t, db.Pool().QueryRow(ctx, query).Scan(&customerID))
+	for account, balance := range balances {
+		_, err := db.Pool().Exec(ctx, `
+            INSERT INTO usersschema.accounts (account_number, username, email, balance, customer_id)
+            VALUES ($1, 'jane', 'jane@example.com', $2, $3)`, account, balance, customerID)
+		require.NoError(t, err)
+	}
+}
@@ -0,0 +1,276 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"transactionService/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Reverse posts the compensating balance movements for trans.OriginalTransactionID in a single
+// database transaction. Deposits and withdrawals can only be reversed in full; transfers can be
+// refunded in several partial reversals up to the original amount. A zero amount reverses
+// whatever has not been refunded yet. Amounts are in the currency of the original; a converted
+// transfer takes back the converted amount at the original rate. The fees charged on the original
+// are refunded in proportion to the amount reversed. On success trans carries the accounts the
+// reversal moved money between, so the ledger entry mirrors the original.
+func (r *TransactionRepository) Reverse(ctx context.Context, trans *models.Transaction) error {
+	if trans.OriginalTransactionID == "" {
+		return fmt.Errorf("reversal must reference an original transaction")
+	}
+	if trans.Amount < 0 {
+		return fmt.Errorf("reversal amount cannot be negative: %.2f", trans.Amount)
+	}
+
+	// Get a connection and start a transaction
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// Defer rollback in case of error
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	// Lock the original so two reversals of the same transaction cannot both succeed
+	query := `
+        SELECT from_account_id, COALESCE(to_account_id, ''), amount, transaction_type, status, reversed_amount,
+               currency, COALESCE(fx_rate, 0), COALESCE(converted_amount, 0), COALESCE(converted_currency, '')
+        FROM usersschema.transactions
+        WHERE id = $1
+        FOR UPDATE`
+
+	var original models.Transaction
+	var reversedAmount float64
+	err = tx.QueryRow(ctx, query, trans.OriginalTransactionID).Scan(&original.FromAccountID, &original.ToAccountID,
+		&original.Amount, &original.TransactionType, &original.Status, &reversedAmount,
+		&original.Currency, &original.FxRate, &original.ConvertedAmount, &original.ConvertedCurrency)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("original transaction not found: %s", trans.OriginalTransactionID)
+		}
+		return fmt.Errorf("failed to get original transaction: %w", err)
+	}
+
+	// Validate the original can still be reversed
+	if original.Status == "reversed" {
+		err = fmt.Errorf("transaction %s is already reversed", trans.OriginalTransactionID)
+		return err
+	}
+	if original.Status != "completed" {
+		err = fmt.Errorf("transaction %s is %s, only completed transactions can be reversed", trans.OriginalTransactionID, original.Status)
+		return err
+	}
+	if original.TransactionType != "deposit" && original.TransactionType != "withdrawal" && original.TransactionType != "transfer" {
+		err = fmt.Errorf("%s transactions cannot be reversed", original.TransactionType)
+		return err
+	}
+
+	remaining := original.Amount - reversedAmount
+	amount := trans.Amount
+	if amount == 0 {
+		amount = remaining
+	}
+	if amount > remaining+1e-9 {
+		err = fmt.Errorf("reversal amount %.2f exceeds the %.2f not yet reversed", amount, remaining)
+		return err
+	}
+	if original.TransactionType != "transfer" && math.Abs(amount-original.Amount) > 1e-9 {
+		err = fmt.Errorf("partial reversals are only supported for transfers")
+		return err
+	}
+
+	// The compensating movement takes money back from whoever received it
+	debitAccount, creditAccount := "", ""
+	switch original.TransactionType {
+	case "deposit":
+		debitAccount = original.FromAccountID
+	case "withdrawal":
+		creditAccount = original.FromAccountID
+	case "transfer":
+		debitAccount, creditAccount = original.ToAccountID, original.FromAccountID
+	}
+
+	// A converted transfer is taken back from the destination in its own currency. The final
+	// reversal takes whatever is left so partial refunds never drift from the converted total.
+	debitAmount := amount
+	if original.ConvertedCurrency != "" {
+		debitAmount = models.ConvertAmount(amount, original.FxRate)
+		if remaining-amount <= 1e-9 {
+			debitAmount = original.ConvertedAmount - models.ConvertAmount(reversedAmount, original.FxRate)
+		}
+	}
+
+	if trans.ID == uuid.Nil {
+		trans.ID = uuid.New()
+	}
+	if err = reverseMovement(ctx, tx, trans, debitAccount, debitAmount, creditAccount, amount); err != nil {
+		return err
+	}
+	if err = refundFees(ctx, tx, trans, trans.OriginalTransactionID, original.FromAccountID,
+		amount/original.Amount, reversedAmount/original.Amount, remaining-amount <= 1e-9); err != nil {
+		return err
+	}
+
+	// Link the original to its reversal and close it once fully refunded
+	status := "completed"
+	if remaining-amount <= 1e-9 {
+		status = "reversed"
+	}
+	updateQuery := `
+        UPDATE usersschema.transactions
+        SET reversed_amount = reversed_amount + $1,
+            status = $2
+        WHERE id = $3`
+	if _, err = tx.Exec(ctx, updateQuery, amount, status, trans.OriginalTransactionID); err != nil {
+		return fmt.Errorf("failed to update original transaction: %w", err)
+	}
+
+	// Record the reversal itself in the direction the money moved. A reversed withdrawal has no
+	// account to debit, so it is recorded from and to the credited account.
+	trans.Amount = amount
+	trans.Currency = original.Currency
+	trans.FromAccountID, trans.ToAccountID = debitAccount, creditAccount
+	if debitAccount == "" {
+		trans.FromAccountID = creditAccount
+	}
+	if original.ConvertedCurrency != "" {
+		trans.Amount, trans.Currency = debitAmount, original.ConvertedCurrency
+		trans.FxRate, trans.ConvertedAmount, trans.ConvertedCurrency = 1/original.FxRate, amount, original.Currency
+	}
+	if trans.Description == "" {
+		trans.Description = fmt.Sprintf("Reversal of %s %s", original.TransactionType, trans.OriginalTransactionID)
+	}
+	if err = recordTransaction(ctx, tx, trans); err != nil {
+		return err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// reverseMovement debits debitAmount from debitAccount and credits creditAmount to creditAccount;
+// either account may be empty.
+// The debit may use the overdraft but is not subject to tier limits, since it returns money
+// that was never the account holder's to keep.
+func reverseMovement(ctx context.Context, tx pgx.Tx, trans *models.Transaction, debitAccount string, debitAmount float64, creditAccount string, creditAmount float64) error {
+	// Lock in account number order to avoid deadlocks with transfers
+	accounts := []string{}
+	for _, account := range []string{debitAccount, creditAccount} {
+		if account != "" {
+			accounts = append(accounts, account)
+		}
+	}
+	rows, err := tx.Query(ctx, "SELECT account_number, balance, overdraft_limit FROM usersschema.accounts WHERE account_number = ANY($1) ORDER BY account_number FOR UPDATE", accounts)
+	if err != nil {
+		return fmt.Errorf("failed to lock accounts: %w", err)
+	}
+	balances := make(map[string]float64)
+	overdraftLimits := make(map[string]float64)
+	for rows.Next() {
+		var accountNumber string
+		var balance, overdraftLimit float64
+		if err := rows.Scan(&accountNumber, &balance, &overdraftLimit); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan account data: %w", err)
+		}
+		balances[accountNumber] = balance
+		overdraftLimits[accountNumber] = overdraftLimit
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating account rows: %w", err)
+	}
+	for _, account := range accounts {
+		if _, ok := balances[account]; !ok {
+			return fmt.Errorf("account not found: %s", account)
+		}
+	}
+
+	if debitAccount != "" {
+		held, err := activeHolds(ctx, tx, debitAccount)
+		if err != nil {
+			return err
+		}
+		if balances[debitAccount]-debitAmount-held < -overdraftLimits[debitAccount] {
+			return fmt.Errorf("insufficient funds in %s to reverse %.2f: current balance %.2f, held %.2f, overdraft limit %.2f",
+				debitAccount, debitAmount, balances[debitAccount], held, overdraftLimits[debitAccount])
+		}
+	}
+
+	if debitAccount != "" {
+		if err := moveBalance(ctx, tx, trans, debitAccount, -debitAmount, "reversal"); err != nil {
+			return err
+		}
+	}
+	if creditAccount != "" {
+		if err := moveBalance(ctx, tx, trans, creditAccount, creditAmount, "reversal"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refundFees pays back to payer the share part of the fees charged on the original transaction,
+// taking it from the accounts they were credited to. reversed is the share refunded by earlier
+// reversals; the final one refunds whatever is left, so partial refunds add up to the fees charged.
+func refundFees(ctx context.Context, tx pgx.Tx, trans *models.Transaction, originalID, payer string, part, reversed float64, final bool) error {
+	query := `
+        SELECT fee_account, SUM(amount), SUM(credited_amount)
+        FROM usersschema.transaction_fees
+        WHERE transaction_id = $1
+        GROUP BY fee_account
+        ORDER BY fee_account`
+	rows, err := tx.Query(ctx, query, originalID)
+	if err != nil {
+		return fmt.Errorf("failed to load fees of %s: %w", originalID, err)
+	}
+	type feeLines struct {
+		account        string
+		paid, credited float64
+	}
+	var charged []feeLines
+	for rows.Next() {
+		var lines feeLines
+		if err := rows.Scan(&lines.account, &lines.paid, &lines.credited); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan fees: %w", err)
+		}
+		charged = append(charged, lines)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating fee rows: %w", err)
+	}
+
+	share := func(total float64) float64 {
+		if final {
+			return total - models.ConvertAmount(total, reversed)
+		}
+		return models.ConvertAmount(total, part)
+	}
+	for _, lines := range charged {
+		if err := moveBalance(ctx, tx, trans, lines.account, -share(lines.credited), "fee_refund"); err != nil {
+			return err
+		}
+		if err := moveBalance(ctx, tx, trans, payer, share(lines.paid), "fee_refund"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"transactionService/database"
+	"transactionService/models"
+	"transactionService/risk"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// RiskRepository implements RiskRepo and supplies the account history read by the risk rules
+type RiskRepository struct {
+	db *database.PostgresPoolDB
+}
+
+// NewRiskRepository creates a new RiskRepository
+func NewRiskRepository(db *database.PostgresPoolDB) *RiskRepository {
+	return &RiskRepository{db: db}
+}
+
+const riskReviewColumns = "id, transaction, decision, score, reasons, status, COALESCE(reviewer, ''), COALESCE(note, ''), created_at, decided_at"
+
+// Facts implements risk.FactSource. Interest and overdraft charges are posted by the bank and
+// do not count as account activity. Unknown accounts have no history; the transaction itself
+// fails later when it is applied.
+func (r *RiskRepository) Facts(ctx context.Context, trans *models.Transaction, window time.Duration) (risk.Facts, error) {
+	query := `
+        SELECT
+            (SELECT COUNT(*) FROM usersschema.transactions
+             WHERE from_account_id = $1 AND transaction_type IN ('withdrawal', 'transfer', 'external_transfer')
+               AND status = 'completed' AND created_at >= NOW() - make_interval(secs => $2)),
+            (SELECT COUNT(*) FROM usersschema.transactions
+             WHERE from_account_id = $1 AND to_account_id = $3 AND transaction_type = 'transfer' AND status <> 'failed'),
+            COALESCE((SELECT MAX(created_at) FROM usersschema.transactions
+                      WHERE (from_account_id = $1 OR to_account_id = $1)
+                        AND transaction_type NOT IN ('interest', 'overdraft_interest')), a.created_at)
+        FROM usersschema.accounts a
+        WHERE a.account_number = $1`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return risk.Facts{}, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	var facts risk.Facts
+	err = conn.QueryRow(ctx, query, trans.FromAccountID, window.Seconds(), trans.ToAccountID).
+		Scan(&facts.RecentDebits, &facts.PriorPayeeTransfer, &facts.LastActivity)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return risk.Facts{}, nil
+		}
+		return risk.Facts{}, fmt.Errorf("failed to load risk facts: %w", err)
+	}
+	return facts, nil
+}
+
+// Rate implements risk.FactSource. The amount is in the transaction's currency or, when it names
+// none, in that of FromAccountID; an unknown account is left unconverted.
+func (r *RiskRepository) Rate(ctx context.Context, trans *models.Transaction, currency string) (float64, error) {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	from := trans.Currency
+	if from == "" {
+		err = conn.QueryRow(ctx, "SELECT currency FROM usersschema.accounts WHERE account_number = $1", trans.FromAccountID).Scan(&from)
+		if err == pgx.ErrNoRows {
+			return 1, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to get account currency: %w", err)
+		}
+	}
+	rate, err := lookupRate(ctx, conn, strings.ToUpper(from), currency)
+	if errors.Is(err, ErrNoRate) {
+		return 0, nil
+	}
+	return rate, err
+}
+
+// CreateReview stores a transaction stopped by the risk checks. A transaction that was already
+// stopped, for instance a redelivered message, returns ErrDuplicateTransaction.
+func (r *RiskRepository) CreateReview(ctx context.Context, review *models.RiskReview) error {
+	payload, err := json.Marshal(review.Transaction)
+	if err != nil {
+		return fmt.Errorf("failed to encode transaction: %w", err)
+	}
+	query := `
+        INSERT INTO usersschema.risk_reviews (transaction_id, transaction, decision, score, reasons, status)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (transaction_id) DO NOTHING
+        RETURNING id, created_at`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	err = conn.QueryRow(ctx, query, review.Transaction.ID, payload, review.Decision, review.Score, review.Reasons, review.Status).
+		Scan(&review.ID, &review.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("%w: %s", ErrDuplicateTransaction, review.Transaction.ID)
+		}
+		return fmt.Errorf("failed to save risk review: %w", err)
+	}
+	return nil
+}
+
+// ListReviews returns the reviews with the given status, or every review if status is empty,
+// oldest first
+func (r *RiskRepository) ListReviews(ctx context.Context, status string) ([]models.RiskReview, error) {
+	query := "SELECT " + riskReviewColumns + " FROM usersschema.risk_reviews WHERE $1 = '' OR status = $1 ORDER BY created_at"
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, query, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list risk reviews: %w", err)
+	}
+	defer rows.Close()
+
+	reviews := []models.RiskReview{}
+	for rows.Next() {
+		review, err := scanRiskReview(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan risk review: %w", err)
+		}
+		reviews = append(reviews, *review)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating risk review rows: %w", err)
+	}
+	return reviews, nil
+}
+
+// GetReview returns a review, or nil if it does not exist
+func (r *RiskRepository) GetReview(ctx context.Context, id string) (*models.RiskReview, error) {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	review, err := scanRiskReview(conn.QueryRow(ctx, "SELECT "+riskReviewColumns+" FROM usersschema.risk_reviews WHERE id = $1", id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get risk review: %w", err)
+	}
+	return review, nil
+}
+
+// GetReviewByTransaction returns the review of a transaction, or nil if it was never stopped
+func (r *RiskRepository) GetReviewByTransaction(ctx context.Context, transactionID string) (*models.RiskReview, error) {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	review, err := scanRiskReview(conn.QueryRow(ctx, "SELECT "+riskReviewColumns+" FROM usersschema.risk_reviews WHERE transaction_id = $1", transactionID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get risk review: %w", err)
+	}
+	return review, nil
+}
+
+// DecideReview moves a pending review to status ("approved" or "rejected"). Only one operator
+// can decide a review; deciding it again fails, except approving an approved review again, which
+// keeps the first decision and lets the operator retry resubmitting the transaction.
+func (r *RiskRepository) DecideReview(ctx context.Context, id, status, reviewer, note string) (*models.RiskReview, error) {
+	if status != "approved" && status != "rejected" {
+		return nil, fmt.Errorf("invalid review decision: %s", status)
+	}
+	query := `
+        UPDATE usersschema.risk_reviews
+        SET status = $2,
+            reviewer = COALESCE(reviewer, NULLIF($3, '')),
+            note = COALESCE(note, NULLIF($4, '')),
+            decided_at = COALESCE(decided_at, NOW())
+        WHERE id = $1 AND (status = 'pending' OR (status = 'approved' AND $2 = 'approved'))
+        RETURNING ` + riskReviewColumns
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	review, err := scanRiskReview(conn.QueryRow(ctx, query, id, status, reviewer, note))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("risk review %s is not pending", id)
+		}
+		return nil, fmt.Errorf("failed to decide risk review: %w", err)
+	}
+	return review, nil
+}
+
+// FailReview records that a transaction approved on review could not be applied. Transactions
+// without an approved review are left alone.
+func (r *RiskRepository) FailReview(ctx context.Context, transactionID string, reason string) error {
+	query := `
+        UPDATE usersschema.risk_reviews
+        SET status = 'failed', note = CONCAT_WS(': ', note, $2::text)
+        WHERE transaction_id = $1 AND status = 'approved'`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, query, transactionID, reason); err != nil {
+		return fmt.Errorf("failed to mark risk review failed: %w", err)
+	}
+	return nil
+}
+
+// scanRiskReview scans a row selected with riskReviewColumns
+func scanRiskReview(row pgx.Row) (*models.RiskReview, error) {
+	review := &models.RiskReview{}
+	var payload []byte
+	err := row.Scan(&review.ID, &payload, &review.Decision, &review.Score, &review.Reasons, &review.Status,
+		&review.Reviewer, &review.Note, &review.CreatedAt, &review.DecidedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(payload, &review.Transaction); err != nil {
+		return nil, fmt.Errorf("invalid transaction on risk review %s: %w", review.ID, err)
+	}
+	return review, nil
+}
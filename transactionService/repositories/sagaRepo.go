@@ -0,0 +1,344 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"transactionService/database"
+	"transactionService/models"
+	"transactionService/saga"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrDuplicateSaga is returned when a saga with the same ID was already started
+var ErrDuplicateSaga = errors.New("saga already started")
+
+const sagaColumns = `id, saga_type, status, request, COALESCE(error, ''), created_at, updated_at`
+
+const sagaStepColumns = `step_index, name, status, command, transaction_id, failure_id, compensation_id, attempts,
+    published_at, COALESCE(error, ''), updated_at`
+
+// sagaQuerier is satisfied by both pooled connections and open transactions
+type sagaQuerier interface {
+	rowQuerier
+	rowsQuerier
+}
+
+// SagaRepository implements SagaRepo, the persistent state of the saga orchestrator
+type SagaRepository struct {
+	db *database.PostgresPoolDB
+}
+
+// NewSagaRepository creates a new SagaRepository
+func NewSagaRepository(db *database.PostgresPoolDB) *SagaRepository {
+	return &SagaRepository{db: db}
+}
+
+// CreateSaga stores a planned saga and its steps
+func (r *SagaRepository) CreateSaga(ctx context.Context, s *models.Saga) error {
+	request, err := json.Marshal(s.Request)
+	if err != nil {
+		return fmt.Errorf("failed to encode saga request: %w", err)
+	}
+
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	query := `
+        INSERT INTO usersschema.sagas (id, saga_type, status, request, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $5)
+        ON CONFLICT (id) DO NOTHING
+        RETURNING id`
+	var id uuid.UUID
+	err = tx.QueryRow(ctx, query, s.ID, s.Type, s.Status, request, s.CreatedAt).Scan(&id)
+	if err == pgx.ErrNoRows {
+		err = fmt.Errorf("%w: %s", ErrDuplicateSaga, s.ID)
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create saga: %w", err)
+	}
+
+	stepQuery := `
+        INSERT INTO usersschema.saga_steps (saga_id, step_index, name, status, command, transaction_id, failure_id,
+                                            compensation_id, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	for _, step := range s.Steps {
+		var command []byte
+		if step.Command != nil {
+			if command, err = json.Marshal(step.Command); err != nil {
+				return fmt.Errorf("failed to encode saga command: %w", err)
+			}
+		}
+		_, err = tx.Exec(ctx, stepQuery, s.ID, step.Index, step.Name, step.Status, command, step.TransactionID,
+			step.FailureID, step.CompensationID, step.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create saga step %s: %w", step.Name, err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetSaga returns a saga with its steps, or nil if it does not exist
+func (r *SagaRepository) GetSaga(ctx context.Context, id string) (*models.Saga, error) {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	s, err := loadSaga(ctx, conn, "SELECT "+sagaColumns+" FROM usersschema.sagas WHERE id = $1", id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get saga: %w", err)
+	}
+	return s, nil
+}
+
+// ListSagas returns the most recent sagas without their steps, optionally filtered by status
+func (r *SagaRepository) ListSagas(ctx context.Context, status string) ([]models.Saga, error) {
+	query := `
+        SELECT ` + sagaColumns + `
+        FROM usersschema.sagas
+        WHERE $1 = '' OR status = $1
+        ORDER BY created_at DESC
+        LIMIT 100`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, query, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sagas: %w", err)
+	}
+	defer rows.Close()
+
+	sagas := []models.Saga{}
+	for rows.Next() {
+		s, err := scanSaga(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan saga: %w", err)
+		}
+		sagas = append(sagas, *s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating saga rows: %w", err)
+	}
+	return sagas, nil
+}
+
+// ApplySagaEvent moves on the saga waiting for the transaction in event. It returns nil when no
+// saga knows the transaction, and the saga as it now stands otherwise.
+func (r *SagaRepository) ApplySagaEvent(ctx context.Context, event saga.Event) (*models.Saga, error) {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	var sagaID uuid.UUID
+	findQuery := `
+        SELECT saga_id
+        FROM usersschema.saga_steps
+        WHERE transaction_id = $1 OR failure_id = $1 OR compensation_id = $1
+        LIMIT 1`
+	err = tx.QueryRow(ctx, findQuery, event.TransactionID).Scan(&sagaID)
+	if err == pgx.ErrNoRows {
+		// most ledger traffic belongs to no saga
+		err = tx.Rollback(ctx)
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find saga: %w", err)
+	}
+
+	// Lock the saga so two events on it are applied one after the other
+	s, err := loadSaga(ctx, tx, "SELECT "+sagaColumns+" FROM usersschema.sagas WHERE id = $1 FOR UPDATE", sagaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock saga: %w", err)
+	}
+	before := make([]models.SagaStep, len(s.Steps))
+	copy(before, s.Steps)
+
+	if !saga.Advance(s, event, time.Now()) {
+		err = tx.Rollback(ctx)
+		return s, err
+	}
+
+	updateQuery := `
+        UPDATE usersschema.sagas
+        SET status = $2, error = NULLIF($3, ''), updated_at = $4
+        WHERE id = $1`
+	if _, err = tx.Exec(ctx, updateQuery, s.ID, s.Status, s.Error, s.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("failed to update saga: %w", err)
+	}
+	// Only the steps the event touched are written, so publications recorded meanwhile are kept
+	stepQuery := `
+        UPDATE usersschema.saga_steps
+        SET status = $3, attempts = $4, published_at = $5, error = NULLIF($6, ''), updated_at = $7
+        WHERE saga_id = $1 AND step_index = $2`
+	for i, step := range s.Steps {
+		if step.Status == before[i].Status && step.Error == before[i].Error {
+			continue
+		}
+		_, err = tx.Exec(ctx, stepQuery, s.ID, step.Index, step.Status, step.Attempts, step.PublishedAt, step.Error, step.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update saga step %s: %w", step.Name, err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return s, nil
+}
+
+// MarkStepPublished records that the command or compensation of a step was published
+func (r *SagaRepository) MarkStepPublished(ctx context.Context, sagaID uuid.UUID, step int) error {
+	query := `
+        UPDATE usersschema.saga_steps
+        SET attempts = attempts + 1, published_at = NOW(), updated_at = NOW()
+        WHERE saga_id = $1 AND step_index = $2`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, query, sagaID, step); err != nil {
+		return fmt.Errorf("failed to mark saga step published: %w", err)
+	}
+	return nil
+}
+
+// UnfinishedSagas returns the running and compensating sagas not updated since before, with their steps
+func (r *SagaRepository) UnfinishedSagas(ctx context.Context, before time.Time) ([]models.Saga, error) {
+	query := `
+        SELECT id
+        FROM usersschema.sagas
+        WHERE status IN ('running', 'compensating') AND updated_at <= $1
+        ORDER BY updated_at
+        LIMIT 100`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, query, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unfinished sagas: %w", err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan saga: %w", err)
+	}
+
+	sagas := make([]models.Saga, 0, len(ids))
+	for _, id := range ids {
+		s, err := loadSaga(ctx, conn, "SELECT "+sagaColumns+" FROM usersschema.sagas WHERE id = $1", id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load saga %s: %w", id, err)
+		}
+		sagas = append(sagas, *s)
+	}
+	return sagas, nil
+}
+
+// TransactionPosted reports whether a transaction was applied, whatever has happened to it since
+func (r *SagaRepository) TransactionPosted(ctx context.Context, id uuid.UUID) (bool, error) {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	var posted bool
+	if err := conn.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM usersschema.transactions WHERE id = $1)", id).Scan(&posted); err != nil {
+		return false, fmt.Errorf("failed to look up transaction: %w", err)
+	}
+	return posted, nil
+}
+
+// loadSaga scans the saga selected by query and loads its steps
+func loadSaga(ctx context.Context, q sagaQuerier, query string, args ...any) (*models.Saga, error) {
+	s, err := scanSaga(q.QueryRow(ctx, query, args...))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.Query(ctx, "SELECT "+sagaStepColumns+" FROM usersschema.saga_steps WHERE saga_id = $1 ORDER BY step_index", s.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load saga steps: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var step models.SagaStep
+		var command []byte
+		err := rows.Scan(&step.Index, &step.Name, &step.Status, &command, &step.TransactionID, &step.FailureID,
+			&step.CompensationID, &step.Attempts, &step.PublishedAt, &step.Error, &step.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan saga step: %w", err)
+		}
+		if command != nil {
+			step.Command = &models.Transaction{}
+			if err := json.Unmarshal(command, step.Command); err != nil {
+				return nil, fmt.Errorf("invalid command on saga %s step %s: %w", s.ID, step.Name, err)
+			}
+		}
+		s.Steps = append(s.Steps, step)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating saga step rows: %w", err)
+	}
+	return s, nil
+}
+
+// scanSaga scans a row selected with sagaColumns
+func scanSaga(row pgx.Row) (*models.Saga, error) {
+	s := &models.Saga{}
+	var request []byte
+	if err := row.Scan(&s.ID, &s.Type, &s.Status, &request, &s.Error, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(request, &s.Request); err != nil {
+		return nil, fmt.Errorf("invalid request on saga %s: %w", s.ID, err)
+	}
+	return s, nil
+}
@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+	"transactionService/models"
+	"transactionService/saga"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSagaWithFee tests that both steps of a transfer_with_fee saga are applied, the fee
+// transfer included, although the fee income account belongs to no customer
+func TestSagaWithFee(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	seedAccounts(t, db, map[string]float64{"ACC1": 100, "ACC2": 0})
+
+	now := time.Now()
+	s, err := saga.Plan(models.SagaRequest{
+		Type: models.SagaTransferWithFee, FromAccountID: "ACC1", ToAccountID: "ACC2", Amount: 50, Fee: 2.5,
+	}, FeeIncomeAccount, now)
+	require.NoError(t, err)
+
+	repo := NewUserRepository(db)
+	for commands := saga.Commands(s, time.Time{}); len(commands) > 0; commands = saga.Commands(s, time.Time{}) {
+		trans := commands[0].Transaction
+		require.NoError(t, repo.TransactionRouter(ctx, &trans), "step %d", commands[0].Step)
+		require.True(t, saga.Advance(s, saga.Event{TransactionID: trans.ID}, now))
+	}
+	assert.Equal(t, models.SagaCompleted, s.Status)
+
+	balances := map[string]float64{}
+	rows, err := db.Pool().Query(ctx, "SELECT account_number, balance FROM usersschema.accounts")
+	require.NoError(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var account string
+		var balance float64
+		require.NoError(t, rows.Scan(&account, &balance))
+		balances[account] = balance
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, 47.5, balances["ACC1"])
+	assert.Equal(t, 50.0, balances["ACC2"])
+	assert.Equal(t, 2.5, balances[FeeIncomeAccount])
+}
@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"transactionService/database"
+	"transactionService/models"
+)
+
+// ScreeningRepository implements ScreeningRepo and risk.ScreeningStore
+type ScreeningRepository struct {
+	db *database.PostgresPoolDB
+}
+
+// NewScreeningRepository creates a new ScreeningRepository
+func NewScreeningRepository(db *database.PostgresPoolDB) *ScreeningRepository {
+	return &ScreeningRepository{db: db}
+}
+
+const screeningResultColumns = "id, subject, reference, party, name, decision, score, COALESCE(entry_id, ''), COALESCE(matched_name, ''), COALESCE(program, ''), created_at"
+
+// AccountHolders returns the names on each account that exists: the holder name, and the legal
+// name of the customer it was opened for and of every other holder
+func (r *ScreeningRepository) AccountHolders(ctx context.Context, accountNumbers ...string) (map[string][]string, error) {
+	query := `
+        SELECT a.account_number, a.username
+        FROM usersschema.accounts a
+        WHERE a.account_number = ANY($1)
+        UNION
+        SELECT a.account_number, c.full_name
+        FROM usersschema.accounts a
+        JOIN usersschema.customers c ON c.id = a.customer_id
+        WHERE a.account_number = ANY($1)
+        UNION
+        SELECT h.account_number, c.full_name
+        FROM usersschema.account_holders h
+        JOIN usersschema.customers c ON c.id = h.customer_id
+        WHERE h.account_number = ANY($1)`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, query, accountNumbers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load account holders: %w", err)
+	}
+	defer rows.Close()
+
+	holders := make(map[string][]string)
+	for rows.Next() {
+		var accountNumber, name string
+		if err := rows.Scan(&accountNumber, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan account holder: %w", err)
+		}
+		holders[accountNumber] = append(holders[accountNumber], name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating account holder rows: %w", err)
+	}
+	return holders, nil
+}
+
+// RecordScreening stores the audit record of a screening decision
+func (r *ScreeningRepository) RecordScreening(ctx context.Context, result *models.ScreeningResult) error {
+	query := `
+        INSERT INTO usersschema.screening_results (subject, reference, party, name, decision, score, entry_id, matched_name, program)
+        VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''))
+        RETURNING id, created_at`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	err = conn.QueryRow(ctx, query, result.Subject, result.Reference, result.Party, result.Name, result.Decision, result.Score,
+		result.EntryID, result.MatchedName, result.Program).Scan(&result.ID, &result.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record screening result: %w", err)
+	}
+	return nil
+}
+
+// ListScreenings returns the screening results for a reference, or the latest results with
+// the given decision when reference is empty, newest first
+func (r *ScreeningRepository) ListScreenings(ctx context.Context, reference, decision string, limit int) ([]models.ScreeningResult, error) {
+	query := `
+        SELECT ` + screeningResultColumns + `
+        FROM usersschema.screening_results
+        WHERE ($1 = '' OR reference = $1) AND ($2 = '' OR decision = $2)
+        ORDER BY created_at DESC
+        LIMIT $3`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, query, reference, decision, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list screening results: %w", err)
+	}
+	defer rows.Close()
+
+	results := []models.ScreeningResult{}
+	for rows.Next() {
+		var result models.ScreeningResult
+		if err := rows.Scan(&result.ID, &result.Subject, &result.Reference, &result.Party, &result.Name, &result.Decision,
+			&result.Score, &result.EntryID, &result.MatchedName, &result.Program, &result.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan screening result: %w", err)
+		}
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating screening result rows: %w", err)
+	}
+	return results, nil
+}
@@ -0,0 +1,211 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"transactionService/database"
+	"transactionService/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// TierRepository implements TierRepo for account tiers
+type TierRepository struct {
+	db *database.PostgresPoolDB
+}
+
+// NewTierRepository creates a new TierRepository
+func NewTierRepository(db *database.PostgresPoolDB) *TierRepository {
+	return &TierRepository{db: db}
+}
+
+// ListTiers returns every configured tier ordered by name
+func (r *TierRepository) ListTiers(ctx context.Context) ([]models.AccountTier, error) {
+	query := `
+        SELECT name, max_per_transaction, daily_withdrawal_limit, monthly_withdrawal_limit,
+               daily_transfer_limit, monthly_transfer_limit, min_balance, currency, updated_at
+        FROM usersschema.account_tiers
+        ORDER BY name`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tiers: %w", err)
+	}
+	defer rows.Close()
+
+	tiers := []models.AccountTier{}
+	for rows.Next() {
+		var tier models.AccountTier
+		if err := rows.Scan(&tier.Name, &tier.MaxPerTransaction, &tier.DailyWithdrawalLimit, &tier.MonthlyWithdrawalLimit,
+			&tier.DailyTransferLimit, &tier.MonthlyTransferLimit, &tier.MinBalance, &tier.Currency, &tier.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan tier: %w", err)
+		}
+		tiers = append(tiers, tier)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tier rows: %w", err)
+	}
+	return tiers, nil
+}
+
+// GetTier returns a single tier, or nil if it does not exist
+func (r *TierRepository) GetTier(ctx context.Context, name string) (*models.AccountTier, error) {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	tier, err := getTier(ctx, conn, "SELECT name, max_per_transaction, daily_withdrawal_limit, monthly_withdrawal_limit, daily_transfer_limit, monthly_transfer_limit, min_balance, currency, updated_at FROM usersschema.account_tiers WHERE name = $1", name)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get tier: %w", err)
+	}
+	return tier, nil
+}
+
+// UpsertTier creates the tier or replaces its limits
+func (r *TierRepository) UpsertTier(ctx context.Context, tier *models.AccountTier) error {
+	if err := tier.Validate(); err != nil {
+		return err
+	}
+	query := `
+        INSERT INTO usersschema.account_tiers (name, max_per_transaction, daily_withdrawal_limit, monthly_withdrawal_limit,
+                                               daily_transfer_limit, monthly_transfer_limit, min_balance, currency, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+        ON CONFLICT (name) DO UPDATE
+        SET max_per_transaction = EXCLUDED.max_per_transaction,
+            daily_withdrawal_limit = EXCLUDED.daily_withdrawal_limit,
+            monthly_withdrawal_limit = EXCLUDED.monthly_withdrawal_limit,
+            daily_transfer_limit = EXCLUDED.daily_transfer_limit,
+            monthly_transfer_limit = EXCLUDED.monthly_transfer_limit,
+            min_balance = EXCLUDED.min_balance,
+            currency = EXCLUDED.currency,
+            updated_at = NOW()
+        RETURNING updated_at`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	err = conn.QueryRow(ctx, query, tier.Name, tier.MaxPerTransaction, tier.DailyWithdrawalLimit, tier.MonthlyWithdrawalLimit,
+		tier.DailyTransferLimit, tier.MonthlyTransferLimit, tier.MinBalance, tier.Currency).Scan(&tier.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save tier: %w", err)
+	}
+	return nil
+}
+
+// AssignTier moves an account onto another tier
+func (r *TierRepository) AssignTier(ctx context.Context, accountNumber, tierName string) error {
+	query := `
+        UPDATE usersschema.accounts
+        SET tier = $1,
+            updated_at = NOW()
+        WHERE account_number = $2`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	result, err := conn.Exec(ctx, query, tierName, accountNumber)
+	if err != nil {
+		return fmt.Errorf("failed to assign tier: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("account not found: %s", accountNumber)
+	}
+	return nil
+}
+
+// rowQuerier is satisfied by both pooled connections and open transactions
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// getTier scans a single tier row returned by query
+func getTier(ctx context.Context, q rowQuerier, query string, args ...any) (*models.AccountTier, error) {
+	tier := &models.AccountTier{}
+	err := q.QueryRow(ctx, query, args...).Scan(&tier.Name, &tier.MaxPerTransaction, &tier.DailyWithdrawalLimit,
+		&tier.MonthlyWithdrawalLimit, &tier.DailyTransferLimit, &tier.MonthlyTransferLimit, &tier.MinBalance, &tier.Currency, &tier.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return tier, nil
+}
+
+// enforceTierLimits checks a pending debit against the tier of the account. It must run inside
+// the transaction that holds the account row lock so the usage totals cannot change underneath it.
+// Amounts are in the account currency and are converted to the tier's currency before comparing.
+// Accounts with an overdraft facility are bounded by the overdraft limit instead of the tier minimum.
+func enforceTierLimits(ctx context.Context, tx pgx.Tx, accountNumber, currency, transactionType string, amount, newBalance, overdraftLimit float64) error {
+	tier, err := getTier(ctx, tx, `
+        SELECT t.name, t.max_per_transaction, t.daily_withdrawal_limit, t.monthly_withdrawal_limit,
+               t.daily_transfer_limit, t.monthly_transfer_limit, t.min_balance, t.currency, t.updated_at
+        FROM usersschema.accounts a
+        JOIN usersschema.account_tiers t ON t.name = a.tier
+        WHERE a.account_number = $1`, accountNumber)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("account not found: %s", accountNumber)
+		}
+		return fmt.Errorf("failed to load account tier: %w", err)
+	}
+	rate, err := lookupRate(ctx, tx, currency, tier.Currency)
+	if err != nil {
+		return fmt.Errorf("failed to convert to the %s tier currency: %w", tier.Name, err)
+	}
+	amount, newBalance = models.ConvertAmount(amount, rate), models.ConvertAmount(newBalance, rate)
+
+	if amount > tier.MaxPerTransaction {
+		return fmt.Errorf("amount %.2f %s exceeds the %s tier per-transaction maximum of %.2f %s",
+			amount, tier.Currency, tier.Name, tier.MaxPerTransaction, tier.Currency)
+	}
+	if overdraftLimit == 0 && newBalance < tier.MinBalance {
+		return fmt.Errorf("balance after %s would be %.2f %s, below the %s tier minimum of %.2f %s",
+			transactionType, newBalance, tier.Currency, tier.Name, tier.MinBalance, tier.Currency)
+	}
+
+	// external transfers count towards the transfer limits
+	dailyLimit, monthlyLimit := tier.DailyWithdrawalLimit, tier.MonthlyWithdrawalLimit
+	countedTypes := []string{transactionType}
+	if transactionType == "transfer" {
+		dailyLimit, monthlyLimit = tier.DailyTransferLimit, tier.MonthlyTransferLimit
+		countedTypes = append(countedTypes, "external_transfer")
+	}
+
+	// the part of a transaction refunded by reversals no longer counts
+	usageQuery := `
+        SELECT COALESCE(SUM(amount - reversed_amount) FILTER (WHERE created_at >= date_trunc('day', NOW())), 0),
+               COALESCE(SUM(amount - reversed_amount), 0)
+        FROM usersschema.transactions
+        WHERE from_account_id = $1
+          AND transaction_type = ANY($2)
+          AND status = 'completed'
+          AND created_at >= date_trunc('month', NOW())`
+
+	// debits are always in the account currency
+	var usedToday, usedThisMonth float64
+	if err := tx.QueryRow(ctx, usageQuery, accountNumber, countedTypes).Scan(&usedToday, &usedThisMonth); err != nil {
+		return fmt.Errorf("failed to load %s usage: %w", transactionType, err)
+	}
+	usedToday, usedThisMonth = models.ConvertAmount(usedToday, rate), models.ConvertAmount(usedThisMonth, rate)
+	if usedToday+amount > dailyLimit {
+		return fmt.Errorf("daily %s limit of %.2f %s exceeded: used %.2f, attempted %.2f",
+			transactionType, dailyLimit, tier.Currency, usedToday, amount)
+	}
+	if usedThisMonth+amount > monthlyLimit {
+		return fmt.Errorf("monthly %s limit of %.2f %s exceeded: used %.2f, attempted %.2f",
+			transactionType, monthlyLimit, tier.Currency, usedThisMonth, amount)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"transactionService/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithdrawalWithinOverdraft tests that an account with an overdraft can go below zero, and
+// below its tier minimum, down to its overdraft limit but no further
+func TestWithdrawalWithinOverdraft(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	seedAccounts(t, db, map[string]float64{"ACC1": 50, "ACC2": 50})
+	_, err := db.Pool().Exec(ctx, "UPDATE usersschema.accounts SET tier = 'premium', overdraft_limit = CASE account_number WHEN 'ACC1' THEN 200 ELSE 0 END")
+	require.NoError(t, err)
+
+	repo := NewUserRepository(db)
+	require.NoError(t, repo.TransactionRouter(ctx, &models.Transaction{FromAccountID: "ACC1", Amount: 150, TransactionType: "withdrawal"}))
+	var balance, used float64
+	require.NoError(t, db.Pool().QueryRow(ctx, "SELECT balance, overdraft_used FROM usersschema.accounts WHERE account_number = 'ACC1'").Scan(&balance, &used))
+	assert.Equal(t, -100.0, balance)
+	assert.Equal(t, 100.0, used)
+
+	assert.ErrorContains(t, repo.TransactionRouter(ctx, &models.Transaction{FromAccountID: "ACC1", Amount: 150, TransactionType: "withdrawal"}), "insufficient funds")
+
+	// without an overdraft the premium tier minimum of 500 still applies
+	assert.ErrorContains(t, repo.TransactionRouter(ctx, &models.Transaction{FromAccountID: "ACC2", Amount: 10, TransactionType: "withdrawal"}), "below the premium tier minimum")
+}
+
+// TestDepositFeeWithinLimits tests that a deposit whose fee is larger than the deposit only
+// takes the difference out of the account within its overdraft and tier minimum
+func TestDepositFeeWithinLimits(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	seedAccounts(t, db, map[string]float64{"ACC1": 0, "ACC2": 0})
+	_, err := db.Pool().Exec(ctx, "UPDATE usersschema.accounts SET overdraft_limit = 10 WHERE account_number = 'ACC2'")
+	require.NoError(t, err)
+	_, err = db.Pool().Exec(ctx, "INSERT INTO usersschema.fee_rules (name, transaction_type, fee_type, amount) VALUES ('Deposit fee', 'deposit', 'flat', 5)")
+	require.NoError(t, err)
+
+	repo := NewUserRepository(db)
+	assert.ErrorContains(t, repo.TransactionRouter(ctx, &models.Transaction{FromAccountID: "ACC1", Amount: 2, TransactionType: "deposit"}), "insufficient funds")
+	require.NoError(t, repo.TransactionRouter(ctx, &models.Transaction{FromAccountID: "ACC2", Amount: 2, TransactionType: "deposit"}))
+
+	balances := map[string]float64{}
+	rows, err := db.Pool().Query(ctx, "SELECT account_number, balance FROM usersschema.accounts WHERE account_number IN ('ACC1', 'ACC2')")
+	require.NoError(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var account string
+		var balance float64
+		require.NoError(t, rows.Scan(&account, &balance))
+		balances[account] = balance
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, 0.0, balances["ACC1"])
+	assert.Equal(t, -3.0, balances["ACC2"])
+}
@@ -3,13 +3,20 @@ package repositories
 import (
 	// "accountservice/database"
 	"context"
+	"errors"
 	"fmt"
 	"transactionService/database"
 	"transactionService/models"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
+// ErrDuplicateTransaction is returned when a transaction ID has already been applied. Producers
+// that can emit the same message twice (such as standing order replicas) rely on it for idempotency.
+var ErrDuplicateTransaction = errors.New("transaction already applied")
+
 // UserRepository implements Repository for User entities
 type TransactionRepository struct {
 	db *database.PostgresPoolDB
@@ -21,24 +28,59 @@ func NewUserRepository(db *database.PostgresPoolDB) *TransactionRepository {
 }
 
 func (r *TransactionRepository) TransactionRouter(ctx context.Context, transmodel *models.Transaction) error {
+	// every applied transaction is recorded under its ID
+	if transmodel.ID == uuid.Nil {
+		transmodel.ID = uuid.New()
+	} else if applied, err := r.transactionExists(ctx, transmodel.ID); err != nil {
+		return err
+	} else if applied {
+		return fmt.Errorf("%w: %s", ErrDuplicateTransaction, transmodel.ID)
+	}
+	// filled in as the balances move
+	transmodel.Postings = nil
+
+	// reversals reference the original transaction instead of an account
+	if transmodel.TransactionType == "reversal" {
+		return r.Reverse(ctx, transmodel)
+	}
+
 	// check account exists or not
 	_, err := r.CheckAccountExists(ctx, transmodel.FromAccountID)
 	if err != nil {
 		return err
 	}
 
-	if transmodel.TransactionType == "deposit" {
-		err := r.Credit(ctx, transmodel.FromAccountID, transmodel.Amount)
+	// transfers to payees are only made once the payee is out of its cooling-off period
+	if transmodel.TransactionType == "transfer" || transmodel.TransactionType == "external_transfer" {
+		if err := r.requirePayee(ctx, transmodel); err != nil {
+			return err
+		}
+	}
+
+	// on accounts with several holders, viewers can see the account but not move money out of it
+	if transmodel.InitiatedBy != "" && (transmodel.TransactionType == "withdrawal" || transmodel.TransactionType == "transfer" || transmodel.TransactionType == "external_transfer") {
+		if err := r.requireSigner(ctx, transmodel.FromAccountID, transmodel.InitiatedBy); err != nil {
+			return err
+		}
+	}
+
+	if transmodel.TransactionType == "deposit" || transmodel.TransactionType == "interest" {
+		err := r.Credit(ctx, transmodel)
 		if err != nil {
 			return err
 		}
 	} else if transmodel.TransactionType == "withdrawal" {
-		err := r.Debit(ctx, transmodel.FromAccountID, transmodel.Amount)
+		err := r.Debit(ctx, transmodel)
 		if err != nil {
 			return err
 		}
 	} else if transmodel.TransactionType == "transfer" {
-		err := r.TransferAmount(ctx, transmodel.FromAccountID, transmodel.ToAccountID, transmodel.Amount)
+		err := r.TransferAmount(ctx, transmodel)
+		if err != nil {
+			return err
+		}
+	} else if transmodel.TransactionType == "external_transfer" {
+		err := r.ExternalTransfer(ctx, transmodel)
 		if err != nil {
 			return err
 		}
@@ -46,6 +88,89 @@ func (r *TransactionRepository) TransactionRouter(ctx context.Context, transmode
 	return nil
 }
 
+// transactionExists checks whether a transaction with the given ID has been recorded
+func (r *TransactionRepository) transactionExists(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := "SELECT EXISTS(SELECT 1 FROM usersschema.transactions WHERE id = $1)"
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	var exists bool
+	if err = conn.QueryRow(ctx, query, id).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check transaction existence: %w", err)
+	}
+	return exists, nil
+}
+
+// requirePayee applies the payee rules to a transfer
+func (r *TransactionRepository) requirePayee(ctx context.Context, trans *models.Transaction) error {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+	return checkPayee(ctx, conn, trans)
+}
+
+// requireSigner fails unless the customer is a verified owner or joint holder of the account
+func (r *TransactionRepository) requireSigner(ctx context.Context, accountNumber, customerID string) error {
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+	return canSign(ctx, conn, accountNumber, customerID)
+}
+
+// customerInitiated reports whether transactions of a type are made by a customer, so every
+// account they involve must be active and belong to a verified customer
+func customerInitiated(transactionType string) bool {
+	switch transactionType {
+	case "deposit", "withdrawal", "transfer", "external_transfer":
+		return true
+	}
+	return false
+}
+
+// internalAccount reports whether an account is one of the bank's own, which have no customer
+func internalAccount(accountNumber string) bool {
+	return accountNumber == FeeIncomeAccount || accountNumber == ClearingSuspenseAccount
+}
+
+// requireVerifiedCustomer fails unless the account is active and belongs to a verified customer;
+// internal accounts, such as the fee income a saga pays fees into, only need to be active.
+// Callers hold the account row lock, so a freeze committed before it cannot be missed.
+func requireVerifiedCustomer(ctx context.Context, q rowQuerier, accountNumber string) error {
+	query := `
+        SELECT a.is_active, c.kyc_status
+        FROM usersschema.accounts a
+        LEFT JOIN usersschema.customers c ON c.id = a.customer_id
+        WHERE a.account_number = $1`
+	var active bool
+	var status *string
+	if err := q.QueryRow(ctx, query, accountNumber).Scan(&active, &status); err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("account not found: %s", accountNumber)
+		}
+		return fmt.Errorf("failed to check customer verification: %w", err)
+	}
+	if !active {
+		return fmt.Errorf("account %s is inactive", accountNumber)
+	}
+	if internalAccount(accountNumber) {
+		return nil
+	}
+	if status == nil {
+		return fmt.Errorf("account %s is not linked to a customer", accountNumber)
+	}
+	if *status != "verified" {
+		return fmt.Errorf("customer of account %s is %s, only verified customers can transact", accountNumber, *status)
+	}
+	return nil
+}
+
 // CheckAccountExists checks if an account exists based on account_number
 func (r *TransactionRepository) CheckAccountExists(ctx context.Context, accountNumber string) (bool, error) {
 	query := "SELECT EXISTS(SELECT 1 FROM usersschema.accounts WHERE account_number = $1)"
@@ -63,8 +188,42 @@ func (r *TransactionRepository) CheckAccountExists(ctx context.Context, accountN
 	return exists, nil
 }
 
+// GetAccount returns the account read model with its ledger and available balances, or nil if it does not exist.
+// The available balance is the ledger balance plus unused overdraft minus funds reserved by active holds.
+func (r *TransactionRepository) GetAccount(ctx context.Context, accountNumber string) (*models.Account, error) {
+	query := `
+        SELECT id, account_number, username, email, balance, overdraft_limit, overdraft_interest_rate,
+               overdraft_used, created_at, updated_at, is_active, tier, currency,
+               COALESCE(interest_plan, ''), customer_id
+        FROM usersschema.accounts
+        WHERE account_number = $1`
+	conn, err := r.db.Pool().Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	account := &models.Account{}
+	err = conn.QueryRow(ctx, query, accountNumber).Scan(&account.ID, &account.AccountNumber, &account.Username, &account.Email,
+		&account.Balance, &account.OverdraftLimit, &account.OverdraftInterestRate, &account.OverdraftUsed,
+		&account.CreatedAt, &account.UpdatedAt, &account.IsActive, &account.Tier, &account.Currency, &account.InterestPlan, &account.CustomerID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	if account.HeldAmount, err = activeHolds(ctx, conn, accountNumber); err != nil {
+		return nil, err
+	}
+	account.AvailableBalance = account.Balance + account.OverdraftLimit - account.HeldAmount
+	return account, nil
+}
+
 // UpdateBalance updates account balance with transaction support for ACID compliance
-func (r *TransactionRepository) UpdateBalance(ctx context.Context, accountNumber string, amount float64, isCredit bool) error {
+func (r *TransactionRepository) UpdateBalance(ctx context.Context, trans *models.Transaction, isCredit bool) error {
+	accountNumber, amount := trans.FromAccountID, trans.Amount
+
 	// Get a connection and start a transaction
 	conn, err := r.db.Pool().Acquire(ctx)
 	if err != nil {
@@ -87,13 +246,14 @@ func (r *TransactionRepository) UpdateBalance(ctx context.Context, accountNumber
 
 	// Lock the row for update to ensure consistency
 	query := `
-        SELECT balance 
+        SELECT balance, overdraft_limit, currency
         FROM usersschema.accounts 
         WHERE account_number = $1 
         FOR UPDATE`
 
-	var currentBalance float64
-	err = tx.QueryRow(ctx, query, accountNumber).Scan(&currentBalance)
+	var currentBalance, overdraftLimit float64
+	var currency string
+	err = tx.QueryRow(ctx, query, accountNumber).Scan(&currentBalance, &overdraftLimit, &currency)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return fmt.Errorf("account not found: %s", accountNumber)
@@ -101,32 +261,71 @@ func (r *TransactionRepository) UpdateBalance(ctx context.Context, accountNumber
 		return fmt.Errorf("failed to get current balance: %w", err)
 	}
 
-	// Calculate new balance
-	newBalance := currentBalance
+	// Deposits and withdrawals are never converted, so they must be in the account currency
+	if err = matchCurrency(trans, accountNumber, currency); err != nil {
+		return err
+	}
+	if customerInitiated(trans.TransactionType) {
+		if err = requireVerifiedCustomer(ctx, tx, accountNumber); err != nil {
+			return err
+		}
+	}
+
+	// Fees are taken from the same account on top of the principal
+	var feeTotal float64
+	if feeTotal, err = assessFees(ctx, tx, trans); err != nil {
+		return err
+	}
+
+	// Calculate new balance. A credit whose fees come to more than the credit itself takes the
+	// difference out of the account, so it is checked like a debit of that difference.
+	newBalance := currentBalance - feeTotal
+	debit := amount
 	if isCredit {
 		newBalance += amount
+		debit = feeTotal - amount
 	} else {
 		newBalance -= amount
-		if newBalance < 0 {
-			return fmt.Errorf("insufficient funds: current balance %.2f, attempted debit %.2f",
-				currentBalance, amount)
+	}
+	if debit > 0 {
+		// Funds reserved by active holds are not available for the debit
+		var held float64
+		if held, err = activeHolds(ctx, tx, accountNumber); err != nil {
+			return err
+		}
+		// The balance may go negative down to the overdraft limit
+		if newBalance-held < -overdraftLimit {
+			err = fmt.Errorf("insufficient funds: current balance %.2f, held %.2f, overdraft limit %.2f, attempted debit %.2f, fees %.2f",
+				currentBalance, held, overdraftLimit, debit, feeTotal)
+			return err
+		}
+		// Enforce the tier limits while the row is locked
+		if err = enforceTierLimits(ctx, tx, accountNumber, currency, "withdrawal", debit, newBalance-held, overdraftLimit); err != nil {
+			return err
 		}
 	}
 
-	// Update balance and updated_at timestamp
-	updateQuery := `
-        UPDATE usersschema.accounts 
-        SET balance = $1, 
-            updated_at = NOW() 
-        WHERE account_number = $2`
-
-	result, err := tx.Exec(ctx, updateQuery, newBalance, accountNumber)
-	if err != nil {
-		return fmt.Errorf("failed to update balance: %w", err)
+	// Append the movement and its fees to the account's events
+	if trans.ID == uuid.Nil {
+		trans.ID = uuid.New()
+	}
+	delta := -amount
+	if isCredit {
+		delta = amount
+	}
+	if err = moveBalance(ctx, tx, trans, accountNumber, delta, trans.TransactionType); err != nil {
+		return err
+	}
+	if err = moveBalance(ctx, tx, trans, accountNumber, -feeTotal, "fee"); err != nil {
+		return err
 	}
 
-	if result.RowsAffected() == 0 {
-		return fmt.Errorf("account not found during update: %s", accountNumber)
+	// Record the movement so it counts towards the daily and monthly limits
+	if err = recordTransaction(ctx, tx, trans); err != nil {
+		return err
+	}
+	if err = postFees(ctx, tx, trans); err != nil {
+		return err
 	}
 
 	// Commit transaction
@@ -138,23 +337,31 @@ func (r *TransactionRepository) UpdateBalance(ctx context.Context, accountNumber
 }
 
 // Debit
-func (r *TransactionRepository) Debit(ctx context.Context, accountNumber string, amount float64) error {
-	if amount < 0 {
-		return fmt.Errorf("debit amount cannot be negative: %.2f", amount)
+func (r *TransactionRepository) Debit(ctx context.Context, trans *models.Transaction) error {
+	if trans.Amount <= 0 {
+		return fmt.Errorf("debit amount must be positive: %.2f", trans.Amount)
 	}
-	return r.UpdateBalance(ctx, accountNumber, amount, false)
+	return r.UpdateBalance(ctx, trans, false)
 }
 
 // credit
-func (r *TransactionRepository) Credit(ctx context.Context, accountNumber string, amount float64) error {
-	if amount < 0 {
-		return fmt.Errorf("credit amount cannot be negative: %.2f", amount)
+func (r *TransactionRepository) Credit(ctx context.Context, trans *models.Transaction) error {
+	if trans.Amount <= 0 {
+		return fmt.Errorf("credit amount must be positive: %.2f", trans.Amount)
 	}
-	return r.UpdateBalance(ctx, accountNumber, amount, true)
+	return r.UpdateBalance(ctx, trans, true)
 }
 
 // TransferAmount transfers money from one account to another with ACID compliance
-func (r *TransactionRepository) TransferAmount(ctx context.Context, fromAccountNumber, toAccountNumber string, amount float64) error {
+func (r *TransactionRepository) TransferAmount(ctx context.Context, trans *models.Transaction) error {
+	return r.transfer(ctx, trans, nil)
+}
+
+// transfer moves money between two accounts. When record is set it runs in the same database
+// transaction after the movement has been recorded, so both commit or roll back together.
+func (r *TransactionRepository) transfer(ctx context.Context, trans *models.Transaction, record func(tx pgx.Tx) error) error {
+	fromAccountNumber, toAccountNumber, amount := trans.FromAccountID, trans.ToAccountID, trans.Amount
+
 	// Validate input
 	if amount <= 0 {
 		return fmt.Errorf("transfer amount must be positive: %.2f", amount)
@@ -185,7 +392,7 @@ func (r *TransactionRepository) TransferAmount(ctx context.Context, fromAccountN
 
 	// Lock both accounts for update to prevent race conditions
 	// Order by account_number to avoid deadlocks (consistent locking order)
-	query := "SELECT account_number, balance FROM usersschema.accounts WHERE account_number IN ($1, $2) FOR UPDATE ORDER BY account_number"
+	query := "SELECT account_number, balance, overdraft_limit, currency FROM usersschema.accounts WHERE account_number IN ($1, $2) ORDER BY account_number FOR UPDATE"
 
 	rows, err := tx.Query(ctx, query, fromAccountNumber, toAccountNumber)
 	if err != nil {
@@ -195,56 +402,115 @@ func (r *TransactionRepository) TransferAmount(ctx context.Context, fromAccountN
 
 	// Collect balances and verify both accounts exist
 	balances := make(map[string]float64)
+	overdraftLimits := make(map[string]float64)
+	currencies := make(map[string]string)
 	for rows.Next() {
-		var accountNumber string
-		var balance float64
-		if err := rows.Scan(&accountNumber, &balance); err != nil {
+		var accountNumber, currency string
+		var balance, overdraftLimit float64
+		if err = rows.Scan(&accountNumber, &balance, &overdraftLimit, &currency); err != nil {
 			return fmt.Errorf("failed to scan account data: %w", err)
 		}
 		balances[accountNumber] = balance
+		overdraftLimits[accountNumber] = overdraftLimit
+		currencies[accountNumber] = currency
 	}
-	if err := rows.Err(); err != nil {
+	if err = rows.Err(); err != nil {
 		return fmt.Errorf("error iterating account rows: %w", err)
 	}
+	rows.Close()
 
 	// Check if both accounts were found
 	if _, fromExists := balances[fromAccountNumber]; !fromExists {
-		return fmt.Errorf("source account not found: %s", fromAccountNumber)
+		err = fmt.Errorf("source account not found: %s", fromAccountNumber)
+		return err
 	}
 	if _, toExists := balances[toAccountNumber]; !toExists {
-		return fmt.Errorf("destination account not found: %s", toAccountNumber)
+		err = fmt.Errorf("destination account not found: %s", toAccountNumber)
+		return err
+	}
+
+	// Customer transfers need both accounts active and verified, external ones only the source
+	if customerInitiated(trans.TransactionType) {
+		if err = requireVerifiedCustomer(ctx, tx, fromAccountNumber); err != nil {
+			return err
+		}
+		if trans.TransactionType == "transfer" {
+			if err = requireVerifiedCustomer(ctx, tx, toAccountNumber); err != nil {
+				return err
+			}
+		}
+	}
+
+	// The amount is debited in the source currency and converted if the destination holds another one
+	if err = matchCurrency(trans, fromAccountNumber, currencies[fromAccountNumber]); err != nil {
+		return err
+	}
+	creditAmount := amount
+	if toCurrency := currencies[toAccountNumber]; toCurrency != trans.Currency {
+		var rate float64
+		if rate, err = lookupRate(ctx, tx, trans.Currency, toCurrency); err != nil {
+			return err
+		}
+		creditAmount = models.ConvertAmount(amount, rate)
+		if creditAmount <= 0 {
+			err = fmt.Errorf("transfer amount %.2f %s converts to nothing in %s", amount, trans.Currency, toCurrency)
+			return err
+		}
+		trans.FxRate, trans.ConvertedAmount, trans.ConvertedCurrency = rate, creditAmount, toCurrency
+	}
+
+	// Fees are taken from the source account on top of the principal
+	var feeTotal float64
+	if feeTotal, err = assessFees(ctx, tx, trans); err != nil {
+		return err
 	}
 
-	// Verify sufficient funds
+	// Verify sufficient available funds, honouring active holds and allowing the source to draw on its overdraft
 	fromBalance := balances[fromAccountNumber]
-	if fromBalance < amount {
-		return fmt.Errorf("insufficient funds in %s: current balance %.2f, transfer amount %.2f",
-			fromAccountNumber, fromBalance, amount)
-	}
-
-	// Calculate new balances
-	newFromBalance := fromBalance - amount
-	newToBalance := balances[toAccountNumber] + amount
-
-	// Update both accounts in a single transaction
-	updateQuery := `
-	    UPDATE usersschema.accounts
-	    SET balance = CASE
-	                    WHEN account_number = $1 THEN $2
-	                    WHEN account_number = $3 THEN $4
-	                  END,
-	        updated_at = NOW()
-	    WHERE account_number IN ($1, $3)`
-
-	result, err := tx.Exec(ctx, updateQuery, fromAccountNumber, newFromBalance, toAccountNumber, newToBalance)
-	if err != nil {
-		return fmt.Errorf("failed to update balances: %w", err)
+	fromOverdraftLimit := overdraftLimits[fromAccountNumber]
+	var fromHeld float64
+	if fromHeld, err = activeHolds(ctx, tx, fromAccountNumber); err != nil {
+		return err
+	}
+	if fromBalance+fromOverdraftLimit-fromHeld < amount+feeTotal {
+		err = fmt.Errorf("insufficient funds in %s: current balance %.2f, held %.2f, overdraft limit %.2f, transfer amount %.2f, fees %.2f",
+			fromAccountNumber, fromBalance, fromHeld, fromOverdraftLimit, amount, feeTotal)
+		return err
 	}
 
-	// Update balance and updated_at timestamp
+	// Calculate the new balance of the source
+	newFromBalance := fromBalance - amount - feeTotal
 
-	if rowsAffected := result.RowsAffected(); rowsAffected != 1 {
-		return fmt.Errorf("expected 2 rows affected, got %d", rowsAffected)
+	// Enforce the tier limits of the source account while both rows are locked
+	if err = enforceTierLimits(ctx, tx, fromAccountNumber, trans.Currency, "transfer", amount, newFromBalance-fromHeld, fromOverdraftLimit); err != nil {
+		return err
+	}
+
+	// Append the debit, its fees and the credit to the accounts' events in a single transaction
+	if trans.ID == uuid.Nil {
+		trans.ID = uuid.New()
+	}
+	if err = moveBalance(ctx, tx, trans, fromAccountNumber, -amount, trans.TransactionType); err != nil {
+		return err
+	}
+	if err = moveBalance(ctx, tx, trans, fromAccountNumber, -feeTotal, "fee"); err != nil {
+		return err
+	}
+	if err = moveBalance(ctx, tx, trans, toAccountNumber, creditAmount, trans.TransactionType); err != nil {
+		return err
+	}
+
+	// Record the movement so it counts towards the daily and monthly limits
+	if err = recordTransaction(ctx, tx, trans); err != nil {
+		return err
+	}
+	if record != nil {
+		if err = record(tx); err != nil {
+			return err
+		}
+	}
+	if err = postFees(ctx, tx, trans); err != nil {
+		return err
 	}
 
 	// Commit transaction
@@ -254,3 +520,47 @@ func (r *TransactionRepository) TransferAmount(ctx context.Context, fromAccountN
 
 	return nil
 }
+
+// recordTransaction stores an applied movement under its transaction ID so that later limit
+// checks and reversals can find it
+func recordTransaction(ctx context.Context, tx pgx.Tx, trans *models.Transaction) error {
+	query := `
+        INSERT INTO usersschema.transactions (id, from_account_id, to_account_id, amount, transaction_type, description, status,
+                                              original_transaction_id, currency, fx_rate, converted_amount, converted_currency)
+        VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, 'completed', NULLIF($7, '')::uuid,
+                COALESCE(NULLIF($8, ''), (SELECT currency FROM usersschema.accounts WHERE account_number = $2)),
+                NULLIF($9, 0::double precision), NULLIF($10, 0::double precision), NULLIF($11, ''))
+        RETURNING currency`
+	if trans.ID == uuid.Nil {
+		trans.ID = uuid.New()
+	}
+	err := tx.QueryRow(ctx, query, trans.ID, trans.FromAccountID, trans.ToAccountID, trans.Amount,
+		trans.TransactionType, trans.Description, trans.OriginalTransactionID, trans.Currency,
+		trans.FxRate, trans.ConvertedAmount, trans.ConvertedCurrency).Scan(&trans.Currency)
+	if err != nil {
+		// A concurrent consumer applied the same ID first; the caller rolls back its movement
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "transactions_pkey" {
+			return fmt.Errorf("%w: %s", ErrDuplicateTransaction, trans.ID)
+		}
+		return fmt.Errorf("failed to record transaction: %w", err)
+	}
+	return nil
+}
+
+// matchCurrency defaults the transaction currency to that of the account and rejects any other
+func matchCurrency(trans *models.Transaction, accountNumber, accountCurrency string) error {
+	if trans.Currency == "" {
+		trans.Currency = accountCurrency
+		return nil
+	}
+	currency, err := models.NormalizeCurrency(trans.Currency)
+	if err != nil {
+		return err
+	}
+	if currency != accountCurrency {
+		return fmt.Errorf("currency mismatch: account %s holds %s, transaction is in %s", accountNumber, accountCurrency, currency)
+	}
+	trans.Currency = currency
+	return nil
+}
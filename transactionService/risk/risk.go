@@ -0,0 +1,63 @@
+// Package risk screens transactions before they touch any balance. A Checker decides whether a
+// transaction is applied straight away, rejected, or parked for a person to review.
+package risk
+
+import (
+	"context"
+	"transactionService/models"
+)
+
+// Decision is the outcome of a risk check
+type Decision string
+
+const (
+	Approve Decision = "approve" // apply the transaction
+	Review  Decision = "review"  // hold the transaction until an operator decides
+	Reject  Decision = "reject"  // refuse the transaction
+)
+
+// severity orders decisions so the strictest one wins when checks are combined
+var severity = map[Decision]int{Approve: 0, Review: 1, Reject: 2}
+
+// Assessment explains a decision
+type Assessment struct {
+	Decision Decision `json:"decision"`
+	Score    int      `json:"score"`   // Sum of the scores of the rules that fired
+	Reasons  []string `json:"reasons"` // One line per rule that fired
+}
+
+// Checker inspects a transaction before it is applied
+type Checker interface {
+	Check(ctx context.Context, trans *models.Transaction) (Assessment, error)
+}
+
+// Chain runs several checkers and keeps the strictest decision, adding up scores and reasons
+type Chain []Checker
+
+// Check implements Checker
+func (c Chain) Check(ctx context.Context, trans *models.Transaction) (Assessment, error) {
+	combined := Assessment{Decision: Approve, Reasons: []string{}}
+	for _, checker := range c {
+		assessment, err := checker.Check(ctx, trans)
+		if err != nil {
+			return Assessment{}, err
+		}
+		combined.merge(assessment)
+	}
+	return combined, nil
+}
+
+// merge folds another assessment into a
+func (a *Assessment) merge(other Assessment) {
+	if severity[other.Decision] > severity[a.Decision] {
+		a.Decision = other.Decision
+	}
+	a.Score += other.Score
+	a.Reasons = append(a.Reasons, other.Reasons...)
+}
+
+// Checked reports whether transactions of the type go through the risk stage. Reversals and
+// interest credits are raised by the bank itself and are never held.
+func Checked(transactionType string) bool {
+	return transactionType == "deposit" || transactionType == "withdrawal" || transactionType == "transfer" || transactionType == "external_transfer"
+}
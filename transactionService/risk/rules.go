@@ -0,0 +1,94 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"transactionService/models"
+)
+
+// Facts is the account history the rules look at
+type Facts struct {
+	RecentDebits       int       // Withdrawals and transfers out within the rapid-fire window
+	LastActivity       time.Time // Latest transaction in or out of the account, or its opening date
+	PriorPayeeTransfer int       // Earlier transfers from the source to the same destination
+}
+
+// FactSource loads the facts for a transaction
+type FactSource interface {
+	Facts(ctx context.Context, trans *models.Transaction, window time.Duration) (Facts, error)
+	// Rate converts the transaction's amount into currency, 0 when no rate is configured
+	Rate(ctx context.Context, trans *models.Transaction, currency string) (float64, error)
+}
+
+// Thresholds configures the built-in rules. A zero threshold disables its rule.
+type Thresholds struct {
+	Currency       string        // Currency the amounts are stated in; empty compares amounts as they are
+	ReviewAmount   float64       // Transactions at or above this amount are reviewed
+	RejectAmount   float64       // Transactions at or above this amount are rejected
+	NewPayeeAmount float64       // Transfers at or above this amount to a first-time payee are reviewed
+	RapidDebits    int           // Debits allowed within RapidWindow before the next one is reviewed
+	RapidWindow    time.Duration // Window for RapidDebits
+	DormantAfter   time.Duration // Accounts idle this long are dormant
+	DormantAmount  float64       // Debits at or above this amount from a dormant account are reviewed
+}
+
+// Rules is the built-in rules engine
+type Rules struct {
+	thresholds Thresholds
+	facts      FactSource
+	now        func() time.Time
+}
+
+// NewRules creates a rules engine reading account history from facts
+func NewRules(thresholds Thresholds, facts FactSource) *Rules {
+	return &Rules{thresholds: thresholds, facts: facts, now: time.Now}
+}
+
+// Check implements Checker
+func (r *Rules) Check(ctx context.Context, trans *models.Transaction) (Assessment, error) {
+	t := r.thresholds
+	assessment := Assessment{Decision: Approve, Reasons: []string{}}
+	fire := func(decision Decision, score int, reason string) {
+		assessment.merge(Assessment{Decision: decision, Score: score, Reasons: []string{reason}})
+	}
+
+	// Amounts are compared in the thresholds' currency; one that cannot be converted is reviewed
+	amount := trans.Amount
+	if t.Currency != "" {
+		rate, err := r.facts.Rate(ctx, trans, t.Currency)
+		if err != nil {
+			return Assessment{}, err
+		}
+		if rate == 0 {
+			fire(Review, 40, fmt.Sprintf("no fx rate converts the amount into %s", t.Currency))
+		}
+		amount = models.ConvertAmount(amount, rate)
+	}
+
+	if t.RejectAmount > 0 && amount >= t.RejectAmount {
+		fire(Reject, 100, fmt.Sprintf("amount %.2f is at or above the rejection threshold of %.2f", amount, t.RejectAmount))
+	} else if t.ReviewAmount > 0 && amount >= t.ReviewAmount {
+		fire(Review, 40, fmt.Sprintf("amount %.2f is at or above the review threshold of %.2f", amount, t.ReviewAmount))
+	}
+
+	// The remaining rules only look at money leaving the account
+	if trans.TransactionType == "deposit" {
+		return assessment, nil
+	}
+	facts, err := r.facts.Facts(ctx, trans, t.RapidWindow)
+	if err != nil {
+		return Assessment{}, err
+	}
+
+	if t.NewPayeeAmount > 0 && trans.TransactionType == "transfer" && facts.PriorPayeeTransfer == 0 && amount >= t.NewPayeeAmount {
+		fire(Review, 30, fmt.Sprintf("first transfer to %s is %.2f, at or above %.2f", trans.ToAccountID, amount, t.NewPayeeAmount))
+	}
+	if t.RapidDebits > 0 && facts.RecentDebits >= t.RapidDebits {
+		fire(Review, 30, fmt.Sprintf("%d debits in the last %s", facts.RecentDebits, t.RapidWindow))
+	}
+	if t.DormantAfter > 0 && !facts.LastActivity.IsZero() && r.now().Sub(facts.LastActivity) >= t.DormantAfter && amount >= t.DormantAmount {
+		fire(Review, 30, fmt.Sprintf("account dormant since %s", facts.LastActivity.Format("2006-01-02")))
+	}
+	return assessment, nil
+}
@@ -0,0 +1,115 @@
+package risk
+
+import (
+	"context"
+	"testing"
+	"time"
+	"transactionService/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeFacts Facts
+
+func (f fakeFacts) Facts(ctx context.Context, trans *models.Transaction, window time.Duration) (Facts, error) {
+	return Facts(f), nil
+}
+
+func (f fakeFacts) Rate(ctx context.Context, trans *models.Transaction, currency string) (float64, error) {
+	return 1, nil
+}
+
+type fakeRate struct {
+	fakeFacts
+	rate float64
+}
+
+func (f fakeRate) Rate(ctx context.Context, trans *models.Transaction, currency string) (float64, error) {
+	return f.rate, nil
+}
+
+var testThresholds = Thresholds{
+	ReviewAmount:   10000,
+	RejectAmount:   100000,
+	NewPayeeAmount: 1000,
+	RapidDebits:    5,
+	RapidWindow:    10 * time.Minute,
+	DormantAfter:   180 * 24 * time.Hour,
+	DormantAmount:  500,
+}
+
+func TestRules(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	active := Facts{LastActivity: now.Add(-time.Hour), PriorPayeeTransfer: 3}
+
+	cases := []struct {
+		name     string
+		trans    models.Transaction
+		facts    Facts
+		decision Decision
+		reasons  int
+	}{
+		{"small withdrawal", models.Transaction{TransactionType: "withdrawal", Amount: 50}, active, Approve, 0},
+		{"large deposit", models.Transaction{TransactionType: "deposit", Amount: 20000}, active, Review, 1},
+		{"huge deposit", models.Transaction{TransactionType: "deposit", Amount: 150000}, active, Reject, 1},
+		{"known payee", models.Transaction{TransactionType: "transfer", ToAccountID: "B", Amount: 5000}, active, Approve, 0},
+		{"new payee", models.Transaction{TransactionType: "transfer", ToAccountID: "B", Amount: 5000},
+			Facts{LastActivity: active.LastActivity}, Review, 1},
+		{"small transfer to new payee", models.Transaction{TransactionType: "transfer", ToAccountID: "B", Amount: 100},
+			Facts{LastActivity: active.LastActivity}, Approve, 0},
+		{"rapid fire", models.Transaction{TransactionType: "withdrawal", Amount: 20},
+			Facts{LastActivity: active.LastActivity, RecentDebits: 5}, Review, 1},
+		{"dormant account", models.Transaction{TransactionType: "withdrawal", Amount: 600},
+			Facts{LastActivity: now.AddDate(-1, 0, 0)}, Review, 1},
+		{"deposit into dormant account", models.Transaction{TransactionType: "deposit", Amount: 600},
+			Facts{LastActivity: now.AddDate(-1, 0, 0)}, Approve, 0},
+		{"several rules", models.Transaction{TransactionType: "transfer", ToAccountID: "B", Amount: 12000},
+			Facts{LastActivity: now.AddDate(-1, 0, 0), RecentDebits: 9}, Review, 4},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rules := NewRules(testThresholds, fakeFacts(c.facts))
+			rules.now = func() time.Time { return now }
+
+			assessment, err := rules.Check(context.Background(), &c.trans)
+			require.NoError(t, err)
+			assert.Equal(t, c.decision, assessment.Decision)
+			assert.Len(t, assessment.Reasons, c.reasons)
+		})
+	}
+}
+
+func TestRulesConvertAmounts(t *testing.T) {
+	thresholds := Thresholds{Currency: "USD", ReviewAmount: 10000, RejectAmount: 100000}
+	cases := []struct {
+		name     string
+		trans    models.Transaction
+		rate     float64
+		decision Decision
+	}{
+		{"below the threshold once converted", models.Transaction{TransactionType: "deposit", Amount: 1000000, Currency: "JPY"}, 0.0067, Approve},
+		{"above the threshold once converted", models.Transaction{TransactionType: "deposit", Amount: 9500, Currency: "EUR"}, 1.1, Review},
+		{"rejected once converted", models.Transaction{TransactionType: "deposit", Amount: 95000, Currency: "EUR"}, 1.1, Reject},
+		{"no rate", models.Transaction{TransactionType: "deposit", Amount: 50, Currency: "XYZ"}, 0, Review},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rules := NewRules(thresholds, fakeRate{rate: c.rate})
+
+			assessment, err := rules.Check(context.Background(), &c.trans)
+			require.NoError(t, err)
+			assert.Equal(t, c.decision, assessment.Decision)
+		})
+	}
+}
+
+func TestChainKeepsStrictestDecision(t *testing.T) {
+	approve := NewRules(Thresholds{}, fakeFacts{})
+	reject := NewRules(Thresholds{RejectAmount: 10}, fakeFacts{})
+
+	assessment, err := Chain{approve, reject}.Check(context.Background(), &models.Transaction{TransactionType: "deposit", Amount: 20})
+	require.NoError(t, err)
+	assert.Equal(t, Reject, assessment.Decision)
+	assert.Equal(t, 100, assessment.Score)
+}
@@ -0,0 +1,85 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"shared/screening"
+	"transactionService/models"
+)
+
+// ScreeningStore looks up the names on accounts and keeps the audit trail of screenings
+type ScreeningStore interface {
+	AccountHolders(ctx context.Context, accountNumbers ...string) (map[string][]string, error)
+	RecordScreening(ctx context.Context, result *models.ScreeningResult) error
+}
+
+// Sanctions screens both parties of a transfer against the watchlist: every name on each account,
+// the customers holding it included. Names are screened on every transfer, not only at account
+// opening, so that additions to the list take effect.
+// A blocked name rejects the transfer and a flagged one holds it for review.
+type Sanctions struct {
+	screener *screening.Screener
+	store    ScreeningStore
+}
+
+// NewSanctions creates a sanctions check
+func NewSanctions(screener *screening.Screener, store ScreeningStore) *Sanctions {
+	return &Sanctions{screener: screener, store: store}
+}
+
+// Check implements Checker
+func (s *Sanctions) Check(ctx context.Context, trans *models.Transaction) (Assessment, error) {
+	assessment := Assessment{Decision: Approve, Reasons: []string{}}
+	if trans.TransactionType != "transfer" && trans.TransactionType != "external_transfer" {
+		return assessment, nil
+	}
+	holders, err := s.store.AccountHolders(ctx, trans.FromAccountID, trans.ToAccountID)
+	if err != nil {
+		return Assessment{}, err
+	}
+
+	// the beneficiary of an external transfer holds no account here and is screened by the name given
+	type party struct{ role, name string }
+	parties := []party{}
+	for _, name := range holders[trans.FromAccountID] {
+		parties = append(parties, party{"payer", name})
+	}
+	if trans.TransactionType == "external_transfer" && trans.Creditor != nil {
+		parties = append(parties, party{"beneficiary", trans.Creditor.Name})
+	} else {
+		for _, name := range holders[trans.ToAccountID] {
+			parties = append(parties, party{"beneficiary", name})
+		}
+	}
+	for _, party := range parties {
+		name := party.name
+		if name == "" {
+			continue
+		}
+		result := s.screener.Screen(name)
+		record := &models.ScreeningResult{
+			Subject:     "transfer",
+			Reference:   trans.ID.String(),
+			Party:       party.role,
+			Name:        name,
+			Decision:    string(result.Decision),
+			Score:       result.Score,
+			EntryID:     result.EntryID,
+			MatchedName: result.MatchedName,
+			Program:     result.Program,
+		}
+		if err := s.store.RecordScreening(ctx, record); err != nil {
+			return Assessment{}, err
+		}
+
+		reason := fmt.Sprintf("%s %q matches watchlist entry %s %q (%s, score %.2f)",
+			party.role, name, result.EntryID, result.MatchedName, result.Program, result.Score)
+		switch result.Decision {
+		case screening.Block:
+			assessment.merge(Assessment{Decision: Reject, Score: 100, Reasons: []string{reason}})
+		case screening.Flag:
+			assessment.merge(Assessment{Decision: Review, Score: 50, Reasons: []string{reason}})
+		}
+	}
+	return assessment, nil
+}
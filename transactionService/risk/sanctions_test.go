@@ -0,0 +1,47 @@
+package risk
+
+import (
+	"context"
+	"shared/screening"
+	"strings"
+	"testing"
+	"transactionService/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStore struct {
+	holders  map[string][]string
+	recorded []models.ScreeningResult
+}
+
+func (f *fakeStore) AccountHolders(ctx context.Context, accountNumbers ...string) (map[string][]string, error) {
+	return f.holders, nil
+}
+
+func (f *fakeStore) RecordScreening(ctx context.Context, result *models.ScreeningResult) error {
+	f.recorded = append(f.recorded, *result)
+	return nil
+}
+
+// TestSanctionsScreensCustomerNames tests that the legal name of the customer holding an account
+// is screened, not only the account's holder name
+func TestSanctionsScreensCustomerNames(t *testing.T) {
+	entries, err := screening.ReadCSV(strings.NewReader("173,\"AL-QAIDA, Osama\",individual,SDGT,Osama bin Laden\n"))
+	require.NoError(t, err)
+	screener := screening.NewScreener(entries, screening.Thresholds{Block: 0.95, Flag: 0.85})
+
+	store := &fakeStore{holders: map[string][]string{
+		"A": {"jsmith", "Jane Smith"},
+		"B": {"obl1957", "Osama bin Laden"},
+	}}
+	sanctions := NewSanctions(screener, store)
+
+	assessment, err := sanctions.Check(context.Background(), &models.Transaction{TransactionType: "transfer", FromAccountID: "A", ToAccountID: "B", Amount: 10})
+	require.NoError(t, err)
+	assert.Equal(t, Reject, assessment.Decision)
+	require.Len(t, assessment.Reasons, 1)
+	assert.Contains(t, assessment.Reasons[0], `beneficiary "Osama bin Laden"`)
+	assert.Len(t, store.recorded, 4)
+}
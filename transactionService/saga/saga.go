@@ -0,0 +1,218 @@
+// Package saga plans multi-step transactions and works out how they move on. Each step posts one
+// transaction through the "transaction" topic and is done when it reaches the ledger; a refused
+// step has the completed steps before it reversed. Every ID is derived from the saga ID, so a
+// command published again after a restart is applied only once.
+package saga
+
+import (
+	"fmt"
+	"time"
+	"transactionService/models"
+
+	"github.com/google/uuid"
+)
+
+// Event is the outcome of a transaction as seen on the ledger topics
+type Event struct {
+	TransactionID uuid.UUID
+	Failed        bool   // Refused and sent to the dead ledger
+	Reason        string // Why it was refused
+}
+
+// Command is a transaction a saga step publishes to the "transaction" topic
+type Command struct {
+	Step        int
+	Transaction models.Transaction
+}
+
+// Plan builds the steps of a saga. Fees are paid into feeAccount.
+func Plan(req models.SagaRequest, feeAccount string, now time.Time) (*models.Saga, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if req.ID == uuid.Nil {
+		req.ID = uuid.New()
+	}
+	s := &models.Saga{
+		ID:        req.ID,
+		Type:      req.Type,
+		Status:    models.SagaRunning,
+		Request:   req,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	// The fee goes first: if it is refused nothing else has happened, and if the transfer is
+	// refused the fee is the one thing to give back
+	if req.Fee > 0 {
+		fee := command(s, "fee", "transfer", now)
+		fee.Command.ToAccountID = feeAccount
+		fee.Command.Amount = req.Fee
+		fee.Command.Description = fmt.Sprintf("Fee for saga %s", s.ID)
+		compensation := stepID(s.ID, "fee:compensation")
+		fee.CompensationID = &compensation
+		s.Steps = append(s.Steps, fee)
+	}
+
+	switch req.Type {
+	case models.SagaTransferWithFee:
+		transfer := command(s, "transfer", "transfer", now)
+		transfer.Command.ToAccountID = req.ToAccountID
+		s.Steps = append(s.Steps, transfer)
+	case models.SagaExternalTransfer:
+		// a returned transfer is credited back by the clearing return, so it needs no compensation
+		transfer := command(s, "external_transfer", "external_transfer", now)
+		transfer.Command.Creditor = req.Creditor
+		transfer.Command.Reference = req.Reference
+		returned := models.ClearingMovementID(transfer.TransactionID, models.ExternalReturned)
+		s.Steps = append(s.Steps, transfer, models.SagaStep{
+			Name:          "settlement",
+			TransactionID: models.ClearingMovementID(transfer.TransactionID, models.ExternalSettled),
+			FailureID:     &returned,
+		})
+	}
+
+	for i := range s.Steps {
+		s.Steps[i].Index = i
+		s.Steps[i].Status = models.StepPending
+		s.Steps[i].UpdatedAt = now
+	}
+	s.Steps[0].Status = models.StepRunning
+	return s, nil
+}
+
+// command builds a step posting a transaction of the request's amount from its source account
+func command(s *models.Saga, name, transactionType string, now time.Time) models.SagaStep {
+	id := stepID(s.ID, name)
+	return models.SagaStep{
+		Name:          name,
+		TransactionID: id,
+		Command: &models.Transaction{
+			ID:              id,
+			FromAccountID:   s.Request.FromAccountID,
+			Amount:          s.Request.Amount,
+			Currency:        s.Request.Currency,
+			TransactionType: transactionType,
+			Description:     s.Request.Description,
+			InitiatedBy:     s.Request.InitiatedBy,
+			CreatedAt:       now,
+			Status:          "pending",
+		},
+	}
+}
+
+// stepID derives the ID of a saga's transaction from the saga ID
+func stepID(sagaID uuid.UUID, name string) uuid.UUID {
+	return uuid.NewSHA1(sagaID, []byte(name))
+}
+
+// Advance applies the outcome of a transaction to the saga and reports whether anything changed.
+// Outcomes of transactions the saga is not waiting for, such as repeated deliveries, are ignored.
+func Advance(s *models.Saga, event Event, now time.Time) bool {
+	for i := range s.Steps {
+		step := &s.Steps[i]
+		switch {
+		case step.Status == models.StepRunning && event.TransactionID == step.TransactionID && !event.Failed:
+			step.Status, step.UpdatedAt = models.StepCompleted, now
+			if i+1 < len(s.Steps) {
+				s.Steps[i+1].Status, s.Steps[i+1].UpdatedAt = models.StepRunning, now
+			} else {
+				s.Status = models.SagaCompleted
+			}
+
+		case step.Status == models.StepRunning && (event.TransactionID == step.TransactionID || matches(step.FailureID, event.TransactionID)):
+			step.Status, step.Error, step.UpdatedAt = models.StepFailed, reason(event), now
+			s.Error = fmt.Sprintf("%s failed: %s", step.Name, step.Error)
+			compensate(s, now)
+
+		case step.Status == models.StepCompensating && matches(step.CompensationID, event.TransactionID):
+			if event.Failed {
+				step.Error, step.UpdatedAt = reason(event), now
+				s.Status = models.SagaFailed
+				s.Error = fmt.Sprintf("%s; compensation of %s failed: %s", s.Error, step.Name, step.Error)
+				break
+			}
+			step.Status, step.UpdatedAt = models.StepCompensated, now
+			compensate(s, now)
+
+		default:
+			continue
+		}
+		s.UpdatedAt = now
+		return true
+	}
+	return false
+}
+
+// compensate starts reversing the last completed step that needs it, or ends the saga when none is left
+func compensate(s *models.Saga, now time.Time) {
+	for i := len(s.Steps) - 1; i >= 0; i-- {
+		step := &s.Steps[i]
+		if step.Status == models.StepCompleted && step.CompensationID != nil {
+			step.Status, step.Attempts, step.PublishedAt, step.UpdatedAt = models.StepCompensating, 0, nil, now
+			s.Status = models.SagaCompensating
+			return
+		}
+	}
+	s.Status = models.SagaCompensated
+}
+
+// MaxAttempts is how many times a command is published before the saga just waits for its
+// outcome, e.g. while the transaction is held for a risk review
+const MaxAttempts = 5
+
+// Commands returns the commands and compensations the saga is waiting on that were not published
+// since before, up to MaxAttempts times. Pass the zero time for those never published.
+func Commands(s *models.Saga, before time.Time) []Command {
+	var commands []Command
+	for _, step := range s.Steps {
+		if step.PublishedAt != nil && (!step.PublishedAt.Before(before) || step.Attempts >= MaxAttempts) {
+			continue
+		}
+		switch {
+		case step.Status == models.StepRunning && step.Command != nil:
+			commands = append(commands, Command{Step: step.Index, Transaction: *step.Command})
+		case step.Status == models.StepCompensating:
+			commands = append(commands, Command{Step: step.Index, Transaction: models.Transaction{
+				ID:                    *step.CompensationID,
+				TransactionType:       "reversal",
+				OriginalTransactionID: step.TransactionID.String(),
+				Description:           fmt.Sprintf("Compensation of %s in saga %s", step.Name, s.ID),
+				CreatedAt:             step.UpdatedAt,
+				Status:                "pending",
+			}})
+		}
+	}
+	return commands
+}
+
+// Awaited returns the transactions whose outcome the saga is waiting for
+func Awaited(s *models.Saga) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, step := range s.Steps {
+		switch step.Status {
+		case models.StepRunning:
+			ids = append(ids, step.TransactionID)
+			if step.FailureID != nil {
+				ids = append(ids, *step.FailureID)
+			}
+		case models.StepCompensating:
+			ids = append(ids, *step.CompensationID)
+		}
+	}
+	return ids
+}
+
+func matches(id *uuid.UUID, transactionID uuid.UUID) bool {
+	return id != nil && *id == transactionID
+}
+
+func reason(event Event) string {
+	if event.Reason != "" {
+		return event.Reason
+	}
+	if event.Failed {
+		return "refused by the transaction service"
+	}
+	return "returned by the clearing system"
+}
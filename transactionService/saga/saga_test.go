@@ -0,0 +1,106 @@
+package saga
+
+import (
+	"testing"
+	"time"
+	"transactionService/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+
+func transferWithFee(t *testing.T) *models.Saga {
+	s, err := Plan(models.SagaRequest{
+		Type: models.SagaTransferWithFee, FromAccountID: "ACC1", ToAccountID: "ACC2", Amount: 100, Fee: 2.5,
+	}, "FEE-INCOME", now)
+	require.NoError(t, err)
+	return s
+}
+
+// TestTransferWithFeeCompletes tests that steps run one after the other and commands are published once
+func TestTransferWithFeeCompletes(t *testing.T) {
+	s := transferWithFee(t)
+	require.Len(t, s.Steps, 2)
+
+	commands := Commands(s, time.Time{})
+	require.Len(t, commands, 1)
+	assert.Equal(t, "FEE-INCOME", commands[0].Transaction.ToAccountID)
+	assert.Equal(t, 2.5, commands[0].Transaction.Amount)
+
+	published := now
+	s.Steps[0].PublishedAt, s.Steps[0].Attempts = &published, 1
+	assert.Empty(t, Commands(s, time.Time{}))
+
+	assert.True(t, Advance(s, Event{TransactionID: s.Steps[0].TransactionID}, now))
+	assert.False(t, Advance(s, Event{TransactionID: s.Steps[0].TransactionID}, now), "repeated delivery")
+	commands = Commands(s, time.Time{})
+	require.Len(t, commands, 1)
+	assert.Equal(t, "ACC2", commands[0].Transaction.ToAccountID)
+	assert.Equal(t, s.Steps[1].TransactionID, commands[0].Transaction.ID)
+
+	assert.True(t, Advance(s, Event{TransactionID: s.Steps[1].TransactionID}, now))
+	assert.Equal(t, models.SagaCompleted, s.Status)
+}
+
+// TestRefusedTransferRefundsFee tests that the fee is reversed when the transfer is refused
+func TestRefusedTransferRefundsFee(t *testing.T) {
+	s := transferWithFee(t)
+	Advance(s, Event{TransactionID: s.Steps[0].TransactionID}, now)
+	Advance(s, Event{TransactionID: s.Steps[1].TransactionID, Failed: true}, now)
+
+	assert.Equal(t, models.SagaCompensating, s.Status)
+	assert.Equal(t, models.StepFailed, s.Steps[1].Status)
+	commands := Commands(s, time.Time{})
+	require.Len(t, commands, 1)
+	assert.Equal(t, "reversal", commands[0].Transaction.TransactionType)
+	assert.Equal(t, s.Steps[0].TransactionID.String(), commands[0].Transaction.OriginalTransactionID)
+
+	assert.True(t, Advance(s, Event{TransactionID: *s.Steps[0].CompensationID}, now))
+	assert.Equal(t, models.SagaCompensated, s.Status)
+	assert.Equal(t, "transfer failed: refused by the transaction service", s.Error)
+
+	// a refused compensation needs an operator
+	s = transferWithFee(t)
+	Advance(s, Event{TransactionID: s.Steps[0].TransactionID}, now)
+	Advance(s, Event{TransactionID: s.Steps[1].TransactionID, Failed: true}, now)
+	Advance(s, Event{TransactionID: *s.Steps[0].CompensationID, Failed: true}, now)
+	assert.Equal(t, models.SagaFailed, s.Status)
+}
+
+// TestExternalTransferReturned tests that a clearing return fails the settlement step and refunds the fee
+func TestExternalTransferReturned(t *testing.T) {
+	s, err := Plan(models.SagaRequest{
+		Type: models.SagaExternalTransfer, FromAccountID: "ACC1", Amount: 100, Fee: 5,
+		Creditor: &models.Party{Name: "John Smith", IBAN: "GB33BUKB20201555555555", BIC: "BUKBGB22"},
+	}, "FEE-INCOME", now)
+	require.NoError(t, err)
+	require.Len(t, s.Steps, 3)
+	transfer := s.Steps[1].TransactionID
+
+	Advance(s, Event{TransactionID: s.Steps[0].TransactionID}, now)
+	Advance(s, Event{TransactionID: transfer}, now)
+	assert.Empty(t, Commands(s, time.Time{}), "settlement is waited for")
+	assert.ElementsMatch(t, Awaited(s), []uuid.UUID{
+		models.ClearingMovementID(transfer, models.ExternalSettled),
+		models.ClearingMovementID(transfer, models.ExternalReturned),
+	})
+
+	Advance(s, Event{TransactionID: models.ClearingMovementID(transfer, models.ExternalReturned)}, now)
+	assert.Equal(t, models.StepFailed, s.Steps[2].Status)
+	assert.Equal(t, models.StepCompleted, s.Steps[1].Status, "the clearing return already credited the customer")
+	assert.Equal(t, models.StepCompensating, s.Steps[0].Status)
+}
+
+// TestCommandsRetry tests that commands are published again until MaxAttempts
+func TestCommandsRetry(t *testing.T) {
+	s := transferWithFee(t)
+	published := now
+	s.Steps[0].PublishedAt, s.Steps[0].Attempts = &published, 1
+	assert.Len(t, Commands(s, now.Add(time.Minute)), 1)
+
+	s.Steps[0].Attempts = MaxAttempts
+	assert.Empty(t, Commands(s, now.Add(time.Minute)))
+}